@@ -0,0 +1,302 @@
+// Command mcclient is an interactive client that connects to a server using minecraft.Dialer, prints the
+// packets it receives and sends packets that are typed in as JSON. It serves both as an example of using a
+// minecraft.Conn and as a console for debugging the protocol of a server.
+//
+// Usage:
+//
+//	mcclient [-auth] [-show Text,SetTime] [-hide MovePlayer] [-dump] play.example.com:19132
+//
+// Once connected, the following commands are read from stdin:
+//
+//	send <packet> <json>   send a packet, for example: send Text {"TextType": 1, "Message": "hello"}
+//	show <packet>...       only print the packets passed, in addition to packets already shown
+//	hide <packet>...       stop printing the packets passed
+//	filter                 print the current filter
+//	clear                  clear the filter, so that all packets are printed
+//	packets                list the names of all packets that may be sent
+//	help                   print this list of commands
+//	quit                   disconnect and exit
+//
+// Received packets are printed as JSON in the same format that send accepts, so that a packet received may
+// be edited and sent back.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+func main() {
+	var (
+		useAuth = flag.Bool("auth", false, "log in with a Microsoft account")
+		show    = flag.String("show", "", "comma separated names of the only packets to print")
+		hide    = flag.String("hide", "", "comma separated names of packets not to print")
+		dump    = flag.Bool("dump", false, "print packets with a breakdown of their fields and payload instead of as JSON")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] <address>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	received, sent := newNames(packet.NewServerPool()), newNames(packet.NewClientPool())
+	f := &filter{show: map[string]bool{}, hide: map[string]bool{}}
+	for _, s := range []struct {
+		list string
+		m    map[string]bool
+	}{{*show, f.show}, {*hide, f.hide}} {
+		for _, name := range strings.Split(s.list, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if _, ok := received.ids[name]; !ok {
+					log.Fatalf("unknown packet %v", name)
+				}
+				s.m[name] = true
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	d := minecraft.Dialer{}
+	if *useAuth {
+		token, err := auth.RequestLiveToken()
+		if err != nil {
+			log.Fatalf("request Live token: %v", err)
+		}
+		d.TokenSource = auth.RefreshTokenSource(token)
+	}
+	conn, err := d.DialContext(ctx, "raknet", flag.Arg(0))
+	if err != nil {
+		log.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		log.Fatalf("spawn: %v", err)
+	}
+	log.Printf("connected to %v, type help for a list of commands", flag.Arg(0))
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	closed, quit := make(chan struct{}), make(chan struct{})
+	go func() {
+		read(conn, received, f, *dump)
+		close(closed)
+	}()
+	go func() {
+		console(conn, sent, received, f)
+		close(quit)
+	}()
+	// Exit when either the connection is closed or quit is typed, whichever happens first.
+	select {
+	case <-closed:
+	case <-quit:
+	}
+}
+
+// read reads packets from the minecraft.Conn passed and prints those that pass the filter until the
+// connection is closed.
+func read(conn *minecraft.Conn, n names, f *filter, dump bool) {
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			var disc minecraft.DisconnectError
+			if errors.As(err, &disc) {
+				log.Printf("disconnected: %v", disc.Error())
+			} else if !errors.Is(err, net.ErrClosed) {
+				log.Printf("read packet: %v", err)
+			}
+			return
+		}
+		name := n.name(pk)
+		if !f.allows(name) {
+			continue
+		}
+		if dump {
+			fmt.Println(packet.Dump(pk, shieldID(conn)))
+			continue
+		}
+		fmt.Println("<", name, marshal(pk))
+	}
+}
+
+// console reads commands from stdin until stdin is closed or quit is typed.
+func console(conn *minecraft.Conn, sent, received names, f *filter) {
+	s := bufio.NewScanner(os.Stdin)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		cmd, args, _ := strings.Cut(line, " ")
+		args = strings.TrimSpace(args)
+		switch cmd {
+		case "":
+		case "send":
+			name, data, _ := strings.Cut(args, " ")
+			if err := send(conn, sent, name, data); err != nil {
+				fmt.Println("error:", err)
+			}
+		case "show", "hide":
+			for _, name := range strings.Fields(args) {
+				if _, ok := received.ids[name]; !ok {
+					fmt.Println("error: unknown packet", name)
+					continue
+				}
+				f.set(name, cmd == "show")
+			}
+		case "filter":
+			fmt.Println(f)
+		case "clear":
+			f.clear()
+		case "packets":
+			fmt.Println(strings.Join(sent.sorted(), " "))
+		case "help":
+			fmt.Println("commands: send <packet> <json>, show <packet>..., hide <packet>..., filter, clear, packets, help, quit")
+		case "quit", "exit":
+			return
+		default:
+			fmt.Printf("error: unknown command %v, type help for a list of commands\n", cmd)
+		}
+	}
+}
+
+// send decodes a packet with the name passed from the JSON data passed and writes it to the minecraft.Conn.
+func send(conn *minecraft.Conn, n names, name, data string) error {
+	id, ok := n.ids[name]
+	if !ok {
+		return fmt.Errorf("unknown packet %v", name)
+	}
+	pk := n.pool[id]()
+	if data = strings.TrimSpace(data); data != "" {
+		dec := json.NewDecoder(strings.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(pk); err != nil {
+			return fmt.Errorf("decode %v: %w", name, err)
+		}
+	}
+	if err := conn.WritePacket(pk); err != nil {
+		return fmt.Errorf("write %v: %w", name, err)
+	}
+	fmt.Println(">", name, marshal(pk))
+	return nil
+}
+
+// marshal encodes a packet as JSON. If the packet cannot be encoded as JSON, it is formatted using %+v.
+func marshal(pk packet.Packet) string {
+	data, err := json.Marshal(pk)
+	if err != nil {
+		return fmt.Sprintf("%+v", pk)
+	}
+	return string(data)
+}
+
+// shieldID returns the runtime ID of the shield item of the minecraft.Conn passed, which is needed to encode
+// items.
+func shieldID(conn *minecraft.Conn) int32 {
+	for _, item := range conn.GameData().Items {
+		if item.Name == "minecraft:shield" {
+			return int32(item.RuntimeID)
+		}
+	}
+	return 0
+}
+
+// names maps the names of the packets in a packet.Pool, such as "Text", to their IDs.
+type names struct {
+	pool packet.Pool
+	ids  map[string]uint32
+}
+
+// newNames creates names for all packets in the packet.Pool passed.
+func newNames(pool packet.Pool) names {
+	n := names{pool: pool, ids: make(map[string]uint32, len(pool))}
+	for id, f := range pool {
+		n.ids[n.name(f())] = id
+	}
+	return n
+}
+
+// name returns the name of a packet, which is the name of its type.
+func (names) name(pk packet.Packet) string {
+	return reflect.TypeOf(pk).Elem().Name()
+}
+
+// sorted returns the names of all packets in alphabetical order.
+func (n names) sorted() []string {
+	s := make([]string, 0, len(n.ids))
+	for name := range n.ids {
+		s = append(s, name)
+	}
+	slices.Sort(s)
+	return s
+}
+
+// filter decides which received packets are printed. If show is non-empty, only the packets in it are
+// printed. Packets in hide are never printed.
+type filter struct {
+	mu         sync.Mutex
+	show, hide map[string]bool
+}
+
+// allows checks if a packet with the name passed should be printed.
+func (f *filter) allows(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.hide[name] && (len(f.show) == 0 || f.show[name])
+}
+
+// set shows or hides the packet with the name passed.
+func (f *filter) set(name string, show bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if show {
+		f.show[name] = true
+		delete(f.hide, name)
+		return
+	}
+	f.hide[name] = true
+	delete(f.show, name)
+}
+
+// clear removes all packets from the filter.
+func (f *filter) clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	clear(f.show)
+	clear(f.hide)
+}
+
+// String ...
+func (f *filter) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := func(m map[string]bool) string {
+		s := make([]string, 0, len(m))
+		for name := range m {
+			s = append(s, name)
+		}
+		slices.Sort(s)
+		return strings.Join(s, " ")
+	}
+	return fmt.Sprintf("show: [%v] hide: [%v]", keys(f.show), keys(f.hide))
+}
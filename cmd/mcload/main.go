@@ -0,0 +1,206 @@
+// Command mcload is a load testing harness that connects many bots to a server at once. Every bot logs in,
+// spawns and runs a script of steps, after which a report of the login and spawn latency percentiles and the
+// errors encountered is printed.
+//
+// Usage:
+//
+//	mcload -n 100 -ramp 50ms -script "wait 1s; move 10; chat hello from {name}; wait 30s" play.example.com:19132
+//
+// By default, bots log in without authentication, using the names bot1, bot2 and so on, which requires the
+// server to have authentication disabled. When -accounts is passed with a directory, every file in it is used
+// as the token file of a Microsoft account, and bots cycle through these accounts. Accounts without a token
+// file yet are logged in using device auth when -add is passed with their names. If the MCLOAD_PASSPHRASE
+// environment variable is set, token files are encrypted with it.
+//
+// Scripts hold steps separated by semicolons:
+//
+//	wait <duration>   do nothing for a duration, such as 5s
+//	move <blocks>     walk the amount of blocks passed along the X axis, or back if negative
+//	chat <message>    send a chat message, in which {name} is replaced with the name of the bot
+//	disconnect        disconnect from the server, ending the script
+//
+// Bots disconnect once their script is done.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"golang.org/x/oauth2"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	var (
+		n        = flag.Int("n", 10, "number of bots")
+		ramp     = flag.Duration("ramp", time.Millisecond*100, "delay between starting two bots")
+		script   = flag.String("script", "wait 1s; move 5; chat hello from {name}; wait 10s", "script run by every bot")
+		prefix   = flag.String("prefix", "bot", "prefix of the names of bots logging in without authentication")
+		accounts = flag.String("accounts", "", "directory holding the token files of Microsoft accounts to log in with")
+		add      = flag.String("add", "", "comma separated names of accounts to add to the -accounts directory using device auth")
+		timeout  = flag.Duration("timeout", time.Second*30, "maximum time a bot may take to log in and spawn")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] <address>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	steps, err := parseScript(*script)
+	if err != nil {
+		log.Fatalf("parse script: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var acc *auth.Accounts
+	if *accounts != "" {
+		if acc, err = loadAccounts(*accounts, *add); err != nil {
+			log.Fatalf("load accounts: %v", err)
+		}
+		if acc.Len() == 0 {
+			log.Fatalf("no accounts in %v, use -add to add some", *accounts)
+		}
+		go acc.RefreshEvery(ctx, time.Minute)
+	}
+
+	var (
+		r  report
+		wg sync.WaitGroup
+	)
+	log.Printf("starting %v bots against %v", *n, flag.Arg(0))
+	for i := 0; i < *n && ctx.Err() == nil; i++ {
+		d := minecraft.Dialer{}
+		if acc != nil {
+			d.TokenSource = acc.Next()
+		} else {
+			d.IdentityData.DisplayName = fmt.Sprintf("%v%v", *prefix, i+1)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.add(run(ctx, d, flag.Arg(0), *timeout, steps))
+		}()
+		if i != *n-1 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(*ramp):
+			}
+		}
+	}
+	wg.Wait()
+	r.write(os.Stdout)
+}
+
+// run runs a single bot: It dials the server using the minecraft.Dialer passed, spawns and runs the steps
+// passed, after which it disconnects.
+func run(ctx context.Context, d minecraft.Dialer, address string, timeout time.Duration, steps []step) (res result) {
+	start := time.Now()
+	loginCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := d.DialContext(loginCtx, "raknet", address)
+	if err != nil {
+		return result{err: err, stage: "login"}
+	}
+	defer conn.Close()
+	res.login = time.Since(start)
+	if err := conn.DoSpawnContext(loginCtx); err != nil {
+		res.err, res.stage = err, "spawn"
+		return res
+	}
+	res.spawn = time.Since(start)
+
+	b := &bot{conn: conn, pos: conn.GameData().PlayerPosition}
+	closed := make(chan error, 1)
+	go func() {
+		// Packets must be read for the connection to keep working, even though the bot ignores them.
+		for {
+			if _, err := conn.ReadPacket(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+	for _, s := range steps {
+		select {
+		case err := <-closed:
+			if !errors.Is(err, net.ErrClosed) {
+				res.err, res.stage = err, "script"
+			}
+			return res
+		default:
+		}
+		if err := s.run(ctx, b); err != nil {
+			if ctx.Err() == nil {
+				res.err, res.stage = err, "script"
+			}
+			return res
+		}
+	}
+	return res
+}
+
+// loadAccounts loads the accounts in the directory passed, adding the accounts with the comma separated
+// names passed using device auth if they do not exist yet.
+func loadAccounts(dir, add string) (*auth.Accounts, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, e := range entries {
+		// Token stores write to temporary files first, which may be left behind if writing failed.
+		if !e.IsDir() && !strings.HasSuffix(e.Name(), ".tmp") {
+			names[e.Name()] = true
+		}
+	}
+	for _, name := range strings.Split(add, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+
+	passphrase := os.Getenv("MCLOAD_PASSPHRASE")
+	acc := auth.NewAccounts()
+	for name := range names {
+		path := filepath.Join(dir, name)
+		var store auth.TokenStore = auth.NewFileTokenStore(path)
+		if passphrase != "" {
+			store = auth.NewEncryptedFileTokenStore(path, []byte(passphrase))
+		}
+		var src oauth2.TokenSource
+		if src, err = auth.StoreTokenSource(store, prefixWriter{name: name}); err != nil {
+			return nil, fmt.Errorf("account %v: %w", name, err)
+		}
+		acc.Add(name, src)
+	}
+	return acc, nil
+}
+
+// prefixWriter writes device auth instructions to stderr, prefixed with the name of the account that they
+// are for.
+type prefixWriter struct{ name string }
+
+// Write ...
+func (w prefixWriter) Write(b []byte) (int, error) {
+	_, err := fmt.Fprintf(os.Stderr, "[%v] %s", w.name, b)
+	return len(b), err
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// result is the outcome of a single bot.
+type result struct {
+	// login is the time from dialing until the connection was logged in, and spawn the time from dialing
+	// until the bot spawned. They are zero if the bot did not get that far.
+	login, spawn time.Duration
+	// err is the error that ended the bot early, if any.
+	err error
+	// stage is the stage of the bot in which err occurred.
+	stage string
+}
+
+// report collects the results of all bots.
+type report struct {
+	mu      sync.Mutex
+	results []result
+}
+
+// add adds the result of a bot to the report.
+func (r *report) add(res result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, res)
+}
+
+// write writes the report to the io.Writer passed: The amount of bots that logged in and spawned, the
+// percentiles of their login and spawn latency and the errors that occurred, grouped by stage and message.
+func (r *report) write(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var login, spawn []time.Duration
+	errs := map[string]int{}
+	for _, res := range r.results {
+		if res.login != 0 {
+			login = append(login, res.login)
+		}
+		if res.spawn != 0 {
+			spawn = append(spawn, res.spawn)
+		}
+		if res.err != nil {
+			errs[res.stage+": "+res.err.Error()]++
+		}
+	}
+	fmt.Fprintf(w, "bots: %v, logged in: %v, spawned: %v, failed: %v\n", len(r.results), len(login), len(spawn), len(r.results)-len(spawn))
+	writePercentiles(w, "login", login)
+	writePercentiles(w, "spawn", spawn)
+
+	if len(errs) == 0 {
+		return
+	}
+	msgs := make([]string, 0, len(errs))
+	for msg := range errs {
+		msgs = append(msgs, msg)
+	}
+	slices.SortFunc(msgs, func(a, b string) int { return errs[b] - errs[a] })
+	fmt.Fprintln(w, "errors:")
+	for _, msg := range msgs {
+		fmt.Fprintf(w, "  %5d %v\n", errs[msg], msg)
+	}
+}
+
+// writePercentiles writes the 50th, 90th and 99th percentile and the maximum of the durations passed.
+func writePercentiles(w io.Writer, name string, d []time.Duration) {
+	if len(d) == 0 {
+		fmt.Fprintf(w, "%v: no samples\n", name)
+		return
+	}
+	slices.Sort(d)
+	p := func(q float64) time.Duration {
+		return d[min(int(q*float64(len(d))), len(d)-1)].Round(time.Millisecond)
+	}
+	fmt.Fprintf(w, "%v: p50 %v, p90 %v, p99 %v, max %v\n", name, p(0.5), p(0.9), p(0.99), d[len(d)-1].Round(time.Millisecond))
+}
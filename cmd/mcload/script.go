@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// walkSpeed is the speed in blocks per second at which bots move, which is the walking speed of a player.
+const walkSpeed = 4.317
+
+// step is a single step of a script, run by a bot once it has spawned.
+type step interface {
+	// run runs the step for the bot passed. run returns early with the error of the context.Context if it is
+	// cancelled.
+	run(ctx context.Context, b *bot) error
+}
+
+// parseScript parses a script of steps separated by semicolons. The following steps are supported:
+//
+//	wait <duration>   do nothing for a duration, such as 5s
+//	move <blocks>     walk the amount of blocks passed along the X axis, or back if negative
+//	chat <message>    send a chat message, in which {name} is replaced with the name of the bot
+//	disconnect        disconnect from the server, ending the script
+func parseScript(s string) ([]step, error) {
+	var steps []step
+	for _, line := range strings.Split(s, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		arg = strings.TrimSpace(arg)
+		switch name {
+		case "":
+			continue
+		case "wait":
+			d, err := time.ParseDuration(arg)
+			if err != nil {
+				return nil, fmt.Errorf("parse wait: %w", err)
+			}
+			steps = append(steps, waitStep(d))
+		case "move":
+			blocks, err := strconv.ParseFloat(arg, 32)
+			if err != nil {
+				return nil, fmt.Errorf("parse move: %w", err)
+			}
+			steps = append(steps, moveStep(blocks))
+		case "chat":
+			if arg == "" {
+				return nil, fmt.Errorf("parse chat: message must not be empty")
+			}
+			steps = append(steps, chatStep(arg))
+		case "disconnect":
+			steps = append(steps, disconnectStep{})
+		default:
+			return nil, fmt.Errorf("unknown step %q", name)
+		}
+	}
+	return steps, nil
+}
+
+// waitStep waits for a duration.
+type waitStep time.Duration
+
+func (s waitStep) run(ctx context.Context, b *bot) error {
+	t := time.NewTimer(time.Duration(s))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// moveStep walks a number of blocks along the X axis, sending a PlayerAuthInput packet every tick like a
+// client with server authoritative movement.
+type moveStep float64
+
+func (s moveStep) run(ctx context.Context, b *bot) error {
+	ticker := time.NewTicker(time.Second / 20)
+	defer ticker.Stop()
+
+	delta := float32(walkSpeed / 20)
+	if s < 0 {
+		delta = -delta
+	}
+	for remaining := float32(s); remaining != 0; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		d := delta
+		if (d > 0 && d > remaining) || (d < 0 && d < remaining) {
+			d = remaining
+		}
+		remaining -= d
+		b.pos = b.pos.Add(mgl32.Vec3{d})
+		b.tick++
+		if err := b.conn.WritePacket(&packet.PlayerAuthInput{
+			Position:  b.pos,
+			Delta:     mgl32.Vec3{d},
+			InputData: protocol.NewBitset(packet.PlayerAuthInputBitsetSize),
+			InputMode: packet.InputModeMouse,
+			PlayMode:  packet.PlayModeNormal,
+			Tick:      b.tick,
+		}); err != nil {
+			return fmt.Errorf("move: %w", err)
+		}
+	}
+	return nil
+}
+
+// chatStep sends a chat message.
+type chatStep string
+
+func (s chatStep) run(_ context.Context, b *bot) error {
+	id := b.conn.IdentityData()
+	if err := b.conn.WritePacket(&packet.Text{
+		TextType:   packet.TextTypeChat,
+		SourceName: id.DisplayName,
+		Message:    strings.ReplaceAll(string(s), "{name}", id.DisplayName),
+		XUID:       id.XUID,
+	}); err != nil {
+		return fmt.Errorf("chat: %w", err)
+	}
+	return nil
+}
+
+// disconnectStep closes the connection of the bot.
+type disconnectStep struct{}
+
+func (disconnectStep) run(_ context.Context, b *bot) error {
+	return b.conn.Close()
+}
+
+// bot is a single connection running a script.
+type bot struct {
+	conn *minecraft.Conn
+	pos  mgl32.Vec3
+	tick uint64
+}
@@ -0,0 +1,182 @@
+// Command packetdiff connects to a vanilla server, or accepts a vanilla client, and decodes every packet
+// sent over the connection using the packets implemented by gophertunnel. Packets with an ID that is not
+// implemented, packets that fail to decode and packets that are decoded with bytes left over are logged with
+// a dump of their payload. Once the connection is closed, or packetdiff is interrupted, a report is written
+// that lists these packets, which speeds up updating the library to a new protocol version.
+//
+// Usage:
+//
+//	packetdiff -connect play.example.com:19132 [-auth]
+//	packetdiff -listen 0.0.0.0:19132
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+func main() {
+	var (
+		connect = flag.String("connect", "", "address of a server to connect to")
+		listen  = flag.String("listen", "", "address to accept a client on")
+		useAuth = flag.Bool("auth", false, "log in with a Microsoft account when connecting, or require clients to be authenticated when listening")
+		dumps   = flag.Int("dumps", 3, "number of packets with problems logged for every packet ID")
+		all     = flag.Bool("all", false, "list packets without problems in the report")
+		out     = flag.String("out", "", "file to write the report to, instead of stdout")
+	)
+	flag.Parse()
+	if (*connect == "") == (*listen == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := newReport(os.Stderr, *dumps)
+	var err error
+	if *connect != "" {
+		err = runClient(ctx, *connect, *useAuth, r)
+	} else {
+		err = runServer(ctx, *listen, *useAuth, r)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("packetdiff: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("create report: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	r.write(w, *all)
+}
+
+// runClient connects to the server at the address passed and decodes all packets sent over the connection
+// until it is closed or the context.Context is cancelled.
+func runClient(ctx context.Context, address string, useAuth bool, r *report) error {
+	var (
+		shieldID atomic.Int32
+		local    atomic.Pointer[string]
+	)
+	received, sent := packet.NewServerPool(), packet.NewClientPool()
+	d := minecraft.Dialer{
+		PacketFunc: func(header packet.Header, payload []byte, src, dst net.Addr) {
+			// The address passed may be a host name, so it cannot be compared with the addresses passed.
+			// The first packet of a connection is always sent by the client, so its source is our address.
+			addr := src.String()
+			local.CompareAndSwap(nil, &addr)
+			if addr != *local.Load() {
+				r.add(received, header, payload, shieldID.Load(), false)
+				return
+			}
+			r.add(sent, header, payload, shieldID.Load(), true)
+		},
+	}
+	if useAuth {
+		token, err := auth.RequestLiveToken()
+		if err != nil {
+			return fmt.Errorf("request Live token: %w", err)
+		}
+		d.TokenSource = auth.RefreshTokenSource(token)
+	}
+	conn, err := d.DialContext(ctx, "raknet", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	shieldID.Store(shieldIDOf(conn.GameData()))
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		return err
+	}
+	log.Printf("packetdiff: connected to %v, press Ctrl+C to stop", address)
+	return readAll(ctx, conn)
+}
+
+// runServer accepts a single client on the address passed and decodes all packets sent over the connection
+// until it is closed or the context.Context is cancelled.
+func runServer(ctx context.Context, address string, useAuth bool, r *report) error {
+	received, sent := packet.NewClientPool(), packet.NewServerPool()
+	var (
+		shieldID atomic.Int32
+		l        *minecraft.Listener
+		err      error
+	)
+	l, err = minecraft.ListenConfig{
+		AuthenticationDisabled: !useAuth,
+		PacketFunc: func(header packet.Header, payload []byte, src, dst net.Addr) {
+			// Packets are only observed once Listen has returned, so l is always set here.
+			if src.String() != l.Addr().String() {
+				r.add(received, header, payload, shieldID.Load(), false)
+				return
+			}
+			r.add(sent, header, payload, shieldID.Load(), true)
+		},
+	}.Listen("raknet", address)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	log.Printf("packetdiff: listening on %v", l.Addr())
+	c, err := l.Accept()
+	if err != nil {
+		return err
+	}
+	conn := c.(*minecraft.Conn)
+	defer conn.Close()
+	data := minecraft.GameData{WorldName: "packetdiff", EntityUniqueID: 1, EntityRuntimeID: 1, PlayerGameMode: 1}
+	if err := conn.StartGameContext(ctx, data); err != nil {
+		return err
+	}
+	shieldID.Store(shieldIDOf(conn.GameData()))
+	log.Printf("packetdiff: %v connected, press Ctrl+C to stop", conn.IdentityData().DisplayName)
+	return readAll(ctx, conn)
+}
+
+// readAll reads packets from the minecraft.Conn passed until it is closed or the context.Context is
+// cancelled. The packets are decoded by the PacketFunc of the Conn already, so they are discarded.
+func readAll(ctx context.Context, conn *minecraft.Conn) error {
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	for {
+		if _, err := conn.ReadPacket(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+}
+
+// shieldIDOf returns the runtime ID of the shield item in the GameData passed, which is needed to decode
+// items.
+func shieldIDOf(data minecraft.GameData) int32 {
+	for _, item := range data.Items {
+		if item.Name == "minecraft:shield" {
+			return int32(item.RuntimeID)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// problem is the kind of problem found while decoding a packet.
+type problem int
+
+const (
+	// problemNone is used for packets that were decoded without problems.
+	problemNone problem = iota
+	// problemUnknown is used for packets with an ID that the library does not implement.
+	problemUnknown
+	// problemDecode is used for packets that could not be decoded, usually because fields were added,
+	// removed or changed in type.
+	problemDecode
+	// problemUnread is used for packets that were decoded with bytes left over, usually because fields were
+	// added at the end of the packet.
+	problemUnread
+)
+
+// String ...
+func (p problem) String() string {
+	switch p {
+	case problemUnknown:
+		return "not implemented"
+	case problemDecode:
+		return "decode error"
+	case problemUnread:
+		return "unread bytes"
+	}
+	return "ok"
+}
+
+// stat holds the results of decoding all packets with a single ID sent in a single direction.
+type stat struct {
+	id       uint32
+	name     string
+	sent     bool
+	count    int
+	problems map[problem]int
+	// minUnread and maxUnread are the smallest and largest amount of bytes left over in packets with
+	// problemUnread.
+	minUnread, maxUnread int
+	// firstErr is the first decode error encountered.
+	firstErr string
+}
+
+// report collects the results of decoding all packets observed on a connection.
+type report struct {
+	mu    sync.Mutex
+	stats map[statKey]*stat
+	// dumps is the number of packets with problems that are logged for every packet ID.
+	dumps int
+	log   io.Writer
+}
+
+// statKey identifies a stat in a report.
+type statKey struct {
+	id   uint32
+	sent bool
+}
+
+// newReport creates a report that logs a dump of the first dumps packets with problems of every packet ID
+// to the io.Writer passed.
+func newReport(log io.Writer, dumps int) *report {
+	return &report{stats: map[statKey]*stat{}, dumps: dumps, log: log}
+}
+
+// add decodes the payload of a packet using the pool passed and adds the results to the report. sent
+// specifies if the packet was sent by the connection that the report is for.
+func (r *report) add(pool packet.Pool, header packet.Header, payload []byte, shieldID int32, sent bool) {
+	p, unread, err := check(pool, header.PacketID, payload, shieldID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := statKey{id: header.PacketID, sent: sent}
+	s, ok := r.stats[key]
+	if !ok {
+		s = &stat{id: header.PacketID, name: packetName(pool, header.PacketID), sent: sent, problems: map[problem]int{}, minUnread: unread}
+		r.stats[key] = s
+	}
+	s.count++
+	if p == problemNone {
+		return
+	}
+	s.problems[p]++
+	if p == problemUnread {
+		s.minUnread, s.maxUnread = min(s.minUnread, unread), max(s.maxUnread, unread)
+	}
+	if err != nil && s.firstErr == "" {
+		s.firstErr = err.Error()
+	}
+	if total := s.problems[problemUnknown] + s.problems[problemDecode] + s.problems[problemUnread]; total <= r.dumps {
+		_, _ = fmt.Fprintf(r.log, "%v %v (%v):\n%v\n", direction(sent), s.name, p, packet.DumpPayload(pool, header.PacketID, payload, shieldID))
+	}
+}
+
+// write writes the report to the io.Writer passed. Packets without problems are only listed if all is true.
+func (r *report) write(w io.Writer, all bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]*stat, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, s)
+	}
+	slices.SortFunc(stats, func(a, b *stat) int {
+		if a.id != b.id {
+			return int(a.id) - int(b.id)
+		}
+		if a.sent == b.sent {
+			return 0
+		} else if a.sent {
+			return 1
+		}
+		return -1
+	})
+
+	var problems int
+	for _, s := range stats {
+		if len(s.problems) == 0 {
+			if all {
+				_, _ = fmt.Fprintf(w, "0x%02x %-40v %-8v %6v ok\n", s.id, s.name, direction(s.sent), s.count)
+			}
+			continue
+		}
+		problems++
+		_, _ = fmt.Fprintf(w, "0x%02x %-40v %-8v %6v", s.id, s.name, direction(s.sent), s.count)
+		for _, p := range []problem{problemUnknown, problemDecode, problemUnread} {
+			if n := s.problems[p]; n != 0 {
+				_, _ = fmt.Fprintf(w, " %v: %v", p, n)
+			}
+		}
+		if s.problems[problemUnread] != 0 {
+			_, _ = fmt.Fprintf(w, " (%v-%v bytes left)", s.minUnread, s.maxUnread)
+		}
+		_, _ = fmt.Fprintln(w)
+		if s.firstErr != "" {
+			_, _ = fmt.Fprintf(w, "     first error: %v\n", s.firstErr)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "%v of %v packet types had problems\n", problems, len(stats))
+}
+
+// check decodes the payload of a packet with the ID passed using the pool passed and returns the problem
+// encountered, if any, and the amount of bytes left unread.
+func check(pool packet.Pool, id uint32, payload []byte, shieldID int32) (p problem, unread int, err error) {
+	f, ok := pool[id]
+	if !ok {
+		return problemUnknown, len(payload), nil
+	}
+	pk := f()
+	buf := bytes.NewBuffer(payload)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			p, unread, err = problemDecode, buf.Len(), fmt.Errorf("decode packet %T at offset %v: %v", pk, len(payload)-buf.Len(), recovered)
+		}
+	}()
+	pk.Marshal(protocol.NewReader(buf, shieldID, false))
+	if buf.Len() != 0 {
+		return problemUnread, buf.Len(), nil
+	}
+	return problemNone, 0, nil
+}
+
+// packetName returns the name of the packet with the ID passed in the pool passed.
+func packetName(pool packet.Pool, id uint32) string {
+	if f, ok := pool[id]; ok {
+		return reflect.TypeOf(f()).Elem().Name()
+	}
+	return "unknown"
+}
+
+// direction returns the name of the direction of a packet.
+func direction(sent bool) string {
+	if sent {
+		return "sent"
+	}
+	return "received"
+}
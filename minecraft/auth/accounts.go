@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/oauth2"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Accounts holds multiple authenticated Microsoft accounts, each with its own ChainSource, and selects one
+// of them when dialing. It is aimed at tools running many connections at once, such as load testing tools,
+// that need a different identity for every connection. An Accounts is safe for concurrent use.
+//
+//	accounts := auth.NewAccounts()
+//	accounts.Add("bot1", src1)
+//	accounts.Add("bot2", src2)
+//	go accounts.RefreshEvery(ctx, time.Minute)
+//	conn, err := minecraft.Dialer{TokenSource: accounts.Next()}.Dial("raknet", address)
+type Accounts struct {
+	mu       sync.Mutex
+	names    []string
+	accounts map[string]*ChainSource
+	next     int
+}
+
+// NewAccounts returns an empty Accounts.
+func NewAccounts() *Accounts {
+	return &Accounts{accounts: make(map[string]*ChainSource)}
+}
+
+// Add adds an account under the name passed, obtaining its Live Connect tokens from the oauth2.TokenSource
+// passed. The ChainSource created for the account is returned. If an account with the same name already
+// exists, it is replaced.
+func (a *Accounts) Add(name string, src oauth2.TokenSource) *ChainSource {
+	cs := NewChainSource(src)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.accounts[name]; !ok {
+		a.names = append(a.names, name)
+	}
+	a.accounts[name] = cs
+	return cs
+}
+
+// Remove removes the account with the name passed. Remove is a no-op if no such account exists.
+func (a *Accounts) Remove(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.accounts[name]; !ok {
+		return
+	}
+	delete(a.accounts, name)
+	a.names = slices.DeleteFunc(a.names, func(n string) bool { return n == name })
+}
+
+// Get returns the ChainSource of the account with the name passed. False is returned if no such account
+// exists.
+func (a *Accounts) Get(name string) (*ChainSource, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cs, ok := a.accounts[name]
+	return cs, ok
+}
+
+// Next returns the ChainSource of the next account, cycling through all accounts in the order that they
+// were added. Next returns nil if no accounts were added.
+func (a *Accounts) Next() *ChainSource {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.names) == 0 {
+		return nil
+	}
+	name := a.names[a.next%len(a.names)]
+	a.next = (a.next + 1) % len(a.names)
+	return a.accounts[name]
+}
+
+// Names returns the names of all accounts, in the order that they were added.
+func (a *Accounts) Names() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.names)
+}
+
+// Len returns the amount of accounts.
+func (a *Accounts) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.names)
+}
+
+// Refresh refreshes the tokens of all accounts that expire within the duration passed, so that dialing
+// with them does not have to wait for a refresh. An error is returned for the first account that could not
+// be refreshed, but all accounts are attempted.
+func (a *Accounts) Refresh(ctx context.Context, within time.Duration) error {
+	a.mu.Lock()
+	names := slices.Clone(a.names)
+	sources := make([]*ChainSource, len(names))
+	for i, name := range names {
+		sources[i] = a.accounts[name]
+	}
+	a.mu.Unlock()
+
+	var firstErr error
+	for i, cs := range sources {
+		if err := cs.refresh(ctx, within); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("refresh account %v: %w", names[i], err)
+		}
+	}
+	return firstErr
+}
+
+// RefreshEvery calls Accounts.Refresh every interval until the context passed is cancelled, refreshing
+// tokens that would otherwise expire before the next call. Errors are ignored, as the tokens of failing
+// accounts are refreshed again when dialing. RefreshEvery blocks, so it should typically be run in a
+// separate goroutine.
+func (a *Accounts) RefreshEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = a.Refresh(ctx, interval*2)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"golang.org/x/oauth2"
+	"sync"
+	"time"
+)
+
+// minecraftRelyingParty is the relying party that XSTS tokens used to obtain Minecraft login chains are
+// requested for.
+const minecraftRelyingParty = "https://multiplayer.minecraft.net/"
+
+// ChainSource produces the signed login chains needed to join servers with a Microsoft account. It obtains
+// Live Connect tokens from an oauth2.TokenSource, exchanges them for an XSTS token and uses that to request a
+// login chain from Minecraft's authentication endpoint.
+// Unlike passing an oauth2.TokenSource to minecraft.Dialer directly, a ChainSource caches the XSTS token
+// until it expires, so that dialing multiple times only requests a new login chain. To also keep the Live
+// Connect tokens across restarts, ChainSource may be combined with StoreTokenSource:
+//
+//	src, _ := auth.StoreTokenSource(auth.NewEncryptedFileTokenStore("token.bin", passphrase), os.Stdout)
+//	dialer := minecraft.Dialer{TokenSource: auth.NewChainSource(src)}
+//
+// A ChainSource is safe for concurrent use.
+type ChainSource struct {
+	src oauth2.TokenSource
+
+	mu  sync.Mutex
+	xbl *XBLToken
+}
+
+// NewChainSource returns a ChainSource that obtains Live Connect tokens from the oauth2.TokenSource passed.
+// Typically, this is the TokenSource variable or a source returned by WriterTokenSource or StoreTokenSource.
+func NewChainSource(src oauth2.TokenSource) *ChainSource {
+	return &ChainSource{src: src}
+}
+
+// Token returns a Live Connect token from the oauth2.TokenSource of the ChainSource. It is implemented so
+// that a ChainSource may be used as the TokenSource of a minecraft.Dialer.
+func (s *ChainSource) Token() (*oauth2.Token, error) {
+	return s.src.Token()
+}
+
+// XBLToken returns the XSTS token of the ChainSource. A new token is requested if none was requested yet or
+// if the cached token has expired.
+func (s *ChainSource) XBLToken(ctx context.Context) (*XBLToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.xbl.Valid() {
+		return s.xbl, nil
+	}
+	liveToken, err := s.src.Token()
+	if err != nil {
+		return nil, fmt.Errorf("request Live Connect token: %w", err)
+	}
+	xbl, err := RequestXBLToken(ctx, liveToken, minecraftRelyingParty)
+	if err != nil {
+		return nil, fmt.Errorf("request XBOX Live token: %w", err)
+	}
+	s.xbl = xbl
+	return xbl, nil
+}
+
+// refresh requests a new XSTS token if the cached token of the ChainSource expires within the duration
+// passed.
+func (s *ChainSource) refresh(ctx context.Context, within time.Duration) error {
+	s.mu.Lock()
+	if s.xbl.Valid() && time.Now().Add(within).Before(s.xbl.AuthorizationToken.NotAfter) {
+		s.mu.Unlock()
+		return nil
+	}
+	s.xbl = nil
+	s.mu.Unlock()
+
+	_, err := s.XBLToken(ctx)
+	return err
+}
+
+// Chain requests a login chain for the ECDSA private key passed, using the cached XSTS token of the
+// ChainSource where possible. The chain returned may be passed to login.Encode to produce a login request.
+// The private key must be the one used for the encryption of the connection that the chain is used for.
+func (s *ChainSource) Chain(ctx context.Context, key *ecdsa.PrivateKey) (string, error) {
+	xbl, err := s.XBLToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	chain, err := RequestMinecraftChain(ctx, xbl, key)
+	if err != nil {
+		// The XSTS token may have been revoked before it expired. Drop it so that the next call requests a
+		// new one.
+		s.mu.Lock()
+		if s.xbl == xbl {
+			s.xbl = nil
+		}
+		s.mu.Unlock()
+		return "", fmt.Errorf("request Minecraft auth chain: %w", err)
+	}
+	return chain, nil
+}
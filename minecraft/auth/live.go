@@ -1,12 +1,14 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -73,20 +75,43 @@ func RequestLiveToken() (*oauth2.Token, error) {
 // RequestLiveTokenWriter does a login request for Microsoft Live Connect using device auth. A login URL will
 // be printed to the io.Writer passed with a user code which the user must use to submit.
 // Once fully authenticated, an oauth2 token is returned which may be used to login to XBOX Live.
+// RequestLiveTokenWriter is the equivalent of RequestLiveTokenContext(context.Background(), w).
 func RequestLiveTokenWriter(w io.Writer) (*oauth2.Token, error) {
-	d, err := startDeviceAuth()
+	return RequestLiveTokenContext(context.Background(), w)
+}
+
+// RequestLiveTokenContext does a login request for Microsoft Live Connect using device auth, like
+// RequestLiveTokenWriter. Polling for the token stops when the context passed is cancelled or when the code
+// printed to the io.Writer expires, after which an error is returned.
+func RequestLiveTokenContext(ctx context.Context, w io.Writer) (*oauth2.Token, error) {
+	d, err := startDeviceAuth(ctx)
 	if err != nil {
 		return nil, err
 	}
 	_, _ = w.Write([]byte(fmt.Sprintf("Authenticate at %v using the code %v.\n", d.VerificationURI, d.UserCode)))
-	ticker := time.NewTicker(time.Second * time.Duration(d.Interval))
-	defer ticker.Stop()
 
-	for range ticker.C {
-		t, err := pollDeviceAuth(d.DeviceCode)
+	interval := time.Second * time.Duration(max(d.Interval, 1))
+	expired := time.After(time.Second * time.Duration(d.ExpiresIn))
+	if d.ExpiresIn <= 0 {
+		expired = nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-expired:
+			return nil, fmt.Errorf("device code %v expired before authentication was finished", d.UserCode)
+		case <-time.After(interval):
+		}
+		t, slowDown, err := pollDeviceAuth(ctx, d.DeviceCode)
 		if err != nil {
 			return nil, fmt.Errorf("error polling for device auth: %w", err)
 		}
+		if slowDown {
+			// The token endpoint asks us to poll less frequently. The interval is increased by 5 seconds as
+			// specified in RFC 8628.
+			interval += time.Second * 5
+		}
 		// If the token could not be obtained yet (authentication wasn't finished yet), the token is nil.
 		// We just retry if this is the case.
 		if t != nil {
@@ -94,13 +119,12 @@ func RequestLiveTokenWriter(w io.Writer) (*oauth2.Token, error) {
 			return t, nil
 		}
 	}
-	panic("unreachable")
 }
 
 // startDeviceAuth starts the device auth, retrieving a login URI for the user and a code the user needs to
 // enter.
-func startDeviceAuth() (*deviceAuthConnect, error) {
-	resp, err := http.PostForm("https://login.live.com/oauth20_connect.srf", url.Values{
+func startDeviceAuth(ctx context.Context) (*deviceAuthConnect, error) {
+	resp, err := postForm(ctx, "https://login.live.com/oauth20_connect.srf", url.Values{
 		"client_id":     {"0000000048183522"},
 		"scope":         {"service::user.auth.xboxlive.com::MBI_SSL"},
 		"response_type": {"device_code"},
@@ -119,32 +143,36 @@ func startDeviceAuth() (*deviceAuthConnect, error) {
 }
 
 // pollDeviceAuth polls the token endpoint for the device code. A token is returned if the user authenticated
-// successfully. If the user has not yet authenticated, err is nil but the token is nil too.
-func pollDeviceAuth(deviceCode string) (t *oauth2.Token, err error) {
-	resp, err := http.PostForm(microsoft.LiveConnectEndpoint.TokenURL, url.Values{
+// successfully. If the user has not yet authenticated, err is nil but the token is nil too. slowDown is true
+// if the endpoint requested to be polled less frequently.
+func pollDeviceAuth(ctx context.Context, deviceCode string) (t *oauth2.Token, slowDown bool, err error) {
+	resp, err := postForm(ctx, microsoft.LiveConnectEndpoint.TokenURL, url.Values{
 		"client_id":   {"0000000048183522"},
 		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 		"device_code": {deviceCode},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("POST https://login.live.com/oauth20_token.srf: %w", err)
+		return nil, false, fmt.Errorf("POST https://login.live.com/oauth20_token.srf: %w", err)
 	}
 	poll := new(deviceAuthPoll)
 	if err := json.NewDecoder(resp.Body).Decode(poll); err != nil {
-		return nil, fmt.Errorf("POST https://login.live.com/oauth20_token.srf: json decode: %w", err)
+		return nil, false, fmt.Errorf("POST https://login.live.com/oauth20_token.srf: json decode: %w", err)
 	}
 	_ = resp.Body.Close()
-	if poll.Error == "authorization_pending" {
-		return nil, nil
-	} else if poll.Error == "" {
+	switch poll.Error {
+	case "authorization_pending":
+		return nil, false, nil
+	case "slow_down":
+		return nil, true, nil
+	case "":
 		return &oauth2.Token{
 			AccessToken:  poll.AccessToken,
 			TokenType:    poll.TokenType,
 			RefreshToken: poll.RefreshToken,
 			Expiry:       time.Now().Add(time.Duration(poll.ExpiresIn) * time.Second),
-		}, nil
+		}, false, nil
 	}
-	return nil, fmt.Errorf("%v: %v", poll.Error, poll.ErrorDescription)
+	return nil, false, fmt.Errorf("%v: %v", poll.Error, poll.ErrorDescription)
 }
 
 // refreshToken refreshes the oauth2.Token passed and returns a new oauth2.Token. An error is returned if
@@ -177,6 +205,17 @@ func refreshToken(t *oauth2.Token) (*oauth2.Token, error) {
 	}, nil
 }
 
+// postForm posts the form values passed to the URL passed, cancelling the request when the context is
+// cancelled.
+func postForm(ctx context.Context, rawURL string, values url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", rawURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return http.DefaultClient.Do(req)
+}
+
 type deviceAuthConnect struct {
 	UserCode        string `json:"user_code"`
 	DeviceCode      string `json:"device_code"`
@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenStore stores an oauth2.Token so that it may be reused in a later session, preventing the user from
+// having to authenticate using device auth every time. MemoryTokenStore, FileTokenStore and
+// EncryptedFileTokenStore implement TokenStore, but it may be implemented to store tokens elsewhere, such as
+// in a database or a secret manager. StoreTokenSource uses a TokenStore to produce an oauth2.TokenSource.
+type TokenStore interface {
+	// Token loads the oauth2.Token held by the TokenStore. If no token was stored yet, Token returns a nil
+	// token and a nil error.
+	Token() (*oauth2.Token, error)
+	// StoreToken stores the oauth2.Token passed, replacing any token stored previously.
+	StoreToken(t *oauth2.Token) error
+}
+
+// MemoryTokenStore is a TokenStore that keeps an oauth2.Token in memory. Tokens stored in a MemoryTokenStore
+// are lost when the process exits. The zero value of a MemoryTokenStore is ready to use.
+type MemoryTokenStore struct {
+	mu sync.Mutex
+	t  *oauth2.Token
+}
+
+// Token returns the token stored in the MemoryTokenStore, or nil if no token was stored yet.
+func (store *MemoryTokenStore) Token() (*oauth2.Token, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.t == nil {
+		return nil, nil
+	}
+	t := *store.t
+	return &t, nil
+}
+
+// StoreToken stores a copy of the token passed in the MemoryTokenStore.
+func (store *MemoryTokenStore) StoreToken(t *oauth2.Token) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	cp := *t
+	store.t = &cp
+	return nil
+}
+
+// FileTokenStore is a TokenStore that stores an oauth2.Token as plain JSON in a file that is only readable by
+// the current user. Because the refresh token in the file gives access to the account, EncryptedFileTokenStore
+// should be preferred wherever the file could be read by others, for example on shared machines or in
+// backups.
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore returns a FileTokenStore that stores a token at the path passed.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Token reads the token stored in the file of the FileTokenStore. If the file does not exist, a nil token and
+// a nil error are returned.
+func (store *FileTokenStore) Token() (*oauth2.Token, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read token: %w", err)
+	}
+	t := new(oauth2.Token)
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("decode token: %w", err)
+	}
+	return t, nil
+}
+
+// StoreToken writes the token passed to the file of the FileTokenStore. The file is replaced atomically.
+func (store *FileTokenStore) StoreToken(t *oauth2.Token) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("encode token: %w", err)
+	}
+	return writeFileAtomic(store.path, data)
+}
+
+// ErrInvalidTokenKey is returned by an EncryptedFileTokenStore if the token file could not be decrypted,
+// either because the key or passphrase used was not the one used to store the token, or because the file was
+// tampered with.
+var ErrInvalidTokenKey = errors.New("decrypt token: invalid key or corrupted file")
+
+// EncryptedFileTokenStore is a TokenStore that stores an oauth2.Token in a file encrypted using AES-256-GCM.
+// The key used is either passed directly or derived from a passphrase using scrypt.
+// The auth package does not read keys from the keychain of the operating system itself. Applications that
+// keep the key in a keychain must load it and pass it to NewEncryptedFileTokenStoreKey.
+type EncryptedFileTokenStore struct {
+	path string
+
+	key        []byte
+	passphrase []byte
+
+	mu sync.Mutex
+}
+
+// encryptedTokenMagic is found at the start of every file written by an EncryptedFileTokenStore.
+var encryptedTokenMagic = []byte("gtet\x01")
+
+const (
+	// saltSize is the size of the salt used to derive a key from a passphrase.
+	saltSize = 16
+	// scryptN, scryptR and scryptP are the scrypt parameters used to derive a key from a passphrase.
+	scryptN, scryptR, scryptP = 1 << 15, 8, 1
+)
+
+// NewEncryptedFileTokenStore returns an EncryptedFileTokenStore that stores a token at the path passed. The
+// key used to encrypt the token is derived from the passphrase passed, using a random salt that is stored
+// alongside the token.
+func NewEncryptedFileTokenStore(path string, passphrase []byte) *EncryptedFileTokenStore {
+	return &EncryptedFileTokenStore{path: path, passphrase: bytes.Clone(passphrase)}
+}
+
+// NewEncryptedFileTokenStoreKey returns an EncryptedFileTokenStore that stores a token at the path passed,
+// encrypted using the key passed. The key must be 32 bytes long. NewEncryptedFileTokenStoreKey should be
+// used if the key is kept in a secure location, such as the keychain of the operating system, from which the
+// caller obtains it.
+func NewEncryptedFileTokenStoreKey(path string, key []byte) (*EncryptedFileTokenStore, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("new encrypted token store: key must be 32 bytes, got %v", len(key))
+	}
+	return &EncryptedFileTokenStore{path: path, key: bytes.Clone(key)}, nil
+}
+
+// Token reads and decrypts the token stored in the file of the EncryptedFileTokenStore. If the file does not
+// exist, a nil token and a nil error are returned. ErrInvalidTokenKey is returned if the file could not be
+// decrypted.
+func (store *EncryptedFileTokenStore) Token() (*oauth2.Token, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read token: %w", err)
+	}
+	if !bytes.HasPrefix(data, encryptedTokenMagic) || len(data) < len(encryptedTokenMagic)+saltSize {
+		return nil, fmt.Errorf("read token: %v is not an encrypted token file", store.path)
+	}
+	data = data[len(encryptedTokenMagic):]
+	salt, data := data[:saltSize], data[saltSize:]
+
+	aead, err := store.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrInvalidTokenKey
+	}
+	nonce, data := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, data, encryptedTokenMagic)
+	if err != nil {
+		return nil, ErrInvalidTokenKey
+	}
+	t := new(oauth2.Token)
+	if err := json.Unmarshal(plain, t); err != nil {
+		return nil, fmt.Errorf("decode token: %w", err)
+	}
+	return t, nil
+}
+
+// StoreToken encrypts the token passed and writes it to the file of the EncryptedFileTokenStore. The file is
+// replaced atomically and is only readable by the current user.
+func (store *EncryptedFileTokenStore) StoreToken(t *oauth2.Token) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	plain, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("encode token: %w", err)
+	}
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("generate salt: %w", err)
+	}
+	aead, err := store.aead(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	data := append(bytes.Clone(encryptedTokenMagic), salt...)
+	data = append(data, nonce...)
+	data = aead.Seal(data, nonce, plain, encryptedTokenMagic)
+	return writeFileAtomic(store.path, data)
+}
+
+// aead returns the cipher.AEAD used to encrypt and decrypt tokens. If the EncryptedFileTokenStore was created
+// using a passphrase, the key is derived from it using the salt passed.
+func (store *EncryptedFileTokenStore) aead(salt []byte) (cipher.AEAD, error) {
+	key := store.key
+	if key == nil {
+		var err error
+		if key, err = scrypt.Key(store.passphrase, salt, scryptN, scryptR, scryptP, 32); err != nil {
+			return nil, fmt.Errorf("derive key: %w", err)
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory as path and renames it to path, so
+// that the file at path is never left partially written.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write token: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if err := f.Chmod(0600); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		_ = f.Close()
+		return fmt.Errorf("write token: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write token: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write token: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("write token: %w", err)
+	}
+	return nil
+}
+
+// StoreTokenSource returns an oauth2.TokenSource that obtains its initial token from the TokenStore passed.
+// If the TokenStore does not hold a token yet, device auth is used, printing the authentication URL and code
+// to the io.Writer passed. Every time the token is refreshed, the new token is stored in the TokenStore.
+func StoreTokenSource(store TokenStore, w io.Writer) (oauth2.TokenSource, error) {
+	t, err := store.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		if t, err = RequestLiveTokenWriter(w); err != nil {
+			return nil, err
+		}
+		if err := store.StoreToken(t); err != nil {
+			return nil, err
+		}
+	}
+	return &storingTokenSource{src: RefreshTokenSourceWriter(t, w), store: store, last: t.AccessToken}, nil
+}
+
+// storingTokenSource wraps around an oauth2.TokenSource and stores every new token returned by it in a
+// TokenStore.
+type storingTokenSource struct {
+	src   oauth2.TokenSource
+	store TokenStore
+
+	mu   sync.Mutex
+	last string
+}
+
+// Token returns a token from the underlying oauth2.TokenSource, storing it if it was refreshed.
+func (src *storingTokenSource) Token() (*oauth2.Token, error) {
+	t, err := src.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if t.AccessToken != src.last {
+		if err := src.store.StoreToken(t); err != nil {
+			return nil, err
+		}
+		src.last = t.AccessToken
+	}
+	return t, nil
+}
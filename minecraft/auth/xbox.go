@@ -30,9 +30,17 @@ type XBLToken struct {
 			} `json:"xui"`
 		}
 		Token string
+		// NotAfter is the time after which the token expires.
+		NotAfter time.Time
 	}
 }
 
+// Valid checks if the XBLToken is non-nil and has not yet expired. A margin of a minute is kept, so that the
+// token does not expire while it is being used.
+func (t *XBLToken) Valid() bool {
+	return t != nil && t.AuthorizationToken.Token != "" && time.Now().Add(time.Minute).Before(t.AuthorizationToken.NotAfter)
+}
+
 // SetAuthHeader returns a string that may be used for the 'Authorization' header used for Minecraft
 // related endpoints that need an XBOX Live authenticated caller.
 func (t XBLToken) SetAuthHeader(r *http.Request) {
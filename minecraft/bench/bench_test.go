@@ -0,0 +1,203 @@
+package bench_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkLogin measures the full login sequence of a client, from dialing the server until the client has
+// spawned, with authentication disabled.
+func BenchmarkLogin(b *testing.B) {
+	addr, closeFn := serve(b, minecraft.ListenConfig{AuthenticationDisabled: true}, nil)
+	defer closeFn()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn := dial(b, minecraft.Dialer{}, addr)
+		_ = conn.Close()
+	}
+}
+
+// BenchmarkPacketThroughput measures the amount of packets per second that a client can send to a server,
+// flushing once every 16 packets.
+func BenchmarkPacketThroughput(b *testing.B) {
+	var received atomic.Int64
+	done := make(chan struct{})
+	addr, closeFn := serve(b, minecraft.ListenConfig{AuthenticationDisabled: true}, func(conn *minecraft.Conn) {
+		for {
+			pk, err := conn.ReadPacket()
+			if err != nil {
+				return
+			}
+			if _, ok := pk.(*packet.Text); ok && received.Add(1) == int64(b.N) {
+				close(done)
+			}
+		}
+	})
+	defer closeFn()
+	conn := dial(b, minecraft.Dialer{}, addr)
+	defer conn.Close()
+
+	pk := &packet.Text{TextType: packet.TextTypeChat, SourceName: "bench", Message: "The quick brown fox jumps over the lazy dog."}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := conn.WritePacket(pk); err != nil {
+			b.Fatalf("write packet: %v", err)
+		}
+		if i%16 == 15 {
+			if err := conn.Flush(); err != nil {
+				b.Fatalf("flush: %v", err)
+			}
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		b.Fatalf("flush: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		b.Fatalf("server received %v out of %v packets", received.Load(), b.N)
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "packets/s")
+}
+
+// BenchmarkResourcePackTransfer measures the login sequence of a client that downloads a resource pack of
+// 8MB of incompressible data from the server.
+func BenchmarkResourcePackTransfer(b *testing.B) {
+	pack := resourcePack(b, 8<<20)
+	addr, closeFn := serve(b, minecraft.ListenConfig{AuthenticationDisabled: true, ResourcePacks: []*resource.Pack{pack}}, nil)
+	defer closeFn()
+
+	d := minecraft.Dialer{}
+
+	b.SetBytes(int64(pack.Len()))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		conn := dial(b, d, addr)
+		if len(conn.ResourcePacks()) != 1 {
+			b.Fatalf("expected 1 resource pack to be downloaded, got %v", len(conn.ResourcePacks()))
+		}
+		_ = conn.Close()
+	}
+}
+
+// BenchmarkIdleConnMemory measures the heap memory held by a connection that has spawned and is idle, on the
+// client and server side combined.
+func BenchmarkIdleConnMemory(b *testing.B) {
+	addr, closeFn := serve(b, minecraft.ListenConfig{AuthenticationDisabled: true}, nil)
+	defer closeFn()
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	conns := make([]*minecraft.Conn, b.N)
+	for i := range conns {
+		conns[i] = dial(b, minecraft.Dialer{}, addr)
+	}
+	// Wait for the first flush of all connections, so that buffers allocated lazily are included.
+	time.Sleep(time.Second / 10)
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.StopTimer()
+
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(b.N), "B/conn")
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+// serveID is used to give every server started its own address on the pipe network.
+var serveID atomic.Int32
+
+// serve starts a server on the pipe network using the ListenConfig passed and returns its address. Every
+// connection accepted is spawned, after which it is passed to handle if not nil. If handle is nil, packets
+// received by the connection are discarded.
+func serve(b *testing.B, cfg minecraft.ListenConfig, handle func(conn *minecraft.Conn)) (string, func()) {
+	// The address must be a valid UDP address, as the client data sent during login is validated.
+	addr := fmt.Sprintf("127.0.0.1:%v", 30000+serveID.Add(1))
+	l, err := cfg.Listen("pipe", addr)
+	if err != nil {
+		b.Fatalf("listen: %v", err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn *minecraft.Conn) {
+				defer conn.Close()
+				if err := conn.StartGame(minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+					return
+				}
+				if handle != nil {
+					handle(conn)
+					return
+				}
+				for {
+					if _, err := conn.ReadPacket(); err != nil {
+						return
+					}
+				}
+			}(c.(*minecraft.Conn))
+		}
+	}()
+	return addr, func() { _ = l.Close() }
+}
+
+// dial connects to the server at the address passed using the Dialer passed and waits for the client to
+// spawn.
+func dial(b *testing.B, d minecraft.Dialer, addr string) *minecraft.Conn {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	conn, err := d.DialContext(ctx, "pipe", addr)
+	if err != nil {
+		b.Fatalf("dial: %v", err)
+	}
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		b.Fatalf("spawn: %v", err)
+	}
+	return conn
+}
+
+// resourcePack creates a resource pack holding a file of n random bytes.
+func resourcePack(b *testing.B, n int) *resource.Pack {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	manifest := fmt.Sprintf(`{"format_version": 2, "header": {"name": "bench", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]}, "modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]}`, uuid.New(), uuid.New())
+	f, err := w.Create("manifest.json")
+	if err != nil {
+		b.Fatalf("create manifest: %v", err)
+	}
+	_, _ = f.Write([]byte(manifest))
+
+	data := make([]byte, n)
+	_, _ = rand.Read(data)
+	if f, err = w.CreateHeader(&zip.FileHeader{Name: "textures/noise.bin", Method: zip.Store}); err != nil {
+		b.Fatalf("create file: %v", err)
+	}
+	_, _ = f.Write(data)
+	if err := w.Close(); err != nil {
+		b.Fatalf("close zip: %v", err)
+	}
+	pack, err := resource.ReadBytes(buf.Bytes())
+	if err != nil {
+		b.Fatalf("read pack: %v", err)
+	}
+	return pack
+}
@@ -0,0 +1,10 @@
+// Package bench holds end-to-end benchmarks of gophertunnel. The benchmarks run a Listener and a Dialer in
+// the same process, connected through an in-memory network, so that the results only depend on the
+// performance of the Encoder, Decoder and Conn and not on that of the network stack. They cover the latency
+// of the login sequence, the throughput of packets and resource packs and the memory held by an idle
+// connection. Compare results of the benchmarks before and after a change using benchstat:
+//
+//	go test -run NONE -bench . -count 10 ./minecraft/bench > old.txt
+//	go test -run NONE -bench . -count 10 ./minecraft/bench > new.txt
+//	benchstat old.txt new.txt
+package bench
@@ -0,0 +1,98 @@
+package minecraft
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/internal"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"sync"
+)
+
+// BlobCache is a server-side store of blobs, keyed by their hash, used to serve clients that have the client
+// blob cache enabled (see Conn.ClientCacheEnabled). Instead of sending chunk data directly, the server sends
+// only the hashes of the blobs that make up a chunk. The client then responds with a ClientCacheBlobStatus
+// packet, holding the hashes of the blobs it does not yet have, which the BlobCache can produce a
+// ClientCacheMissResponse for.
+// A BlobCache is safe for concurrent use and is typically shared by all connections of a Listener. Blobs
+// are never evicted automatically: BlobCache.Remove must be called when a blob is no longer used.
+type BlobCache struct {
+	mu    sync.RWMutex
+	blobs map[uint64][]byte
+}
+
+// NewBlobCache returns a new, empty BlobCache.
+func NewBlobCache() *BlobCache {
+	return &BlobCache{blobs: make(map[uint64][]byte)}
+}
+
+// BlobHash returns the hash of the blob payload passed: Its xxHash64, which is the hash that the client uses
+// to identify blobs. The hash is deterministic, so that clients that stored the blob in an earlier session
+// do not need to receive it again.
+func BlobHash(payload []byte) uint64 {
+	return internal.XXHash64(payload)
+}
+
+// Store stores the blob payload passed in the BlobCache and returns its hash. The payload must not be
+// modified after it has been stored.
+func (c *BlobCache) Store(payload []byte) uint64 {
+	hash := BlobHash(payload)
+	c.mu.Lock()
+	c.blobs[hash] = payload
+	c.mu.Unlock()
+	return hash
+}
+
+// Blob looks up the payload of a blob by its hash. If no blob with the hash was stored, false is returned.
+func (c *BlobCache) Blob(hash uint64) ([]byte, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	payload, ok := c.blobs[hash]
+	return payload, ok
+}
+
+// Remove removes the blob with the hash passed from the BlobCache.
+func (c *BlobCache) Remove(hash uint64) {
+	c.mu.Lock()
+	delete(c.blobs, hash)
+	c.mu.Unlock()
+}
+
+// Len returns the amount of blobs currently stored in the BlobCache.
+func (c *BlobCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.blobs)
+}
+
+// LevelChunk stores the sub chunks and biomes passed in the BlobCache and returns a LevelChunk packet with
+// the blob cache enabled that refers to them by their hashes. The payload passed holds the remaining data of
+// the chunk, which is the border blocks and block entities, and is sent as is.
+// LevelChunk should only be used for connections that have the client blob cache enabled.
+func (c *BlobCache) LevelChunk(pos protocol.ChunkPos, dimension int32, subChunks [][]byte, biomes, payload []byte) *packet.LevelChunk {
+	hashes := make([]uint64, 0, len(subChunks)+1)
+	for _, sub := range subChunks {
+		hashes = append(hashes, c.Store(sub))
+	}
+	hashes = append(hashes, c.Store(biomes))
+	return &packet.LevelChunk{
+		Position:      pos,
+		Dimension:     dimension,
+		SubChunkCount: uint32(len(subChunks)),
+		CacheEnabled:  true,
+		BlobHashes:    hashes,
+		RawPayload:    payload,
+	}
+}
+
+// MissResponse returns a ClientCacheMissResponse holding the blobs requested in the ClientCacheBlobStatus
+// passed. Hashes of blobs not present in the BlobCache are skipped.
+func (c *BlobCache) MissResponse(status *packet.ClientCacheBlobStatus) *packet.ClientCacheMissResponse {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	blobs := make([]protocol.CacheBlob, 0, len(status.MissHashes))
+	for _, hash := range status.MissHashes {
+		if payload, ok := c.blobs[hash]; ok {
+			blobs = append(blobs, protocol.CacheBlob{Hash: hash, Payload: payload})
+		}
+	}
+	return &packet.ClientCacheMissResponse{Blobs: blobs}
+}
@@ -0,0 +1,198 @@
+// Package book implements the writable and written books that players edit using the BookEdit packet. The
+// client sends every change to a book as a separate edit, such as replacing the text of a page or signing the
+// book. FromPacket turns these packets into typed Edits, which may be applied to a Book to keep track of its
+// content. A Book validates the edits applied to it against the limits enforced by vanilla servers, so that
+// clients cannot create books that other clients fail to display.
+//
+// The NBT of a Book, which holds its pages and, once signed, its title and author, may be set as the NBT of a
+// writable or written book item stack, for example to show a book-based UI to a player.
+package book
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/world/items"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// MaxPages is the maximum amount of pages that a book may have.
+	MaxPages = 50
+	// MaxPageLength is the maximum length in bytes of the text of a page.
+	MaxPageLength = 256
+	// MaxTitleLength is the maximum length in characters of the title of a signed book.
+	MaxTitleLength = 16
+)
+
+const (
+	// WritableBook is the name of the item of books that have not yet been signed.
+	WritableBook = "minecraft:writable_book"
+	// WrittenBook is the name of the item of signed books.
+	WrittenBook = "minecraft:written_book"
+)
+
+// Generation is the generation of a written book, which is increased every time the book is copied.
+type Generation int32
+
+const (
+	GenerationOriginal Generation = iota
+	GenerationCopyOfOriginal
+	GenerationCopyOfCopy
+	GenerationTattered
+)
+
+// Page is a page of a Book.
+type Page struct {
+	// Text is the text written on the page.
+	Text string
+	// Photo is the name of the photo on the page. It is only used by Education Edition.
+	Photo string
+}
+
+// Book holds the content of a writable or written book. The zero value is an empty writable book.
+type Book struct {
+	// Pages holds the pages of the book.
+	Pages []Page
+	// Signed is true if the book was signed, making it a written book. Title, Author, XUID and Generation
+	// are only used if Signed is true.
+	Signed bool
+	// Title is the title of the book.
+	Title string
+	// Author is the author of the book.
+	Author string
+	// XUID is the XUID of the player that signed the book.
+	XUID string
+	// Generation is the generation of the book.
+	Generation Generation
+}
+
+// FromEdits returns a Book that results from applying the edits passed to an empty writable book, such as
+// the edits received from a client while it edits a book. An error is returned if any of the edits is not
+// valid.
+func FromEdits(edits ...Edit) (Book, error) {
+	var b Book
+	for _, e := range edits {
+		if err := b.Apply(e); err != nil {
+			return Book{}, err
+		}
+	}
+	return b, nil
+}
+
+// Apply applies the Edit passed to the Book. An error is returned and the Book is left unchanged if the Edit
+// is not valid for the Book, for example because the page it concerns does not exist, the book would exceed
+// one of the limits of books or the book was already signed.
+func (b *Book) Apply(e Edit) error {
+	if b.Signed {
+		return fmt.Errorf("apply book edit: book is signed")
+	}
+	switch e := e.(type) {
+	case ReplacePage:
+		if e.Page < 0 || e.Page >= MaxPages {
+			return fmt.Errorf("replace page: page %v out of range", e.Page)
+		}
+		if len(e.Text) > MaxPageLength {
+			return fmt.Errorf("replace page: text of %v bytes exceeds limit of %v", len(e.Text), MaxPageLength)
+		}
+		// The client may write to a page beyond the last page, in which case the pages in between are empty.
+		for len(b.Pages) <= e.Page {
+			b.Pages = append(b.Pages, Page{})
+		}
+		b.Pages[e.Page] = Page{Text: e.Text, Photo: e.Photo}
+	case AddPage:
+		if e.Page < 0 || e.Page > len(b.Pages) {
+			return fmt.Errorf("add page: page %v out of range", e.Page)
+		}
+		if len(b.Pages) >= MaxPages {
+			return fmt.Errorf("add page: book already has %v pages", MaxPages)
+		}
+		if len(e.Text) > MaxPageLength {
+			return fmt.Errorf("add page: text of %v bytes exceeds limit of %v", len(e.Text), MaxPageLength)
+		}
+		b.Pages = append(b.Pages, Page{})
+		copy(b.Pages[e.Page+1:], b.Pages[e.Page:])
+		b.Pages[e.Page] = Page{Text: e.Text, Photo: e.Photo}
+	case DeletePage:
+		if e.Page < 0 || e.Page >= len(b.Pages) {
+			return fmt.Errorf("delete page: page %v out of range", e.Page)
+		}
+		b.Pages = append(b.Pages[:e.Page], b.Pages[e.Page+1:]...)
+	case SwapPages:
+		if e.A < 0 || e.A >= len(b.Pages) || e.B < 0 || e.B >= len(b.Pages) {
+			return fmt.Errorf("swap pages: pages %v and %v out of range", e.A, e.B)
+		}
+		b.Pages[e.A], b.Pages[e.B] = b.Pages[e.B], b.Pages[e.A]
+	case Sign:
+		if n := utf8.RuneCountInString(e.Title); n > MaxTitleLength {
+			return fmt.Errorf("sign book: title of %v characters exceeds limit of %v", n, MaxTitleLength)
+		}
+		b.Signed, b.Title, b.Author, b.XUID, b.Generation = true, e.Title, e.Author, e.XUID, GenerationOriginal
+	default:
+		return fmt.Errorf("apply book edit: unknown edit %T", e)
+	}
+	return nil
+}
+
+// ItemName returns the name of the item that the Book is, which is WrittenBook if the Book is signed and
+// WritableBook otherwise.
+func (b Book) ItemName() string {
+	if b.Signed {
+		return WrittenBook
+	}
+	return WritableBook
+}
+
+// NBT returns the NBT of the item of the Book, as set in the NBTData field of a protocol.ItemStack.
+func (b Book) NBT() map[string]any {
+	pages := make([]any, len(b.Pages))
+	for i, page := range b.Pages {
+		pages[i] = map[string]any{"text": page.Text, "photoname": page.Photo}
+	}
+	m := map[string]any{"pages": pages}
+	if !b.Signed {
+		return m
+	}
+	m["title"], m["author"], m["generation"] = b.Title, b.Author, int32(b.Generation)
+	if xuid, err := strconv.ParseInt(b.XUID, 10, 64); err == nil {
+		m["xuid"] = xuid
+	}
+	return m
+}
+
+// FromNBT returns the Book held by the item NBT passed, such as the NBT of a writable or written book item
+// stack received from a client. Fields missing from the NBT are left empty. The Book is treated as signed if
+// the NBT has a title.
+func FromNBT(m map[string]any) Book {
+	var b Book
+	pages, _ := m["pages"].([]any)
+	for _, p := range pages {
+		page, _ := p.(map[string]any)
+		text, _ := page["text"].(string)
+		photo, _ := page["photoname"].(string)
+		b.Pages = append(b.Pages, Page{Text: text, Photo: photo})
+	}
+	b.Title, b.Signed = m["title"].(string)
+	b.Author, _ = m["author"].(string)
+	if generation, ok := m["generation"].(int32); ok {
+		b.Generation = Generation(generation)
+	}
+	if xuid, ok := m["xuid"].(int64); ok {
+		b.XUID = strconv.FormatInt(xuid, 10)
+	}
+	return b
+}
+
+// Stack returns an item stack of a single item of the Book, using the item registry passed to look up the
+// runtime ID of the item returned by Book.ItemName. An error is returned if the registry holds no such item.
+func (b Book) Stack(r *items.Registry) (protocol.ItemStack, error) {
+	rid, ok := r.RuntimeID(b.ItemName())
+	if !ok {
+		return protocol.ItemStack{}, fmt.Errorf("book item stack: no item %v in registry", b.ItemName())
+	}
+	return protocol.ItemStack{
+		ItemType: protocol.ItemType{NetworkID: int32(rid)},
+		Count:    1,
+		NBTData:  b.NBT(),
+	}, nil
+}
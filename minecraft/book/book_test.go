@@ -0,0 +1,91 @@
+package book
+
+import (
+	"bytes"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"reflect"
+	"testing"
+)
+
+func TestFromEdits(t *testing.T) {
+	b, err := FromEdits(
+		ReplacePage{Page: 1, Text: "second"},
+		AddPage{Page: 0, Text: "first"},
+		SwapPages{A: 1, B: 2},
+		DeletePage{Page: 2},
+		Sign{Title: "Title", Author: "Author", XUID: "2535400000000000"},
+	)
+	if err != nil {
+		t.Fatalf("error applying edits: %v", err)
+	}
+	want := []Page{{Text: "first"}, {Text: "second"}}
+	if !reflect.DeepEqual(b.Pages, want) {
+		t.Fatalf("pages: got %v, want %v", b.Pages, want)
+	}
+	if !b.Signed || b.ItemName() != WrittenBook {
+		t.Fatalf("book was not signed")
+	}
+	if err := b.Apply(AddPage{}); err == nil {
+		t.Fatalf("expected error editing signed book")
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	for _, e := range []Edit{
+		ReplacePage{Page: MaxPages},
+		AddPage{Page: 1},
+		DeletePage{Page: 0},
+		SwapPages{A: 0, B: 1},
+		ReplacePage{Text: string(make([]byte, MaxPageLength+1))},
+		Sign{Title: "a title that is too long"},
+	} {
+		var b Book
+		if err := b.Apply(e); err == nil {
+			t.Errorf("expected error applying %#v", e)
+		}
+		if len(b.Pages) != 0 || b.Signed {
+			t.Errorf("book changed after invalid edit %#v", e)
+		}
+	}
+}
+
+func TestPacket(t *testing.T) {
+	for _, e := range []Edit{
+		ReplacePage{Page: 3, Text: "text", Photo: "photo"},
+		AddPage{Page: 2, Text: "text"},
+		DeletePage{Page: 1},
+		SwapPages{A: 4, B: 5},
+		Sign{Title: "Title", Author: "Author", XUID: "1"},
+	} {
+		pk := e.Packet(7)
+		buf := bytes.NewBuffer(nil)
+		pk.Marshal(protocol.NewWriter(buf, 0))
+		read := &packet.BookEdit{}
+		read.Marshal(protocol.NewReader(buf, 0, false))
+
+		got, err := FromPacket(read)
+		if err != nil {
+			t.Fatalf("error reading edit: %v", err)
+		}
+		if got != e || read.InventorySlot != 7 {
+			t.Errorf("edit changed after encoding: got %#v, want %#v", got, e)
+		}
+	}
+}
+
+func TestNBT(t *testing.T) {
+	b := Book{Pages: []Page{{Text: "a"}, {Text: "b", Photo: "c"}}, Signed: true, Title: "Title", Author: "Author", XUID: "123", Generation: GenerationCopyOfOriginal}
+	data, err := nbt.Marshal(b.NBT())
+	if err != nil {
+		t.Fatalf("error encoding NBT: %v", err)
+	}
+	var m map[string]any
+	if err := nbt.Unmarshal(data, &m); err != nil {
+		t.Fatalf("error decoding NBT: %v", err)
+	}
+	if got := FromNBT(m); !reflect.DeepEqual(got, b) {
+		t.Fatalf("book changed after encoding: got %#v, want %#v", got, b)
+	}
+}
@@ -0,0 +1,101 @@
+package book
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Edit is an edit made to a book by a player, as sent in a BookEdit packet. It is one of ReplacePage, AddPage,
+// DeletePage, SwapPages or Sign. Edits may be applied to a Book using Book.Apply.
+type Edit interface {
+	// Packet returns the BookEdit packet holding the Edit, for the book in the inventory slot passed.
+	Packet(slot byte) *packet.BookEdit
+}
+
+// ReplacePage is an Edit that replaces the content of a page. The client sends it after the player stops
+// typing on a page, which may be a page beyond the last page of the book.
+type ReplacePage struct {
+	// Page is the index of the page replaced, starting at 0.
+	Page int
+	// Text is the new text of the page.
+	Text string
+	// Photo is the name of the photo on the page. It is only used by Education Edition.
+	Photo string
+}
+
+// Packet ...
+func (e ReplacePage) Packet(slot byte) *packet.BookEdit {
+	return &packet.BookEdit{ActionType: packet.BookActionReplacePage, InventorySlot: slot, PageNumber: byte(e.Page), Text: e.Text, PhotoName: e.Photo}
+}
+
+// AddPage is an Edit that inserts a new page before the page currently at the index Page.
+type AddPage struct {
+	// Page is the index of the page added, starting at 0. It may be equal to the amount of pages in the book
+	// to add a page at the end.
+	Page int
+	// Text is the text of the page added.
+	Text string
+	// Photo is the name of the photo on the page. It is only used by Education Edition.
+	Photo string
+}
+
+// Packet ...
+func (e AddPage) Packet(slot byte) *packet.BookEdit {
+	return &packet.BookEdit{ActionType: packet.BookActionAddPage, InventorySlot: slot, PageNumber: byte(e.Page), Text: e.Text, PhotoName: e.Photo}
+}
+
+// DeletePage is an Edit that removes a page, moving all pages after it back by one.
+type DeletePage struct {
+	// Page is the index of the page removed, starting at 0.
+	Page int
+}
+
+// Packet ...
+func (e DeletePage) Packet(slot byte) *packet.BookEdit {
+	return &packet.BookEdit{ActionType: packet.BookActionDeletePage, InventorySlot: slot, PageNumber: byte(e.Page)}
+}
+
+// SwapPages is an Edit that swaps the content of two pages.
+type SwapPages struct {
+	// A and B are the indices of the pages swapped, starting at 0.
+	A, B int
+}
+
+// Packet ...
+func (e SwapPages) Packet(slot byte) *packet.BookEdit {
+	return &packet.BookEdit{ActionType: packet.BookActionSwapPages, InventorySlot: slot, PageNumber: byte(e.A), SecondaryPageNumber: byte(e.B)}
+}
+
+// Sign is an Edit that signs a book, turning a writable book into a written book that may no longer be edited.
+type Sign struct {
+	// Title is the title given to the book.
+	Title string
+	// Author is the author given to the book. It is chosen by the client and need not be the name of the
+	// player that signed it.
+	Author string
+	// XUID is the XUID of the player that signed the book.
+	XUID string
+}
+
+// Packet ...
+func (e Sign) Packet(slot byte) *packet.BookEdit {
+	return &packet.BookEdit{ActionType: packet.BookActionSign, InventorySlot: slot, Title: e.Title, Author: e.Author, XUID: e.XUID}
+}
+
+// FromPacket returns the Edit held by the BookEdit packet passed. An error is returned if the action type of
+// the packet is unknown.
+func FromPacket(pk *packet.BookEdit) (Edit, error) {
+	switch pk.ActionType {
+	case packet.BookActionReplacePage:
+		return ReplacePage{Page: int(pk.PageNumber), Text: pk.Text, Photo: pk.PhotoName}, nil
+	case packet.BookActionAddPage:
+		return AddPage{Page: int(pk.PageNumber), Text: pk.Text, Photo: pk.PhotoName}, nil
+	case packet.BookActionDeletePage:
+		return DeletePage{Page: int(pk.PageNumber)}, nil
+	case packet.BookActionSwapPages:
+		return SwapPages{A: int(pk.PageNumber), B: int(pk.SecondaryPageNumber)}, nil
+	case packet.BookActionSign:
+		return Sign{Title: pk.Title, Author: pk.Author, XUID: pk.XUID}, nil
+	}
+	return nil, fmt.Errorf("unknown book edit action type %v", pk.ActionType)
+}
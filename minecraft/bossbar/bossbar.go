@@ -0,0 +1,165 @@
+// Package bossbar implements a boss bar that may be shown to players connected through a minecraft.Listener.
+// It wraps around the BossEvent packet, keeping track of the players that the bar is shown to and sending the
+// packets needed to update them in the order that the client expects.
+package bossbar
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"sync"
+)
+
+// BossBar is a bar shown at the top of the screen of players, with a title and a health percentage. A BossBar
+// may be shown to any number of players at the same time. Changes made to the BossBar are sent to all players
+// that it is currently shown to.
+// The bar is attached to the player's own entity, so that its title and health may be changed freely. As a
+// result, a player can only see one BossBar at a time: Showing a BossBar replaces any other BossBar shown
+// to that player.
+// A BossBar is safe for concurrent use.
+type BossBar struct {
+	mu      sync.Mutex
+	title   string
+	health  float32
+	colour  uint32
+	viewers map[*minecraft.Conn]struct{}
+}
+
+// New creates a new BossBar with the title passed. The BossBar has full health and is purple by default. It
+// is not shown to any players until BossBar.Show is called.
+func New(title string) *BossBar {
+	return &BossBar{
+		title:   title,
+		health:  1,
+		colour:  packet.BossEventColourPurple,
+		viewers: make(map[*minecraft.Conn]struct{}),
+	}
+}
+
+// Show shows the BossBar to the connection passed. If the BossBar is already shown to the connection, Show
+// resends its full state. The connection must have spawned before Show is called, as the client ignores
+// boss events sent before that.
+func (bar *BossBar) Show(conn *minecraft.Conn) error {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	bar.viewers[conn] = struct{}{}
+	return conn.WritePacket(&packet.BossEvent{
+		BossEntityUniqueID: conn.GameData().EntityUniqueID,
+		EventType:          packet.BossEventShow,
+		BossBarTitle:       bar.title,
+		HealthPercentage:   bar.health,
+		Colour:             bar.colour,
+	})
+}
+
+// Hide hides the BossBar from the connection passed. Hide is a no-op if the BossBar is not currently shown to
+// the connection.
+func (bar *BossBar) Hide(conn *minecraft.Conn) error {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	if _, ok := bar.viewers[conn]; !ok {
+		return nil
+	}
+	delete(bar.viewers, conn)
+	return conn.WritePacket(&packet.BossEvent{
+		BossEntityUniqueID: conn.GameData().EntityUniqueID,
+		EventType:          packet.BossEventHide,
+	})
+}
+
+// HideAll hides the BossBar from all connections that it is currently shown to.
+func (bar *BossBar) HideAll() {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	for conn := range bar.viewers {
+		_ = conn.WritePacket(&packet.BossEvent{
+			BossEntityUniqueID: conn.GameData().EntityUniqueID,
+			EventType:          packet.BossEventHide,
+		})
+	}
+	clear(bar.viewers)
+}
+
+// Viewers returns all connections that the BossBar is currently shown to.
+func (bar *BossBar) Viewers() []*minecraft.Conn {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	viewers := make([]*minecraft.Conn, 0, len(bar.viewers))
+	for conn := range bar.viewers {
+		viewers = append(viewers, conn)
+	}
+	return viewers
+}
+
+// Title returns the current title of the BossBar.
+func (bar *BossBar) Title() string {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	return bar.title
+}
+
+// SetTitle changes the title of the BossBar and updates it for all connections it is shown to.
+func (bar *BossBar) SetTitle(title string) {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	bar.title = title
+	bar.broadcast(func(id int64) *packet.BossEvent {
+		return &packet.BossEvent{BossEntityUniqueID: id, EventType: packet.BossEventTitle, BossBarTitle: title}
+	})
+}
+
+// Health returns the current health percentage of the BossBar, ranging from 0 to 1.
+func (bar *BossBar) Health() float32 {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	return bar.health
+}
+
+// SetHealth changes the health percentage of the BossBar and updates it for all connections it is shown to.
+// The health passed is clamped to the range 0-1.
+func (bar *BossBar) SetHealth(health float32) {
+	health = min(max(health, 0), 1)
+
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	bar.health = health
+	bar.broadcast(func(id int64) *packet.BossEvent {
+		return &packet.BossEvent{BossEntityUniqueID: id, EventType: packet.BossEventHealthPercentage, HealthPercentage: health}
+	})
+}
+
+// Colour returns the current colour of the BossBar. It is one of the packet.BossEventColour constants.
+func (bar *BossBar) Colour() uint32 {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	return bar.colour
+}
+
+// SetColour changes the colour of the BossBar and updates it for all connections it is shown to. The colour
+// must be one of the packet.BossEventColour constants.
+func (bar *BossBar) SetColour(colour uint32) {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	bar.colour = colour
+	bar.broadcast(func(id int64) *packet.BossEvent {
+		// The client does not update the colour of the bar through BossEventAppearanceProperties, so the
+		// bar is shown again with the new colour.
+		return &packet.BossEvent{
+			BossEntityUniqueID: id,
+			EventType:          packet.BossEventShow,
+			BossBarTitle:       bar.title,
+			HealthPercentage:   bar.health,
+			Colour:             colour,
+		}
+	})
+}
+
+// broadcast sends the packet returned by f to all viewers of the BossBar. f is called with the entity unique
+// ID of each viewer. broadcast must be called with the mutex of the BossBar held. Connections that were closed
+// are removed from the viewers.
+func (bar *BossBar) broadcast(f func(id int64) *packet.BossEvent) {
+	for conn := range bar.viewers {
+		if err := conn.WritePacket(f(conn.GameData().EntityUniqueID)); err != nil {
+			delete(bar.viewers, conn)
+		}
+	}
+}
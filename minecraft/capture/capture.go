@@ -0,0 +1,221 @@
+// Package capture implements recording the packets of a minecraft.Conn to a file and reading them back. A
+// capture holds every packet read from and written to the connection, along with the direction it was sent
+// in and the time at which it was observed. Packets are stored decrypted and decompressed, exactly as they
+// are passed to packet observers of the connection.
+//
+// Captures may be used for offline debugging of the protocol, or replayed using a Replayer to feed the
+// packets of a real session into code under test. A capture may also be read into a Session, which groups
+// its packets by tick and may be cut, filtered and spliced together with other sessions. Sessions are
+// exported as replay files, which hold an index by tick so that the packets of any tick may be looked up
+// directly, for example to implement replays on a server.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"sync"
+	"time"
+)
+
+// magic is written at the start of every capture.
+var magic = [4]byte{'G', 'T', 'C', 'P'}
+
+// version is the version of the capture format.
+const version = 1
+
+// maxEntrySize is the maximum size of a single entry read from a capture. It prevents corrupted captures
+// from causing huge allocations.
+const maxEntrySize = 1 << 26
+
+// Direction is the direction in which a packet in a capture was sent, seen from the connection that was
+// recorded.
+type Direction uint8
+
+const (
+	// DirectionReceived is the Direction of packets read from the connection.
+	DirectionReceived Direction = iota
+	// DirectionSent is the Direction of packets written to the connection.
+	DirectionSent
+)
+
+// String ...
+func (d Direction) String() string {
+	switch d {
+	case DirectionReceived:
+		return "received"
+	case DirectionSent:
+		return "sent"
+	}
+	return fmt.Sprintf("Direction(%d)", uint8(d))
+}
+
+// Info holds information about the connection that a capture was recorded for. It is written once at the
+// start of a capture.
+type Info struct {
+	// Protocol is the protocol version that the connection used. The payloads of packets in the capture are
+	// encoded using this protocol.
+	Protocol int32
+	// Server is true if the capture was recorded on the server side of the connection, meaning packets
+	// received were sent by a client, and false if it was recorded on the client side.
+	Server bool
+}
+
+// Entry is a single packet in a capture.
+type Entry struct {
+	// Direction is the direction that the packet was sent in.
+	Direction Direction
+	// Time is the time at which the packet was observed.
+	Time time.Time
+	// Header is the header of the packet, holding its ID.
+	Header packet.Header
+	// Payload is the encoded payload of the packet, without its header.
+	Payload []byte
+}
+
+// Writer writes a capture to an io.Writer. A Writer is safe for concurrent use. Entries are buffered, so
+// Flush must be called once done writing.
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	hdr bytes.Buffer
+	buf []byte
+}
+
+// NewWriter creates a Writer that writes a capture to the io.Writer passed, starting with the Info passed.
+func NewWriter(w io.Writer, info Info) (*Writer, error) {
+	writer := &Writer{w: bufio.NewWriter(w)}
+	var flags byte
+	if info.Server {
+		flags |= 1
+	}
+	hdr := append(magic[:], version, flags)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(info.Protocol))
+	if _, err := writer.w.Write(hdr); err != nil {
+		return nil, fmt.Errorf("write capture header: %w", err)
+	}
+	return writer, nil
+}
+
+// Write writes the Entry passed to the capture.
+func (w *Writer) Write(e Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.hdr.Reset()
+	_ = e.Header.Write(&w.hdr)
+
+	b := append(w.buf[:0], byte(e.Direction))
+	b = binary.LittleEndian.AppendUint64(b, uint64(e.Time.UnixNano()))
+	b = binary.AppendUvarint(b, uint64(w.hdr.Len()+len(e.Payload)))
+	b = append(b, w.hdr.Bytes()...)
+	w.buf = b
+
+	if _, err := w.w.Write(b); err != nil {
+		return fmt.Errorf("write capture entry: %w", err)
+	}
+	if _, err := w.w.Write(e.Payload); err != nil {
+		return fmt.Errorf("write capture entry: %w", err)
+	}
+	return nil
+}
+
+// Flush writes all buffered entries to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+// Reader reads a capture written by a Writer from an io.Reader.
+type Reader struct {
+	r    *bufio.Reader
+	info Info
+}
+
+// NewReader creates a Reader that reads a capture from the io.Reader passed. An error is returned if the
+// io.Reader does not start with a valid capture header.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+	var hdr [10]byte
+	if _, err := io.ReadFull(reader.r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("read capture header: %w", err)
+	}
+	if [4]byte(hdr[:4]) != magic {
+		return nil, fmt.Errorf("read capture header: not a capture")
+	}
+	if hdr[4] != version {
+		return nil, fmt.Errorf("read capture header: unsupported version %v", hdr[4])
+	}
+	reader.info = Info{Server: hdr[5]&1 != 0, Protocol: int32(binary.LittleEndian.Uint32(hdr[6:]))}
+	return reader, nil
+}
+
+// Info returns the Info written at the start of the capture.
+func (r *Reader) Info() Info {
+	return r.info
+}
+
+// Next reads the next Entry from the capture. io.EOF is returned once the end of the capture is reached.
+func (r *Reader) Next() (Entry, error) {
+	var fixed [9]byte
+	if _, err := io.ReadFull(r.r, fixed[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Entry{}, io.EOF
+		}
+		return Entry{}, fmt.Errorf("read capture entry: %w", err)
+	}
+	l, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return Entry{}, fmt.Errorf("read capture entry: %w", unexpectedEOF(err))
+	}
+	if l > maxEntrySize {
+		return Entry{}, fmt.Errorf("read capture entry: size %v exceeds maximum of %v", l, maxEntrySize)
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return Entry{}, fmt.Errorf("read capture entry: %w", unexpectedEOF(err))
+	}
+	buf := bytes.NewBuffer(data)
+	e := Entry{Direction: Direction(fixed[0]), Time: time.Unix(0, int64(binary.LittleEndian.Uint64(fixed[1:])))}
+	if err := e.Header.Read(buf); err != nil {
+		return Entry{}, fmt.Errorf("read capture entry: read packet header: %w", err)
+	}
+	e.Payload = buf.Bytes()
+	return e, nil
+}
+
+// unexpectedEOF turns io.EOF into io.ErrUnexpectedEOF, as the end of a capture may only be reached between
+// entries.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decode decodes the payload of the Entry passed into a packet from the pool passed. Packets not found in
+// the pool are returned as a *packet.Unknown.
+func decode(e Entry, pool packet.Pool, shieldID int32) (pk packet.Packet, err error) {
+	if f, ok := pool[e.Header.PacketID]; ok {
+		pk = f()
+	} else {
+		pk = &packet.Unknown{PacketID: e.Header.PacketID}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decode packet %T: %v", pk, r)
+		}
+	}()
+	buf := bytes.NewBuffer(e.Payload)
+	pk.Marshal(protocol.NewReader(buf, shieldID, false))
+	if buf.Len() != 0 {
+		return pk, fmt.Errorf("decode packet %T: %v unread bytes left", pk, buf.Len())
+	}
+	return pk, nil
+}
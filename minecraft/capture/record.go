@@ -0,0 +1,52 @@
+package capture
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Record starts recording all packets read from and written to the minecraft.Conn passed to a capture written
+// to the io.Writer passed. Recording continues until the stop function returned is called, which flushes the
+// capture and returns the first error that occurred while writing it, if any. Record should be called before
+// any packets are read from or written to the Conn that should be part of the capture.
+func Record(conn *minecraft.Conn, w io.Writer) (stop func() error, err error) {
+	// Only connections obtained through a Listener have a client protocol set.
+	info := Info{Protocol: conn.Protocol().ID(), Server: conn.ClientProtocol() != 0}
+	writer, err := NewWriter(w, info)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		writeErr error
+		remote   = conn.RemoteAddr().String()
+	)
+	remove := conn.AddPacketObserver(func(header packet.Header, payload []byte, src, dst net.Addr) {
+		direction := DirectionSent
+		if src.String() == remote {
+			direction = DirectionReceived
+		}
+		if err := writer.Write(Entry{Direction: direction, Time: time.Now(), Header: header, Payload: payload}); err != nil {
+			mu.Lock()
+			if writeErr == nil {
+				writeErr = err
+			}
+			mu.Unlock()
+		}
+	})
+	return func() error {
+		remove()
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("flush capture: %w", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return writeErr
+	}, nil
+}
@@ -0,0 +1,119 @@
+package capture
+
+import (
+	"errors"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Replayer feeds the packets received in a capture back to code under test, taking the place of the
+// minecraft.Conn that the capture was recorded for. ReadPacket returns the packets that the recorded
+// connection received, in the same order, and packets written using WritePacket are collected so that they
+// may be compared with the packets sent in the capture. Packets are decoded using the latest protocol, so
+// captures of connections using other protocols cannot be replayed.
+// A Replayer is safe for concurrent use.
+type Replayer struct {
+	r *Reader
+
+	mu       sync.Mutex
+	received packet.Pool
+	sent     packet.Pool
+	shieldID int32
+	expected []packet.Packet
+	written  []packet.Packet
+}
+
+// NewReplayer creates a Replayer that replays the capture read by the Reader passed.
+func NewReplayer(r *Reader) *Replayer {
+	rp := &Replayer{r: r, received: packet.NewServerPool(), sent: packet.NewClientPool()}
+	if r.Info().Server {
+		rp.received, rp.sent = rp.sent, rp.received
+	}
+	return rp
+}
+
+// ReadPacket returns the next packet received by the recorded connection. Packets sent by the recorded
+// connection that are passed in the meantime are stored, so that they may be obtained using Expected. Once
+// all packets of the capture are read, io.EOF is returned.
+func (rp *Replayer) ReadPacket() (packet.Packet, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for {
+		e, err := rp.r.Next()
+		if err != nil {
+			return nil, err
+		}
+		pool := rp.received
+		if e.Direction == DirectionSent {
+			pool = rp.sent
+		}
+		pk, err := decode(e, pool, rp.shieldID)
+		if err != nil {
+			return nil, err
+		}
+		rp.trackShieldID(pk)
+		if e.Direction == DirectionReceived {
+			return pk, nil
+		}
+		rp.expected = append(rp.expected, pk)
+	}
+}
+
+// WritePacket stores the packet passed, so that it may be obtained using Written. It never returns an error.
+func (rp *Replayer) WritePacket(pk packet.Packet) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.written = append(rp.written, pk)
+	return nil
+}
+
+// Written returns all packets written to the Replayer using WritePacket so far.
+func (rp *Replayer) Written() []packet.Packet {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return slices.Clone(rp.written)
+}
+
+// Expected returns the packets that the recorded connection sent, up to the packet last returned by
+// ReadPacket. Code under test that behaves like the recorded application writes the same packets.
+func (rp *Replayer) Expected() []packet.Packet {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return slices.Clone(rp.expected)
+}
+
+// Close is a no-op. It is present so that the Replayer may be used in place of a connection.
+func (rp *Replayer) Close() error {
+	return nil
+}
+
+// Drain reads all remaining packets of the capture, returning the amount of packets received that were
+// read. Packets sent by the recorded connection are stored, so that they may be obtained using Expected.
+func (rp *Replayer) Drain() (int, error) {
+	n := 0
+	for {
+		if _, err := rp.ReadPacket(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+		n++
+	}
+}
+
+// trackShieldID updates the shield ID used to decode item stacks if the packet passed is a StartGame packet,
+// just like a minecraft.Conn does.
+func (rp *Replayer) trackShieldID(pk packet.Packet) {
+	startGame, ok := pk.(*packet.StartGame)
+	if !ok {
+		return
+	}
+	for _, item := range startGame.Items {
+		if item.Name == "minecraft:shield" {
+			rp.shieldID = int32(item.RuntimeID)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// A replay file is the export format of a Session. Unlike a capture, which is written while recording and
+// may only be read from start to end, a replay file holds an index by tick, so that the entries of any tick
+// may be read without reading the entries before it. Replay files are written using Session.WriteReplay and
+// read using OpenReplay. All integers are little endian, and uvarints are encoded as by
+// binary.AppendUvarint. A replay file is laid out as follows:
+//
+//	header:
+//	  magic         [4]byte "GTRP"
+//	  version       uint8   currently 1
+//	  flags         uint8   bit 0 set if the session was recorded on the server side
+//	  protocol      int32   protocol version of the payloads
+//	  start         int64   start time of the session in nanoseconds since the Unix epoch
+//	  tick duration int64   duration of a tick in nanoseconds
+//	  index size    uint32  size of the index in bytes
+//	index:
+//	  count         uvarint number of ticks in the index
+//	  count times, sorted by tick:
+//	    tick        uvarint tick, counted from the start time
+//	    offset      uvarint offset of the first entry of the tick, from the start of the entries
+//	    entries     uvarint number of entries observed in the tick
+//	entries, sorted by time, encoded the same way as entries in a capture:
+//	  direction     uint8   0 if received, 1 if sent
+//	  time          int64   time of the entry in nanoseconds since the Unix epoch
+//	  size          uvarint size of the packet header and payload
+//	  packet        [size]byte packet header followed by the payload
+//
+// Ticks without entries are not present in the index.
+
+// replayMagic is written at the start of every replay file.
+var replayMagic = [4]byte{'G', 'T', 'R', 'P'}
+
+// replayVersion is the version of the replay file format.
+const replayVersion = 1
+
+// replayHeaderSize is the size of the fixed header of a replay file.
+const replayHeaderSize = 4 + 1 + 1 + 4 + 8 + 8 + 4
+
+// WriteReplay exports the Session as a replay file to the io.Writer passed.
+func (s *Session) WriteReplay(w io.Writer) error {
+	var (
+		entries bytes.Buffer
+		index   []byte
+		ticks   int
+	)
+	writer := &Writer{w: bufio.NewWriter(&entries)}
+	for i := 0; i < len(s.Entries); {
+		// Entries before the start of the Session are part of the first tick.
+		tick, n := max(s.Tick(s.Entries[i]), 0), 1
+		for i+n < len(s.Entries) && max(s.Tick(s.Entries[i+n]), 0) == tick {
+			n++
+		}
+		index = binary.AppendUvarint(index, uint64(tick))
+		index = binary.AppendUvarint(index, uint64(entries.Len()+writer.w.Buffered()))
+		index = binary.AppendUvarint(index, uint64(n))
+		ticks++
+
+		for _, e := range s.Entries[i : i+n] {
+			if err := writer.Write(e); err != nil {
+				return fmt.Errorf("write replay: %w", err)
+			}
+		}
+		i += n
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("write replay: %w", err)
+	}
+	index = append(binary.AppendUvarint(nil, uint64(ticks)), index...)
+
+	var flags byte
+	if s.Info.Server {
+		flags |= 1
+	}
+	hdr := append(replayMagic[:], replayVersion, flags)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(s.Info.Protocol))
+	hdr = binary.LittleEndian.AppendUint64(hdr, uint64(s.Start.UnixNano()))
+	hdr = binary.LittleEndian.AppendUint64(hdr, uint64(TickDuration))
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(len(index)))
+	for _, b := range [][]byte{hdr, index, entries.Bytes()} {
+		if _, err := w.Write(b); err != nil {
+			return fmt.Errorf("write replay: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReplayFile is a replay file opened using OpenReplay. Entries are read from the underlying io.ReaderAt
+// when they are requested, so that only the entries of the ticks requested are held in memory. A ReplayFile
+// is safe for concurrent use if its io.ReaderAt is.
+type ReplayFile struct {
+	r            io.ReaderAt
+	info         Info
+	start        time.Time
+	tickDuration time.Duration
+	ticks        []replayTick
+	entriesStart int64
+}
+
+// replayTick is a single tick in the index of a replay file.
+type replayTick struct {
+	tick, offset, count uint64
+}
+
+// OpenReplay opens a replay file written using Session.WriteReplay, reading its header and index from the
+// io.ReaderAt passed.
+func OpenReplay(r io.ReaderAt) (*ReplayFile, error) {
+	var hdr [replayHeaderSize]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return nil, fmt.Errorf("read replay header: %w", unexpectedEOF(err))
+	}
+	if [4]byte(hdr[:4]) != replayMagic {
+		return nil, fmt.Errorf("read replay header: not a replay file")
+	}
+	if hdr[4] != replayVersion {
+		return nil, fmt.Errorf("read replay header: unsupported version %v", hdr[4])
+	}
+	f := &ReplayFile{
+		r:            r,
+		info:         Info{Server: hdr[5]&1 != 0, Protocol: int32(binary.LittleEndian.Uint32(hdr[6:]))},
+		start:        time.Unix(0, int64(binary.LittleEndian.Uint64(hdr[10:]))),
+		tickDuration: time.Duration(binary.LittleEndian.Uint64(hdr[18:])),
+	}
+	indexSize := binary.LittleEndian.Uint32(hdr[26:])
+	if indexSize > maxEntrySize {
+		return nil, fmt.Errorf("read replay index: size %v exceeds maximum of %v", indexSize, maxEntrySize)
+	}
+	index := make([]byte, indexSize)
+	if _, err := r.ReadAt(index, replayHeaderSize); err != nil {
+		return nil, fmt.Errorf("read replay index: %w", unexpectedEOF(err))
+	}
+	f.entriesStart = replayHeaderSize + int64(indexSize)
+
+	buf := bytes.NewReader(index)
+	n, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return nil, fmt.Errorf("read replay index: %w", unexpectedEOF(err))
+	}
+	if n > uint64(indexSize) {
+		return nil, fmt.Errorf("read replay index: tick count %v exceeds index size", n)
+	}
+	f.ticks = make([]replayTick, n)
+	for i := range f.ticks {
+		for _, v := range []*uint64{&f.ticks[i].tick, &f.ticks[i].offset, &f.ticks[i].count} {
+			if *v, err = binary.ReadUvarint(buf); err != nil {
+				return nil, fmt.Errorf("read replay index: %w", unexpectedEOF(err))
+			}
+		}
+	}
+	return f, nil
+}
+
+// Info returns the Info of the Session that the replay file was exported from.
+func (f *ReplayFile) Info() Info {
+	return f.info
+}
+
+// Start returns the time at which the Session that the replay file was exported from started.
+func (f *ReplayFile) Start() time.Time {
+	return f.start
+}
+
+// TickDuration returns the duration of a single tick in the replay file.
+func (f *ReplayFile) TickDuration() time.Duration {
+	return f.tickDuration
+}
+
+// Ticks returns the ticks in which entries were observed, in ascending order.
+func (f *ReplayFile) Ticks() []int64 {
+	ticks := make([]int64, len(f.ticks))
+	for i, t := range f.ticks {
+		ticks[i] = int64(t.tick)
+	}
+	return ticks
+}
+
+// ReadTick reads the entries observed in the tick passed. If no entries were observed in the tick, ReadTick
+// returns no entries and no error.
+func (f *ReplayFile) ReadTick(tick int64) ([]Entry, error) {
+	i, ok := slices.BinarySearchFunc(f.ticks, tick, func(t replayTick, tick int64) int {
+		return cmp.Compare(int64(t.tick), tick)
+	})
+	if !ok {
+		return nil, nil
+	}
+	return f.read(f.ticks[i])
+}
+
+// Session reads all entries of the replay file into a Session.
+func (f *ReplayFile) Session() (*Session, error) {
+	s := &Session{Info: f.info, Start: f.start}
+	for _, t := range f.ticks {
+		entries, err := f.read(t)
+		if err != nil {
+			return nil, err
+		}
+		s.Entries = append(s.Entries, entries...)
+	}
+	return s, nil
+}
+
+// read reads the entries of the replayTick passed.
+func (f *ReplayFile) read(t replayTick) ([]Entry, error) {
+	r := &Reader{r: bufio.NewReader(io.NewSectionReader(f.r, f.entriesStart+int64(t.offset), 1<<62)), info: f.info}
+	entries := make([]Entry, 0, min(t.count, 1024))
+	for i := uint64(0); i < t.count; i++ {
+		e, err := r.Next()
+		if err != nil {
+			return nil, fmt.Errorf("read replay tick %v: %w", t.tick, unexpectedEOF(err))
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
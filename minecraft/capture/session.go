@@ -0,0 +1,165 @@
+package capture
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// TickDuration is the duration of a single tick of a Session. Entries of a Session are grouped by the tick in
+// which they were observed, counted from the start of the Session.
+const TickDuration = time.Second / 20
+
+// Session is a capture held in memory, so that it may be edited and exported. A Session is typically read
+// from a capture written by Record using ReadSession. The methods of Session that edit it return a new
+// Session and leave the Session they are called on unchanged, though the payloads of entries are shared.
+type Session struct {
+	// Info holds information about the connection that the Session was recorded for.
+	Info Info
+	// Start is the time at which the Session started. The tick of an Entry is the number of whole ticks
+	// between Start and the time of the Entry.
+	Start time.Time
+	// Entries holds the entries of the Session, sorted by time.
+	Entries []Entry
+}
+
+// ReadSession reads all entries of the capture read by the Reader passed into a Session. The Session starts
+// at the time of the first entry of the capture.
+func ReadSession(r *Reader) (*Session, error) {
+	s := &Session{Info: r.Info()}
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	if len(s.Entries) != 0 {
+		s.Start = s.Entries[0].Time
+	}
+	return s, nil
+}
+
+// Tick returns the tick of the Session in which the Entry passed was observed.
+func (s *Session) Tick(e Entry) int64 {
+	return int64(e.Time.Sub(s.Start) / TickDuration)
+}
+
+// Ticks returns the number of ticks that the Session spans: The tick of its last Entry plus one, or zero if
+// the Session has no entries.
+func (s *Session) Ticks() int64 {
+	if len(s.Entries) == 0 {
+		return 0
+	}
+	return s.Tick(s.Entries[len(s.Entries)-1]) + 1
+}
+
+// Range returns the entries observed in the ticks from up to, but not including, to.
+func (s *Session) Range(from, to int64) []Entry {
+	i, _ := slices.BinarySearchFunc(s.Entries, from, func(e Entry, tick int64) int {
+		return cmpTick(s.Tick(e), tick)
+	})
+	j, _ := slices.BinarySearchFunc(s.Entries, to, func(e Entry, tick int64) int {
+		return cmpTick(s.Tick(e), tick)
+	})
+	return s.Entries[i:max(i, j)]
+}
+
+// Cut returns a Session holding only the entries observed in the ticks from up to, but not including, to.
+// The Session returned starts at tick from, so that the first tick of the Session returned is tick from of
+// the Session that Cut is called on.
+func (s *Session) Cut(from, to int64) *Session {
+	return &Session{
+		Info:    s.Info,
+		Start:   s.Start.Add(time.Duration(from) * TickDuration),
+		Entries: slices.Clone(s.Range(from, to)),
+	}
+}
+
+// Filter returns a Session holding only the entries for which the function passed returns true.
+func (s *Session) Filter(f func(e Entry) bool) *Session {
+	filtered := &Session{Info: s.Info, Start: s.Start}
+	for _, e := range s.Entries {
+		if f(e) {
+			filtered.Entries = append(filtered.Entries, e)
+		}
+	}
+	return filtered
+}
+
+// Splice returns a Session with all entries of the Session other inserted at the tick passed. The entries of
+// other keep their position relative to the start of other, and all entries of s from the tick passed onwards
+// are moved back by the number of ticks that other spans. An error is returned if other was recorded using a
+// different protocol or on a different side of a connection.
+func (s *Session) Splice(tick int64, other *Session) (*Session, error) {
+	if other.Info != s.Info {
+		return nil, fmt.Errorf("splice session: info %+v does not match %+v", other.Info, s.Info)
+	}
+	at := s.Start.Add(time.Duration(tick) * TickDuration)
+	shift := time.Duration(other.Ticks()) * TickDuration
+
+	before := s.Range(0, tick)
+	spliced := &Session{Info: s.Info, Start: s.Start, Entries: make([]Entry, 0, len(s.Entries)+len(other.Entries))}
+	spliced.Entries = append(spliced.Entries, before...)
+	for _, e := range other.Entries {
+		e.Time = at.Add(e.Time.Sub(other.Start))
+		spliced.Entries = append(spliced.Entries, e)
+	}
+	for _, e := range s.Entries[len(before):] {
+		e.Time = e.Time.Add(shift)
+		spliced.Entries = append(spliced.Entries, e)
+	}
+	return spliced, nil
+}
+
+// Append returns a Session with all entries of the Session other added after the last tick of s. It is
+// equivalent to calling Splice with the number of ticks of s.
+func (s *Session) Append(other *Session) (*Session, error) {
+	return s.Splice(s.Ticks(), other)
+}
+
+// WriteCapture writes the Session as a capture to the io.Writer passed, so that it may be read again using a
+// Reader.
+func (s *Session) WriteCapture(w io.Writer) error {
+	writer, err := NewWriter(w, s.Info)
+	if err != nil {
+		return err
+	}
+	for _, e := range s.Entries {
+		if err := writer.Write(e); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Replayer returns a Replayer that replays the entries of the Session.
+func (s *Session) Replayer() (*Replayer, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := s.WriteCapture(buf); err != nil {
+		return nil, err
+	}
+	r, err := NewReader(buf)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(r), nil
+}
+
+// cmpTick compares the tick of an Entry with the tick searched for, treating entries before the start of a
+// Session as part of tick 0.
+func cmpTick(entryTick, tick int64) int {
+	entryTick = max(entryTick, 0)
+	switch {
+	case entryTick < tick:
+		return -1
+	case entryTick > tick:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,405 @@
+package minecraft
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/clock"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Faults describes the faults injected into the batches travelling in one direction of a connection of a
+// Chaos network. A batch is the unit in which a Conn writes packets to the network, so every fault affects
+// all packets in the batch.
+type Faults struct {
+	// Latency is the delay added to every batch.
+	Latency time.Duration
+	// Jitter is the maximum random delay added to every batch on top of Latency. Batches are still delivered
+	// in the order that they were sent, unless they are reordered.
+	Jitter time.Duration
+	// Reorder is the probability, from 0 to 1, that a batch is held back by ReorderDelay, so that batches sent
+	// after it may be delivered before it.
+	Reorder float64
+	// ReorderDelay is the delay added to batches that are reordered. If zero, 100 milliseconds is used.
+	ReorderDelay time.Duration
+	// Duplicate is the probability, from 0 to 1, that a batch is delivered twice.
+	Duplicate float64
+	// Drop is the probability, from 0 to 1, that a batch is never delivered.
+	Drop float64
+}
+
+// Chaos is a Network that wraps another Network and injects latency, jitter, reordering, duplication and
+// drops between the Conn and the connections of the wrapped Network, for resilience testing. The faults are
+// injected into batches, after compression and encryption, so reordering, duplicating or dropping batches
+// once encryption is enabled will make decryption fail. ListenConfig.EncryptionDisabledFunc may be used to
+// disable encryption when testing these faults beyond the login sequence.
+// Like RakNet, a Chaos is used by registering it under an ID using RegisterNetwork:
+//
+//	minecraft.RegisterNetwork("chaos", func(l *slog.Logger) minecraft.Network {
+//		return minecraft.Chaos{Network: minecraft.RakNet{ErrorLog: l}, Send: minecraft.Faults{Latency: time.Millisecond * 50}}
+//	})
+type Chaos struct {
+	// Network is the Network wrapped. It must be non-nil.
+	Network Network
+	// Send holds the Faults injected into batches written to connections, and Receive the Faults injected
+	// into batches read from connections. Connections on both ends of a Chaos network thus see the Send
+	// Faults of one end combined with the Receive Faults of the other.
+	Send, Receive Faults
+	// Seed is the seed of the random faults injected into every connection. If zero, a random seed is used
+	// for every connection. A non-zero Seed makes the faults of a connection reproducible, provided that the
+	// same batches are sent in the same order.
+	Seed int64
+	// Clock is the clock.Clock used to delay batches. If nil, clock.System is used.
+	Clock clock.Clock
+}
+
+// DialContext ...
+func (c Chaos) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := c.Network.DialContext(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return c.wrap(conn), nil
+}
+
+// PingContext ...
+func (c Chaos) PingContext(ctx context.Context, address string) (response []byte, err error) {
+	return c.Network.PingContext(ctx, address)
+}
+
+// Listen ...
+func (c Chaos) Listen(address string) (NetworkListener, error) {
+	l, err := c.Network.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	return chaosListener{NetworkListener: l, c: c}, nil
+}
+
+// Compression ...
+func (c Chaos) Compression(conn net.Conn) packet.Compression {
+	if cc, ok := conn.(*chaosConn); ok {
+		conn = cc.Conn
+	}
+	return c.Network.Compression(conn)
+}
+
+// wrap wraps a net.Conn of the wrapped Network in a chaosConn.
+func (c Chaos) wrap(conn net.Conn) *chaosConn {
+	clk, seed := c.Clock, c.Seed
+	if clk == nil {
+		clk = clock.System
+	}
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	cc := &chaosConn{
+		Conn:   conn,
+		c:      c,
+		in:     make(chan chaosBatch),
+		closed: make(chan struct{}),
+	}
+	cc.send = newChaosQueue(c.Send, clk, rand.New(rand.NewSource(seed)), cc.closed, cc.deliverSend)
+	cc.recv = newChaosQueue(c.Receive, clk, rand.New(rand.NewSource(seed+1)), cc.closed, cc.deliverReceive)
+	go cc.receive()
+	return cc
+}
+
+// chaosListener wraps a NetworkListener so that all connections it accepts are wrapped in a chaosConn.
+type chaosListener struct {
+	NetworkListener
+	c Chaos
+}
+
+// Accept ...
+func (l chaosListener) Accept() (net.Conn, error) {
+	conn, err := l.NetworkListener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.c.wrap(conn), nil
+}
+
+// chaosBatch is a single batch travelling through a chaosQueue, or an error that ended the connection.
+type chaosBatch struct {
+	data []byte
+	err  error
+}
+
+// chaosConn is a net.Conn of a Chaos network. Batches written to it are delayed by its send chaosQueue before
+// being written to the wrapped net.Conn, and batches read from the wrapped net.Conn are delayed by its
+// receive chaosQueue before they are returned by ReadPacket.
+type chaosConn struct {
+	net.Conn
+	c Chaos
+
+	send, recv *chaosQueue
+
+	// in holds batches that passed the receive chaosQueue and may be returned by ReadPacket.
+	in chan chaosBatch
+	// remaining holds data of a batch not yet returned by Read, because the slice passed to Read was too
+	// short to hold it.
+	remaining []byte
+	// err holds the first error returned by writing to the wrapped net.Conn.
+	err atomic.Pointer[error]
+
+	once   sync.Once
+	closed chan struct{}
+}
+
+// ReadPacket reads the next batch that passed the receive Faults of the chaosConn.
+func (conn *chaosConn) ReadPacket() ([]byte, error) {
+	select {
+	case b := <-conn.in:
+		return b.data, b.err
+	case <-conn.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Read reads the data of the next batch into b.
+func (conn *chaosConn) Read(b []byte) (int, error) {
+	if len(conn.remaining) == 0 {
+		data, err := conn.ReadPacket()
+		if err != nil {
+			return 0, err
+		}
+		conn.remaining = data
+	}
+	n := copy(b, conn.remaining)
+	conn.remaining = conn.remaining[n:]
+	return n, nil
+}
+
+// Write queues b to be written to the wrapped net.Conn once the send Faults of the chaosConn allow it. If an
+// earlier write to the wrapped net.Conn failed, its error is returned.
+func (conn *chaosConn) Write(b []byte) (int, error) {
+	if err := conn.err.Load(); err != nil {
+		return 0, *err
+	}
+	select {
+	case <-conn.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	conn.send.push(chaosBatch{data: slices.Clone(b)})
+	return len(b), nil
+}
+
+// Close writes all batches still queued to the wrapped net.Conn, ignoring the remainder of their delay, and
+// closes it.
+func (conn *chaosConn) Close() error {
+	conn.once.Do(func() {
+		close(conn.closed)
+		for _, b := range conn.send.drain() {
+			conn.deliverSend(b)
+		}
+	})
+	return conn.Conn.Close()
+}
+
+// Latency returns the latency of the wrapped net.Conn, if it has one, increased by the average latency that
+// the Faults of the chaosConn add.
+func (conn *chaosConn) Latency() time.Duration {
+	var latency time.Duration
+	if c, ok := conn.Conn.(interface{ Latency() time.Duration }); ok {
+		latency = c.Latency()
+	}
+	added := conn.c.Send.Latency + conn.c.Send.Jitter/2 + conn.c.Receive.Latency + conn.c.Receive.Jitter/2
+	return latency + added/2
+}
+
+// receive reads batches from the wrapped net.Conn and pushes them to the receive chaosQueue until reading
+// fails.
+func (conn *chaosConn) receive() {
+	var (
+		pr, ok = conn.Conn.(interface{ ReadPacket() ([]byte, error) })
+		buf    []byte
+	)
+	if !ok {
+		buf = make([]byte, 1024*1024*3)
+	}
+	for {
+		var (
+			data []byte
+			err  error
+		)
+		if ok {
+			data, err = pr.ReadPacket()
+		} else {
+			var n int
+			n, err = conn.Conn.Read(buf)
+			data = buf[:n]
+		}
+		if err != nil {
+			// The error is not subject to the Faults, but it is delivered after all batches still queued.
+			conn.recv.push(chaosBatch{err: err})
+			return
+		}
+		conn.recv.push(chaosBatch{data: slices.Clone(data)})
+	}
+}
+
+// deliverSend writes a batch that passed the send chaosQueue to the wrapped net.Conn.
+func (conn *chaosConn) deliverSend(b chaosBatch) {
+	if _, err := conn.Conn.Write(b.data); err != nil {
+		conn.err.CompareAndSwap(nil, &err)
+	}
+}
+
+// deliverReceive makes a batch that passed the receive chaosQueue available to ReadPacket.
+func (conn *chaosConn) deliverReceive(b chaosBatch) {
+	select {
+	case conn.in <- b:
+	case <-conn.closed:
+	}
+}
+
+// chaosQueue delays batches according to Faults and delivers them in the order of their delay.
+type chaosQueue struct {
+	f       Faults
+	clk     clock.Clock
+	deliver func(b chaosBatch)
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+	// pending holds the batches not yet delivered, sorted by their deadline. Batches with equal deadlines
+	// are kept in the order that they were pushed.
+	pending []chaosPending
+	// last is the deadline of the last batch pushed that was not reordered. Batches that are not reordered
+	// are never delivered before it.
+	last   time.Time
+	pushed chan struct{}
+}
+
+// chaosPending is a batch waiting in a chaosQueue.
+type chaosPending struct {
+	b        chaosBatch
+	deadline time.Time
+}
+
+// newChaosQueue creates a chaosQueue that delivers batches using the function passed until closed is closed.
+func newChaosQueue(f Faults, clk clock.Clock, rnd *rand.Rand, closed <-chan struct{}, deliver func(b chaosBatch)) *chaosQueue {
+	if f.ReorderDelay == 0 {
+		f.ReorderDelay = time.Millisecond * 100
+	}
+	q := &chaosQueue{f: f, clk: clk, rnd: rnd, deliver: deliver, pushed: make(chan struct{}, 1)}
+	go q.run(closed)
+	return q
+}
+
+// push queues a batch, applying the Faults of the chaosQueue to it. Batches holding an error are never
+// dropped, duplicated or reordered.
+func (q *chaosQueue) push(b chaosBatch) {
+	q.mu.Lock()
+	now := q.clk.Now()
+	copies := 1
+	if b.err == nil {
+		if q.chance(q.f.Drop) {
+			q.mu.Unlock()
+			return
+		}
+		if q.chance(q.f.Duplicate) {
+			copies = 2
+		}
+	}
+	deadline := now.Add(q.f.Latency)
+	if q.f.Jitter > 0 {
+		deadline = deadline.Add(time.Duration(q.rnd.Int63n(int64(q.f.Jitter))))
+	}
+	if b.err == nil && q.chance(q.f.Reorder) {
+		deadline = deadline.Add(q.f.ReorderDelay)
+	} else {
+		if deadline.Before(q.last) {
+			deadline = q.last
+		}
+		q.last = deadline
+	}
+	for i := 0; i < copies; i++ {
+		if i > 0 {
+			// Every copy gets its own data, as the reader of a batch may modify it, for example to decrypt it.
+			b.data = slices.Clone(b.data)
+		}
+		idx, _ := slices.BinarySearchFunc(q.pending, deadline, func(p chaosPending, t time.Time) int {
+			if p.deadline.After(t) {
+				return 1
+			}
+			return -1
+		})
+		q.pending = slices.Insert(q.pending, idx, chaosPending{b: b, deadline: deadline})
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.pushed <- struct{}{}:
+	default:
+	}
+}
+
+// chance returns true with the probability passed. q.mu must be held.
+func (q *chaosQueue) chance(p float64) bool {
+	return p > 0 && q.rnd.Float64() < p
+}
+
+// drain removes all batches from the chaosQueue and returns them.
+func (q *chaosQueue) drain() []chaosBatch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	batches := make([]chaosBatch, len(q.pending))
+	for i, p := range q.pending {
+		batches[i] = p.b
+	}
+	q.pending = nil
+	return batches
+}
+
+// run delivers batches once their deadline passes until closed is closed.
+func (q *chaosQueue) run(closed <-chan struct{}) {
+	timer := q.clk.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		now := q.clk.Now()
+		i := 0
+		for i < len(q.pending) && !q.pending[i].deadline.After(now) {
+			i++
+		}
+		due := make([]chaosBatch, i)
+		for j, p := range q.pending[:i] {
+			due[j] = p.b
+		}
+		q.pending = q.pending[i:]
+		wait := time.Duration(-1)
+		if len(q.pending) != 0 {
+			wait = q.pending[0].deadline.Sub(now)
+		}
+		q.mu.Unlock()
+
+		for _, b := range due {
+			q.deliver(b)
+		}
+		if len(due) != 0 {
+			// Delivering may have taken a while, so check for batches that became due in the meantime.
+			continue
+		}
+
+		var fired <-chan time.Time
+		if wait >= 0 {
+			timer.Reset(wait)
+			fired = timer.C()
+		}
+		select {
+		case <-fired:
+		case <-q.pushed:
+			timer.Stop()
+		case <-closed:
+			return
+		}
+	}
+}
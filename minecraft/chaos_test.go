@@ -0,0 +1,88 @@
+package minecraft_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"log/slog"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestChaosResourcePackDownload verifies that a resource pack of multiple chunks is downloaded completely
+// over a connection with latency and jitter in both directions.
+func TestChaosResourcePackDownload(t *testing.T) {
+	faults := minecraft.Faults{Latency: time.Millisecond * 5, Jitter: time.Millisecond * 20}
+	minecraft.RegisterNetwork("chaos-test", func(l *slog.Logger) minecraft.Network {
+		return minecraft.Chaos{Network: minecraft.RakNet{ErrorLog: l}, Send: faults, Receive: faults, Seed: 1}
+	})
+
+	pack := chaosTestPack(t)
+	l, err := minecraft.ListenConfig{
+		AuthenticationDisabled: true,
+		ResourcePacks:          []*resource.Pack{pack},
+		EncryptionDisabledFunc: func(net.Addr) bool { return true },
+	}.Listen("chaos-test", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		if err := c.(*minecraft.Conn).StartGameContext(ctx, minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+			return
+		}
+		<-ctx.Done()
+	}()
+
+	client, err := minecraft.Dialer{}.DialContext(ctx, "chaos-test", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	if err := client.DoSpawnContext(ctx); err != nil {
+		t.Fatalf("spawn: %v", err)
+	}
+	packs := client.ResourcePacks()
+	if len(packs) != 1 {
+		t.Fatalf("expected 1 resource pack, got %v", len(packs))
+	}
+	if packs[0].Checksum() != pack.Checksum() {
+		t.Fatalf("downloaded resource pack has checksum %x, expected %x", packs[0].Checksum(), pack.Checksum())
+	}
+}
+
+// chaosTestPack returns a resource pack that spans multiple chunks when downloaded.
+func chaosTestPack(t *testing.T) *resource.Pack {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	manifest := fmt.Sprintf(`{"format_version": 2, "header": {"name": "chaos", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]}, "modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]}`, uuid.New(), uuid.New())
+	f, _ := w.Create("manifest.json")
+	_, _ = f.Write([]byte(manifest))
+
+	// Random data does not compress, so the pack ends up being several chunks in size.
+	data := make([]byte, 512*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	f, _ = w.CreateHeader(&zip.FileHeader{Name: "data.bin", Method: zip.Store})
+	_, _ = f.Write(data)
+	_ = w.Close()
+
+	pack, err := resource.ReadBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	return pack
+}
@@ -0,0 +1,85 @@
+// Package clock abstracts the passing of time for gophertunnel, so that timers used for flushing, read
+// deadlines and login timeouts may be driven by a Fake clock in tests instead of by the system clock.
+package clock
+
+import (
+	"time"
+)
+
+// Clock provides the current time and timers that fire after a duration has passed.
+type Clock interface {
+	// Now returns the current time of the Clock.
+	Now() time.Time
+	// NewTimer creates a Timer that sends the time of the Clock on its channel once the duration passed has
+	// passed.
+	NewTimer(d time.Duration) Timer
+	// NewTicker creates a Ticker that sends the time of the Clock on its channel every time the duration
+	// passed has passed. NewTicker panics if d is not positive.
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc calls the function passed in its own goroutine once the duration passed has passed. The
+	// Timer returned may be used to cancel the call. Its channel is nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a single event of a Clock, similar to a time.Timer.
+type Timer interface {
+	// C returns the channel that the time is sent on when the Timer fires.
+	C() <-chan time.Time
+	// Stop prevents the Timer from firing. It returns false if the Timer already fired or was stopped.
+	Stop() bool
+	// Reset changes the Timer to fire after the duration passed. It returns true if the Timer was active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a repeating event of a Clock, similar to a time.Ticker.
+type Ticker interface {
+	// C returns the channel that the time is sent on every time the Ticker ticks.
+	C() <-chan time.Time
+	// Stop turns off the Ticker.
+	Stop()
+	// Reset stops the Ticker and resets its period to the duration passed.
+	Reset(d time.Duration)
+}
+
+// System is the Clock backed by the system clock, using the functions of the time package. It is the Clock
+// used when none is set.
+var System Clock = system{}
+
+// system implements the System Clock.
+type system struct{}
+
+// Now ...
+func (system) Now() time.Time { return time.Now() }
+
+// NewTimer ...
+func (system) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+// NewTicker ...
+func (system) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+// AfterFunc ...
+func (system) AfterFunc(d time.Duration, f func()) Timer { return systemTimer{time.AfterFunc(d, f)} }
+
+// systemTimer wraps a time.Timer to implement Timer.
+type systemTimer struct{ t *time.Timer }
+
+// C ...
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+
+// Stop ...
+func (t systemTimer) Stop() bool { return t.t.Stop() }
+
+// Reset ...
+func (t systemTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+// systemTicker wraps a time.Ticker to implement Ticker.
+type systemTicker struct{ t *time.Ticker }
+
+// C ...
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+
+// Stop ...
+func (t systemTicker) Stop() { t.t.Stop() }
+
+// Reset ...
+func (t systemTicker) Reset(d time.Duration) { t.t.Reset(d) }
@@ -0,0 +1,198 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock of which the time only moves when Advance is called. Timers, Tickers and functions passed
+// to AfterFunc fire during the call to Advance that moves the time past their deadline, in the order of
+// their deadlines. A Fake may be used concurrently.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	added   chan struct{}
+}
+
+// NewFake returns a Fake clock set to the time passed.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, added: make(chan struct{})}
+}
+
+// Now returns the current time of the Fake.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer ...
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1)}
+	f.add(w, d)
+	return w
+}
+
+// NewTicker ...
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1), period: d}
+	f.add(w, d)
+	return fakeTicker{w}
+}
+
+// AfterFunc ...
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &fakeWaiter{f: f, fn: fn}
+	f.add(w, d)
+	return w
+}
+
+// Advance moves the time of the Fake forward by the duration passed and fires all Timers and Tickers with a
+// deadline up to the new time. Functions passed to AfterFunc are called by Advance itself, rather than in
+// their own goroutine, so that their effects are visible once Advance returns.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for {
+		w := f.next(end)
+		if w == nil {
+			break
+		}
+		f.now = w.deadline
+		f.remove(w)
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+			f.waiters = append(f.waiters, w)
+		}
+		now := f.now
+
+		f.mu.Unlock()
+		w.fire(now)
+		f.mu.Lock()
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+// Waiters returns the number of Timers, Tickers and functions passed to AfterFunc that are currently waiting
+// to fire.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n Timers, Tickers and functions passed to AfterFunc are waiting to fire.
+// It may be used to wait for a goroutine to create a Timer before calling Advance.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= n {
+			f.mu.Unlock()
+			return
+		}
+		added := f.added
+		f.mu.Unlock()
+		<-added
+	}
+}
+
+// add schedules the fakeWaiter passed to fire after the duration passed.
+func (f *Fake) add(w *fakeWaiter, d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.deadline = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+
+	close(f.added)
+	f.added = make(chan struct{})
+}
+
+// remove removes the fakeWaiter passed and returns true if it was waiting.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	i := slices.Index(f.waiters, w)
+	if i == -1 {
+		return false
+	}
+	f.waiters = slices.Delete(f.waiters, i, i+1)
+	return true
+}
+
+// next returns the fakeWaiter with the earliest deadline that is not after the time passed, or nil if no such
+// fakeWaiter exists. Waiters with equal deadlines are returned in the order that they were added.
+func (f *Fake) next(end time.Time) *fakeWaiter {
+	var first *fakeWaiter
+	for _, w := range f.waiters {
+		if !w.deadline.After(end) && (first == nil || w.deadline.Before(first.deadline)) {
+			first = w
+		}
+	}
+	return first
+}
+
+// fakeWaiter implements Timer for a Fake clock, and Ticker through fakeTicker.
+type fakeWaiter struct {
+	f        *Fake
+	c        chan time.Time
+	fn       func()
+	period   time.Duration
+	deadline time.Time
+}
+
+// fire fires the fakeWaiter at the time passed.
+func (w *fakeWaiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+	// Like a time.Timer, a value is dropped if the previous one was not yet received.
+	select {
+	case w.c <- now:
+	default:
+	}
+}
+
+// C ...
+func (w *fakeWaiter) C() <-chan time.Time { return w.c }
+
+// Stop ...
+func (w *fakeWaiter) Stop() bool {
+	w.f.mu.Lock()
+	defer w.f.mu.Unlock()
+	return w.f.remove(w)
+}
+
+// Reset ...
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.f.mu.Lock()
+	active := w.f.remove(w)
+	w.f.mu.Unlock()
+
+	w.f.add(w, d)
+	return active
+}
+
+// fakeTicker implements Ticker for a Fake clock.
+type fakeTicker struct{ w *fakeWaiter }
+
+// C ...
+func (t fakeTicker) C() <-chan time.Time { return t.w.c }
+
+// Stop ...
+func (t fakeTicker) Stop() { t.w.Stop() }
+
+// Reset ...
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.w.f.mu.Lock()
+	t.w.period = d
+	t.w.f.mu.Unlock()
+	t.w.Reset(d)
+}
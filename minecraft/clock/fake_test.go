@@ -0,0 +1,91 @@
+package clock_test
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/clock"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestFakeAdvance verifies that timers, tickers and functions of a Fake clock fire in the order of their
+// deadlines when the clock is advanced, and only then.
+func TestFakeAdvance(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := clock.NewFake(start)
+
+	var fired []string
+	c.AfterFunc(time.Second*3, func() { fired = append(fired, "func") })
+	timer := c.NewTimer(time.Second)
+	ticker := c.NewTicker(time.Second * 2)
+	defer ticker.Stop()
+
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired before the clock was advanced")
+	default:
+	}
+	c.Advance(time.Second)
+	if got := <-timer.C(); !got.Equal(start.Add(time.Second)) {
+		t.Fatalf("timer fired at %v, expected %v", got, start.Add(time.Second))
+	}
+	if timer.Stop() {
+		t.Fatal("expected Stop to return false for a timer that fired")
+	}
+
+	c.Advance(time.Second * 2)
+	if got := <-ticker.C(); !got.Equal(start.Add(time.Second * 2)) {
+		t.Fatalf("ticker ticked at %v, expected %v", got, start.Add(time.Second*2))
+	}
+	if !slices.Equal(fired, []string{"func"}) {
+		t.Fatalf("expected func to be called once, got %v", fired)
+	}
+	if !c.Now().Equal(start.Add(time.Second * 3)) {
+		t.Fatalf("expected time %v, got %v", start.Add(time.Second*3), c.Now())
+	}
+
+	c.Advance(time.Second)
+	if got := <-ticker.C(); !got.Equal(start.Add(time.Second * 4)) {
+		t.Fatalf("ticker ticked at %v, expected %v", got, start.Add(time.Second*4))
+	}
+}
+
+// TestFakeReset verifies that resetting and stopping a Timer of a Fake clock changes when it fires.
+func TestFakeReset(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	timer := c.NewTimer(time.Second)
+	c.Advance(time.Millisecond * 500)
+	if !timer.Reset(time.Second) {
+		t.Fatal("expected Reset to return true for an active timer")
+	}
+	c.Advance(time.Millisecond * 500)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired before its reset deadline")
+	default:
+	}
+	if !timer.Stop() {
+		t.Fatal("expected Stop to return true for an active timer")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	if c.Waiters() != 0 {
+		t.Fatalf("expected no waiters, got %v", c.Waiters())
+	}
+}
+
+// TestFakeBlockUntil verifies that BlockUntil returns once a goroutine creates a Timer.
+func TestFakeBlockUntil(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		<-c.NewTimer(time.Minute).C()
+		close(done)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	<-done
+}
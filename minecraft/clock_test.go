@@ -0,0 +1,45 @@
+package minecraft_test
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/clock"
+	"testing"
+	"time"
+)
+
+// TestReadDeadlineClock verifies that the read deadline of a Conn expires when the clock.Clock of the Conn
+// passes it, without waiting for the actual time to pass.
+func TestReadDeadlineClock(t *testing.T) {
+	c := clock.NewFake(time.Now())
+	client, server, err := minecraft.Pipe(minecraft.PipeConfig{Dialer: minecraft.Dialer{Clock: c}})
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer server.Close()
+	defer client.Close()
+
+	if err := client.SetReadDeadline(c.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("set read deadline: %v", err)
+	}
+	read := make(chan error, 1)
+	go func() {
+		_, err := client.ReadPacket()
+		read <- err
+	}()
+
+	c.Advance(time.Hour - time.Second)
+	select {
+	case err := <-read:
+		t.Fatalf("read returned before the deadline: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+	c.Advance(time.Second)
+	select {
+	case err := <-read:
+		if err == nil {
+			t.Fatal("expected read to fail after the deadline")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("read did not return after the deadline passed")
+	}
+}
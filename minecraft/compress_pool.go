@@ -0,0 +1,42 @@
+package minecraft
+
+// compressPool is a bounded pool of goroutines that compress the batches sent by the connections of a
+// Listener. It allows the batches flushed by a single connection, such as a burst of chunks, to be compressed
+// simultaneously, while the packet.Encoder of the connection still writes them in order.
+type compressPool struct {
+	jobs  chan func()
+	close <-chan struct{}
+}
+
+// newCompressPool creates a compressPool with n workers. The workers stop once the close channel passed is
+// closed.
+func newCompressPool(n int, close <-chan struct{}) *compressPool {
+	p := &compressPool{jobs: make(chan func()), close: close}
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// work runs jobs submitted to the compressPool until it is closed.
+func (p *compressPool) work() {
+	for {
+		select {
+		case <-p.close:
+			return
+		case f := <-p.jobs:
+			f()
+		}
+	}
+}
+
+// run hands the function passed off to one of the workers of the pool. Because the jobs channel is
+// unbuffered, every job handed off is run by a worker. If the pool is closed, f is run on the calling
+// goroutine instead, so that batches flushed while the Listener closes are still written.
+func (p *compressPool) run(f func()) {
+	select {
+	case <-p.close:
+		f()
+	case p.jobs <- f:
+	}
+}
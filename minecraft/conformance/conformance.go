@@ -0,0 +1,243 @@
+// Package conformance holds a reference client and server that run the login, resource pack and spawn
+// sequences of gophertunnel against each other over the in-memory "pipe" network. Every Scenario records
+// the exact order in which the client and the server receive packets during these sequences and compares
+// it against the order expected, so that changes to the order in which a Conn handles and sends packets
+// are caught. The scenarios are run by the tests of this package.
+package conformance
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scenario is a single run of the reference client against the reference server.
+type Scenario struct {
+	// Name is the name of the Scenario.
+	Name string
+	// ListenConfig is the ListenConfig of the reference server. Authentication should generally be disabled.
+	ListenConfig minecraft.ListenConfig
+	// Dialer is the Dialer of the reference client.
+	Dialer minecraft.Dialer
+	// GameData is the GameData that the reference server starts the game with.
+	GameData minecraft.GameData
+
+	// Client holds the IDs of the packets that the client is expected to receive, in the order that they
+	// are expected to arrive in, from the moment the client connects until it has spawned.
+	Client []uint32
+	// Server holds the IDs of the packets that the server is expected to receive, in the order that they are
+	// expected to arrive in, from the moment the client connects until it has spawned.
+	Server []uint32
+}
+
+// Scenarios returns all Scenarios that gophertunnel is expected to pass.
+func Scenarios() []Scenario {
+	gameData := minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}
+	return []Scenario{
+		{
+			Name:         "login",
+			ListenConfig: minecraft.ListenConfig{AuthenticationDisabled: true},
+			GameData:     gameData,
+			Client: []uint32{
+				packet.IDNetworkSettings,
+				packet.IDServerToClientHandshake,
+				packet.IDPlayStatus,
+				packet.IDResourcePacksInfo,
+				packet.IDResourcePackStack,
+				packet.IDStartGame,
+				packet.IDChunkRadiusUpdated,
+				packet.IDBiomeDefinitionList,
+				packet.IDPlayStatus,
+				packet.IDCreativeContent,
+			},
+			Server: []uint32{
+				packet.IDRequestNetworkSettings,
+				packet.IDLogin,
+				packet.IDClientToServerHandshake,
+				packet.IDClientCacheStatus,
+				packet.IDResourcePackClientResponse,
+				packet.IDResourcePackClientResponse,
+				packet.IDRequestChunkRadius,
+				packet.IDSetLocalPlayerAsInitialised,
+			},
+		},
+		{
+			Name:         "resource packs",
+			ListenConfig: minecraft.ListenConfig{AuthenticationDisabled: true, ResourcePacks: []*resource.Pack{testPack()}},
+			GameData:     gameData,
+			Client: []uint32{
+				packet.IDNetworkSettings,
+				packet.IDServerToClientHandshake,
+				packet.IDPlayStatus,
+				packet.IDResourcePacksInfo,
+				packet.IDResourcePackDataInfo,
+				packet.IDResourcePackChunkData,
+				packet.IDResourcePackStack,
+				packet.IDStartGame,
+				packet.IDChunkRadiusUpdated,
+				packet.IDBiomeDefinitionList,
+				packet.IDPlayStatus,
+				packet.IDCreativeContent,
+			},
+			Server: []uint32{
+				packet.IDRequestNetworkSettings,
+				packet.IDLogin,
+				packet.IDClientToServerHandshake,
+				packet.IDClientCacheStatus,
+				packet.IDResourcePackClientResponse,
+				packet.IDResourcePackChunkRequest,
+				packet.IDResourcePackClientResponse,
+				packet.IDResourcePackClientResponse,
+				packet.IDRequestChunkRadius,
+				packet.IDSetLocalPlayerAsInitialised,
+			},
+		},
+	}
+}
+
+// Run runs the Scenario and returns the IDs of the packets received by the client and the server, in the
+// order that they arrived in, until the client has spawned.
+func (s Scenario) Run(ctx context.Context) (client, server []uint32, err error) {
+	addr := fmt.Sprintf("127.0.0.1:%v", 19132+addrID.Add(1))
+
+	var mu sync.Mutex
+	s.ListenConfig.PacketFunc = func(header packet.Header, _ []byte, _, dst net.Addr) {
+		if dst.String() == addr {
+			mu.Lock()
+			server = append(server, header.PacketID)
+			mu.Unlock()
+		}
+	}
+	s.Dialer.PacketFunc = func(header packet.Header, _ []byte, src, _ net.Addr) {
+		if src.String() == addr {
+			mu.Lock()
+			client = append(client, header.PacketID)
+			mu.Unlock()
+		}
+	}
+
+	l, err := s.ListenConfig.Listen("pipe", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listen: %w", err)
+	}
+	defer l.Close()
+
+	spawned := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			spawned <- fmt.Errorf("accept: %w", err)
+			return
+		}
+		defer c.Close()
+		conn := c.(*minecraft.Conn)
+		if err := conn.StartGameContext(ctx, s.GameData); err != nil {
+			spawned <- fmt.Errorf("start game: %w", err)
+			return
+		}
+		// Packets sent by the server during the spawn sequence may still arrive at the client after it has
+		// spawned. The marker tells the client that all of them have arrived.
+		_ = conn.WritePacket(marker)
+		_ = conn.Flush()
+		spawned <- nil
+		// Keep the connection open until the client has read the marker.
+		_, _ = conn.ReadPacket()
+	}()
+
+	conn, err := s.Dialer.DialContext(ctx, "pipe", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		return nil, nil, fmt.Errorf("spawn: %w", err)
+	}
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			return nil, nil, fmt.Errorf("read marker: %w", err)
+		}
+		if text, ok := pk.(*packet.Text); ok && text.Message == marker.Message {
+			break
+		}
+	}
+	select {
+	case err := <-spawned:
+		if err != nil {
+			return nil, nil, err
+		}
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("start game: %w", ctx.Err())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The marker is always the last packet received by the client.
+	return slices.Clone(client[:len(client)-1]), slices.Clone(server), nil
+}
+
+// Check runs the Scenario and returns an error if the order in which the client or the server received
+// packets differs from the order expected.
+func (s Scenario) Check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	client, server, err := s.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("%v: %w", s.Name, err)
+	}
+	if !slices.Equal(client, s.Client) {
+		return fmt.Errorf("%v: client received packets out of order:\nexpected: %v\ngot:      %v", s.Name, names(packet.NewServerPool(), s.Client), names(packet.NewServerPool(), client))
+	}
+	if !slices.Equal(server, s.Server) {
+		return fmt.Errorf("%v: server received packets out of order:\nexpected: %v\ngot:      %v", s.Name, names(packet.NewClientPool(), s.Server), names(packet.NewClientPool(), server))
+	}
+	return nil
+}
+
+// marker is sent by the server after the client has spawned, so that the client knows that it has received
+// all packets of the spawn sequence.
+var marker = &packet.Text{TextType: packet.TextTypeRaw, Message: "conformance"}
+
+// addrID is used to give every Scenario run its own address on the pipe network.
+var addrID atomic.Int32
+
+// names returns the names of the packets with the IDs passed, looked up in the packet.Pool passed.
+func names(pool packet.Pool, ids []uint32) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = fmt.Sprintf("0x%x", id)
+		if f, ok := pool[id]; ok {
+			s[i] = reflect.TypeOf(f()).Elem().Name()
+		}
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+// testPack returns a small resource pack that fits in a single chunk.
+func testPack() *resource.Pack {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	manifest := fmt.Sprintf(`{"format_version": 2, "header": {"name": "conformance", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]}, "modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]}`, uuid.New(), uuid.New())
+	f, _ := w.Create("manifest.json")
+	_, _ = f.Write([]byte(manifest))
+	_ = w.Close()
+
+	pack, err := resource.ReadBytes(buf.Bytes())
+	if err != nil {
+		panic(fmt.Errorf("conformance: read pack: %w", err))
+	}
+	return pack
+}
@@ -0,0 +1,18 @@
+package conformance_test
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/conformance"
+	"testing"
+)
+
+// TestScenarios runs all conformance Scenarios and verifies that packets arrive at the client and the server
+// in the order expected.
+func TestScenarios(t *testing.T) {
+	for _, s := range conformance.Scenarios() {
+		t.Run(s.Name, func(t *testing.T) {
+			if err := s.Check(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -7,11 +7,12 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -20,13 +21,15 @@ import (
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/clock"
 	"github.com/sandertv/gophertunnel/minecraft/internal"
-	"github.com/sandertv/gophertunnel/minecraft/nbt"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 	"github.com/sandertv/gophertunnel/minecraft/resource"
 	"github.com/sandertv/gophertunnel/minecraft/text"
+	"github.com/sandertv/gophertunnel/minecraft/world/biomes"
+	"github.com/sandertv/gophertunnel/minecraft/world/entities"
 )
 
 // exemptedResourcePack is a resource pack that is exempted from being downloaded. These packs may be directly
@@ -54,9 +57,36 @@ type Conn struct {
 	once  sync.Once
 	close chan struct{}
 
-	conn        net.Conn
-	log         *slog.Logger
-	authEnabled bool
+	conn net.Conn
+	// log is the logger of the Conn. Its records hold the address of the Conn and, once known, the XUID and
+	// name of the player, which are added through logHandler.
+	log           *slog.Logger
+	logHandler    *connHandler
+	authEnabled   bool
+	authenticator login.Authenticator
+	// clientDataLimits limits the size of the skin in the client data sent by the client during login.
+	clientDataLimits login.ClientDataLimits
+	// loginPolicy is checked for the client after its login request is verified.
+	loginPolicy login.Policy
+	// replayCache records the login request of the client to reject requests that were replayed.
+	replayCache login.ReplayCache
+	// replayWindow is the maximum duration for which login requests are recorded in the replayCache.
+	replayWindow time.Duration
+	// logins is the pool of the Listener that the login request of the client is verified on. If nil, the
+	// login request is verified on the goroutine reading packets.
+	logins *loginPool
+	// encryptionDisabled specifies if the server side of the connection skips the ServerToClientHandshake
+	// and does not encrypt the connection. requireEncryption specifies if the client side of the connection
+	// refuses to log in without encryption, and encrypted is set once the client side enabled encryption.
+	encryptionDisabled bool
+	requireEncryption  bool
+	encrypted          bool
+	// serverKeyFingerprints and verifyServerKey are used by the client side of the connection to validate
+	// the public key that the server presents in the ServerToClientHandshake.
+	serverKeyFingerprints []string
+	verifyServerKey       func(key *ecdsa.PublicKey) error
+	// handshakeFunc, if non-nil, is called with the parameters of the encryption handshake of the connection.
+	handshakeFunc func(info HandshakeInfo)
 
 	proto         Protocol
 	acceptedProto []Protocol
@@ -66,18 +96,40 @@ type Conn struct {
 	compression   packet.Compression
 	readerLimits  bool
 
+	// stats counts the batches sent and received through enc and dec for NetworkStats.
+	stats connStats
+
+	// clientProtocol is the protocol version that a client connected with. It is only set for connections
+	// obtained through a Listener.
+	clientProtocol   int32
+	protocolMismatch func(clientProtocol int32, addr net.Addr) ProtocolMismatchResult
+	// tarpit is the delay applied to every batch handled during the login sequence. It is non-zero for
+	// connections marked with ReputationTarpit.
+	tarpit time.Duration
+
 	disconnectOnUnknownPacket bool
 	disconnectOnInvalidPacket bool
 
 	identityData login.IdentityData
 	clientData   login.ClientData
+	chainInfo    login.ChainInfo
+	// connectionRequest is the raw login request sent by the client. It is only set for connections obtained
+	// through a Listener.
+	connectionRequest []byte
 
 	gameData         GameData
 	gameDataReceived atomic.Bool
-
-	// privateKey is the private key of this end of the connection. Each connection, regardless of which side
-	// the connection is on, server or client, has a unique private key generated.
-	privateKey *ecdsa.PrivateKey
+	// chunkRadius is the current chunk radius of the connection. maxChunkRadius is the maximum radius
+	// approved for the client, and chunkRadiusFunc is called when the client changes its radius after
+	// spawning. Both are only set for connections obtained through a Listener.
+	chunkRadius     atomic.Int32
+	maxChunkRadius  int32
+	chunkRadiusFunc func(conn *Conn, radius int)
+
+	// key holds the private key of this end of the connection. Connections obtained through a Dialer have a
+	// unique private key generated, while connections obtained through a Listener share the key of the
+	// Listener.
+	key Signer
 	// salt is a 16 byte long randomly generated byte slice which is only used if the Conn is a server sided
 	// connection. It is otherwise left unused.
 	salt []byte
@@ -98,11 +150,41 @@ type Conn struct {
 	deferredPackets []*packetData
 	readDeadline    <-chan time.Time
 
+	// sendMu guards bufferedSend. It is only held while appending packets to, or swapping out, bufferedSend,
+	// so that goroutines writing to the Conn do not wait for each other encoding packets or for a Flush
+	// compressing a batch.
 	sendMu sync.Mutex
 	// bufferedSend is a slice of byte slices containing packets that are 'written'. They are buffered until
 	// they are sent each 20th of a second.
 	bufferedSend [][]byte
-	hdr          *packet.Header
+	// flushMu is held while encoding a batch in Flush, so that batches are encoded in order. flushing is
+	// the slice that bufferedSend is swapped with in Flush, so that it is re-used every other Flush.
+	flushMu  sync.Mutex
+	flushing [][]byte
+	// clock is the clock.Clock used for the timers of the Conn.
+	clock clock.Clock
+	// flusher is the flushScheduler that the Conn is scheduled with when packets are written to it. It is nil
+	// if the Conn is not flushed automatically. flushScheduled is true while the Conn is scheduled and
+	// flushPending is true while the flushScheduler is flushing the Conn.
+	flusher        *flushScheduler
+	flushScheduled atomic.Bool
+	flushPending   atomic.Bool
+
+	// memoryBudget is the maximum amount of bytes that memoryUsed may reach. If 0, memory usage is not
+	// limited. memoryUsed is the amount of bytes held by packets received but not yet read, packets written
+	// but not yet flushed and resource packs that are being downloaded.
+	memoryBudget int64
+	memoryUsed   atomic.Int64
+
+	// flushThreshold is the amount of bytes buffered in bufferedSend after which the Conn is flushed
+	// immediately. If 0, the Conn is only flushed at its flush rate. bufferedBytes is the amount of bytes
+	// currently buffered.
+	flushThreshold int64
+	bufferedBytes  atomic.Int64
+
+	// labels holds the pprof labels of the Conn, as set using setPhase. It is nil until setPhase is first
+	// called.
+	labels atomic.Pointer[context.Context]
 
 	// readyToLogin is a bool indicating if the connection is ready to login. This is used to ensure that the client
 	// has received the relevant network settings before the login sequence starts.
@@ -123,9 +205,16 @@ type Conn struct {
 	// resourcePacks is a slice of resource packs that the listener may hold. Each client will be asked to
 	// download these resource packs upon joining.
 	resourcePacks []*resource.Pack
-	// biomes is a map of biome definitions that the listener may hold. Each client will be sent these biome
-	// definitions upon joining.
-	biomes map[string]any
+	// orderResourcePacks orders the resource packs of the connection in the resource pack stack. If nil,
+	// resource.OrderByDependencies is used.
+	orderResourcePacks func(packs []*resource.Pack) ([]*resource.Pack, error)
+	// biomes holds the encoded biome definitions that the listener may hold. Each client will be sent these
+	// biome definitions upon joining. If nil, the biomeDefinitions are sent instead.
+	biomes []byte
+	// biomeDefinitions is the registry of biomes sent to the client if biomes is nil.
+	biomeDefinitions *biomes.Registry
+	// entityIdentifiers is the registry of entity identifiers sent to the client. It may be nil.
+	entityIdentifiers *entities.Registry
 	// texturePacksRequired specifies if clients that join must accept the texture pack in order for them to
 	// be able to join the server. If they don't accept, they can only leave the server.
 	texturePacksRequired bool
@@ -133,15 +222,39 @@ type Conn struct {
 	// downloadResourcePack is an optional function passed to a Dial() call. If set, each resource pack received
 	// from the server will call this function to see if it should be downloaded or not.
 	downloadResourcePack func(id uuid.UUID, version string, currentPack, totalPacks int) bool
+	// maxPackSize and maxPackDownloadSize are the maximum size of a single resource pack and of all resource
+	// packs downloaded from the server. They are not limited if 0.
+	maxPackSize, maxPackDownloadSize uint64
+	// packDownloadTimeout is the maximum duration of the download of all resource packs. If exceeded, the
+	// connection is closed by packDownloadTimer.
+	packDownloadTimeout time.Duration
+	packDownloadTimer   clock.Timer
+	// packCache, if non-nil, holds resource packs downloaded by the client during earlier connections.
+	packCache *packCache
+	// packDeltas, if non-nil, holds the earlier versions of the resource packs of a server that deltas are
+	// sent against to clients that have one of them cached. serverPackDeltas is true for a client if the
+	// server it connected to can send such deltas.
+	packDeltas       *packDeltas
+	serverPackDeltas bool
+	// downloadPacksFromURL specifies if the client downloads resource packs that the server offers on an
+	// external URL from that URL rather than from the server.
+	downloadPacksFromURL bool
+	// packDownloadWindow is the maximum amount of resource pack chunks requested by the client that may be
+	// outstanding at the same time.
+	packDownloadWindow int
 	// ignoredResourcePacks is a slice of resource packs that are not being downloaded due to the downloadResourcePack
 	// func returning false for the specific pack.
 	ignoredResourcePacks []exemptedResourcePack
 
 	cacheEnabled bool
 
-	// packetFunc is an optional function passed to a Dial() call. If set, each packet read from and written
-	// to this connection will call this function.
-	packetFunc func(header packet.Header, payload []byte, src, dst net.Addr)
+	// packetFunc is an optional function passed to a Dial() call or set using SetPacketFunc. If set, each
+	// packet read from and written to this connection will call this function.
+	packetFunc atomic.Pointer[func(header packet.Header, payload []byte, src, dst net.Addr)]
+	// observers holds the packet observers added using AddPacketObserver. The slice is replaced entirely
+	// when an observer is added or removed, so that it may be read without locking.
+	observers   atomic.Pointer[[]*packetObserver]
+	observersMu sync.Mutex
 
 	disconnectMessage atomic.Pointer[string]
 
@@ -154,10 +267,11 @@ type Conn struct {
 // Minecraft packets to that net.Conn.
 // newConn accepts a private key which will be used to identify the connection. If a nil key is passed, the
 // key is generated.
-func newConn(netConn net.Conn, key *ecdsa.PrivateKey, log *slog.Logger, proto Protocol, flushRate time.Duration, limits bool, readBatches bool) *Conn {
+func newConn(netConn net.Conn, key Signer, log *slog.Logger, proto Protocol, clk clock.Clock, flushRate time.Duration, limits bool, readBatches bool) *Conn {
+	h := newConnHandler(log.Handler().WithAttrs([]slog.Attr{slog.String("raddr", netConn.RemoteAddr().String())}))
 	conn := &Conn{
-		enc:           packet.NewEncoder(netConn),
-		dec:           packet.NewDecoder(netConn),
+		log:           slog.New(h),
+		logHandler:    h,
 		salt:          make([]byte, 16),
 		packets:       make(chan *packetData, 8),
 		packetBatches: make(chan []*packetData, 8),
@@ -165,13 +279,15 @@ func newConn(netConn net.Conn, key *ecdsa.PrivateKey, log *slog.Logger, proto Pr
 		close:         make(chan struct{}),
 		spawn:         make(chan struct{}),
 		conn:          netConn,
-		privateKey:    key,
-		log:           log.With("raddr", netConn.RemoteAddr().String()),
-		hdr:           &packet.Header{},
+		key:           key,
 		proto:         proto,
+		clock:         clk,
 		readerLimits:  limits,
 		readBatches:   readBatches,
 	}
+	conn.enc = packet.NewEncoder(conn.stats.writer(netConn))
+	conn.dec = packet.NewDecoder(conn.stats.reader(netConn))
+
 	var s string
 	conn.disconnectMessage.Store(&s)
 
@@ -183,19 +299,9 @@ func newConn(netConn net.Conn, key *ecdsa.PrivateKey, log *slog.Logger, proto Pr
 
 	conn.expectedIDs.Store([]uint32{packet.IDLogin, packet.IDRequestNetworkSettings})
 
-	if flushRate <= 0 {
-		return conn
+	if flushRate > 0 {
+		conn.flusher = flushSchedulerFor(flushRate, clk)
 	}
-	go func() {
-		ticker := time.NewTicker(flushRate)
-		defer ticker.Stop()
-		for range ticker.C {
-			if err := conn.Flush(); err != nil {
-				_ = conn.Close()
-				return
-			}
-		}
-	}()
 	return conn
 }
 
@@ -204,6 +310,13 @@ func (conn *Conn) Protocol() Protocol {
 	return conn.proto
 }
 
+// ClientProtocol returns the protocol version that the client requested when connecting. It may differ from
+// the ID of the Protocol returned by Protocol if a ProtocolMismatchFunc accepted the client through a
+// different Protocol. ClientProtocol returns 0 for connections obtained through a Dialer.
+func (conn *Conn) ClientProtocol() int32 {
+	return conn.clientProtocol
+}
+
 // DisconnectReason returns the disconnect message that is stored but never used anywhere for some reason! lolxd
 func (conn *Conn) DisconnectReason() string {
 	return *(conn.disconnectMessage.Load())
@@ -215,6 +328,20 @@ func (conn *Conn) IdentityData() login.IdentityData {
 	return conn.identityData
 }
 
+// ChainInfo returns information about the login chain that the client connected with, such as the time it
+// was issued and the time it expires. It is only set for connections obtained through a Listener.
+func (conn *Conn) ChainInfo() login.ChainInfo {
+	return conn.chainInfo
+}
+
+// ConnectionRequest returns the login request that the client connected with, exactly as it was sent by the
+// client. The chain in it is still signed, so a proxy may pass it to Dialer.ForwardedLogin to let a backend
+// server verify the identity of the client. ConnectionRequest returns nil for connections obtained through a
+// Dialer.
+func (conn *Conn) ConnectionRequest() []byte {
+	return conn.connectionRequest
+}
+
 // ClientData returns the client data the client connected with. Note that this client data may be changed
 // during the session, so the data should only be used directly after connection, and should be updated after
 // that by the caller.
@@ -340,9 +467,6 @@ func (conn *Conn) WritePacket(pk packet.Packet) error {
 		return conn.closeErr("write packet")
 	default:
 	}
-	conn.sendMu.Lock()
-	defer conn.sendMu.Unlock()
-
 	buf := internal.BufferPool.Get().(*bytes.Buffer)
 	defer func() {
 		// Reset the buffer, so we can return it to the buffer pool safely.
@@ -350,21 +474,173 @@ func (conn *Conn) WritePacket(pk packet.Packet) error {
 		internal.BufferPool.Put(buf)
 	}()
 
-	conn.hdr.PacketID = pk.ID()
-	_ = conn.hdr.Write(buf)
-	l := buf.Len()
+	// Packets are encoded before acquiring sendMu, so that goroutines writing to the Conn simultaneously
+	// only wait for each other to append the encoded packets.
+	var (
+		hdr     packet.Header
+		encoded [1][]byte
+	)
+	converted := conn.proto.ConvertFromLatest(pk, conn)
+	data := encoded[:0]
+	for _, c := range converted {
+		// The ID of the converted packet may differ from that of pk if the Protocol of the Conn remaps it.
+		buf.Reset()
+		hdr.PacketID = c.ID()
+		_ = hdr.Write(buf)
+		l := buf.Len()
+		c.Marshal(conn.proto.NewWriter(buf, conn.shieldID.Load()))
 
-	for _, converted := range conn.proto.ConvertFromLatest(pk, conn) {
-		converted.Marshal(conn.proto.NewWriter(buf, conn.shieldID.Load()))
+		conn.observe(hdr, buf.Bytes()[l:], conn.LocalAddr(), conn.RemoteAddr())
+		data = append(data, append([]byte(nil), buf.Bytes()...))
+	}
 
-		if conn.packetFunc != nil {
-			conn.packetFunc(*conn.hdr, buf.Bytes()[l:], conn.LocalAddr(), conn.RemoteAddr())
-		}
-		conn.bufferedSend = append(conn.bufferedSend, append([]byte(nil), buf.Bytes()...))
+	n := 0
+	for _, b := range data {
+		n += len(b)
+	}
+	conn.sendMu.Lock()
+	conn.bufferedSend = append(conn.bufferedSend, data...)
+	conn.sendMu.Unlock()
+	return conn.buffered(n)
+}
+
+// WriteRaw writes a packet pre-serialised using packet.Marshal to the Conn. Just like packets written using
+// WritePacket, the packet is buffered until the next 20th of a second. Unlike WritePacket, the packet is not
+// encoded again, so writing the same packet.Marshalled to many connections is considerably cheaper than
+// calling WritePacket for each of them. If the Conn uses a Protocol other than DefaultProtocol, or a
+// different shield ID than the one the packet was marshalled with, the packet is encoded again as with
+// WritePacket.
+func (conn *Conn) WriteRaw(m *packet.Marshalled) error {
+	if _, ok := conn.proto.(proto); !ok || conn.shieldID.Load() != m.ShieldID() {
+		return conn.WritePacket(m.Packet())
+	}
+	select {
+	case <-conn.close:
+		return conn.closeErr("write raw")
+	default:
+	}
+	conn.observe(packet.Header{PacketID: m.Packet().ID()}, m.Payload(), conn.LocalAddr(), conn.RemoteAddr())
+
+	// The data of the packet.Marshalled is never modified, so it may be shared between all connections that
+	// it is written to without copying it.
+	conn.sendMu.Lock()
+	conn.bufferedSend = append(conn.bufferedSend, m.Bytes())
+	conn.sendMu.Unlock()
+	return conn.buffered(len(m.Bytes()))
+}
+
+// scheduleFlush schedules the Conn to be flushed by its flushScheduler, if it is flushed automatically.
+func (conn *Conn) scheduleFlush() {
+	if conn.flusher != nil {
+		conn.flusher.schedule(conn)
 	}
+}
+
+// buffered accounts for n bytes of packets that were just added to bufferedSend. If the packets buffered
+// cause the Conn to exceed its memory budget or flush threshold, the Conn is flushed immediately, applying
+// back-pressure to the goroutine writing. Otherwise, the Conn is scheduled to be flushed.
+func (conn *Conn) buffered(n int) error {
+	total := conn.bufferedBytes.Add(int64(n))
+	if conn.reserve(n) != nil || (conn.flushThreshold > 0 && total >= conn.flushThreshold) {
+		return conn.Flush()
+	}
+	conn.scheduleFlush()
 	return nil
 }
 
+// MemoryUsage returns the amount of bytes held by the Conn in packets received but not yet read, packets
+// written but not yet flushed and resource packs that are being downloaded. It is the amount checked against
+// the MemoryBudget of the Dialer or ListenConfig that the Conn was created with.
+func (conn *Conn) MemoryUsage() int {
+	return int(conn.memoryUsed.Load())
+}
+
+// reserve adds n bytes to the memory used by the Conn. errMemoryBudget is returned if the Conn uses more
+// memory than its budget afterwards. The bytes are added regardless, and must be released again using
+// release.
+func (conn *Conn) reserve(n int) error {
+	if used := conn.memoryUsed.Add(int64(n)); conn.memoryBudget > 0 && used > conn.memoryBudget {
+		return fmt.Errorf("%w: %v bytes used, budget is %v bytes", errMemoryBudget, used, conn.memoryBudget)
+	}
+	return nil
+}
+
+// release subtracts n bytes from the memory used by the Conn.
+func (conn *Conn) release(n int) {
+	conn.memoryUsed.Add(-int64(n))
+}
+
+// SetPacketFunc sets the function called for every packet read from and written to the Conn, replacing the
+// PacketFunc passed to the Dialer or ListenConfig. SetPacketFunc may be called at any time, including while
+// the Conn is in use. Passing nil stops calling a function for packets. The function is called with the
+// header of the packet, its raw payload, the address from which the packet originated and the destination
+// address.
+func (conn *Conn) SetPacketFunc(f func(header packet.Header, payload []byte, src, dst net.Addr)) {
+	if f == nil {
+		conn.packetFunc.Store(nil)
+		return
+	}
+	conn.packetFunc.Store(&f)
+}
+
+// AddPacketObserver adds a function that is called for packets read from and written to the Conn, in
+// addition to the PacketFunc and other observers already present. If one or more packet IDs are passed, the
+// function is only called for packets with one of those IDs. AddPacketObserver may be called at any time,
+// including while the Conn is in use, and returns a function that removes the observer again.
+// The payload passed to the function is only valid until it returns, so it must be copied if it is used
+// after that.
+func (conn *Conn) AddPacketObserver(f func(header packet.Header, payload []byte, src, dst net.Addr), ids ...uint32) (remove func()) {
+	o := &packetObserver{f: f, ids: slices.Clone(ids)}
+
+	conn.observersMu.Lock()
+	defer conn.observersMu.Unlock()
+	var observers []*packetObserver
+	if current := conn.observers.Load(); current != nil {
+		observers = slices.Clone(*current)
+	}
+	observers = append(observers, o)
+	conn.observers.Store(&observers)
+
+	return func() {
+		conn.observersMu.Lock()
+		defer conn.observersMu.Unlock()
+		current := conn.observers.Load()
+		if current == nil {
+			return
+		}
+		observers := slices.DeleteFunc(slices.Clone(*current), func(other *packetObserver) bool {
+			return other == o
+		})
+		conn.observers.Store(&observers)
+	}
+}
+
+// observe calls the PacketFunc and all packet observers of the Conn for the packet passed.
+func (conn *Conn) observe(header packet.Header, payload []byte, src, dst net.Addr) {
+	if f := conn.packetFunc.Load(); f != nil {
+		(*f)(header, payload, src, dst)
+	}
+	if observers := conn.observers.Load(); observers != nil {
+		for _, o := range *observers {
+			if len(o.ids) == 0 || slices.Contains(o.ids, header.PacketID) {
+				o.f(header, payload, src, dst)
+			}
+		}
+	}
+}
+
+// PacketSize returns the exact size in bytes that the packet passed would occupy in a batch if it were written
+// to the Conn using WritePacket. The packet is converted to the Protocol of the Conn first, so the size
+// returned is the size sent over network before compression and encryption. If the packet converts to multiple
+// packets, the sum of their sizes is returned.
+func (conn *Conn) PacketSize(pk packet.Packet) int {
+	n := 0
+	for _, converted := range conn.proto.ConvertFromLatest(pk, conn) {
+		n += packet.SizeShieldID(converted, conn.shieldID.Load())
+	}
+	return n
+}
+
 // ReadPacket reads a packet from the Conn, depending on the packet ID that is found in front of the packet
 // data. If a read deadline is set, an error is returned if the deadline is reached before any packet is
 // received. ReadPacket must not be called on multiple goroutines simultaneously.
@@ -396,6 +672,7 @@ func (conn *Conn) ReadPacket() (pk packet.Packet, err error) {
 	case <-conn.readDeadline:
 		return nil, conn.wrap(context.DeadlineExceeded, "read packet")
 	case data := <-conn.packets:
+		conn.release(len(data.full))
 		pk, err := data.decode(conn)
 		if err != nil {
 			conn.log.Error("read packet: " + err.Error())
@@ -451,6 +728,7 @@ func (conn *Conn) ReadBatch() (pks []packet.Packet, err error) {
 		return nil, conn.wrap(context.DeadlineExceeded, "read batch")
 	case batch := <-conn.packetBatches:
 		for _, data := range batch {
+			conn.release(len(data.full))
 			pk, err := data.decode(conn)
 			if err != nil {
 				conn.log.Error("decode batch: " + err.Error())
@@ -483,9 +761,11 @@ func (conn *Conn) ResourcePacks() []*resource.Pack {
 // tick, after which it is flushed to the connection. Write returns the amount of bytes written n.
 func (conn *Conn) Write(b []byte) (n int, err error) {
 	conn.sendMu.Lock()
-	defer conn.sendMu.Unlock()
-
 	conn.bufferedSend = append(conn.bufferedSend, b)
+	conn.sendMu.Unlock()
+	if err := conn.buffered(len(b)); err != nil {
+		return 0, err
+	}
 	return len(b), nil
 }
 
@@ -501,6 +781,7 @@ func (conn *Conn) ReadBytes() ([]byte, error) {
 	case <-conn.readDeadline:
 		return nil, conn.wrap(context.DeadlineExceeded, "read")
 	case data := <-conn.packets:
+		conn.release(len(data.full))
 		return data.full, nil
 	}
 }
@@ -521,6 +802,7 @@ func (conn *Conn) Read(b []byte) (n int, err error) {
 	case <-conn.readDeadline:
 		return 0, conn.wrap(context.DeadlineExceeded, "read")
 	case data := <-conn.packets:
+		conn.release(len(data.full))
 		if len(b) < len(data.full) {
 			return 0, conn.wrap(errBufferTooSmall, "read")
 		}
@@ -536,23 +818,34 @@ func (conn *Conn) Flush() error {
 		return conn.closeErr("flush")
 	default:
 	}
+	conn.flushMu.Lock()
+	defer conn.flushMu.Unlock()
+
+	// Swap out the packets buffered so that the batch can be encoded without holding sendMu, allowing other
+	// goroutines to continue writing packets in the meantime.
 	conn.sendMu.Lock()
-	defer conn.sendMu.Unlock()
+	batch := conn.bufferedSend
+	conn.bufferedSend = conn.flushing
+	conn.sendMu.Unlock()
 
-	if len(conn.bufferedSend) > 0 {
-		if err := conn.enc.Encode(conn.bufferedSend); err != nil && !errors.Is(err, net.ErrClosed) {
+	if len(batch) > 0 {
+		if err := conn.enc.Encode(batch); err != nil && !errors.Is(err, net.ErrClosed) {
 			// Should never happen.
 			panic(fmt.Errorf("error encoding packet batch: %w", err))
 		}
-		// First manually clear out conn.bufferedSend so that re-using the slice after resetting its length to
-		// 0 doesn't result in an 'invisible' memory leak.
-		for i := range conn.bufferedSend {
-			conn.bufferedSend[i] = nil
+		n := 0
+		for _, b := range batch {
+			n += len(b)
 		}
-		// Slice the conn.bufferedSend to a length of 0 so we don't have to re-allocate space in this slice
-		// every time.
-		conn.bufferedSend = conn.bufferedSend[:0]
-	}
+		conn.release(n)
+		conn.bufferedBytes.Add(-int64(n))
+		// First manually clear out the batch so that re-using the slice after resetting its length to 0
+		// doesn't result in an 'invisible' memory leak.
+		clear(batch)
+	}
+	// Slice the batch to a length of 0 so we don't have to re-allocate space in this slice every time. It is
+	// swapped back in for bufferedSend in the next Flush.
+	conn.flushing = batch[:0]
 	return nil
 }
 
@@ -562,8 +855,14 @@ func (conn *Conn) Close() error {
 	var err error
 	conn.once.Do(func() {
 		err = conn.Flush()
+		// Wait for batches that are still being compressed, so that they are written before the underlying
+		// connection is closed.
+		_ = conn.enc.Wait()
 		close(conn.close)
 		_ = conn.conn.Close()
+		if conn.flusher != nil {
+			conn.flusher.release()
+		}
 	})
 	return err
 }
@@ -590,10 +889,10 @@ func (conn *Conn) SetReadDeadline(t time.Time) error {
 	empty := time.Time{}
 	if t == empty {
 		conn.readDeadline = make(chan time.Time)
-	} else if t.Before(time.Now()) {
+	} else if now := conn.clock.Now(); t.Before(now) {
 		panic(fmt.Errorf("error setting read deadline: time passed is before time.Now()"))
 	} else {
-		conn.readDeadline = time.After(time.Until(t))
+		conn.readDeadline = conn.clock.NewTimer(t.Sub(now)).C()
 	}
 	return nil
 }
@@ -621,11 +920,12 @@ func (conn *Conn) ClientCacheEnabled() bool {
 	return conn.cacheEnabled
 }
 
-// ChunkRadius returns the initial chunk radius of the connection. For connections obtained through a
-// Listener, this is the radius that the client requested. For connections obtained through a Dialer, this
-// is the radius that the server approved upon.
+// ChunkRadius returns the chunk radius of the connection. For connections obtained through a Listener, this
+// is the radius that was last approved for the client, which is updated when the client changes it if
+// ListenConfig.MaximumChunkRadius is set. For connections obtained through a Dialer, this is the initial
+// radius that the server approved upon.
 func (conn *Conn) ChunkRadius() int {
-	return int(conn.gameData.ChunkRadius)
+	return int(conn.chunkRadius.Load())
 }
 
 // takeDeferredPacket locks the deferred packets lock and takes the next packet from the list of deferred
@@ -643,6 +943,7 @@ func (conn *Conn) takeDeferredPacket() (*packetData, bool) {
 	// makes sure garbage collecting the packet is possible.
 	conn.deferredPackets[0] = nil
 	conn.deferredPackets = conn.deferredPackets[1:]
+	conn.release(len(data.full))
 	return data, true
 }
 
@@ -674,6 +975,12 @@ func (conn *Conn) receive(data []byte) error {
 		return nil
 	}
 	if conn.loggedIn && !conn.waitingForSpawn.Load() {
+		if pkData.h.PacketID == packet.IDRequestChunkRadius && conn.maxChunkRadius != 0 {
+			return conn.handleChunkRadiusChange(pkData)
+		}
+		if err := conn.reserve(len(pkData.full)); err != nil {
+			return err
+		}
 		select {
 		case <-conn.close:
 		case previous := <-conn.packets:
@@ -713,10 +1020,23 @@ func (conn *Conn) receiveMultiple(data [][]byte) error {
 		if conn.waitingForSpawn.Load() && pkData.h.PacketID == packet.IDPlayerAuthInput {
 			continue
 		}
+		if conn.loggedIn && !conn.waitingForSpawn.Load() && pkData.h.PacketID == packet.IDRequestChunkRadius && conn.maxChunkRadius != 0 {
+			if err := conn.handleChunkRadiusChange(pkData); err != nil {
+				return err
+			}
+			continue
+		}
 		packets = append(packets, pkData)
 	}
 
 	if conn.loggedIn && !conn.waitingForSpawn.Load() {
+		n := 0
+		for _, pkData := range packets {
+			n += len(pkData.full)
+		}
+		if err := conn.reserve(n); err != nil {
+			return err
+		}
 		select {
 		case <-conn.close:
 		case conn.packetBatches <- packets:
@@ -748,6 +1068,9 @@ func (conn *Conn) handle(pkData *packetData) error {
 	}
 	// This is not the packet we expected next in the login sequence. We push it back so that it may
 	// be handled by the user.
+	if err := conn.reserve(len(pkData.full)); err != nil {
+		return err
+	}
 	conn.deferPacket(pkData)
 	return nil
 }
@@ -775,7 +1098,13 @@ func (conn *Conn) handlePacket(pk packet.Packet) error {
 	case *packet.RequestNetworkSettings:
 		return conn.handleRequestNetworkSettings(pk)
 	case *packet.Login:
-		return conn.handleLogin(pk)
+		if conn.logins != nil {
+			return conn.logins.run(func(ctx context.Context) error {
+				defer conn.applyLabels()()
+				return conn.handleLogin(ctx, pk)
+			}, conn.close)
+		}
+		return conn.handleLogin(context.Background(), pk)
 	case *packet.ClientToServerHandshake:
 		return conn.handleClientToServerHandshake()
 	case *packet.ClientCacheStatus:
@@ -815,24 +1144,8 @@ func (conn *Conn) handlePacket(pk packet.Packet) error {
 // handleRequestNetworkSettings handles an incoming RequestNetworkSettings packet. It returns an error if the protocol
 // version is not supported, otherwise sending back a NetworkSettings packet.
 func (conn *Conn) handleRequestNetworkSettings(pk *packet.RequestNetworkSettings) error {
-	found := false
-
-	for _, pro := range conn.acceptedProto {
-		if pro.ID() == pk.ClientProtocol {
-			conn.proto = pro
-			conn.pool = pro.Packets(true)
-			found = true
-			break
-		}
-	}
-	if !found {
-		status := packet.PlayStatusLoginFailedClient
-		if pk.ClientProtocol > protocol.CurrentProtocol {
-			// The server is outdated in this case, so we have to change the status we send.
-			status = packet.PlayStatusLoginFailedServer
-		}
-		_ = conn.WritePacket(&packet.PlayStatus{Status: status})
-		return fmt.Errorf("incompatible protocol version: expected %v, got %v", protocol.CurrentProtocol, pk.ClientProtocol)
+	if err := conn.selectProtocol(pk.ClientProtocol); err != nil {
+		return err
 	}
 
 	conn.expect(packet.IDLogin)
@@ -855,6 +1168,46 @@ func (conn *Conn) handleRequestNetworkSettings(pk *packet.RequestNetworkSettings
 	return nil
 }
 
+// selectProtocol selects the Protocol used to communicate with a client that connected with the protocol
+// version passed. If none of the accepted protocols has this version, the ProtocolMismatchFunc is called to
+// decide what to do with the connection. An error is returned if the client could not be accepted.
+func (conn *Conn) selectProtocol(clientProtocol int32) error {
+	conn.clientProtocol = clientProtocol
+	for _, pro := range conn.acceptedProto {
+		if pro.ID() == clientProtocol {
+			conn.proto = pro
+			conn.pool = pro.Packets(true)
+			return nil
+		}
+	}
+	var res ProtocolMismatchResult
+	if conn.protocolMismatch != nil {
+		res = conn.protocolMismatch(clientProtocol, conn.RemoteAddr())
+	}
+	switch {
+	case res.Protocol != nil:
+		// The client will be accepted through the Protocol returned, which translates the packets of the
+		// client.
+		conn.proto = res.Protocol
+		conn.pool = res.Protocol.Packets(true)
+		return nil
+	case res.TransferAddress != "":
+		_ = conn.WritePacket(&packet.Transfer{Address: res.TransferAddress, Port: res.TransferPort})
+		_ = conn.Flush()
+	case res.Message != "":
+		_ = conn.WritePacket(&packet.Disconnect{Message: res.Message})
+		_ = conn.Flush()
+	default:
+		status := packet.PlayStatusLoginFailedClient
+		if clientProtocol > protocol.CurrentProtocol {
+			// The server is outdated in this case, so we have to change the status we send.
+			status = packet.PlayStatusLoginFailedServer
+		}
+		_ = conn.WritePacket(&packet.PlayStatus{Status: status})
+	}
+	return fmt.Errorf("incompatible protocol version: expected %v, got %v", protocol.CurrentProtocol, clientProtocol)
+}
+
 // handleNetworkSettings handles an incoming NetworkSettings packet, enabling compression for future packets.
 func (conn *Conn) handleNetworkSettings(pk *packet.NetworkSettings) error {
 	alg, ok := packet.CompressionByID(pk.CompressionAlgorithm)
@@ -875,25 +1228,18 @@ func (conn *Conn) handleNetworkSettings(pk *packet.NetworkSettings) error {
 }
 
 // handleLogin handles an incoming login packet. It verifies and decodes the login request found in the packet
-// and returns an error if it couldn't be done successfully.
-func (conn *Conn) handleLogin(pk *packet.Login) error {
-	found := false
-	for _, pro := range conn.acceptedProto {
-		if pro.ID() == pk.ClientProtocol {
-			conn.proto = pro
-			conn.pool = pro.Packets(true)
-			found = true
-			break
-		}
-	}
-	if !found {
-		status := packet.PlayStatusLoginFailedClient
-		if pk.ClientProtocol > protocol.CurrentProtocol {
-			// The server is outdated in this case, so we have to change the status we send.
-			status = packet.PlayStatusLoginFailedServer
+// and returns an error if it couldn't be done successfully. Handling stops early once the context passed is
+// cancelled, which happens when the Conn is closed while the login is verified.
+func (conn *Conn) handleLogin(ctx context.Context, pk *packet.Login) error {
+	if ctx.Err() != nil {
+		return conn.closeErr("handle login")
+	}
+	if pk.ClientProtocol != conn.clientProtocol {
+		// The protocol was not yet negotiated through a RequestNetworkSettings packet, or the client sent a
+		// different protocol this time.
+		if err := conn.selectProtocol(pk.ClientProtocol); err != nil {
+			return err
 		}
-		_ = conn.WritePacket(&packet.PlayStatus{Status: status})
-		return fmt.Errorf("%v connected with an incompatible protocol: expected protocol = %v, client protocol = %v", conn.identityData.DisplayName, protocol.CurrentProtocol, pk.ClientProtocol)
 	}
 
 	// The next expected packet is a response from the client to the handshake.
@@ -902,16 +1248,49 @@ func (conn *Conn) handleLogin(pk *packet.Login) error {
 		err        error
 		authResult login.AuthResult
 	)
-	conn.identityData, conn.clientData, authResult, err = login.Parse(pk.ConnectionRequest)
+	conn.identityData, conn.clientData, authResult, err = login.ParseWith(pk.ConnectionRequest, conn.authenticator, conn.clientDataLimits)
+	if err == nil {
+		err = conn.loginPolicy.Check(conn.identityData, conn.clientData, authResult)
+	}
+	if err == nil {
+		err = conn.loginPolicy.Skin.Apply(&conn.clientData)
+	}
+	if errors.Is(err, login.ErrStaleChain) {
+		_ = conn.WritePacket(&packet.Disconnect{Message: text.Colourf("<red>Your login has expired. Please restart your game.</red>")})
+	} else if errors.Is(err, login.ErrBannedDevice) || errors.Is(err, login.ErrTitleNotAllowed) {
+		_ = conn.WritePacket(&packet.Disconnect{Message: text.Colourf("<red>You are not allowed to join this server.</red>")})
+	} else if errors.Is(err, login.ErrInvalidSkin) {
+		_ = conn.WritePacket(&packet.Disconnect{Message: text.Colourf("<red>Your skin is not allowed on this server.</red>")})
+	}
 	if err != nil {
 		return fmt.Errorf("parse login request: %w", err)
 	}
+	conn.logIdentity()
+	conn.chainInfo = authResult.Chain
+	conn.connectionRequest = slices.Clone(pk.ConnectionRequest)
 
 	// Make sure the player is logged in with XBOX Live when necessary.
 	if !authResult.XBOXLiveAuthenticated && conn.authEnabled {
 		_ = conn.WritePacket(&packet.Disconnect{Message: text.Colourf("<red>You must be logged in with XBOX Live to join.</red>")})
 		return fmt.Errorf("client was not authenticated to XBOX Live")
 	}
+	// Only authenticated requests are checked for replays: Self-signed requests can be created freely by
+	// anyone, so recording them would only allow clients to fill the replay cache.
+	if authResult.XBOXLiveAuthenticated && conn.replayCache != nil {
+		if err := login.CheckReplay(conn.replayCache, pk.ConnectionRequest, authResult, conn.replayWindow); err != nil {
+			return fmt.Errorf("parse login request: %w", err)
+		}
+	}
+	if ctx.Err() != nil {
+		// The connection was closed while the login request was verified, so there is no point in deriving
+		// the shared secret or continuing the login sequence.
+		return conn.closeErr("handle login")
+	}
+	if conn.encryptionDisabled {
+		// The connection comes from a trusted network, so we skip the handshake and continue the login
+		// sequence as if the client had already responded to it.
+		return conn.handleClientToServerHandshake()
+	}
 	if err := conn.enableEncryption(authResult.PublicKey); err != nil {
 		return fmt.Errorf("enable encryption: %w", err)
 	}
@@ -949,13 +1328,16 @@ func (conn *Conn) handleClientToServerHandshake() error {
 // saltClaims holds the claims for the salt sent by the server in the ServerToClientHandshake packet.
 type saltClaims struct {
 	Salt string `json:"salt"`
+	// PackDeltas is set by a Listener of gophertunnel that can send deltas of resource packs. It is not part
+	// of the vanilla handshake.
+	PackDeltas bool `json:"gophertunnelPackDeltas,omitempty"`
 }
 
 // handleServerToClientHandshake handles an incoming ServerToClientHandshake packet. It initialises encryption
 // on the client side of the connection, using the hash and the public key from the server exposed in the
 // packet.
 func (conn *Conn) handleServerToClientHandshake(pk *packet.ServerToClientHandshake) error {
-	tok, err := jwt.ParseSigned(string(pk.JWT))
+	tok, err := login.ParseToken(string(pk.JWT))
 	if err != nil {
 		return fmt.Errorf("parse server token: %w", err)
 	}
@@ -967,6 +1349,18 @@ func (conn *Conn) handleServerToClientHandshake(pk *packet.ServerToClientHandsha
 	if err := login.ParsePublicKey(kStr, pub); err != nil {
 		return fmt.Errorf("parse server public key: %w", err)
 	}
+	if len(conn.serverKeyFingerprints) != 0 {
+		if fingerprint := login.PublicKeyFingerprint(pub); !slices.Contains(conn.serverKeyFingerprints, fingerprint) {
+			_ = conn.Close()
+			return fmt.Errorf("server public key %v is not pinned", fingerprint)
+		}
+	}
+	if conn.verifyServerKey != nil {
+		if err := conn.verifyServerKey(pub); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("verify server public key: %w", err)
+		}
+	}
 
 	var c saltClaims
 	if err := tok.Claims(pub, &c); err != nil {
@@ -978,15 +1372,13 @@ func (conn *Conn) handleServerToClientHandshake(pk *packet.ServerToClientHandsha
 		return fmt.Errorf("decode ServerToClientHandshake salt: %w", err)
 	}
 
-	x, _ := pub.Curve.ScalarMult(pub.X, pub.Y, conn.privateKey.D.Bytes())
-	// Make sure to pad the shared secret up to 96 bytes.
-	sharedSecret := append(bytes.Repeat([]byte{0}, 48-len(x.Bytes())), x.Bytes()...)
-
-	keyBytes := sha256.Sum256(append(salt, sharedSecret...))
-
-	// Finally we enable encryption for the enc and dec using the secret pubKey bytes we produced.
-	conn.enc.EnableEncryption(conn.proto.Encryption(keyBytes))
-	conn.dec.EnableEncryption(conn.proto.Encryption(keyBytes))
+	// Finally we enable encryption for the enc and dec using the secret key bytes we produce.
+	if err := conn.deriveEncryption(pk.JWT, salt, pub); err != nil {
+		return fmt.Errorf("enable encryption: %w", err)
+	}
+	conn.encrypted = true
+	// Deltas are only useful if there are earlier versions of packs to apply them to.
+	conn.serverPackDeltas = c.PackDeltas && conn.packCache != nil
 
 	// We write a ClientToServerHandshake packet (which has no payload) as a response.
 	_ = conn.WritePacket(&packet.ClientToServerHandshake{})
@@ -1013,6 +1405,10 @@ func (conn *Conn) handleResourcePacksInfo(pk *packet.ResourcePacksInfo) error {
 	}
 	packsToDownload := make([]string, 0, totalPacks)
 
+	var (
+		totalSize uint64
+		urlPacks  []protocol.TexturePackInfo
+	)
 	for index, pack := range pk.TexturePacks {
 		id := pack.UUID.String()
 		if _, ok := conn.packQueue.downloadingPacks[id]; ok {
@@ -1028,28 +1424,133 @@ func (conn *Conn) handleResourcePacksInfo(pk *packet.ResourcePacksInfo) error {
 			conn.packQueue.packAmount--
 			continue
 		}
-		// This UUID_Version is a hack Mojang put in place.
-		packsToDownload = append(packsToDownload, id+"_"+pack.Version)
-		conn.packQueue.downloadingPacks[id] = downloadingPack{
-			size:       pack.Size,
-			buf:        bytes.NewBuffer(make([]byte, 0, pack.Size)),
-			newFrag:    make(chan []byte),
-			contentKey: pack.ContentKey,
+		if conn.packCache != nil {
+			if p, ok := conn.packCache.load(pack); ok {
+				conn.packMu.Lock()
+				conn.resourcePacks = append(conn.resourcePacks, p)
+				conn.packMu.Unlock()
+				conn.packQueue.packAmount--
+				continue
+			}
+		}
+		if conn.maxPackSize > 0 && pack.Size > conn.maxPackSize {
+			return fmt.Errorf("resource pack %v is too large: %v bytes, maximum is %v", pack.UUID, pack.Size, conn.maxPackSize)
+		}
+		if totalSize += pack.Size; conn.maxPackDownloadSize > 0 && totalSize > conn.maxPackDownloadSize {
+			return fmt.Errorf("resource packs are too large: more than %v bytes, maximum is %v", totalSize, conn.maxPackDownloadSize)
+		}
+		if pack.DownloadURL != "" && conn.downloadPacksFromURL {
+			// The server offers the pack on an external URL, such as a CDN. It is downloaded from there once all
+			// packs have been checked.
+			urlPacks = append(urlPacks, pack)
+			continue
+		}
+		packsToDownload = append(packsToDownload, conn.queueResourcePackDownload(pack))
+	}
+
+	if (len(packsToDownload) != 0 || len(urlPacks) != 0) && conn.packDownloadTimeout > 0 {
+		conn.packDownloadTimer = conn.clock.AfterFunc(conn.packDownloadTimeout, func() {
+			select {
+			case <-conn.close:
+				return
+			default:
+			}
+			conn.log.Error("download resource packs: timed out", "timeout", conn.packDownloadTimeout)
+			_ = conn.Close()
+		})
+	}
+	if len(urlPacks) != 0 {
+		// Downloading the packs may take a long time, so it is done off the goroutine reading packets. The
+		// server waits for our response before sending anything else, so no packets are expected meanwhile.
+		conn.expect()
+		go conn.downloadResourcePacksURL(urlPacks, packsToDownload)
+		return nil
+	}
+	conn.requestResourcePacks(packsToDownload)
+	return nil
+}
+
+// newDownloadingPack returns a downloadingPack for the resource pack passed, which is downloaded from the
+// server in chunks.
+func (conn *Conn) newDownloadingPack(pack protocol.TexturePackInfo) downloadingPack {
+	return downloadingPack{
+		size: pack.Size,
+		// The size is sent by the server, so we don't trust it to allocate the full buffer upfront.
+		buf:        bytes.NewBuffer(make([]byte, 0, min(pack.Size, maxPackPreallocation))),
+		newFrag:    make(chan []byte, conn.packDownloadWindow),
+		contentKey: pack.ContentKey,
+	}
+}
+
+// queueResourcePackDownload queues the resource pack passed to be downloaded from the server in chunks and
+// returns the entry that it should be requested with in the ResourcePackClientResponse packet. If the server
+// can send deltas and an earlier version of the pack is cached, a delta against that version is requested.
+func (conn *Conn) queueResourcePackDownload(pack protocol.TexturePackInfo) string {
+	id := pack.UUID.String()
+	p := conn.newDownloadingPack(pack)
+	// This UUID_Version is a hack Mojang put in place.
+	request := id + "_" + pack.Version
+	if conn.serverPackDeltas {
+		if base, ok := conn.packCache.loadEarlier(pack); ok {
+			sum := base.Checksum()
+			p.base = base
+			request += "_" + hex.EncodeToString(sum[:])
 		}
 	}
+	conn.packQueue.downloadingPacks[id] = p
+	return request
+}
 
+// requestResourcePacks responds to the ResourcePacksInfo packet of the server by requesting the packs passed,
+// or by notifying the server that all packs are downloaded if no packs are passed.
+func (conn *Conn) requestResourcePacks(packsToDownload []string) {
 	if len(packsToDownload) != 0 {
 		conn.expect(packet.IDResourcePackDataInfo, packet.IDResourcePackChunkData)
 		_ = conn.WritePacket(&packet.ResourcePackClientResponse{
 			Response:        packet.PackResponseSendPacks,
 			PacksToDownload: packsToDownload,
 		})
-		return nil
+		return
+	}
+	if conn.packDownloadTimer != nil {
+		conn.packDownloadTimer.Stop()
 	}
 	conn.expect(packet.IDResourcePackStack)
-
 	_ = conn.WritePacket(&packet.ResourcePackClientResponse{Response: packet.PackResponseAllPacksDownloaded})
-	return nil
+}
+
+// downloadResourcePacksURL downloads the resource packs passed from their DownloadURL and then requests the
+// packs in packsToDownload from the server, along with all packs of which the download failed. The downloads
+// are cancelled when the connection is closed, which happens if the ResourcePackDownloadTimeout is exceeded.
+func (conn *Conn) downloadResourcePacksURL(packs []protocol.TexturePackInfo, packsToDownload []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-conn.close:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, pack := range packs {
+		p, err := downloadResourcePackURL(ctx, pack)
+		if err == nil {
+			conn.cachePack(p)
+			conn.packMu.Lock()
+			conn.resourcePacks = append(conn.resourcePacks, p.WithContentKey(pack.ContentKey))
+			conn.packMu.Unlock()
+			conn.packQueue.packAmount--
+			continue
+		}
+		if ctx.Err() != nil {
+			// The connection was closed.
+			return
+		}
+		conn.log.Warn("download resource pack: download from URL failed, requesting pack from server instead", "UUID", pack.UUID, "err", err)
+		packsToDownload = append(packsToDownload, conn.queueResourcePackDownload(pack))
+	}
+	conn.requestResourcePacks(packsToDownload)
 }
 
 // handleResourcePackStack handles a ResourcePackStack packet sent by the server. The stack defines the order
@@ -1109,6 +1610,10 @@ func (conn *Conn) hasPack(uuid string, version string, hasBehaviours bool) bool
 // packChunkSize is the size of a single chunk of data from a resource pack: 512 kB or 0.5 MB
 const packChunkSize = 1024 * 128
 
+// maxPackPreallocation is the maximum amount of bytes allocated for a resource pack before any of its data
+// is downloaded.
+const maxPackPreallocation = 1024 * 1024 * 16
+
 // handleResourcePackClientResponse handles an incoming resource pack client response packet. The packet is
 // handled differently depending on the response.
 func (conn *Conn) handleResourcePackClientResponse(pk *packet.ResourcePackClientResponse) error {
@@ -1119,7 +1624,7 @@ func (conn *Conn) handleResourcePackClientResponse(pk *packet.ResourcePackClient
 		return conn.Close()
 	case packet.PackResponseSendPacks:
 		packs := pk.PacksToDownload
-		conn.packQueue = &resourcePackQueue{packs: conn.resourcePacks}
+		conn.packQueue = &resourcePackQueue{packs: conn.resourcePacks, deltas: conn.packDeltas}
 		if err := conn.packQueue.Request(packs); err != nil {
 			return fmt.Errorf("lookup resource packs by UUID: %w", err)
 		}
@@ -1130,7 +1635,15 @@ func (conn *Conn) handleResourcePackClientResponse(pk *packet.ResourcePackClient
 		}
 	case packet.PackResponseAllPacksDownloaded:
 		pk := &packet.ResourcePackStack{BaseGameVersion: protocol.CurrentVersion, Experiments: []protocol.ExperimentData{{Name: "cameras", Enabled: true}}}
-		for _, pack := range conn.resourcePacks {
+		order := conn.orderResourcePacks
+		if order == nil {
+			order = resource.OrderByDependencies
+		}
+		packs, err := order(conn.resourcePacks)
+		if err != nil {
+			return fmt.Errorf("order resource pack stack: %w", err)
+		}
+		for _, pack := range packs {
 			resourcePack := protocol.StackResourcePack{UUID: pack.UUID().String(), Version: pack.Version()}
 			// If it has behaviours, add it to the behaviour pack list. If not, we add it to the texture packs
 			// list.
@@ -1160,6 +1673,9 @@ func (conn *Conn) handleResourcePackClientResponse(pk *packet.ResourcePackClient
 // startGame sends a StartGame packet using the game data of the connection.
 func (conn *Conn) startGame() {
 	data := conn.gameData
+	// Expect the response before sending the StartGame packet: A client may respond before the Flush below
+	// returns, particularly on low latency connections.
+	conn.expect(packet.IDRequestChunkRadius, packet.IDSetLocalPlayerAsInitialised)
 	_ = conn.WritePacket(&packet.StartGame{
 		Difficulty:                   data.Difficulty,
 		EntityUniqueID:               data.EntityUniqueID,
@@ -1202,7 +1718,6 @@ func (conn *Conn) startGame() {
 		UseBlockNetworkIDHashes:      data.UseBlockNetworkIDHashes,
 	})
 	_ = conn.Flush()
-	conn.expect(packet.IDRequestChunkRadius, packet.IDSetLocalPlayerAsInitialised)
 }
 
 // nextResourcePackDownload moves to the next resource pack to download and sends a resource pack data info
@@ -1220,6 +1735,31 @@ func (conn *Conn) nextResourcePackDownload() error {
 	return nil
 }
 
+// cachePack stores a resource pack downloaded from the server in the pack cache of the connection, if it has
+// one.
+func (conn *Conn) cachePack(pack *resource.Pack) {
+	if conn.packCache == nil {
+		return
+	}
+	if err := conn.packCache.store(pack); err != nil {
+		conn.log.Warn("cache resource pack: "+err.Error(), "UUID", pack.UUID())
+	}
+}
+
+// downloadResourcePackURL downloads the resource pack passed from its DownloadURL and checks that the pack
+// downloaded is the one that the server announced. The download fails if it is larger than the size of the
+// pack announced.
+func downloadResourcePackURL(ctx context.Context, info protocol.TexturePackInfo) (*resource.Pack, error) {
+	p, err := resource.ReadURLContext(ctx, info.DownloadURL, info.Size)
+	if err != nil {
+		return nil, err
+	}
+	if p.UUID() != info.UUID || p.Version() != info.Version || uint64(p.Len()) != info.Size {
+		return nil, fmt.Errorf("pack downloaded (%v v%v, %v bytes) does not match pack announced (%v v%v, %v bytes)", p.UUID(), p.Version(), p.Len(), info.UUID, info.Version, info.Size)
+	}
+	return p, nil
+}
+
 // handleResourcePackDataInfo handles a resource pack data info packet, which initiates the downloading of the
 // pack by the client.
 func (conn *Conn) handleResourcePackDataInfo(pk *packet.ResourcePackDataInfo) error {
@@ -1231,11 +1771,26 @@ func (conn *Conn) handleResourcePackDataInfo(pk *packet.ResourcePackDataInfo) er
 		// sent in the ResourcePacksInfo packet.
 		return fmt.Errorf("handle ResourcePackDataInfo: unknown pack (UUID=%v)", id)
 	}
-	if pack.size != pk.Size {
+	if strings.HasSuffix(pk.UUID, packDeltaSuffix) {
+		// The server sends a delta against the earlier version of the pack that we requested it for.
+		if pack.base == nil {
+			return fmt.Errorf("handle ResourcePackDataInfo: delta sent for resource pack %v without requesting it", id)
+		}
+		if pk.Size >= pack.size {
+			return fmt.Errorf("handle ResourcePackDataInfo: delta for resource pack %v is not smaller than the pack: %v bytes", id, pk.Size)
+		}
+		pack.delta, pack.fullSize, pack.size = true, pack.size, pk.Size
+	} else if pack.size != pk.Size {
 		// Size mismatch: The ResourcePacksInfo packet had a size for the pack that did not match with the
 		// size sent here.
 		conn.log.Warn("handle ResourcePackDataInfo: pack had a different size in ResourcePacksInfo than in ResourcePackDataInfo", "UUID", id)
 		pack.size = pk.Size
+		if conn.maxPackSize > 0 && pack.size > conn.maxPackSize {
+			return fmt.Errorf("handle ResourcePackDataInfo: resource pack %v is too large: %v bytes, maximum is %v", id, pack.size, conn.maxPackSize)
+		}
+	}
+	if pk.DataChunkSize == 0 {
+		return fmt.Errorf("handle ResourcePackDataInfo: chunk size of resource pack %v is 0", id)
 	}
 
 	// Remove the resource pack from the downloading packs and add it to the awaiting packets.
@@ -1251,13 +1806,26 @@ func (conn *Conn) handleResourcePackDataInfo(pk *packet.ResourcePackDataInfo) er
 		chunkCount++
 	}
 
-	idCopy := pk.UUID
+	idCopy, hash := pk.UUID, pk.Hash
 	go func() {
-		for i := uint32(0); i < chunkCount; i++ {
+		// The chunks of the pack are accounted for while it is downloaded, but not once it has been parsed.
+		defer func() {
+			conn.release(pack.buf.Len())
+		}()
+		// Up to packDownloadWindow chunks are requested ahead, so that the download is not slowed down by a
+		// full round trip for every chunk. A new chunk is requested every time one arrives.
+		var requested uint32
+		request := func() {
 			_ = conn.WritePacket(&packet.ResourcePackChunkRequest{
 				UUID:       idCopy,
-				ChunkIndex: i,
+				ChunkIndex: requested,
 			})
+			requested++
+		}
+		for requested < chunkCount && requested < uint32(conn.packDownloadWindow) {
+			request()
+		}
+		for i := uint32(0); i < chunkCount; i++ {
 			select {
 			case <-conn.close:
 				return
@@ -1265,24 +1833,33 @@ func (conn *Conn) handleResourcePackDataInfo(pk *packet.ResourcePackDataInfo) er
 				// Write the fragment to the full buffer of the downloading resource pack.
 				_, _ = pack.buf.Write(frag)
 			}
+			if requested < chunkCount {
+				request()
+			}
 		}
 		conn.packMu.Lock()
 		defer conn.packMu.Unlock()
 
 		if pack.buf.Len() != int(pack.size) {
 			conn.log.Error(fmt.Sprintf("download resource pack: incorrect resource pack size: expected %v, got %v", pack.size, pack.buf.Len()), "UUID", id)
+			_ = conn.Close()
 			return
 		}
 		// First parse the resource pack from the total byte buffer we obtained.
-		newPack, err := resource.Read(pack.buf)
+		newPack, err := pack.parse(hash)
 		if err != nil {
-			conn.log.Error("download resource pack: invalid full resource pack data: "+err.Error(), "UUID", id)
+			conn.log.Error("download resource pack: "+err.Error(), "UUID", id)
+			_ = conn.Close()
 			return
 		}
+		conn.cachePack(newPack)
 		conn.packQueue.packAmount--
 		// Finally we add the resource to the resource packs slice.
 		conn.resourcePacks = append(conn.resourcePacks, newPack.WithContentKey(pack.contentKey))
 		if conn.packQueue.packAmount == 0 {
+			if conn.packDownloadTimer != nil {
+				conn.packDownloadTimer.Stop()
+			}
 			conn.expect(packet.IDResourcePackStack)
 			_ = conn.WritePacket(&packet.ResourcePackClientResponse{Response: packet.PackResponseAllPacksDownloaded})
 		}
@@ -1300,7 +1877,7 @@ func (conn *Conn) handleResourcePackChunkData(pk *packet.ResourcePackChunkData)
 		// download a resource pack.
 		return fmt.Errorf("chunk data for resource pack that was not being downloaded")
 	}
-	lastData := pack.buf.Len()+int(pack.chunkSize) >= int(pack.size)
+	lastData := uint64(pk.ChunkIndex+1)*uint64(pack.chunkSize) >= pack.size
 	if !lastData && uint32(len(pk.Data)) != pack.chunkSize {
 		// The chunk data didn't have the full size and wasn't the last data to be sent for the resource pack,
 		// meaning we got too little data.
@@ -1309,37 +1886,41 @@ func (conn *Conn) handleResourcePackChunkData(pk *packet.ResourcePackChunkData)
 	if pk.ChunkIndex != pack.expectedIndex {
 		return fmt.Errorf("expected chunk index %v, got %v", pack.expectedIndex, pk.ChunkIndex)
 	}
+	if uint64(pk.ChunkIndex)*uint64(pack.chunkSize)+uint64(len(pk.Data)) > pack.size {
+		return fmt.Errorf("chunk data exceeds resource pack size %v", pack.size)
+	}
 	pack.expectedIndex++
-	pack.newFrag <- pk.Data
+	if err := conn.reserve(len(pk.Data)); err != nil {
+		return fmt.Errorf("download resource pack: %w", err)
+	}
+	select {
+	case <-conn.close:
+		// The connection was closed, so the goroutine downloading the pack no longer receives chunks.
+	case pack.newFrag <- pk.Data:
+	}
 	return nil
 }
 
 // handleResourcePackChunkRequest handles a resource pack chunk request, which requests a part of the resource
 // pack to be downloaded.
 func (conn *Conn) handleResourcePackChunkRequest(pk *packet.ResourcePackChunkRequest) error {
-	current := conn.packQueue.currentPack
-	if current.UUID().String() != pk.UUID {
-		return fmt.Errorf("expected pack UUID %v, but got %v", current.UUID(), pk.UUID)
+	if conn.packQueue.currentID != pk.UUID {
+		return fmt.Errorf("expected pack UUID %v, but got %v", conn.packQueue.currentID, pk.UUID)
 	}
 	if conn.packQueue.currentOffset != uint64(pk.ChunkIndex)*packChunkSize {
 		return fmt.Errorf("expected pack UUID %v, but got %v", conn.packQueue.currentOffset/packChunkSize, pk.ChunkIndex)
 	}
+	// The chunk data points directly into the content of the pack, which is shared by all connections, so
+	// that serving the same pack to many clients does not allocate a copy of every chunk for each of them.
 	response := &packet.ResourcePackChunkData{
 		UUID:       pk.UUID,
 		ChunkIndex: pk.ChunkIndex,
 		DataOffset: conn.packQueue.currentOffset,
-		Data:       make([]byte, packChunkSize),
+		Data:       conn.packQueue.currentChunk(int64(conn.packQueue.currentOffset), packChunkSize),
 	}
 	conn.packQueue.currentOffset += packChunkSize
-	// We read the data directly into the response's data.
-	if n, err := current.ReadAt(response.Data, int64(response.DataOffset)); err != nil {
-		// If we hit an EOF, we don't need to return an error, as we've simply reached the end of the content
-		// AKA the last chunk.
-		if err != io.EOF {
-			return fmt.Errorf("read resource pack chunk: %w", err)
-		}
-		response.Data = response.Data[:n]
-
+	if conn.packQueue.currentOffset >= uint64(conn.packQueue.currentLen()) {
+		// This was the last chunk of the pack, so move on to the next pack once it is sent.
 		defer func() {
 			if !conn.packQueue.AllDownloaded() {
 				_ = conn.nextResourcePackDownload()
@@ -1414,20 +1995,21 @@ func (conn *Conn) handleRequestChunkRadius(pk *packet.RequestChunkRadius) error
 	if r := conn.gameData.ChunkRadius; r != 0 {
 		radius = r
 	}
+	radius = conn.approveChunkRadius(radius)
 	_ = conn.WritePacket(&packet.ChunkRadiusUpdated{ChunkRadius: radius})
-	conn.gameData.ChunkRadius = pk.ChunkRadius
+	conn.gameData.ChunkRadius = radius
+	conn.chunkRadius.Store(radius)
 
+	if conn.entityIdentifiers != nil {
+		if pk := conn.entityIdentifiers.Packet(); pk != nil {
+			_ = conn.WritePacket(pk)
+		}
+	}
 	// The client crashes when not sending all biomes, due to achievements assuming all biomes are present.
-	//noinspection SpellCheckingInspection
 	if conn.biomes == nil {
-		const s = `CgAKDWJhbWJvb19qdW5nbGUFCGRvd25mYWxsZmZmPwULdGVtcGVyYXR1cmUzM3M/AAoTYmFtYm9vX2p1bmdsZV9oaWxscwUIZG93bmZhbGxmZmY/BQt0ZW1wZXJhdHVyZTMzcz8ACgViZWFjaAUIZG93bmZhbGzNzMw+BQt0ZW1wZXJhdHVyZc3MTD8ACgxiaXJjaF9mb3Jlc3QFCGRvd25mYWxsmpkZPwULdGVtcGVyYXR1cmWamRk/AAoSYmlyY2hfZm9yZXN0X2hpbGxzBQhkb3duZmFsbJqZGT8FC3RlbXBlcmF0dXJlmpkZPwAKGmJpcmNoX2ZvcmVzdF9oaWxsc19tdXRhdGVkBQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlMzMzPwAKFGJpcmNoX2ZvcmVzdF9tdXRhdGVkBQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlMzMzPwAKCmNvbGRfYmVhY2gFCGRvd25mYWxsmpmZPgULdGVtcGVyYXR1cmXNzEw9AAoKY29sZF9vY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAD8ACgpjb2xkX3RhaWdhBQhkb3duZmFsbM3MzD4FC3RlbXBlcmF0dXJlAAAAvwAKEGNvbGRfdGFpZ2FfaGlsbHMFCGRvd25mYWxszczMPgULdGVtcGVyYXR1cmUAAAC/AAoSY29sZF90YWlnYV9tdXRhdGVkBQhkb3duZmFsbM3MzD4FC3RlbXBlcmF0dXJlAAAAvwAKD2RlZXBfY29sZF9vY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAD8AChFkZWVwX2Zyb3plbl9vY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAAAAChNkZWVwX2x1a2V3YXJtX29jZWFuBQhkb3duZmFsbAAAAD8FC3RlbXBlcmF0dXJlAAAAPwAKCmRlZXBfb2NlYW4FCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmUAAAA/AAoPZGVlcF93YXJtX29jZWFuBQhkb3duZmFsbAAAAD8FC3RlbXBlcmF0dXJlAAAAPwAKBmRlc2VydAUIZG93bmZhbGwAAAAABQt0ZW1wZXJhdHVyZQAAAEAACgxkZXNlcnRfaGlsbHMFCGRvd25mYWxsAAAAAAULdGVtcGVyYXR1cmUAAABAAAoOZGVzZXJ0X211dGF0ZWQFCGRvd25mYWxsAAAAAAULdGVtcGVyYXR1cmUAAABAAAoNZXh0cmVtZV9oaWxscwUIZG93bmZhbGyamZk+BQt0ZW1wZXJhdHVyZc3MTD4AChJleHRyZW1lX2hpbGxzX2VkZ2UFCGRvd25mYWxsmpmZPgULdGVtcGVyYXR1cmXNzEw+AAoVZXh0cmVtZV9oaWxsc19tdXRhdGVkBQhkb3duZmFsbJqZmT4FC3RlbXBlcmF0dXJlzcxMPgAKGGV4dHJlbWVfaGlsbHNfcGx1c190cmVlcwUIZG93bmZhbGyamZk+BQt0ZW1wZXJhdHVyZc3MTD4ACiBleHRyZW1lX2hpbGxzX3BsdXNfdHJlZXNfbXV0YXRlZAUIZG93bmZhbGyamZk+BQt0ZW1wZXJhdHVyZc3MTD4ACg1mbG93ZXJfZm9yZXN0BQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlMzMzPwAKBmZvcmVzdAUIZG93bmZhbGzNzEw/BQt0ZW1wZXJhdHVyZTMzMz8ACgxmb3Jlc3RfaGlsbHMFCGRvd25mYWxszcxMPwULdGVtcGVyYXR1cmUzMzM/AAoMZnJvemVuX29jZWFuBQhkb3duZmFsbAAAAD8FC3RlbXBlcmF0dXJlAAAAAAAKDGZyb3plbl9yaXZlcgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAAAACgRoZWxsBQhkb3duZmFsbAAAAAAFC3RlbXBlcmF0dXJlAAAAQAAKDWljZV9tb3VudGFpbnMFCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmUAAAAAAAoKaWNlX3BsYWlucwUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAAAAChFpY2VfcGxhaW5zX3NwaWtlcwUIZG93bmZhbGwAAIA/BQt0ZW1wZXJhdHVyZQAAAAAACgZqdW5nbGUFCGRvd25mYWxsZmZmPwULdGVtcGVyYXR1cmUzM3M/AAoLanVuZ2xlX2VkZ2UFCGRvd25mYWxszcxMPwULdGVtcGVyYXR1cmUzM3M/AAoTanVuZ2xlX2VkZ2VfbXV0YXRlZAUIZG93bmZhbGzNzEw/BQt0ZW1wZXJhdHVyZTMzcz8ACgxqdW5nbGVfaGlsbHMFCGRvd25mYWxsZmZmPwULdGVtcGVyYXR1cmUzM3M/AAoOanVuZ2xlX211dGF0ZWQFCGRvd25mYWxsZmZmPwULdGVtcGVyYXR1cmUzM3M/AAoTbGVnYWN5X2Zyb3plbl9vY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAAAACg5sdWtld2FybV9vY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAD8ACgptZWdhX3RhaWdhBQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlmpmZPgAKEG1lZ2FfdGFpZ2FfaGlsbHMFCGRvd25mYWxszcxMPwULdGVtcGVyYXR1cmWamZk+AAoEbWVzYQUIZG93bmZhbGwAAAAABQt0ZW1wZXJhdHVyZQAAAEAACgptZXNhX2JyeWNlBQhkb3duZmFsbAAAAAAFC3RlbXBlcmF0dXJlAAAAQAAKDG1lc2FfcGxhdGVhdQUIZG93bmZhbGwAAAAABQt0ZW1wZXJhdHVyZQAAAEAAChRtZXNhX3BsYXRlYXVfbXV0YXRlZAUIZG93bmZhbGwAAAAABQt0ZW1wZXJhdHVyZQAAAEAAChJtZXNhX3BsYXRlYXVfc3RvbmUFCGRvd25mYWxsAAAAAAULdGVtcGVyYXR1cmUAAABAAAoabWVzYV9wbGF0ZWF1X3N0b25lX211dGF0ZWQFCGRvd25mYWxsAAAAAAULdGVtcGVyYXR1cmUAAABAAAoPbXVzaHJvb21faXNsYW5kBQhkb3duZmFsbAAAgD8FC3RlbXBlcmF0dXJlZmZmPwAKFW11c2hyb29tX2lzbGFuZF9zaG9yZQUIZG93bmZhbGwAAIA/BQt0ZW1wZXJhdHVyZWZmZj8ACgVvY2VhbgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAD8ACgZwbGFpbnMFCGRvd25mYWxszczMPgULdGVtcGVyYXR1cmXNzEw/AAobcmVkd29vZF90YWlnYV9oaWxsc19tdXRhdGVkBQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlmpmZPgAKFXJlZHdvb2RfdGFpZ2FfbXV0YXRlZAUIZG93bmZhbGzNzEw/BQt0ZW1wZXJhdHVyZQAAgD4ACgVyaXZlcgUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZQAAAD8ACg1yb29mZWRfZm9yZXN0BQhkb3duZmFsbM3MTD8FC3RlbXBlcmF0dXJlMzMzPwAKFXJvb2ZlZF9mb3Jlc3RfbXV0YXRlZAUIZG93bmZhbGzNzEw/BQt0ZW1wZXJhdHVyZTMzMz8ACgdzYXZhbm5hBQhkb3duZmFsbAAAAAAFC3RlbXBlcmF0dXJlmpmZPwAKD3NhdmFubmFfbXV0YXRlZAUIZG93bmZhbGwAAAA/BQt0ZW1wZXJhdHVyZc3MjD8ACg9zYXZhbm5hX3BsYXRlYXUFCGRvd25mYWxsAAAAAAULdGVtcGVyYXR1cmUAAIA/AAoXc2F2YW5uYV9wbGF0ZWF1X211dGF0ZWQFCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmUAAIA/AAoLc3RvbmVfYmVhY2gFCGRvd25mYWxsmpmZPgULdGVtcGVyYXR1cmXNzEw+AAoQc3VuZmxvd2VyX3BsYWlucwUIZG93bmZhbGzNzMw+BQt0ZW1wZXJhdHVyZc3MTD8ACglzd2FtcGxhbmQFCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmXNzEw/AAoRc3dhbXBsYW5kX211dGF0ZWQFCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmXNzEw/AAoFdGFpZ2EFCGRvd25mYWxszcxMPwULdGVtcGVyYXR1cmUAAIA+AAoLdGFpZ2FfaGlsbHMFCGRvd25mYWxszcxMPwULdGVtcGVyYXR1cmUAAIA+AAoNdGFpZ2FfbXV0YXRlZAUIZG93bmZhbGzNzEw/BQt0ZW1wZXJhdHVyZQAAgD4ACgd0aGVfZW5kBQhkb3duZmFsbAAAAD8FC3RlbXBlcmF0dXJlAAAAPwAKCndhcm1fb2NlYW4FCGRvd25mYWxsAAAAPwULdGVtcGVyYXR1cmUAAAA/AAA=`
-		b, _ := base64.StdEncoding.DecodeString(s)
-		_ = conn.WritePacket(&packet.BiomeDefinitionList{
-			SerialisedBiomeDefinitions: b,
-		})
+		_ = conn.WritePacket(conn.biomeDefinitions.Packet())
 	} else {
-		b, _ := nbt.MarshalEncoding(conn.biomes, nbt.NetworkLittleEndian)
-		_ = conn.WritePacket(&packet.BiomeDefinitionList{SerialisedBiomeDefinitions: b})
+		_ = conn.WritePacket(&packet.BiomeDefinitionList{SerialisedBiomeDefinitions: conn.biomes})
 	}
 
 	_ = conn.WritePacket(&packet.PlayStatus{Status: packet.PlayStatusPlayerSpawn})
@@ -1435,6 +2017,42 @@ func (conn *Conn) handleRequestChunkRadius(pk *packet.RequestChunkRadius) error
 	return nil
 }
 
+// handleChunkRadiusChange handles a RequestChunkRadius packet sent by the client after spawning. It approves
+// the radius requested, up to the maximum chunk radius, and calls the chunk radius function if the radius
+// changed.
+func (conn *Conn) handleChunkRadiusChange(pkData *packetData) error {
+	pks, err := pkData.decode(conn)
+	if err != nil {
+		return err
+	}
+	for _, pk := range pks {
+		req, ok := pk.(*packet.RequestChunkRadius)
+		if !ok {
+			continue
+		}
+		if req.ChunkRadius < 1 {
+			return fmt.Errorf("expected chunk radius of at least 1, got %v", req.ChunkRadius)
+		}
+		radius := conn.approveChunkRadius(req.ChunkRadius)
+		if err := conn.WritePacket(&packet.ChunkRadiusUpdated{ChunkRadius: radius}); err != nil {
+			return fmt.Errorf("send ChunkRadiusUpdated: %w", err)
+		}
+		if previous := conn.chunkRadius.Swap(radius); previous != radius && conn.chunkRadiusFunc != nil {
+			conn.chunkRadiusFunc(conn, int(radius))
+		}
+	}
+	return conn.Flush()
+}
+
+// approveChunkRadius returns the chunk radius approved for a client requesting the radius passed. It is
+// limited to the maximum chunk radius of the connection, if set.
+func (conn *Conn) approveChunkRadius(radius int32) int32 {
+	if conn.maxChunkRadius != 0 {
+		return min(radius, conn.maxChunkRadius)
+	}
+	return radius
+}
+
 // handleChunkRadiusUpdated handles an incoming ChunkRadiusUpdated packet, which updates the initial chunk
 // radius of the connection.
 func (conn *Conn) handleChunkRadiusUpdated(pk *packet.ChunkRadiusUpdated) error {
@@ -1444,6 +2062,7 @@ func (conn *Conn) handleChunkRadiusUpdated(pk *packet.ChunkRadiusUpdated) error
 	conn.expect(packet.IDPlayStatus)
 
 	conn.gameData.ChunkRadius = pk.ChunkRadius
+	conn.chunkRadius.Store(pk.ChunkRadius)
 	conn.gameDataReceived.Store(true)
 
 	conn.tryFinaliseClientConn()
@@ -1468,6 +2087,10 @@ func (conn *Conn) handleSetLocalPlayerAsInitialised(pk *packet.SetLocalPlayerAsI
 func (conn *Conn) handlePlayStatus(pk *packet.PlayStatus) error {
 	switch pk.Status {
 	case packet.PlayStatusLoginSuccess:
+		if conn.requireEncryption && !conn.encrypted {
+			_ = conn.Close()
+			return fmt.Errorf("server did not enable encryption")
+		}
 		if err := conn.WritePacket(&packet.ClientCacheStatus{Enabled: conn.cacheEnabled}); err != nil {
 			return fmt.Errorf("send ClientCacheStatus: %w", err)
 		}
@@ -1525,12 +2148,15 @@ func (conn *Conn) tryFinaliseClientConn() {
 // enableEncryption enables encryption on the server side over the connection. It sends an unencrypted
 // handshake packet to the client and enables encryption after that.
 func (conn *Conn) enableEncryption(clientPublicKey *ecdsa.PublicKey) error {
-	signer, _ := jose.NewSigner(jose.SigningKey{Key: conn.privateKey, Algorithm: jose.ES384}, &jose.SignerOptions{
-		ExtraHeaders: map[jose.HeaderKey]any{"x5u": login.MarshalPublicKey(&conn.privateKey.PublicKey)},
+	signer, err := jose.NewSigner(jose.SigningKey{Key: joseSigner{s: conn.key}, Algorithm: jose.ES384}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{"x5u": login.MarshalPublicKey(conn.key.Public())},
 	})
+	if err != nil {
+		return fmt.Errorf("create signer: %w", err)
+	}
 	// We produce an encoded JWT using the header and payload above, then we send the JWT in a ServerToClient-
 	// Handshake packet so that the client can initialise encryption.
-	serverJWT, err := jwt.Signed(signer).Claims(saltClaims{Salt: base64.RawStdEncoding.EncodeToString(conn.salt)}).CompactSerialize()
+	serverJWT, err := jwt.Signed(signer).Claims(saltClaims{Salt: base64.RawStdEncoding.EncodeToString(conn.salt), PackDeltas: conn.packDeltas != nil}).CompactSerialize()
 	if err != nil {
 		return fmt.Errorf("compact serialise server JWT: %w", err)
 	}
@@ -1540,17 +2166,33 @@ func (conn *Conn) enableEncryption(clientPublicKey *ecdsa.PublicKey) error {
 	// Flush immediately as we'll enable encryption after this.
 	_ = conn.Flush()
 
-	// We first compute the shared secret.
-	x, _ := clientPublicKey.Curve.ScalarMult(clientPublicKey.X, clientPublicKey.Y, conn.privateKey.D.Bytes())
+	// Finally we enable encryption for the encoder and decoder using the secret key bytes we produce.
+	return conn.deriveEncryption([]byte(serverJWT), conn.salt, clientPublicKey)
+}
 
-	sharedSecret := append(bytes.Repeat([]byte{0}, 48-len(x.Bytes())), x.Bytes()...)
+// deriveEncryption computes the shared secret of the connection from the private key of this end and the
+// public key of the other end passed, derives the encryption key from it and the salt passed, and enables
+// encryption for the encoder and decoder of the connection. The shared secret and key are zeroed after use,
+// so that they do not linger in memory, unless a handshakeFunc is set, which receives a copy of the key
+// along with the handshake JWT passed.
+func (conn *Conn) deriveEncryption(handshakeJWT, salt []byte, pub *ecdsa.PublicKey) error {
+	sharedSecret, err := conn.key.SharedSecret(pub)
+	if err != nil {
+		return fmt.Errorf("compute shared secret: %w", err)
+	}
+	defer clear(sharedSecret)
 
-	keyBytes := sha256.Sum256(append(conn.salt, sharedSecret...))
+	var keyBytes [32]byte
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(sharedSecret)
+	h.Sum(keyBytes[:0])
+	h.Reset()
+	defer clear(keyBytes[:])
+	conn.handshake(handshakeJWT, salt, keyBytes)
 
-	// Finally we enable encryption for the encoder and decoder using the secret key bytes we produced.
 	conn.enc.EnableEncryption(conn.proto.Encryption(keyBytes))
 	conn.dec.EnableEncryption(conn.proto.Encryption(keyBytes))
-
 	return nil
 }
 
@@ -1559,6 +2201,19 @@ func (conn *Conn) expect(packetIDs ...uint32) {
 	conn.expectedIDs.Store(packetIDs)
 }
 
+// delay blocks for the duration passed, or until the connection is closed. False is returned if the
+// connection was closed before the duration passed.
+func (conn *Conn) delay(d time.Duration) bool {
+	t := conn.clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-conn.close:
+		return false
+	case <-t.C():
+		return true
+	}
+}
+
 // closeErr returns an adequate connection closed error for the op passed. If the connection was closed
 // through a Disconnect packet, the message is contained.
 func (conn *Conn) closeErr(op string) error {
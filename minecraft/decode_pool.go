@@ -0,0 +1,145 @@
+package minecraft
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// decodeBurst is the maximum amount of batches that a worker of a decodePool decodes for a single connection
+// before giving other connections waiting for a worker a turn.
+const decodeBurst = 16
+
+// decodePool is a bounded pool of goroutines that decrypt, decompress and handle the batches received by the
+// logged in connections of a Listener. The goroutine of a connection only reads raw batches and queues them,
+// so that the work and memory needed to decode them is bounded by the amount of workers rather than by the
+// amount of connections.
+//
+// Connections of a Listener outlive it, so the workers keep running after the Listener is closed, until the
+// last connection reading through the pool is closed too.
+type decodePool struct {
+	ready chan *decodeQueue
+
+	mu      sync.Mutex
+	queues  int
+	closed  bool
+	stopped bool
+	done    chan struct{}
+}
+
+// decodeQueue holds the raw batches read by a single connection that are waiting to be decoded. A queue is
+// handed to at most one worker at a time, so that the batches of a connection are always decoded and handled
+// in the order that they were read.
+type decodeQueue struct {
+	pool    *decodePool
+	batches chan []byte
+	handle  func(data []byte)
+
+	// scheduled is true while the queue is waiting for or being processed by a worker.
+	scheduled atomic.Bool
+}
+
+// newDecodePool creates a decodePool with n workers. The workers stop once the close channel passed is
+// closed and all queues of the pool are released.
+func newDecodePool(n int, close <-chan struct{}) *decodePool {
+	p := &decodePool{ready: make(chan *decodeQueue, n), done: make(chan struct{})}
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	go func() {
+		<-close
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.closed = true
+		p.stopIfIdle()
+	}()
+	return p
+}
+
+// queue creates a decodeQueue that calls the function passed for every batch submitted to it, on one of
+// the workers of the pool. The queue must be released once no more batches are submitted to it. queue returns
+// nil if the workers of the pool were already stopped.
+func (p *decodePool) queue(handle func(data []byte)) *decodeQueue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return nil
+	}
+	p.queues++
+	return &decodeQueue{pool: p, batches: make(chan []byte, decodeBurst), handle: handle}
+}
+
+// release releases a queue created using queue.
+func (p *decodePool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queues--
+	p.stopIfIdle()
+}
+
+// stopIfIdle stops the workers of the pool if it was closed and none of its queues are in use. p.mu must be
+// held.
+func (p *decodePool) stopIfIdle() {
+	if p.closed && p.queues == 0 && !p.stopped {
+		p.stopped = true
+		close(p.done)
+	}
+}
+
+// work processes the queues that have batches waiting until the pool is stopped.
+func (p *decodePool) work() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case q := <-p.ready:
+			q.run()
+		}
+	}
+}
+
+// submit queues the raw batch passed and schedules the queue on a worker if it is not already. The data is
+// copied, as the data returned by packet.Decoder.Read is only valid until the next call. submit blocks if
+// the queue is full and returns false if the done channel passed is closed while waiting.
+func (q *decodeQueue) submit(data []byte, done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return false
+	case q.batches <- append([]byte(nil), data...):
+	}
+	if !q.scheduled.CompareAndSwap(false, true) {
+		// A worker is already processing the queue and will find the batch.
+		return true
+	}
+	select {
+	case <-done:
+		return false
+	case q.pool.ready <- q:
+		return true
+	}
+}
+
+// run handles the batches in the queue until it is empty. After decodeBurst batches, the queue is handed back
+// to the pool if other workers are free to pick it up, so that a connection flooding batches does not hold on
+// to a worker.
+func (q *decodeQueue) run() {
+	for {
+		for i := 0; i < decodeBurst; i++ {
+			select {
+			case data := <-q.batches:
+				q.handle(data)
+			default:
+				q.scheduled.Store(false)
+				// A batch may have been submitted after the queue was found empty, but before scheduled was
+				// reset, in which case the submitter did not schedule the queue.
+				if len(q.batches) == 0 || !q.scheduled.CompareAndSwap(false, true) {
+					return
+				}
+			}
+		}
+		select {
+		case q.pool.ready <- q:
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package minecraft_test
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"testing"
+	"time"
+)
+
+// TestDecodeWorkers checks if the packets received by a connection of a Listener with DecodeWorkers are read
+// in the order that they were sent, also after the Listener is closed.
+func TestDecodeWorkers(t *testing.T) {
+	// Pipe closes the Listener once the connections are logged in.
+	client, server, err := minecraft.Pipe(minecraft.PipeConfig{
+		Login:        true,
+		ListenConfig: minecraft.ListenConfig{AuthenticationDisabled: true, DecodeWorkers: 2},
+		Dialer:       minecraft.Dialer{FlushRate: -1},
+	})
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer server.Close()
+	defer client.Close()
+
+	const n = 200
+	go func() {
+		for i := 0; i < n; i++ {
+			_ = client.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: fmt.Sprint(i)})
+			if i%3 == 0 {
+				_ = client.Flush()
+			}
+		}
+		_ = client.Flush()
+	}()
+	if err := server.SetReadDeadline(time.Now().Add(time.Second * 10)); err != nil {
+		t.Fatalf("set read deadline: %v", err)
+	}
+	for i := 0; i < n; {
+		pk, err := server.ReadPacket()
+		if err != nil {
+			t.Fatalf("read packet %v: %v", i, err)
+		}
+		text, ok := pk.(*packet.Text)
+		if !ok {
+			continue
+		}
+		if text.Message != fmt.Sprint(i) {
+			t.Fatalf("expected packet %v, got %v", i, text.Message)
+		}
+		i++
+	}
+}
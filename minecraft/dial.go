@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/clock"
 	"github.com/sandertv/gophertunnel/minecraft/internal"
 	"log/slog"
 
@@ -48,9 +49,14 @@ type Dialer struct {
 	// TokenSource is the source for Microsoft Live Connect tokens. If set to a non-nil oauth2.TokenSource,
 	// this field is used to obtain tokens which in turn are used to authenticate to XBOX Live.
 	// The minecraft/auth package provides an oauth2.TokenSource implementation (auth.tokenSource) to use
-	// device auth to login.
-	// If TokenSource is nil, the connection will not use authentication.
+	// device auth to login. If TokenSource is an *auth.ChainSource, the XBOX Live token it caches is re-used
+	// for every dial.
+	// If TokenSource and ChainFunc are nil, the connection will not use authentication.
 	TokenSource oauth2.TokenSource
+	// ChainFunc is called to obtain the login chain for the private key of the connection if TokenSource is
+	// nil. It may be set to log in using a chain issued by a custom authentication service, for example one
+	// returned by login.IssueChain, to join servers that trust that service through ListenConfig.ChainIssuer.
+	ChainFunc func(ctx context.Context, key *ecdsa.PrivateKey) (string, error)
 
 	// PacketFunc is called whenever a packet is read from or written to the connection returned when using
 	// Dialer.Dial(). It includes packets that are otherwise covered in the connection sequence, such as the
@@ -63,6 +69,38 @@ type Dialer struct {
 	// and version of the resource pack, the number of the current pack being downloaded, and the total amount of packs.
 	// The boolean returned determines if the pack will be downloaded or not.
 	DownloadResourcePack func(id uuid.UUID, version string, current, total int) bool
+	// ResourcePackDownloadWindow is the maximum amount of resource pack chunks that are requested from the
+	// server at the same time. Requesting multiple chunks ahead avoids waiting a full round trip for every
+	// chunk, which greatly speeds up joining servers with large resource packs. If zero, a window of 8 chunks
+	// is used. Setting it to 1 requests chunks one at a time.
+	ResourcePackDownloadWindow int
+	// DownloadResourcePacksFromURL specifies if resource packs that the server offers on an external URL, such
+	// as a CDN, are downloaded from that URL instead of being requested from the server in chunks. If the
+	// download fails, or if the pack downloaded does not match the pack announced by the server, the pack is
+	// requested from the server as usual.
+	DownloadResourcePacksFromURL bool
+	// ResourcePackCacheDir is a directory in which resource packs downloaded from servers are stored. Packs
+	// found in this directory with the same UUID, version and size as a pack sent by the server are loaded
+	// from it rather than downloaded again, like the vanilla client does when reconnecting to a server. If
+	// an earlier version of a pack is cached and the server is a Listener with PreviousResourcePacks, only
+	// the changes to the pack are downloaded. If empty, packs are not cached.
+	ResourcePackCacheDir string
+	// MaxResourcePackSize is the maximum size in bytes of a single resource pack downloaded from the server.
+	// The connection fails if the server sends a larger pack. If zero, the size of packs is not limited.
+	MaxResourcePackSize uint64
+	// MaxResourcePackDownloadSize is the maximum size in bytes of all resource packs downloaded from the
+	// server combined. The connection fails if the server sends more. If zero, it is not limited.
+	MaxResourcePackDownloadSize uint64
+	// ResourcePackDownloadTimeout is the maximum duration of downloading all resource packs from the server,
+	// including packs downloaded from a URL. The connection is closed if the download takes longer. If zero, the download has no timeout other than
+	// the context passed to DialContext.
+	ResourcePackDownloadTimeout time.Duration
+	// MemoryBudget is the maximum amount of memory in bytes that the connection may hold in packets received
+	// but not yet read, packets written but not yet flushed and resource packs that are being downloaded. The
+	// connection is closed if receiving packets or downloading resource packs causes the budget to be
+	// exceeded. Writing packets that cause the budget to be exceeded flushes the connection immediately. If
+	// zero (by default), the memory held by the connection is not limited.
+	MemoryBudget int
 
 	// DisconnectOnUnknownPackets specifies if the connection should disconnect if packets received are not present
 	// in the packet pool. If true, such packets lead to the connection being closed immediately.
@@ -90,6 +128,14 @@ type Dialer struct {
 	// will not be flushed automatically. In this case, calling `(*Conn).Flush()` is required after any
 	// calls to `(*Conn).Write()` or `(*Conn).WritePacket()` to send the packets over network.
 	FlushRate time.Duration
+	// FlushThreshold is the amount of bytes of packets written to the connection after which it is flushed
+	// immediately, instead of at the next FlushRate tick. The goroutine writing the packet that exceeds the
+	// threshold performs the flush. If zero (by default), the connection is only flushed at the FlushRate.
+	FlushThreshold int
+	// Clock is the clock.Clock used for the timers of the connection, such as those for flushing, read
+	// deadlines and the ResourcePackDownloadTimeout. If nil, clock.System is used. The deadline of the
+	// context.Context passed to DialContext is not affected by the Clock.
+	Clock clock.Clock
 
 	IPAddress string
 
@@ -103,6 +149,37 @@ type Dialer struct {
 	// the client when an XUID is present without logging in.
 	// For getting this to work with BDS, authentication should be disabled.
 	KeepXBLIdentityData bool
+
+	// ForwardedLogin is the login request of a client connected to a proxy, as returned by
+	// Conn.ConnectionRequest. If set, the Dialer forwards the still-signed chain of this request to the
+	// server, appending a token signed by ForwardingKey, instead of obtaining a chain of its own. The server
+	// must trust the public key of ForwardingKey, for example through ListenConfig.ForwarderKeys.
+	// TokenSource and IdentityData are ignored if ForwardedLogin is set.
+	ForwardedLogin []byte
+	// ForwardingKey is the private key of the proxy used to sign forwarded login requests. It must be set if
+	// ForwardedLogin is set.
+	ForwardingKey *ecdsa.PrivateKey
+
+	// ServerKeyFingerprints is a list of fingerprints, as returned by login.PublicKeyFingerprint, of the
+	// public keys that the server is allowed to use in the ServerToClientHandshake. If non-empty, the Dialer
+	// refuses servers that present any other key, or that do not enable encryption at all, protecting
+	// against man-in-the-middle attacks when connecting to a known server.
+	ServerKeyFingerprints []string
+	// VerifyServerKey, if non-nil, is called with the public key that the server presents in the
+	// ServerToClientHandshake. If it returns an error, the connection is closed. Like ServerKeyFingerprints,
+	// setting VerifyServerKey makes the Dialer refuse servers that do not enable encryption.
+	VerifyServerKey func(key *ecdsa.PublicKey) error
+	// HandshakeFunc, if non-nil, is called with the handshake JWT, the salt and the encryption key of the
+	// connection once encryption is enabled. It exists so that packet capture analysis tools can decrypt
+	// their own sessions for debugging. The key grants access to all traffic of the connection, so
+	// HandshakeFunc should never be set in production.
+	HandshakeFunc func(info HandshakeInfo)
+
+	// RequireEncryption, if set to true, makes the Dialer refuse servers that complete the login sequence
+	// without first sending a ServerToClientHandshake to enable encryption, such as a Listener with an
+	// EncryptionDisabledFunc. By default, such servers are accepted so that the Dialer may be used over
+	// trusted internal links without encryption.
+	RequireEncryption bool
 }
 
 // Dial dials a Minecraft connection to the address passed over the network passed. The network is typically
@@ -164,6 +241,12 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 	if d.FlushRate == 0 {
 		d.FlushRate = time.Second / 20
 	}
+	if d.Clock == nil {
+		d.Clock = clock.System
+	}
+	if d.ResourcePackDownloadWindow <= 0 {
+		d.ResourcePackDownloadWindow = 8
+	}
 
 	key, _ := ecdsa.GenerateKey(elliptic.P384(), cryptorand.Reader)
 	var chainData string
@@ -177,6 +260,11 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 			return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: err}
 		}
 		d.IdentityData = identityData
+	} else if d.ChainFunc != nil {
+		chainData, err = d.ChainFunc(ctx, key)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("obtain login chain: %w", err)}
+		}
 	}
 
 	n, ok := networkByID(network, d.ErrorLog)
@@ -195,13 +283,27 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 		return nil, err
 	}
 
-	conn = newConn(netConn, key, d.ErrorLog, d.Protocol, d.FlushRate, false, d.ReadBatches)
+	conn = newConn(netConn, NewPrivateKey(key), d.ErrorLog, d.Protocol, d.Clock, d.FlushRate, false, d.ReadBatches)
 	conn.pool = conn.proto.Packets(false)
 	conn.identityData = d.IdentityData
 	conn.clientData = d.ClientData
-	conn.packetFunc = d.PacketFunc
+	conn.SetPacketFunc(d.PacketFunc)
 	conn.downloadResourcePack = d.DownloadResourcePack
+	conn.packDownloadWindow = d.ResourcePackDownloadWindow
+	conn.downloadPacksFromURL = d.DownloadResourcePacksFromURL
+	conn.memoryBudget = int64(d.MemoryBudget)
+	conn.flushThreshold = int64(d.FlushThreshold)
+	conn.maxPackSize = d.MaxResourcePackSize
+	conn.maxPackDownloadSize = d.MaxResourcePackDownloadSize
+	conn.packDownloadTimeout = d.ResourcePackDownloadTimeout
+	if d.ResourcePackCacheDir != "" {
+		conn.packCache = &packCache{dir: d.ResourcePackCacheDir}
+	}
 	conn.cacheEnabled = d.EnableClientCache
+	conn.requireEncryption = d.RequireEncryption || len(d.ServerKeyFingerprints) != 0 || d.VerifyServerKey != nil
+	conn.serverKeyFingerprints = d.ServerKeyFingerprints
+	conn.verifyServerKey = d.VerifyServerKey
+	conn.handshakeFunc = d.HandshakeFunc
 	conn.disconnectOnInvalidPacket = d.DisconnectOnInvalidPackets
 	conn.disconnectOnUnknownPacket = d.DisconnectOnUnknownPackets
 
@@ -209,7 +311,17 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 	defaultClientData(address, conn.identityData.DisplayName, &conn.clientData)
 
 	var request []byte
-	if d.TokenSource == nil {
+	if d.ForwardedLogin != nil {
+		if d.ForwardingKey == nil {
+			return nil, conn.wrap(fmt.Errorf("forward login: no forwarding key set"), "dial")
+		}
+		if conn.identityData, _, _, err = login.Parse(d.ForwardedLogin); err != nil {
+			return nil, conn.wrap(fmt.Errorf("forward login: %w", err), "dial")
+		}
+		if request, err = login.EncodeForwarded(d.ForwardedLogin, conn.clientData, d.ForwardingKey, key); err != nil {
+			return nil, conn.wrap(fmt.Errorf("forward login: %w", err), "dial")
+		}
+	} else if chainData == "" {
 		// We haven't logged into the user's XBL account. We create a login request with only one token
 		// holding the identity data set in the Dialer after making sure we clear data from the identity data
 		// that is only present when logged in.
@@ -220,7 +332,9 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 	} else {
 		// We login as an Android device and this will show up in the 'titleId' field in the JWT chain, which
 		// we can't edit. We just enforce Android data for logging in.
-		setAndroidData(&conn.clientData)
+		if d.TokenSource != nil {
+			setAndroidData(&conn.clientData)
+		}
 
 		request = login.Encode(chainData, conn.clientData, key)
 		identityData, _, _, _ := login.Parse(request)
@@ -229,6 +343,8 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 		conn.identityData = identityData
 	}
 
+	conn.logIdentity()
+
 	readyForLogin, connected := make(chan struct{}), make(chan struct{})
 	ctx, cancel := context.WithCancelCause(ctx)
 	go listenConn(conn, readyForLogin, connected, cancel)
@@ -238,14 +354,18 @@ func (d Dialer) DialContext(ctx context.Context, network, address string) (conn
 		return nil, conn.wrap(fmt.Errorf("send request network settings: %w", err), "dial")
 	}
 
-	fchan := make(chan bool, 1)
+	// Packets sent during the login sequence are flushed regularly even if the FlushRate of the Dialer is
+	// negative. The goroutine stops once DialContext returns.
+	stopFlush := make(chan struct{})
+	defer close(stopFlush)
 	go func() {
-		t := time.NewTicker(time.Millisecond * 50)
+		t := d.Clock.NewTicker(time.Millisecond * 50)
+		defer t.Stop()
 		for {
 			select {
-			case <-t.C:
-				conn.Flush()
-			case <-fchan:
+			case <-t.C():
+				_ = conn.Flush()
+			case <-stopFlush:
 				return
 			}
 		}
@@ -306,6 +426,7 @@ func listenConn(conn *Conn, readyForLogin, connected chan struct{}, cancel conte
 	defer func() {
 		_ = conn.Close()
 	}()
+	conn.setPhase(phaseLogin)
 	cancelContext := true
 	for {
 		// We finally arrived at the packet decoding loop. We constantly decode packets that arrive
@@ -321,7 +442,16 @@ func listenConn(conn *Conn, readyForLogin, connected chan struct{}, cancel conte
 			}
 			return
 		}
-		for _, data := range packets {
+		for i, data := range packets {
+			if conn.readBatches && conn.loggedIn {
+				// Once logged in, the remaining packets of the batch are passed on together, so that they may
+				// be read using ReadBatch.
+				if err := conn.receiveMultiple(packets[i:]); err != nil {
+					conn.log.Error(err.Error())
+					return
+				}
+				break
+			}
 			loggedInBefore, readyToLoginBefore := conn.loggedIn, conn.readyToLogin
 			if err := conn.receive(data); err != nil {
 				if cancelContext {
@@ -340,6 +470,7 @@ func listenConn(conn *Conn, readyForLogin, connected chan struct{}, cancel conte
 				// This is the signal that the connection was considered logged in, so we put a value in the channel so
 				// that it may be detected.
 				cancelContext = false
+				conn.setPhase(phasePlay)
 				connected <- struct{}{}
 			}
 		}
@@ -349,6 +480,10 @@ func listenConn(conn *Conn, readyForLogin, connected chan struct{}, cancel conte
 // authChain requests the Minecraft auth JWT chain using the credentials passed. If successful, an encoded
 // chain ready to be put in a login request is returned.
 func authChain(ctx context.Context, src oauth2.TokenSource, key *ecdsa.PrivateKey) (string, error) {
+	if cs, ok := src.(*auth.ChainSource); ok {
+		// The ChainSource caches the XSTS token, so only the chain itself needs to be requested.
+		return cs.Chain(ctx, key)
+	}
 	// Obtain the Live token, and using that the XSTS token.
 	liveToken, err := src.Token()
 	if err != nil {
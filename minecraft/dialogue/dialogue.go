@@ -0,0 +1,127 @@
+// Package dialogue implements the NPC dialogues that may be shown to players using the NPCDialogue packet.
+// A dialogue is shown as if spoken by an entity and has a title, a body of text and a list of buttons. The
+// buttons are sent as JSON in the ActionJSON field of the packet, which follows a schema that is not
+// documented: Dialogue takes care of producing it.
+//
+// The entity that a dialogue is shown for must exist on the client and must have the
+// protocol.EntityDataKeyHasNPC metadata set to 1, otherwise the client does not show the dialogue.
+//
+// Dialogues may be sent to a connection using a Manager, which calls the handler passed when the player
+// presses a button or closes the dialogue.
+package dialogue
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"strings"
+)
+
+// Dialogue is an NPC dialogue shown to a player.
+type Dialogue struct {
+	// Title is the title of the dialogue, shown as the name of the NPC.
+	Title string
+	// Body is the text shown in the dialogue.
+	Body string
+	// Buttons is a list of buttons shown below the body, from top to bottom.
+	Buttons []Button
+}
+
+// Mode is the mode of a Button, which specifies when its commands are run by the client.
+type Mode int
+
+const (
+	// ModeButton is the Mode of buttons shown in the dialogue. Their commands are run when they are pressed.
+	ModeButton Mode = iota
+	// ModeOnClose is the Mode of buttons that are not shown, but whose commands are run when the dialogue is
+	// closed.
+	ModeOnClose
+	// ModeOnEnter is the Mode of buttons that are not shown, but whose commands are run when the dialogue is
+	// opened.
+	ModeOnEnter
+)
+
+// Button is a button of a Dialogue.
+type Button struct {
+	// Text is the text shown on the button.
+	Text string
+	// Commands is a list of commands that the client runs when the button is pressed, such as '/say hi'.
+	// Servers that handle button presses through a Manager typically leave it empty.
+	Commands []string
+	// Mode specifies when the commands of the button are run. It is ModeButton for regular buttons.
+	Mode Mode
+}
+
+const (
+	// buttonTypeCommand is the type of buttons that run commands, which all buttons sent by this package are.
+	buttonTypeCommand = 1
+	// commandVersion is the command version sent for the commands of a button.
+	commandVersion = 17
+)
+
+// MarshalJSON ...
+func (b Button) MarshalJSON() ([]byte, error) {
+	type command struct {
+		Line    string `json:"cmd_line"`
+		Version int    `json:"cmd_ver"`
+	}
+	commands := make([]command, len(b.Commands))
+	for i, cmd := range b.Commands {
+		commands[i] = command{Line: cmd, Version: commandVersion}
+	}
+	return json.Marshal(struct {
+		Name string    `json:"button_name"`
+		Data []command `json:"data"`
+		Mode Mode      `json:"mode"`
+		Text string    `json:"text"`
+		Type int       `json:"type"`
+	}{
+		Name: b.Text,
+		Data: commands,
+		Mode: b.Mode,
+		Text: strings.Join(b.Commands, "\n"),
+		Type: buttonTypeCommand,
+	})
+}
+
+// ActionJSON returns the JSON of the buttons of the Dialogue, as sent in the ActionJSON field of the
+// NPCDialogue packet.
+func (d Dialogue) ActionJSON() (string, error) {
+	buttons := d.Buttons
+	if buttons == nil {
+		buttons = []Button{}
+	}
+	data, err := json.Marshal(buttons)
+	if err != nil {
+		return "", fmt.Errorf("encode dialogue buttons: %w", err)
+	}
+	return string(data), nil
+}
+
+// Packet returns the NPCDialogue packet that opens the Dialogue for the entity with the unique ID passed. The
+// scene name identifies the dialogue in the NPCRequest packets sent by the client in response. It must not
+// be empty.
+func (d Dialogue) Packet(entityUniqueID int64, scene string) (*packet.NPCDialogue, error) {
+	actions, err := d.ActionJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &packet.NPCDialogue{
+		EntityUniqueID: uint64(entityUniqueID),
+		ActionType:     packet.NPCDialogueActionOpen,
+		Dialogue:       d.Body,
+		SceneName:      scene,
+		NPCName:        d.Title,
+		ActionJSON:     actions,
+	}, nil
+}
+
+// Close returns the NPCDialogue packet that closes the dialogue with the scene name passed, shown for the
+// entity with the unique ID passed.
+func Close(entityUniqueID int64, scene string) *packet.NPCDialogue {
+	return &packet.NPCDialogue{
+		EntityUniqueID: uint64(entityUniqueID),
+		ActionType:     packet.NPCDialogueActionClose,
+		SceneName:      scene,
+	}
+}
@@ -0,0 +1,116 @@
+package dialogue
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"sync"
+)
+
+// Response is the response of a player to a Dialogue sent using a Manager.
+type Response struct {
+	// Dialogue is the Dialogue that was responded to.
+	Dialogue Dialogue
+	// Closed is true if the player closed the dialogue without pressing a button.
+	Closed bool
+	// Button is the index of the button in Dialogue.Buttons that was pressed. It is only valid if Closed is
+	// false.
+	Button int
+}
+
+// Manager sends dialogues to a single connection and matches the responses of the player to the dialogues
+// sent. Every dialogue sent gets a unique scene name, through which the responses are matched. A Manager is
+// safe for concurrent use.
+type Manager struct {
+	conn *minecraft.Conn
+
+	mu        sync.Mutex
+	nextScene uint64
+	pending   map[string]pendingDialogue
+}
+
+// pendingDialogue is a dialogue sent by a Manager that has not yet been responded to.
+type pendingDialogue struct {
+	d              Dialogue
+	entityUniqueID int64
+	h              func(Response)
+}
+
+// NewManager returns a Manager that sends dialogues to the connection passed. Packets read from the
+// connection must be passed to Manager.HandlePacket for the Manager to receive responses.
+func NewManager(conn *minecraft.Conn) *Manager {
+	return &Manager{conn: conn, pending: make(map[string]pendingDialogue)}
+}
+
+// Send shows the Dialogue passed to the connection of the Manager, as if spoken by the entity with the unique
+// ID passed. The handler passed is called once the player presses a button or closes the dialogue. It is
+// called from the goroutine that calls Manager.HandlePacket. The dialogue is closed once a button is pressed.
+func (m *Manager) Send(entityUniqueID int64, d Dialogue, h func(Response)) error {
+	m.mu.Lock()
+	scene := fmt.Sprintf("gophertunnel:%v", m.nextScene)
+	m.nextScene++
+	m.mu.Unlock()
+
+	pk, err := d.Packet(entityUniqueID, scene)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.pending[scene] = pendingDialogue{d: d, entityUniqueID: entityUniqueID, h: h}
+	m.mu.Unlock()
+
+	if err := m.conn.WritePacket(pk); err != nil {
+		m.mu.Lock()
+		delete(m.pending, scene)
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// HandlePacket handles a packet read from the connection of the Manager. If the packet is a response to a
+// dialogue sent by the Manager, the handler of that dialogue is called and true is returned. An error is
+// returned if the response is for a dialogue that was not sent by the Manager or for a button that does not
+// exist.
+func (m *Manager) HandlePacket(pk packet.Packet) (bool, error) {
+	req, ok := pk.(*packet.NPCRequest)
+	if !ok {
+		return false, nil
+	}
+	switch req.RequestType {
+	case packet.NPCRequestActionExecuteAction, packet.NPCRequestActionExecuteClosingCommands:
+	default:
+		// The client also sends a request when the dialogue is opened, which needs no handling.
+		return true, nil
+	}
+	m.mu.Lock()
+	p, ok := m.pending[req.SceneName]
+	delete(m.pending, req.SceneName)
+	m.mu.Unlock()
+	if !ok {
+		return true, fmt.Errorf("handle dialogue response: no dialogue with scene %q pending", req.SceneName)
+	}
+
+	r := Response{Dialogue: p.d, Closed: req.RequestType == packet.NPCRequestActionExecuteClosingCommands}
+	if !r.Closed {
+		r.Button = int(req.ActionType)
+		if r.Button >= len(p.d.Buttons) {
+			return true, fmt.Errorf("handle dialogue response: button %v does not exist", r.Button)
+		}
+		// The client does not close the dialogue itself when a button is pressed.
+		if err := m.conn.WritePacket(Close(p.entityUniqueID, req.SceneName)); err != nil {
+			return true, err
+		}
+	}
+	if p.h != nil {
+		p.h(r)
+	}
+	return true, nil
+}
+
+// Pending returns the amount of dialogues that were sent by the Manager and not yet responded to.
+func (m *Manager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
@@ -5,7 +5,11 @@ import (
 	"net"
 )
 
-var errBufferTooSmall = errors.New("a message sent was larger than the buffer used to receive the message into")
+var (
+	errBufferTooSmall = errors.New("a message sent was larger than the buffer used to receive the message into")
+	// errMemoryBudget is returned when a connection holds more memory than its MemoryBudget allows.
+	errMemoryBudget = errors.New("connection exceeded its memory budget")
+)
 
 // wrap wraps the error passed into a net.OpError with the op as operation and returns it, or nil if the error
 // passed is nil.
@@ -0,0 +1,117 @@
+package minecraft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/clock"
+)
+
+// flushScheduler flushes connections sharing the same flush rate. Instead of every connection running a
+// goroutine with a ticker, a connection is only scheduled with the flushScheduler once a packet is written
+// to it, after which it is flushed at the end of the current window of the flush rate together with all
+// other connections scheduled in that window. Idle connections therefore consume no goroutines or timers.
+//
+// Connections with the same flush rate and clock.Clock share a flushScheduler. The flushScheduler is removed
+// once the last of these connections is closed, so that schedulers of clocks that are no longer used, such
+// as a clock.Fake per test, do not pile up.
+type flushScheduler struct {
+	rate  time.Duration
+	clock clock.Clock
+	// refs is the amount of connections using the flushScheduler. It is guarded by flushSchedulersMu.
+	refs int
+
+	mu    sync.Mutex
+	armed bool
+	due   []*Conn
+}
+
+// flushSchedulerKey identifies the flushScheduler of a flush rate and clock.Clock.
+type flushSchedulerKey struct {
+	rate  time.Duration
+	clock clock.Clock
+}
+
+var (
+	flushSchedulersMu sync.Mutex
+	flushSchedulers   = map[flushSchedulerKey]*flushScheduler{}
+)
+
+// flushSchedulerFor returns the flushScheduler for the flush rate and clock.Clock passed, creating it if it
+// does not yet exist. release must be called on the flushScheduler returned once it is no longer used.
+func flushSchedulerFor(rate time.Duration, clk clock.Clock) *flushScheduler {
+	flushSchedulersMu.Lock()
+	defer flushSchedulersMu.Unlock()
+
+	key := flushSchedulerKey{rate: rate, clock: clk}
+	s, ok := flushSchedulers[key]
+	if !ok {
+		s = &flushScheduler{rate: rate, clock: clk}
+		flushSchedulers[key] = s
+	}
+	s.refs++
+	return s
+}
+
+// release releases a flushScheduler obtained using flushSchedulerFor. The flushScheduler is removed once it
+// is released by all connections using it. Connections still scheduled with it are flushed regardless.
+func (s *flushScheduler) release() {
+	flushSchedulersMu.Lock()
+	defer flushSchedulersMu.Unlock()
+
+	if s.refs--; s.refs == 0 {
+		delete(flushSchedulers, flushSchedulerKey{rate: s.rate, clock: s.clock})
+	}
+}
+
+// schedule schedules the Conn passed to be flushed at the end of the current window. Calling schedule for a
+// Conn that is already scheduled has no effect.
+func (s *flushScheduler) schedule(conn *Conn) {
+	if !conn.flushScheduled.CompareAndSwap(false, true) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.due = append(s.due, conn)
+	if !s.armed {
+		s.armed = true
+		s.clock.AfterFunc(s.rate, s.flush)
+	}
+}
+
+// flush flushes all connections scheduled in the window that just ended. Every connection is flushed on its
+// own goroutine, so that a connection that is slow to flush does not hold up any other connection or the next
+// window. A connection of which the previous flush has not yet finished is scheduled for the next window
+// instead.
+func (s *flushScheduler) flush() {
+	s.mu.Lock()
+	conns := s.due
+	s.due, s.armed = nil, false
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		// The Conn is unscheduled before flushing, so that packets written during the Flush schedule it
+		// again.
+		conn.flushScheduled.Store(false)
+		if !conn.flushPending.CompareAndSwap(false, true) {
+			select {
+			case <-conn.close:
+			default:
+				s.schedule(conn)
+			}
+			continue
+		}
+		go s.flushConn(conn)
+	}
+}
+
+// flushConn flushes the Conn passed, closing it if flushing fails.
+func (s *flushScheduler) flushConn(conn *Conn) {
+	defer conn.flushPending.Store(false)
+	reset := conn.applyLabels()
+	defer reset()
+	if err := conn.Flush(); err != nil {
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,263 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Custom is a form with a title and a list of elements that the player may fill out, such as inputs, toggles
+// and sliders. The response to a Custom form is a CustomResponse, which holds a value for every element.
+type Custom struct {
+	// Title is the title shown at the top of the form.
+	Title string
+	// Elements is a list of elements shown in the form, from top to bottom.
+	Elements []Element
+}
+
+// Element is an element of a Custom form. Element is implemented by Label, Input, Toggle, Slider, Dropdown
+// and StepSlider.
+type Element interface {
+	json.Marshaler
+	// parse parses the value submitted for the element and returns it as the type that the element
+	// produces.
+	parse(data json.RawMessage) (any, error)
+}
+
+// MarshalJSON ...
+func (f Custom) MarshalJSON() ([]byte, error) {
+	elements := f.Elements
+	if elements == nil {
+		elements = []Element{}
+	}
+	return json.Marshal(map[string]any{
+		"type":    "custom_form",
+		"title":   f.Title,
+		"content": elements,
+	})
+}
+
+// ParseResponse parses the response data of a Custom form. The values of the CustomResponse returned are
+// validated against the elements of the form, so that they may be obtained using the accessors of
+// CustomResponse without further checks.
+func (f Custom) ParseResponse(data []byte) (CustomResponse, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return CustomResponse{}, fmt.Errorf("parse custom form response: %w", err)
+	}
+	if len(raw) != len(f.Elements) {
+		return CustomResponse{}, fmt.Errorf("parse custom form response: expected %v values, got %v", len(f.Elements), len(raw))
+	}
+	values := make([]any, len(raw))
+	for i, e := range f.Elements {
+		v, err := e.parse(raw[i])
+		if err != nil {
+			return CustomResponse{}, fmt.Errorf("parse custom form response: element %v: %w", i, err)
+		}
+		values[i] = v
+	}
+	return CustomResponse{values: values}, nil
+}
+
+// CustomResponse is the response to a Custom form. It holds a value for every element in the form, which may
+// be obtained using the accessor matching the type of the element at that index.
+type CustomResponse struct {
+	values []any
+}
+
+// Len returns the amount of values in the CustomResponse, which is equal to the amount of elements in the
+// form.
+func (r CustomResponse) Len() int {
+	return len(r.values)
+}
+
+// Input returns the text submitted for the Input element at index i.
+func (r CustomResponse) Input(i int) string {
+	v, _ := r.value(i).(string)
+	return v
+}
+
+// Toggle returns the value submitted for the Toggle element at index i.
+func (r CustomResponse) Toggle(i int) bool {
+	v, _ := r.value(i).(bool)
+	return v
+}
+
+// Slider returns the value submitted for the Slider element at index i.
+func (r CustomResponse) Slider(i int) float64 {
+	v, _ := r.value(i).(float64)
+	return v
+}
+
+// Dropdown returns the index of the option selected for the Dropdown element at index i.
+func (r CustomResponse) Dropdown(i int) int {
+	v, _ := r.value(i).(int)
+	return v
+}
+
+// StepSlider returns the index of the step selected for the StepSlider element at index i.
+func (r CustomResponse) StepSlider(i int) int {
+	return r.Dropdown(i)
+}
+
+// value returns the value at index i, or nil if i is out of range.
+func (r CustomResponse) value(i int) any {
+	if i < 0 || i >= len(r.values) {
+		return nil
+	}
+	return r.values[i]
+}
+
+// Label is an element that shows text in a Custom form. The player cannot interact with it, and its value in
+// the CustomResponse is always nil.
+type Label struct {
+	// Text is the text shown.
+	Text string
+}
+
+// MarshalJSON ...
+func (e Label) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "label", "text": e.Text})
+}
+
+func (e Label) parse(json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// Input is an element in which the player may enter text. Its value in the CustomResponse is obtained using
+// CustomResponse.Input.
+type Input struct {
+	// Text is the text shown above the input field.
+	Text string
+	// Placeholder is the text shown in the input field while it is empty.
+	Placeholder string
+	// Default is the text that the input field is filled with initially.
+	Default string
+}
+
+// MarshalJSON ...
+func (e Input) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "input", "text": e.Text, "placeholder": e.Placeholder, "default": e.Default})
+}
+
+func (e Input) parse(data json.RawMessage) (any, error) {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("input: %w", err)
+	}
+	return v, nil
+}
+
+// Toggle is an element with an on and off state. Its value in the CustomResponse is obtained using
+// CustomResponse.Toggle.
+type Toggle struct {
+	// Text is the text shown next to the toggle.
+	Text string
+	// Default is the initial state of the toggle.
+	Default bool
+}
+
+// MarshalJSON ...
+func (e Toggle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "toggle", "text": e.Text, "default": e.Default})
+}
+
+func (e Toggle) parse(data json.RawMessage) (any, error) {
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("toggle: %w", err)
+	}
+	return v, nil
+}
+
+// Slider is an element with which the player may select a number in a range. Its value in the CustomResponse
+// is obtained using CustomResponse.Slider.
+type Slider struct {
+	// Text is the text shown above the slider.
+	Text string
+	// Min and Max are the minimum and maximum value of the slider.
+	Min, Max float64
+	// Step is the amount that the value of the slider changes with every step. If zero, a step of 1 is used.
+	Step float64
+	// Default is the initial value of the slider.
+	Default float64
+}
+
+// MarshalJSON ...
+func (e Slider) MarshalJSON() ([]byte, error) {
+	step := e.Step
+	if step == 0 {
+		step = 1
+	}
+	return json.Marshal(map[string]any{"type": "slider", "text": e.Text, "min": e.Min, "max": e.Max, "step": step, "default": e.Default})
+}
+
+func (e Slider) parse(data json.RawMessage) (any, error) {
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("slider: %w", err)
+	}
+	if v < e.Min || v > e.Max {
+		return nil, fmt.Errorf("slider: value %v out of range [%v, %v]", v, e.Min, e.Max)
+	}
+	return v, nil
+}
+
+// Dropdown is an element with which the player may select one of a list of options. Its value in the
+// CustomResponse is obtained using CustomResponse.Dropdown.
+type Dropdown struct {
+	// Text is the text shown above the dropdown.
+	Text string
+	// Options is the list of options that may be selected.
+	Options []string
+	// Default is the index of the option selected initially.
+	Default int
+}
+
+// MarshalJSON ...
+func (e Dropdown) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "dropdown", "text": e.Text, "options": nonNil(e.Options), "default": e.Default})
+}
+
+func (e Dropdown) parse(data json.RawMessage) (any, error) {
+	return parseIndex("dropdown", data, len(e.Options))
+}
+
+// StepSlider is an element that shows a slider with a list of named steps, of which the player may select
+// one. Its value in the CustomResponse is obtained using CustomResponse.StepSlider.
+type StepSlider struct {
+	// Text is the text shown above the step slider.
+	Text string
+	// Steps is the list of steps that may be selected.
+	Steps []string
+	// Default is the index of the step selected initially.
+	Default int
+}
+
+// MarshalJSON ...
+func (e StepSlider) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "step_slider", "text": e.Text, "steps": nonNil(e.Steps), "default": e.Default})
+}
+
+func (e StepSlider) parse(data json.RawMessage) (any, error) {
+	return parseIndex("step slider", data, len(e.Steps))
+}
+
+// parseIndex parses an index into a list of n options from the data passed.
+func parseIndex(name string, data json.RawMessage, n int) (any, error) {
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	if v < 0 || v >= n {
+		return nil, fmt.Errorf("%v: index %v out of range [0, %v)", name, v, n)
+	}
+	return v, nil
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that it is encoded as an empty JSON array.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
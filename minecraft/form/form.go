@@ -0,0 +1,124 @@
+// Package form implements the forms that may be sent to players using the ModalFormRequest packet. Three types
+// of forms exist: Modal forms, which have two buttons, Menu forms, which have a list of buttons, and Custom
+// forms, which have a list of elements such as inputs, toggles and sliders that the player may fill out.
+//
+// Forms may be sent to a connection using a Manager, which assigns form IDs and calls the handler passed when
+// the player submits or closes the form.
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Form is a form that may be sent to a player. Form is implemented by Modal, Menu and Custom. The JSON
+// returned by MarshalJSON is sent as the FormData of a ModalFormRequest packet.
+type Form interface {
+	json.Marshaler
+}
+
+// Modal is a form with a title, a body of text and exactly two buttons. The response to a Modal form is a
+// bool that is true if the first button was pressed and false if the second button was pressed.
+type Modal struct {
+	// Title is the title shown at the top of the form.
+	Title string
+	// Content is the body of text shown in the form.
+	Content string
+	// Button1 and Button2 are the texts of the two buttons of the form. If left empty, 'gui.yes' and
+	// 'gui.no' are shown.
+	Button1, Button2 string
+}
+
+// MarshalJSON ...
+func (f Modal) MarshalJSON() ([]byte, error) {
+	b1, b2 := f.Button1, f.Button2
+	if b1 == "" {
+		b1 = "gui.yes"
+	}
+	if b2 == "" {
+		b2 = "gui.no"
+	}
+	return json.Marshal(map[string]any{
+		"type":    "modal",
+		"title":   f.Title,
+		"content": f.Content,
+		"button1": b1,
+		"button2": b2,
+	})
+}
+
+// ParseResponse parses the response data of a Modal form. It returns true if the first button was pressed
+// and false if the second button was pressed.
+func (f Modal) ParseResponse(data []byte) (bool, error) {
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return false, fmt.Errorf("parse modal response: %w", err)
+	}
+	return v, nil
+}
+
+// Menu is a form with a title, a body of text and a list of buttons that the player may choose from. The
+// response to a Menu form is the index of the button pressed.
+type Menu struct {
+	// Title is the title shown at the top of the form.
+	Title string
+	// Content is the body of text shown above the buttons of the form.
+	Content string
+	// Buttons is a list of buttons shown in the form.
+	Buttons []Button
+}
+
+// Button is a button of a Menu form. It holds a text and, optionally, an image shown next to the text.
+type Button struct {
+	// Text is the text shown on the button.
+	Text string
+	// Image is the path or URL of the image shown on the button. Paths point to textures in the resource
+	// packs applied, such as 'textures/items/apple'. Image may be left empty to show no image.
+	Image string
+}
+
+// MarshalJSON ...
+func (b Button) MarshalJSON() ([]byte, error) {
+	m := map[string]any{"text": b.Text}
+	if b.Image != "" {
+		typ := "path"
+		if isURL(b.Image) {
+			typ = "url"
+		}
+		m["image"] = map[string]any{"type": typ, "data": b.Image}
+	}
+	return json.Marshal(m)
+}
+
+// MarshalJSON ...
+func (f Menu) MarshalJSON() ([]byte, error) {
+	buttons := f.Buttons
+	if buttons == nil {
+		buttons = []Button{}
+	}
+	return json.Marshal(map[string]any{
+		"type":    "form",
+		"title":   f.Title,
+		"content": f.Content,
+		"buttons": buttons,
+	})
+}
+
+// ParseResponse parses the response data of a Menu form. It returns the index of the button that was
+// pressed. An error is returned if the index is not the index of one of the buttons of the Menu.
+func (f Menu) ParseResponse(data []byte) (int, error) {
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, fmt.Errorf("parse menu response: %w", err)
+	}
+	if v < 0 || v >= len(f.Buttons) {
+		return 0, fmt.Errorf("parse menu response: button index %v out of range [0, %v)", v, len(f.Buttons))
+	}
+	return v, nil
+}
+
+// isURL checks if the image passed is a URL rather than a path.
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
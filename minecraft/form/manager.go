@@ -0,0 +1,104 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"sync"
+)
+
+// Response is the response of a player to a form sent using a Manager.
+type Response struct {
+	// Form is the form that was responded to.
+	Form Form
+	// Closed is true if the player closed the form without submitting it. If true, Data is nil.
+	Closed bool
+	// CancelReason is the reason that the form was closed. It is one of the packet.ModalFormCancelReason
+	// constants and is only set if Closed is true.
+	CancelReason uint8
+	// Data is the raw JSON response of the player. It may be parsed using the ParseResponse method of the
+	// Form, for example Custom.ParseResponse.
+	Data []byte
+}
+
+// Manager sends forms to a single connection and matches the responses of the player to the forms sent. It
+// assigns a unique ID to every form sent. A Manager is safe for concurrent use.
+type Manager struct {
+	conn *minecraft.Conn
+
+	mu      sync.Mutex
+	nextID  uint32
+	pending map[uint32]pendingForm
+}
+
+// pendingForm is a form sent by a Manager that has not yet been responded to.
+type pendingForm struct {
+	f Form
+	h func(Response)
+}
+
+// NewManager returns a Manager that sends forms to the connection passed. Packets read from the connection
+// must be passed to Manager.HandlePacket for the Manager to receive responses.
+func NewManager(conn *minecraft.Conn) *Manager {
+	return &Manager{conn: conn, pending: make(map[uint32]pendingForm)}
+}
+
+// Send sends the form passed to the connection of the Manager. The handler passed is called once the player
+// submits or closes the form. It is called from the goroutine that calls Manager.HandlePacket.
+func (m *Manager) Send(f Form, h func(Response)) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encode form: %w", err)
+	}
+	m.mu.Lock()
+	id := m.nextID
+	m.nextID++
+	m.pending[id] = pendingForm{f: f, h: h}
+	m.mu.Unlock()
+
+	if err := m.conn.WritePacket(&packet.ModalFormRequest{FormID: id, FormData: data}); err != nil {
+		m.mu.Lock()
+		delete(m.pending, id)
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// HandlePacket handles a packet read from the connection of the Manager. If the packet is a response to a
+// form sent by the Manager, the handler of that form is called and true is returned. An error is returned if
+// the response is for a form that was not sent by the Manager.
+func (m *Manager) HandlePacket(pk packet.Packet) (bool, error) {
+	resp, ok := pk.(*packet.ModalFormResponse)
+	if !ok {
+		return false, nil
+	}
+	m.mu.Lock()
+	p, ok := m.pending[resp.FormID]
+	delete(m.pending, resp.FormID)
+	m.mu.Unlock()
+	if !ok {
+		return true, fmt.Errorf("handle form response: no form with ID %v pending", resp.FormID)
+	}
+
+	r := Response{Form: p.f}
+	data, ok := resp.ResponseData.Value()
+	if !ok || string(data) == "null" || string(data) == "null\n" {
+		r.Closed = true
+		r.CancelReason, _ = resp.CancelReason.Value()
+	} else {
+		r.Data = data
+	}
+	if p.h != nil {
+		p.h(r)
+	}
+	return true, nil
+}
+
+// Pending returns the amount of forms that were sent by the Manager and not yet responded to.
+func (m *Manager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
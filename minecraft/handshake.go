@@ -0,0 +1,32 @@
+package minecraft
+
+import "net"
+
+// HandshakeInfo holds the parameters of the handshake that enables encryption on a connection. It is passed
+// to the HandshakeFunc of a Dialer or ListenConfig, so that packet capture analysis tools can decrypt the
+// traffic of their own sessions.
+type HandshakeInfo struct {
+	// LocalAddr and RemoteAddr are the addresses of the two ends of the connection.
+	LocalAddr, RemoteAddr net.Addr
+	// JWT is the signed token sent in the ServerToClientHandshake packet, which holds the salt.
+	JWT []byte
+	// Salt is the salt that the encryption key was derived with.
+	Salt []byte
+	// Key is the 32 byte key derived from the shared secret and the salt, which the connection is encrypted
+	// with. Anyone holding it can decrypt all traffic of the connection.
+	Key []byte
+}
+
+// handshake calls the handshakeFunc of the connection, if set, with the parameters passed.
+func (conn *Conn) handshake(jwt, salt []byte, key [32]byte) {
+	if conn.handshakeFunc == nil {
+		return
+	}
+	conn.handshakeFunc(HandshakeInfo{
+		LocalAddr:  conn.LocalAddr(),
+		RemoteAddr: conn.RemoteAddr(),
+		JWT:        append([]byte(nil), jwt...),
+		Salt:       append([]byte(nil), salt...),
+		Key:        key[:],
+	})
+}
@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestXXHash64 checks if XXHash64 produces the hashes of the reference implementation of xxHash64.
+func TestXXHash64(t *testing.T) {
+	tests := []struct {
+		data string
+		hash uint64
+	}{
+		{data: "", hash: 0xef46db3751d8e999},
+		{data: "a", hash: 0xd24ec4f1a98c6e5b},
+		{data: "abc", hash: 0x44bc2cf5ad770999},
+		{data: strings.Repeat("gophertunnel", 10), hash: 0x9ba8c5195d5972cd},
+	}
+	for _, test := range tests {
+		if h := XXHash64([]byte(test.data)); h != test.hash {
+			t.Errorf("XXHash64(%q): expected %x, got %x", test.data, test.hash, h)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package minecraft
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+const (
+	// phaseLogin is the phase of a Conn from the moment it connects until it has logged in.
+	phaseLogin = "login"
+	// phasePlay is the phase of a Conn after it has logged in.
+	phasePlay = "play"
+)
+
+// setPhase sets the pprof labels of the Conn to its remote address and the phase passed, and applies them
+// to the calling goroutine, which must be the goroutine reading packets from the Conn. CPU profiles then
+// attribute the cost of processing packets of the Conn to its address and phase, which may be filtered on
+// using the -tagfocus flag of go tool pprof.
+func (conn *Conn) setPhase(phase string) {
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("raddr", conn.RemoteAddr().String(), "phase", phase))
+	conn.labels.Store(&ctx)
+	pprof.SetGoroutineLabels(ctx)
+}
+
+// applyLabels applies the pprof labels of the Conn to the calling goroutine, which is typically a worker
+// shared by many connections, such as those of the decodePool or the flushScheduler. The function returned
+// removes the labels from the goroutine again.
+func (conn *Conn) applyLabels() (reset func()) {
+	ctx := conn.labels.Load()
+	if ctx == nil {
+		return func() {}
+	}
+	pprof.SetGoroutineLabels(*ctx)
+	return resetLabels
+}
+
+// resetLabels removes all pprof labels from the calling goroutine.
+func resetLabels() {
+	pprof.SetGoroutineLabels(context.Background())
+}
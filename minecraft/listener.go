@@ -2,11 +2,11 @@ package minecraft
 
 import (
 	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
 	"errors"
 	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/clock"
 	"github.com/sandertv/gophertunnel/minecraft/internal"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
 	"log/slog"
 	"net"
 	"slices"
@@ -16,8 +16,11 @@ import (
 
 	"github.com/sandertv/go-raknet"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"github.com/sandertv/gophertunnel/minecraft/world/biomes"
+	"github.com/sandertv/gophertunnel/minecraft/world/entities"
 )
 
 // ListenConfig holds settings that may be edited to change behaviour of a Listener.
@@ -30,6 +33,49 @@ type ListenConfig struct {
 	// verification will be done to ensure that the player connecting is authenticated using their XBOX Live
 	// account.
 	AuthenticationDisabled bool
+	// TrustedRootKeys is the set of public keys trusted to sign the login chains of players authenticated
+	// with XBOX Live. Multiple keys may be set to support rotation of the root key. If empty, only
+	// login.MojangPublicKey is trusted.
+	TrustedRootKeys []*ecdsa.PublicKey
+	// MaximumChainAge is the maximum time that may have passed since the login chain of a player was
+	// issued. Players joining with an older chain are disconnected during login. If zero, the age of login
+	// chains is not limited.
+	MaximumChainAge time.Duration
+	// ChainIssuer is the issuer that login chains signed by one of the TrustedRootKeys must carry. Together
+	// with TrustedRootKeys, it may be set to accept chains issued by a custom authentication service using
+	// login.IssueChain instead of chains signed by Mojang, so that closed ecosystems can still verify the
+	// identity of players. If empty, 'Mojang' is used.
+	ChainIssuer string
+	// Authenticator verifies the login chains of players that join and decodes their identity. It may be set
+	// to plug in custom trust roots, allow-lists or third-party authentication services. If nil, a
+	// login.Verifier with TrustedRootKeys and MaximumChainAge is used. Players that are not
+	// XBOXLiveAuthenticated according to the Authenticator are still disconnected unless
+	// AuthenticationDisabled is true.
+	Authenticator login.Authenticator
+	// ForwarderKeys is the set of public keys of proxies trusted to forward the login requests of players
+	// using minecraft.Dialer.ForwardedLogin. If non-empty and Authenticator is nil, the Listener only accepts
+	// login requests forwarded by these proxies, verifying them using a login.ForwardVerifier, so that the
+	// XBOX Live authentication of players can still be checked behind a proxy.
+	ForwarderKeys []*ecdsa.PublicKey
+	// ReplayCache records the login requests of players that join, so that a login request captured from one
+	// connection is rejected if it is replayed on another within the validity of its chain. Requests are
+	// recorded for at most MaximumChainAge, or login.DefaultReplayWindow if MaximumChainAge is zero, and only
+	// if the player was authenticated with XBOX Live. It may be implemented using a shared store to reject
+	// replays across a cluster of servers. If nil, a login.MemoryReplayCache shared by all connections of the
+	// Listener is used.
+	ReplayCache login.ReplayCache
+	// LoginPolicy holds checks applied to every player after its login request is verified, such as a
+	// minimum freshness of the login chain and a list of banned devices. Players that do not pass the checks
+	// are disconnected during login. By default, all players are allowed.
+	LoginPolicy login.Policy
+	// Signer holds the private key that the Listener signs the handshake of connections with and derives
+	// their encryption keys from. It may be set to keep the key in an external key management service or
+	// hardware security module. If nil, a random PrivateKey is generated.
+	Signer Signer
+	// ClientDataLimits limits the size of the skins of players that join. Players whose client data exceeds
+	// these limits are disconnected during login. Fields left zero are set to their default, as documented
+	// in login.ClientDataLimits.
+	ClientDataLimits login.ClientDataLimits
 
 	// MaximumPlayers is the maximum amount of players accepted in the server. If non-zero, players that
 	// attempt to join while the server is full will be kicked during login. If zero, the maximum player count
@@ -53,8 +99,14 @@ type ListenConfig struct {
 
 	// AcceptedProtocols is a slice of Protocol accepted by a Listener created with this ListenConfig. The current
 	// Protocol is always added to this slice. Clients with a protocol version that is not present in this slice will
-	// be disconnected.
+	// be disconnected, unless ProtocolMismatchFunc decides otherwise.
 	AcceptedProtocols []Protocol
+	// ProtocolMismatchFunc is called when a client connects with a protocol version that is not present in
+	// AcceptedProtocols. The ProtocolMismatchResult returned decides if the client is accepted through a
+	// translating Protocol, disconnected with a custom message or transferred to another server. If nil, or if
+	// a zero ProtocolMismatchResult is returned, the client is sent a PlayStatus packet with a login failed
+	// status.
+	ProtocolMismatchFunc func(clientProtocol int32, addr net.Addr) ProtocolMismatchResult
 	// Compression is the packet.Compression to use for packets sent over this Conn. If set to nil, the compression
 	// will default to packet.flateCompression.
 	Compression packet.Compression // TODO: Change this to snappy once Windows crashes are resolved.
@@ -65,27 +117,178 @@ type ListenConfig struct {
 	// will not be flushed automatically. In this case, calling `(*Conn).Flush()` is required after any
 	// calls to `(*Conn).Write()` or `(*Conn).WritePacket()` to send the packets over network.
 	FlushRate time.Duration
+	// FlushThreshold is the amount of bytes of packets written to a connection after which it is flushed
+	// immediately, instead of at the next FlushRate tick. Setting it prevents bulk data, such as chunks,
+	// from piling up between ticks and being sent in one large burst. The goroutine writing the packet that
+	// exceeds the threshold performs the flush. If zero (by default), connections are only flushed at the
+	// FlushRate.
+	FlushThreshold int
+	// Clock is the clock.Clock used for the timers of the Listener and its connections, such as those for
+	// flushing, read deadlines and the LoginTimeout and LoginStepTimeout. If nil, clock.System is used. A
+	// clock.Fake may be set in tests to advance time without sleeping.
+	Clock clock.Clock
 	// ReadBatches determines whether packets should be retrieved in conn's batches. When enabled, the conn.ReadBatch()
 	// function should be used as opposed to conn.ReadPacket()
 	ReadBatches bool
+	// DecodeWorkers is the amount of goroutines shared by all connections of the Listener that decrypt,
+	// decompress and handle the batches received once a connection is logged in. If non-zero, the goroutine
+	// of a logged in connection only reads raw batches and queues them for these workers, so that many
+	// mostly idle connections do not each hold on to the buffers and stack needed to decode batches. Batches
+	// of a single connection are always handled in the order they were received. Connections are read by a
+	// goroutine of their own regardless, because reads from the underlying net.Conn block. DecodeWorkers has
+	// no effect if ReadBatches is true. If zero (by default), each connection decodes its own batches.
+	DecodeWorkers int
+	// CompressWorkers is the amount of goroutines shared by all connections of the Listener that compress the
+	// batches sent. If non-zero, flushing a connection only hands its batch off to these workers, so that
+	// the batches of a connection sending many large packets, such as chunks, are compressed in parallel.
+	// Batches of a single connection are always written in the order they were flushed. If zero (by default),
+	// each connection compresses its own batches when flushing.
+	CompressWorkers int
+	// MemoryBudget is the maximum amount of memory in bytes that a single connection may hold in packets
+	// received but not yet read, and packets written but not yet flushed. A connection that receives packets
+	// faster than they are read, causing it to exceed the budget, is closed. Writing packets that cause the
+	// budget to be exceeded flushes the connection immediately, blocking the writer until the packets are
+	// sent. If zero (by default), the memory held by connections is not limited.
+	MemoryBudget int
+	// LoginWorkers is the amount of goroutines shared by all connections of the Listener that verify login
+	// requests and set up encryption, which involves expensive ECDSA operations. If non-zero, at most
+	// LoginWorkers logins are verified at the same time, so that a flood of logins cannot starve connections
+	// that are already playing of CPU time. If zero (by default), each connection verifies its own login.
+	LoginWorkers int
+	// LoginTimeout is the maximum duration of the login sequence of a connection, from the moment it connects
+	// until it has logged in, handshake and resource pack downloads included. Connections that take longer are
+	// disconnected, so that clients that stall during the login sequence cannot hold on to resources of the
+	// Listener indefinitely. If zero, a timeout of 2 minutes is used. If negative, the login sequence has no
+	// overall timeout.
+	LoginTimeout time.Duration
+	// LoginStepTimeout is the maximum time that a connection may go without sending any packets during its
+	// login sequence, such as when the server is waiting for the client to answer the handshake or to request
+	// the next resource pack chunk. If zero, a timeout of 30 seconds is used. If negative, steps of the login
+	// sequence have no timeout.
+	LoginStepTimeout time.Duration
 
 	// ResourcePacks is a slice of resource packs that the listener may hold. Each client will be asked to
 	// download these resource packs upon joining.
 	// Use Listener.AddResourcePack() to add a resource pack and Listener.RemoveResourcePack() to remove a resource pack
 	// after having called ListenConfig.Listen(). Note that these methods will not update resource packs for active connections.
 	ResourcePacks []*resource.Pack
+	// PreviousResourcePacks holds earlier versions of the ResourcePacks that clients may still have cached.
+	// Clients of gophertunnel with a ResourcePackCacheDir that have one of these versions cached are sent only
+	// the changes to the current version of the pack, computed using resource.Diff, rather than the full
+	// pack. Vanilla clients, clients without such a version cached and connections with encryption disabled
+	// download the full pack as usual.
+	PreviousResourcePacks []*resource.Pack
 	// Biomes contains information about all biomes that the server has registered, which the client can use
-	// to render the world more effectively. If these are nil, the default biome definitions will be used.
+	// to render the world more effectively. If these are nil, the BiomeDefinitions are used. biomes.Encode
+	// may be used to create this map from a list of definitions.
 	Biomes map[string]any
+	// BiomeDefinitions is the registry of biome definitions sent to clients if Biomes is nil. Biomes may be
+	// registered to it at any time, and are sent to connections that spawn afterwards. If nil, a
+	// biomes.Registry holding only the vanilla biomes is used.
+	BiomeDefinitions *biomes.Registry
+	// EntityIdentifiers is the registry of entity identifiers sent to clients in the
+	// AvailableActorIdentifiers packet. Identifiers may be registered to it at any time, and are sent to
+	// connections that spawn afterwards. If nil or empty, the packet is not sent.
+	EntityIdentifiers *entities.Registry
 	// TexturePacksRequired specifies if clients that join must accept the texture pack in order for them to
 	// be able to join the server. If they don't accept, they can only leave the server.
 	TexturePacksRequired bool
+	// OrderResourcePacks is called with the resource packs of the Listener to decide the order in which they
+	// are sent in the resource pack stack, where packs earlier in the stack take priority over packs later in
+	// the stack. Listen returns an error if the ResourcePacks cannot be ordered. If nil, the packs are ordered
+	// using resource.OrderByDependencies, so that every pack comes before the packs that it depends on.
+	OrderResourcePacks func(packs []*resource.Pack) ([]*resource.Pack, error)
+	// MaximumChunkRadius is the maximum chunk radius approved for clients. Clients requesting a larger radius
+	// are sent this radius instead. If non-zero, RequestChunkRadius packets sent by clients after spawning
+	// are answered by the Listener too, and are no longer returned by Conn.ReadPacket. If zero, the radius is
+	// not limited and only the RequestChunkRadius packet sent while spawning is answered.
+	MaximumChunkRadius int
+	// ChunkRadiusFunc is called when a client changes its chunk radius after spawning, with the radius that
+	// was approved for it. It is only called if MaximumChunkRadius is non-zero.
+	ChunkRadiusFunc func(conn *Conn, radius int)
 
 	// PacketFunc is called whenever a packet is read from or written to a connection returned when using
 	// Listener.Accept. It includes packets that are otherwise covered in the connection sequence, such as the
 	// Login packet. The function is called with the header of the packet and its raw payload, the address
 	// from which the packet originated, and the destination address.
 	PacketFunc func(header packet.Header, payload []byte, src, dst net.Addr)
+
+	// DuplicateLogin specifies what happens when a player logs in with an XUID of a player that is already
+	// connected to the Listener. By default, DuplicateLoginAllow is used, meaning both connections are
+	// accepted. The policy is applied atomically before the new connection is returned by Accept, so that no
+	// additional locking is needed by the user.
+	DuplicateLogin DuplicateLoginPolicy
+	// DuplicateLoginMessage is the disconnect message sent to the connection that is closed as a result of
+	// the DuplicateLogin policy. If left empty, a default message is used.
+	DuplicateLoginMessage string
+
+	// EncryptionDisabledFunc is called for every connection that logs in, with the address it originates
+	// from. If it returns true, the ServerToClientHandshake is skipped and packets sent over the connection
+	// are not encrypted. This saves CPU on trusted internal networks, for example between a proxy and its
+	// backend servers, where traffic is already encrypted between the client and the proxy. It must never
+	// return true for connections from untrusted networks. If nil, all connections are encrypted.
+	EncryptionDisabledFunc func(addr net.Addr) bool
+	// HandshakeFunc, if non-nil, is called with the handshake JWT, the salt and the encryption key of every
+	// connection once encryption is enabled. It exists so that packet capture analysis tools can decrypt
+	// sessions for debugging. The key grants access to all traffic of the connection, so HandshakeFunc
+	// should never be set in production.
+	HandshakeFunc func(info HandshakeInfo)
+
+	// ReputationFunc is called for every new connection, before any of its packets are processed, to decide
+	// how the connection is treated based on the address it originates from. Connections with
+	// ReputationReject are closed immediately, while connections with ReputationTarpit have every response
+	// during the login sequence delayed by TarpitDelay. If nil, all connections are allowed.
+	// ReputationFunc is called from the goroutine that accepts connections, so it should return quickly.
+	ReputationFunc func(addr net.Addr) Reputation
+	// TarpitDelay is the delay applied to every batch of packets handled during the login sequence of a
+	// connection marked with ReputationTarpit. If zero, a delay of 3 seconds is used.
+	TarpitDelay time.Duration
+}
+
+// Reputation specifies how a Listener treats a connection based on the address it originates from. It is
+// returned by ListenConfig.ReputationFunc.
+type Reputation int
+
+const (
+	// ReputationAllow handles the connection as normal.
+	ReputationAllow Reputation = iota
+	// ReputationTarpit accepts the connection, but slows down its login sequence by delaying every response
+	// by ListenConfig.TarpitDelay. Compared to rejecting a connection outright, this wastes the time of
+	// sources flooding the Listener with junk logins while still allowing legitimate players to join.
+	ReputationTarpit
+	// ReputationReject closes the connection immediately.
+	ReputationReject
+)
+
+// DuplicateLoginPolicy specifies how a Listener handles a player logging in with an XUID that is already
+// connected.
+type DuplicateLoginPolicy int
+
+const (
+	// DuplicateLoginAllow accepts the new connection without closing the connection that was already logged
+	// in with the same XUID.
+	DuplicateLoginAllow DuplicateLoginPolicy = iota
+	// DuplicateLoginRejectNew disconnects the new connection, keeping the connection that was already logged
+	// in with the same XUID.
+	DuplicateLoginRejectNew
+	// DuplicateLoginKickOld disconnects the connection that was already logged in with the same XUID and
+	// accepts the new connection.
+	DuplicateLoginKickOld
+)
+
+// ProtocolMismatchResult is returned by ListenConfig.ProtocolMismatchFunc to decide what happens with a client
+// that connected with a protocol version that the Listener does not accept. Only one of the fields should be
+// set. If multiple are set, Protocol takes precedence over TransferAddress, which takes precedence over Message.
+type ProtocolMismatchResult struct {
+	// Protocol, if non-nil, is the Protocol used to communicate with the client. It should translate the
+	// packets of the client to those of the latest protocol, so that the client can still join.
+	Protocol Protocol
+	// TransferAddress and TransferPort, if the address is not empty, specify a server that the client is
+	// transferred to, for example one that supports the protocol version of the client.
+	TransferAddress string
+	TransferPort    uint16
+	// Message, if not empty, is the message that the client is disconnected with.
+	Message string
 }
 
 // Listener implements a Minecraft listener on top of an unspecific net.Listener. It abstracts away the
@@ -105,7 +308,26 @@ type Listener struct {
 	incoming chan *Conn
 	close    chan struct{}
 
-	key *ecdsa.PrivateKey
+	// online holds all connections that were accepted by the Listener, indexed by their XUID. It is only
+	// used if the DuplicateLogin policy is not DuplicateLoginAllow.
+	online   map[string]*Conn
+	onlineMu sync.Mutex
+
+	// decoders is the pool that batches of logged in connections are decoded on. It is nil if DecodeWorkers
+	// is 0 or ReadBatches is true.
+	decoders *decodePool
+	// compressors is the pool that batches sent by connections are compressed on. It is nil if
+	// CompressWorkers is 0.
+	compressors *compressPool
+	// logins is the pool that login requests of connections are verified on. It is nil if LoginWorkers is 0.
+	logins *loginPool
+
+	key Signer
+	// biomes holds the encoded ListenConfig.Biomes, or nil if the ListenConfig.BiomeDefinitions are sent.
+	biomes []byte
+	// packDeltas holds the PreviousResourcePacks and the deltas computed against them. It is nil if
+	// PreviousResourcePacks is empty.
+	packDeltas *packDeltas
 }
 
 // Listen announces on the local network address. The network is typically "raknet".
@@ -125,6 +347,43 @@ func (cfg ListenConfig) Listen(network string, address string) (*Listener, error
 	if cfg.FlushRate == 0 {
 		cfg.FlushRate = time.Second / 20
 	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.System
+	}
+	if cfg.DuplicateLoginMessage == "" {
+		cfg.DuplicateLoginMessage = "Logged in from another location."
+	}
+	if cfg.TarpitDelay == 0 {
+		cfg.TarpitDelay = time.Second * 3
+	}
+	if cfg.ReplayCache == nil {
+		cfg.ReplayCache = &login.MemoryReplayCache{}
+	}
+	if cfg.LoginTimeout == 0 {
+		cfg.LoginTimeout = time.Minute * 2
+	}
+	if cfg.LoginStepTimeout == 0 {
+		cfg.LoginStepTimeout = time.Second * 30
+	}
+
+	if cfg.OrderResourcePacks == nil {
+		cfg.OrderResourcePacks = resource.OrderByDependencies
+	}
+	if _, err := cfg.OrderResourcePacks(cfg.ResourcePacks); err != nil {
+		return nil, fmt.Errorf("listen: order resource packs: %w", err)
+	}
+
+	// The biome definitions are the same for every connection, so they are only encoded once.
+	var encodedBiomes []byte
+	if cfg.Biomes != nil {
+		b, err := nbt.MarshalEncoding(cfg.Biomes, nbt.NetworkLittleEndian)
+		if err != nil {
+			return nil, fmt.Errorf("listen: encode biomes: %w", err)
+		}
+		encodedBiomes = b
+	} else if cfg.BiomeDefinitions == nil {
+		cfg.BiomeDefinitions = biomes.NewRegistry()
+	}
 
 	n, ok := networkByID(network, cfg.ErrorLog)
 	if !ok {
@@ -135,14 +394,34 @@ func (cfg ListenConfig) Listen(network string, address string) (*Listener, error
 	if err != nil {
 		return nil, err
 	}
-	key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	var key Signer = cfg.Signer
+	if key == nil {
+		if key, err = GeneratePrivateKey(); err != nil {
+			_ = netListener.Close()
+			return nil, err
+		}
+	}
 	listener := &Listener{
 		cfg:      cfg,
 		listener: netListener,
 		packs:    slices.Clone(cfg.ResourcePacks),
 		incoming: make(chan *Conn),
 		close:    make(chan struct{}),
+		online:   make(map[string]*Conn),
 		key:      key,
+		biomes:   encodedBiomes,
+	}
+	if len(cfg.PreviousResourcePacks) != 0 {
+		listener.packDeltas = newPackDeltas(slices.Clone(cfg.PreviousResourcePacks))
+	}
+	if cfg.DecodeWorkers > 0 && !cfg.ReadBatches {
+		listener.decoders = newDecodePool(cfg.DecodeWorkers, listener.close)
+	}
+	if cfg.CompressWorkers > 0 {
+		listener.compressors = newCompressPool(cfg.CompressWorkers, listener.close)
+	}
+	if cfg.LoginWorkers > 0 {
+		listener.logins = newLoginPool(cfg.LoginWorkers, listener.close)
 	}
 
 	// Actually start listening.
@@ -216,9 +495,13 @@ func (listener *Listener) Close() error {
 // server name of the listener, provided the listener isn't currently hijacking the pong of another server.
 func (listener *Listener) updatePongData() {
 	s := listener.status()
+	var port int
+	if addr, ok := listener.Addr().(*net.UDPAddr); ok {
+		port = addr.Port
+	}
 	listener.listener.PongData([]byte(fmt.Sprintf("MCPE;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;%v;",
 		s.ServerName, protocol.CurrentProtocol, protocol.CurrentVersion, s.PlayerCount, s.MaxPlayers,
-		listener.listener.ID(), s.ServerSubName, "Creative", 1, listener.Addr().(*net.UDPAddr).Port, listener.Addr().(*net.UDPAddr).Port, 0,
+		listener.listener.ID(), s.ServerSubName, "Creative", 1, port, port, 0,
 	)))
 }
 
@@ -227,11 +510,11 @@ func (listener *Listener) updatePongData() {
 func (listener *Listener) listen(n Network) {
 	listener.updatePongData()
 	go func() {
-		ticker := time.NewTicker(time.Second * 4)
+		ticker := listener.cfg.Clock.NewTicker(time.Second * 4)
 		defer ticker.Stop()
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
 				listener.updatePongData()
 			case <-listener.close:
 				return
@@ -257,27 +540,73 @@ func (listener *Listener) listen(n Network) {
 // createConn creates a connection for the net.Conn passed and adds it to the listener, so that it may be
 // accepted once its login sequence is complete.
 func (listener *Listener) createConn(n Network, netConn net.Conn) {
+	var tarpit time.Duration
+	if listener.cfg.ReputationFunc != nil {
+		switch listener.cfg.ReputationFunc(netConn.RemoteAddr()) {
+		case ReputationReject:
+			_ = netConn.Close()
+			return
+		case ReputationTarpit:
+			tarpit = listener.cfg.TarpitDelay
+		}
+	}
+
 	listener.packsMu.RLock()
 	packs := slices.Clone(listener.packs)
 	listener.packsMu.RUnlock()
 
-	conn := newConn(netConn, listener.key, listener.cfg.ErrorLog, proto{}, listener.cfg.FlushRate, true, listener.cfg.ReadBatches)
+	conn := newConn(netConn, listener.key, listener.cfg.ErrorLog, proto{}, listener.cfg.Clock, listener.cfg.FlushRate, true, listener.cfg.ReadBatches)
 	conn.acceptedProto = append(listener.cfg.AcceptedProtocols, proto{})
+	conn.protocolMismatch = listener.cfg.ProtocolMismatchFunc
 	conn.compression = listener.cfg.Compression
 	conn.pool = conn.proto.Packets(true)
 	// Temporarily set the protocol to the latest: We don't know the actual protocol until we read the Login packet.
 	conn.proto = proto{}
 	conn.pool = conn.proto.Packets(true)
-	conn.packetFunc = listener.cfg.PacketFunc
+	conn.SetPacketFunc(listener.cfg.PacketFunc)
 	conn.texturePacksRequired = listener.cfg.TexturePacksRequired
 	conn.resourcePacks = packs
-	conn.biomes = listener.cfg.Biomes
+	conn.packDeltas = listener.packDeltas
+	conn.orderResourcePacks = listener.cfg.OrderResourcePacks
+	conn.biomes = listener.biomes
+	conn.biomeDefinitions = listener.cfg.BiomeDefinitions
+	conn.entityIdentifiers = listener.cfg.EntityIdentifiers
+	conn.maxChunkRadius = int32(listener.cfg.MaximumChunkRadius)
+	conn.chunkRadiusFunc = listener.cfg.ChunkRadiusFunc
+	conn.memoryBudget = int64(listener.cfg.MemoryBudget)
+	conn.flushThreshold = int64(listener.cfg.FlushThreshold)
+	if listener.compressors != nil {
+		conn.enc.EnableParallelCompression(func(f func()) {
+			listener.compressors.run(func() {
+				defer conn.applyLabels()()
+				f()
+			})
+		})
+	}
 	conn.gameData.WorldName = listener.status().ServerName
 	conn.authEnabled = !listener.cfg.AuthenticationDisabled
+	conn.authenticator = listener.cfg.Authenticator
+	conn.clientDataLimits = listener.cfg.ClientDataLimits
+	conn.loginPolicy = listener.cfg.LoginPolicy
+	conn.replayCache = listener.cfg.ReplayCache
+	conn.replayWindow = listener.cfg.MaximumChainAge
+	conn.handshakeFunc = listener.cfg.HandshakeFunc
+	conn.logins = listener.logins
+	if f := listener.cfg.EncryptionDisabledFunc; f != nil {
+		conn.encryptionDisabled = f(netConn.RemoteAddr())
+	}
+	if conn.authenticator == nil {
+		v := login.Verifier{RootKeys: listener.cfg.TrustedRootKeys, MaxAge: listener.cfg.MaximumChainAge, Issuer: listener.cfg.ChainIssuer}
+		conn.authenticator = v
+		if len(listener.cfg.ForwarderKeys) != 0 {
+			conn.authenticator = login.ForwardVerifier{Verifier: v, ForwarderKeys: listener.cfg.ForwarderKeys}
+		}
+	}
 	conn.disconnectOnUnknownPacket = !listener.cfg.AllowUnknownPackets
 	conn.disconnectOnInvalidPacket = !listener.cfg.AllowInvalidPackets
+	conn.tarpit = tarpit
 
-	if netConn.(*raknet.Conn).ProtocolVersion() <= 10 {
+	if c, ok := netConn.(*raknet.Conn); ok && c.ProtocolVersion() <= 10 {
 		conn.enc.EnableCompression(n.Compression(netConn), true)
 		conn.dec.SetCompression(n.Compression(netConn))
 	}
@@ -308,9 +637,13 @@ func (listener *Listener) status() ServerStatus {
 func (listener *Listener) handleConn(conn *Conn) {
 	defer func() {
 		_ = conn.Close()
+		listener.unregister(conn)
 		listener.playerCount.Add(-1)
 		listener.updatePongData()
 	}()
+	conn.setPhase(phaseLogin)
+	progress, loginDone := make(chan struct{}, 1), make(chan struct{})
+	go listener.watchLogin(conn, progress, loginDone)
 	for {
 		// We finally arrived at the packet decoding loop. We constantly decode packets that arrive
 		// and push them to the Conn so that they may be processed.
@@ -321,6 +654,15 @@ func (listener *Listener) handleConn(conn *Conn) {
 			}
 			return
 		}
+		if !conn.loggedIn {
+			select {
+			case progress <- struct{}{}:
+			default:
+			}
+		}
+		if conn.tarpit > 0 && !conn.loggedIn && !conn.delay(conn.tarpit) {
+			return
+		}
 
 		if conn.readBatches {
 			loggedInBefore := conn.loggedIn
@@ -329,15 +671,10 @@ func (listener *Listener) handleConn(conn *Conn) {
 				return
 			}
 			if !loggedInBefore && conn.loggedIn {
-				select {
-				case <-listener.close:
-					// The listener was closed while this one was logged in, so the incoming channel will be
-					// closed. Just return so the connection is closed and cleaned up.
+				close(loginDone)
+				conn.setPhase(phasePlay)
+				if !listener.handleLoggedIn(conn) {
 					return
-				case listener.incoming <- conn:
-					// The connection was previously not logged in, but was after receiving this packet,
-					// meaning the connection is fully completely now. We add it to the channel so that
-					// a call to Accept() can receive it.
 				}
 			}
 
@@ -351,17 +688,169 @@ func (listener *Listener) handleConn(conn *Conn) {
 				return
 			}
 			if !loggedInBefore && conn.loggedIn {
-				select {
-				case <-listener.close:
-					// The listener was closed while this one was logged in, so the incoming channel will be
-					// closed. Just return so the connection is closed and cleaned up.
+				close(loginDone)
+				conn.setPhase(phasePlay)
+				if !listener.handleLoggedIn(conn) {
 					return
-				case listener.incoming <- conn:
-					// The connection was previously not logged in, but was after receiving this packet,
-					// meaning the connection is fully completely now. We add it to the channel so that
-					// a call to Accept() can receive it.
 				}
 			}
 		}
+		if conn.loggedIn && listener.decoders != nil {
+			// The connection is logged in, so its batches are decoded by the shared decodePool from now on.
+			listener.readQueued(conn)
+			return
+		}
+	}
+}
+
+// readQueued reads the raw batches of a logged in connection and queues them to be decoded and handled by the
+// decodePool of the Listener. It returns once the connection is closed.
+func (listener *Listener) readQueued(conn *Conn) {
+	handle := func(data []byte) {
+		select {
+		case <-conn.close:
+			// An earlier batch closed the connection, so the remaining batches are dropped.
+			return
+		default:
+		}
+		reset := conn.applyLabels()
+		defer reset()
+
+		packets, err := conn.dec.DecodeBatch(data)
+		if err != nil {
+			conn.log.Error(err.Error())
+			_ = conn.Close()
+			return
+		}
+		for _, data := range packets {
+			if err := conn.receive(data); err != nil {
+				conn.log.Error(err.Error())
+				_ = conn.Close()
+				return
+			}
+		}
+	}
+	q := listener.decoders.queue(handle)
+	if q != nil {
+		defer listener.decoders.release()
+	}
+	for {
+		data, err := conn.dec.Read()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				conn.log.Error(err.Error())
+			}
+			return
+		}
+		if q == nil {
+			// The Listener was closed and the workers of the pool stopped before the connection logged in.
+			handle(data)
+			continue
+		}
+		if !q.submit(data, conn.close) {
+			return
+		}
+	}
+}
+
+// watchLogin disconnects the connection passed if its login sequence is not completed within the LoginTimeout
+// of the Listener, or if no packets arrive for LoginStepTimeout during the sequence. A value is sent on the
+// progress channel passed for every batch received during the login sequence, and loginDone is closed once
+// the connection has logged in.
+func (listener *Listener) watchLogin(conn *Conn, progress <-chan struct{}, loginDone <-chan struct{}) {
+	var timeout, stepTimeout <-chan time.Time
+	if listener.cfg.LoginTimeout > 0 {
+		t := listener.cfg.Clock.NewTimer(listener.cfg.LoginTimeout)
+		defer t.Stop()
+		timeout = t.C()
+	}
+	var step clock.Timer
+	if listener.cfg.LoginStepTimeout > 0 {
+		step = listener.cfg.Clock.NewTimer(listener.cfg.LoginStepTimeout)
+		defer step.Stop()
+		stepTimeout = step.C()
+	}
+	for {
+		select {
+		case <-loginDone:
+			return
+		case <-conn.close:
+			return
+		case <-progress:
+			if step != nil {
+				step.Reset(listener.cfg.LoginStepTimeout)
+			}
+		case <-timeout:
+			conn.log.Error("login sequence timed out", "timeout", listener.cfg.LoginTimeout)
+			_ = listener.Disconnect(conn, "Login timed out.")
+			return
+		case <-stepTimeout:
+			conn.log.Error("login sequence stalled", "timeout", listener.cfg.LoginStepTimeout)
+			_ = listener.Disconnect(conn, "Login timed out.")
+			return
+		}
+	}
+}
+
+// handleLoggedIn handles a connection that has just completed its login sequence. The DuplicateLogin policy
+// of the Listener is applied, after which the connection is added to the incoming channel so that a call to
+// Accept() can receive it. handleLoggedIn returns false if the connection should be closed.
+func (listener *Listener) handleLoggedIn(conn *Conn) bool {
+	if !listener.register(conn) {
+		return false
+	}
+	select {
+	case <-listener.close:
+		// The listener was closed while this one was logged in, so the incoming channel will be closed.
+		return false
+	case listener.incoming <- conn:
+		// The connection was previously not logged in, but was after receiving this packet, meaning the
+		// connection is fully completely now.
+		return true
+	}
+}
+
+// register registers the connection passed by its XUID and applies the DuplicateLogin policy of the Listener
+// if a connection with the same XUID was already registered. False is returned if the connection passed was
+// rejected as a result.
+func (listener *Listener) register(conn *Conn) bool {
+	xuid := conn.IdentityData().XUID
+	if listener.cfg.DuplicateLogin == DuplicateLoginAllow || xuid == "" {
+		return true
+	}
+	// The policy is applied under the lock, but the connection kicked as a result is only disconnected after
+	// releasing it, so that a slow connection does not hold up the registration of others.
+	var kick *Conn
+	listener.onlineMu.Lock()
+	existing, ok := listener.online[xuid]
+	switch {
+	case ok && listener.cfg.DuplicateLogin == DuplicateLoginRejectNew:
+		kick = conn
+	case ok && listener.cfg.DuplicateLogin == DuplicateLoginKickOld:
+		kick = existing
+		listener.online[xuid] = conn
+	default:
+		listener.online[xuid] = conn
+	}
+	listener.onlineMu.Unlock()
+
+	if kick != nil {
+		_ = listener.Disconnect(kick, listener.cfg.DuplicateLoginMessage)
+	}
+	return kick != conn
+}
+
+// unregister removes the connection passed from the connections registered by their XUID, provided it was
+// not already replaced by a newer connection with the same XUID.
+func (listener *Listener) unregister(conn *Conn) {
+	xuid := conn.IdentityData().XUID
+	if xuid == "" {
+		return
+	}
+	listener.onlineMu.Lock()
+	defer listener.onlineMu.Unlock()
+
+	if listener.online[xuid] == conn {
+		delete(listener.online, xuid)
 	}
 }
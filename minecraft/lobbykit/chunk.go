@@ -0,0 +1,73 @@
+package lobbykit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/sandertv/gophertunnel/minecraft/world/blocks"
+)
+
+const (
+	// subChunkCount is the amount of sub chunks present in a chunk of the overworld, which ranges from y=-64
+	// to y=320.
+	subChunkCount = 24
+	// minSubChunk is the index of the lowest sub chunk in the overworld.
+	minSubChunk = -4
+	// plainsBiome is the ID of the plains biome, which is used for every block in the lobby.
+	plainsBiome = 1
+)
+
+// emptyChunk returns the payload of a chunk that does not contain any blocks. Such chunks are sent around
+// the platform so that the client does not wait for terrain that is never going to arrive.
+func emptyChunk() []byte {
+	buf := bytes.NewBuffer(nil)
+	writeBiomes(buf)
+	return buf.Bytes()
+}
+
+// platformChunk returns the payload of a chunk that has a single layer of the block passed at the lowest y
+// value of the overworld. The payload holds exactly one sub chunk.
+func platformChunk(block string) []byte {
+	buf := bytes.NewBuffer(nil)
+	// Sub chunk version 9, with one storage layer, followed by the Y index of the sub chunk.
+	y := int8(minSubChunk)
+	buf.Write([]byte{9, 1, byte(y)})
+
+	// The palette consists of air (0) and the block (1), so a single bit per block suffices: 32 blocks are
+	// stored in every word, resulting in 4096/32 words. The blocks are indexed as x<<8 | z<<4 | y, so the
+	// blocks at y=0 are found at every 16th index.
+	const bitsPerBlock = 1
+	buf.WriteByte(bitsPerBlock<<1 | 1)
+	words := make([]uint32, 4096/32)
+	for i := 0; i < 4096; i += 16 {
+		words[i/32] |= 1 << (i % 32)
+	}
+	_ = binary.Write(buf, binary.LittleEndian, words)
+
+	// The lobby sets GameData.UseBlockNetworkIDHashes, so that blocks are identified by their hash and the
+	// block palette of the protocol version of the client is not needed.
+	writeVarint32(buf, 2)
+	writeVarint32(buf, int32(blocks.Hash("minecraft:air", nil)))
+	writeVarint32(buf, int32(blocks.Hash(block, nil)))
+
+	writeBiomes(buf)
+	return buf.Bytes()
+}
+
+// writeBiomes writes the biomes of every sub chunk in a chunk to buf, followed by an empty list of border
+// blocks. Every sub chunk consists of only plains.
+func writeBiomes(buf *bytes.Buffer) {
+	for i := 0; i < subChunkCount; i++ {
+		// A palette with 0 bits per block holds a single value and no words.
+		buf.WriteByte(0<<1 | 1)
+		writeVarint32(buf, plainsBiome)
+	}
+	// Border block count.
+	buf.WriteByte(0)
+}
+
+// writeVarint32 writes a zigzag encoded varint32 to buf.
+func writeVarint32(buf *bytes.Buffer, x int32) {
+	var b [binary.MaxVarintLen32]byte
+	n := binary.PutVarint(b[:], int64(x))
+	buf.Write(b[:n])
+}
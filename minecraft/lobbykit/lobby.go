@@ -0,0 +1,276 @@
+// Package lobbykit implements a minimal Minecraft server built purely on top of the public API of the
+// minecraft package. Players joining a Lobby spawn on a flat platform in a void world, may chat with each
+// other and may use the /transfer command to move on to one of the servers configured.
+// The package serves both as documentation of what it takes to get a client to spawn in a world, and as a
+// drop-in waiting room for proxy networks.
+package lobbykit
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Config holds the settings of a Lobby. A Lobby may be created by calling Config.Listen.
+type Config struct {
+	// ListenConfig is the configuration used to listen for incoming connections.
+	ListenConfig minecraft.ListenConfig
+	// WorldName is the name of the world shown in the pause menu. If left empty, the name of the server as
+	// found in the status of the ListenConfig is used.
+	WorldName string
+	// PlatformBlock is the name of the block that the platform players spawn on is made of. The block must
+	// not have any block states. If left empty, minecraft:grass_block is used.
+	PlatformBlock string
+	// Servers maps the names of servers to the addresses, formatted as 'host:port', that players may be
+	// transferred to using the /transfer command.
+	Servers map[string]string
+	// Log is the logger that errors from connections are logged to. If left nil, slog.Default() is used.
+	Log *slog.Logger
+}
+
+// Lobby is a minimal server that keeps players in a void world until they are transferred elsewhere.
+type Lobby struct {
+	conf     Config
+	listener *minecraft.Listener
+
+	platform []byte
+	empty    []byte
+
+	mu      sync.Mutex
+	players map[*minecraft.Conn]struct{}
+}
+
+const (
+	// platformChunkRadius is the radius in chunks around the platform in which empty chunks are sent.
+	platformChunkRadius = 2
+	// platformY is the y coordinate of the platform that players spawn on.
+	platformY = minSubChunk * 16
+)
+
+// spawnPosition is the position that players spawn at: The centre of the platform in chunk 0,0.
+var spawnPosition = mgl32.Vec3{8.5, platformY + 1 + 1.62, 8.5}
+
+// Listen creates a Lobby that listens on the network and address passed. Listen returns an error if the
+// underlying minecraft.Listener could not be created. Lobby.Serve must be called to start accepting
+// players.
+func (conf Config) Listen(network, address string) (*Lobby, error) {
+	if conf.PlatformBlock == "" {
+		conf.PlatformBlock = "minecraft:grass_block"
+	}
+	if conf.Log == nil {
+		conf.Log = slog.Default()
+	}
+	if conf.Servers == nil {
+		conf.Servers = map[string]string{}
+	}
+	for name, addr := range conf.Servers {
+		if _, _, err := splitAddress(addr); err != nil {
+			return nil, fmt.Errorf("lobbykit: invalid address for server %v: %w", name, err)
+		}
+	}
+	l, err := conf.ListenConfig.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Lobby{
+		conf:     conf,
+		listener: l,
+		platform: platformChunk(conf.PlatformBlock),
+		empty:    emptyChunk(),
+		players:  map[*minecraft.Conn]struct{}{},
+	}, nil
+}
+
+// Listener returns the minecraft.Listener that the Lobby accepts connections from.
+func (lobby *Lobby) Listener() *minecraft.Listener {
+	return lobby.listener
+}
+
+// Serve accepts connections from the Listener of the Lobby and handles them until the Lobby is closed.
+// Serve always returns a non-nil error: If the Lobby was closed, net.ErrClosed is returned.
+func (lobby *Lobby) Serve() error {
+	for {
+		c, err := lobby.listener.Accept()
+		if err != nil {
+			return err
+		}
+		go lobby.handleConn(c.(*minecraft.Conn))
+	}
+}
+
+// Close closes the Lobby, disconnecting all players connected to it.
+func (lobby *Lobby) Close() error {
+	return lobby.listener.Close()
+}
+
+// Transfer transfers the connection passed to the server with the name passed, as configured in
+// Config.Servers. An error is returned if no server with that name exists.
+func (lobby *Lobby) Transfer(conn *minecraft.Conn, server string) error {
+	addr, ok := lobby.conf.Servers[server]
+	if !ok {
+		return fmt.Errorf("lobbykit: unknown server %v", server)
+	}
+	host, port, _ := splitAddress(addr)
+	return conn.WritePacket(&packet.Transfer{Address: host, Port: port})
+}
+
+// Broadcast sends a message to all players currently in the Lobby.
+func (lobby *Lobby) Broadcast(message string) {
+	lobby.mu.Lock()
+	defer lobby.mu.Unlock()
+	for conn := range lobby.players {
+		_ = conn.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: message})
+	}
+}
+
+// handleConn spawns the connection passed in the lobby world and handles the packets it sends until the
+// connection is closed.
+func (lobby *Lobby) handleConn(conn *minecraft.Conn) {
+	defer func() {
+		lobby.mu.Lock()
+		delete(lobby.players, conn)
+		lobby.mu.Unlock()
+		_ = conn.Close()
+	}()
+	if err := conn.StartGame(lobby.gameData()); err != nil {
+		lobby.conf.Log.Error("lobbykit: start game: " + err.Error())
+		return
+	}
+	if err := lobby.sendWorld(conn); err != nil {
+		lobby.conf.Log.Error("lobbykit: send world: " + err.Error())
+		return
+	}
+	lobby.mu.Lock()
+	lobby.players[conn] = struct{}{}
+	lobby.mu.Unlock()
+
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				var disc minecraft.DisconnectError
+				if !errors.As(err, &disc) {
+					lobby.conf.Log.Error("lobbykit: read packet: " + err.Error())
+				}
+			}
+			return
+		}
+		switch pk := pk.(type) {
+		case *packet.Text:
+			if pk.TextType != packet.TextTypeChat {
+				continue
+			}
+			lobby.Broadcast("<" + conn.IdentityData().DisplayName + "> " + pk.Message)
+		case *packet.CommandRequest:
+			lobby.handleCommand(conn, pk)
+		}
+	}
+}
+
+// handleCommand handles a command sent by a player. Only the /transfer command is supported.
+func (lobby *Lobby) handleCommand(conn *minecraft.Conn, pk *packet.CommandRequest) {
+	args := strings.Fields(strings.TrimPrefix(pk.CommandLine, "/"))
+	if len(args) == 0 || args[0] != "transfer" {
+		lobby.message(conn, "§cUnknown command. Use /transfer <server>.")
+		return
+	}
+	if len(args) != 2 {
+		lobby.message(conn, "§cUsage: /transfer <"+strings.Join(lobby.serverNames(), "|")+">")
+		return
+	}
+	if err := lobby.Transfer(conn, args[1]); err != nil {
+		lobby.message(conn, "§cUnknown server "+args[1]+".")
+	}
+}
+
+// message sends a raw message to the connection passed.
+func (lobby *Lobby) message(conn *minecraft.Conn, message string) {
+	_ = conn.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: message})
+}
+
+// gameData returns the minecraft.GameData used to spawn players in the lobby.
+func (lobby *Lobby) gameData() minecraft.GameData {
+	return minecraft.GameData{
+		WorldName:       lobby.conf.WorldName,
+		EntityUniqueID:  1,
+		EntityRuntimeID: 1,
+		PlayerGameMode:  packet.GameTypeAdventure,
+		WorldGameMode:   packet.GameTypeAdventure,
+		BaseGameVersion: "1.17.40",
+		PlayerPosition:  spawnPosition,
+		WorldSpawn:      protocol.BlockPos{8, platformY + 1, 8},
+		Time:            6000,
+		GameRules: []protocol.GameRule{
+			protocol.BoolGameRule(protocol.GameRuleDoDaylightCycle, false, false),
+			protocol.BoolGameRule(protocol.GameRuleDoWeatherCycle, false, false),
+			protocol.BoolGameRule(protocol.GameRuleFallDamage, false, false),
+			protocol.BoolGameRule(protocol.GameRulePVP, false, false),
+		},
+		PlayerMovementSettings: protocol.PlayerMovementSettings{
+			MovementType: protocol.PlayerMovementModeServer,
+		},
+		ChunkRadius:             platformChunkRadius + 1,
+		UseBlockNetworkIDHashes: true,
+	}
+}
+
+// sendWorld sends the chunks of the lobby world and the commands available to the connection passed.
+func (lobby *Lobby) sendWorld(conn *minecraft.Conn) error {
+	_ = conn.WritePacket(&packet.NetworkChunkPublisherUpdate{
+		Position: protocol.BlockPos{8, platformY, 8},
+		Radius:   (platformChunkRadius + 1) << 4,
+	})
+	for x := int32(-platformChunkRadius); x <= platformChunkRadius; x++ {
+		for z := int32(-platformChunkRadius); z <= platformChunkRadius; z++ {
+			pk := &packet.LevelChunk{Position: protocol.ChunkPos{x, z}, RawPayload: lobby.empty}
+			if x == 0 && z == 0 {
+				pk.SubChunkCount, pk.RawPayload = 1, lobby.platform
+			}
+			_ = conn.WritePacket(pk)
+		}
+	}
+	return conn.WritePacket(&packet.AvailableCommands{
+		Commands: []protocol.Command{{
+			Name:        "transfer",
+			Description: "Transfer to another server",
+			Overloads: []protocol.CommandOverload{{
+				Parameters: []protocol.CommandParameter{{
+					Name: "server",
+					Type: protocol.CommandArgValid | protocol.CommandArgTypeString,
+				}},
+			}},
+		}},
+	})
+}
+
+// serverNames returns the sorted names of all servers that players may be transferred to.
+func (lobby *Lobby) serverNames() []string {
+	names := make([]string, 0, len(lobby.conf.Servers))
+	for name := range lobby.conf.Servers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// splitAddress splits an address formatted as 'host:port' into its host and port.
+func splitAddress(addr string) (string, uint16, error) {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseUint(p, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse port: %w", err)
+	}
+	return host, uint16(port), nil
+}
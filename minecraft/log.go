@@ -0,0 +1,116 @@
+package minecraft
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// connHandler is the slog.Handler of the logger of a Conn. Attributes may be added to it after the logger
+// was created, such as the XUID of a connection once it has logged in, while the logger is in use on other
+// goroutines.
+type connHandler struct {
+	h atomic.Pointer[slog.Handler]
+}
+
+// newConnHandler returns a connHandler that passes records on to the slog.Handler passed.
+func newConnHandler(h slog.Handler) *connHandler {
+	c := &connHandler{}
+	c.h.Store(&h)
+	return c
+}
+
+// addAttrs adds the attributes passed to all records logged after the call.
+func (c *connHandler) addAttrs(attrs ...slog.Attr) {
+	h := (*c.h.Load()).WithAttrs(attrs)
+	c.h.Store(&h)
+}
+
+// Enabled ...
+func (c *connHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return (*c.h.Load()).Enabled(ctx, level)
+}
+
+// Handle ...
+func (c *connHandler) Handle(ctx context.Context, r slog.Record) error {
+	return (*c.h.Load()).Handle(ctx, r)
+}
+
+// WithAttrs ...
+func (c *connHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return (&derivedHandler{parent: c}).with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+// WithGroup ...
+func (c *connHandler) WithGroup(name string) slog.Handler {
+	return (&derivedHandler{parent: c}).with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+// derivedHandler is a slog.Handler returned by connHandler.WithAttrs and connHandler.WithGroup. Rather than
+// deriving from the slog.Handler of the connHandler at the time of the call, it re-applies its attributes
+// and groups to the current slog.Handler of the connHandler, so that attributes added to the connHandler
+// later, such as the XUID of the connection, are also present on records logged with it.
+type derivedHandler struct {
+	parent *connHandler
+	ops    []func(slog.Handler) slog.Handler
+
+	cache atomic.Pointer[derivedCache]
+}
+
+// derivedCache holds the slog.Handler derived by a derivedHandler from a slog.Handler of its connHandler.
+type derivedCache struct {
+	base *slog.Handler
+	h    slog.Handler
+}
+
+// with returns a derivedHandler that applies the operation passed after all operations of d.
+func (d *derivedHandler) with(op func(slog.Handler) slog.Handler) *derivedHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(d.ops), len(d.ops)+1)
+	copy(ops, d.ops)
+	return &derivedHandler{parent: d.parent, ops: append(ops, op)}
+}
+
+// handler returns the slog.Handler derived from the current slog.Handler of the connHandler. It is only
+// derived again if the slog.Handler of the connHandler changed since the last call.
+func (d *derivedHandler) handler() slog.Handler {
+	base := d.parent.h.Load()
+	if cache := d.cache.Load(); cache != nil && cache.base == base {
+		return cache.h
+	}
+	h := *base
+	for _, op := range d.ops {
+		h = op(h)
+	}
+	d.cache.Store(&derivedCache{base: base, h: h})
+	return h
+}
+
+// Enabled ...
+func (d *derivedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return d.handler().Enabled(ctx, level)
+}
+
+// Handle ...
+func (d *derivedHandler) Handle(ctx context.Context, r slog.Record) error {
+	return d.handler().Handle(ctx, r)
+}
+
+// WithAttrs ...
+func (d *derivedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+// WithGroup ...
+func (d *derivedHandler) WithGroup(name string) slog.Handler {
+	return d.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+// logIdentity adds the XUID and display name of the identity data of the Conn to the attributes of its
+// logger, so that records logged by the Conn may be attributed to a player.
+func (conn *Conn) logIdentity() {
+	attrs := []slog.Attr{slog.String("name", conn.identityData.DisplayName)}
+	if conn.identityData.XUID != "" {
+		attrs = append(attrs, slog.String("xuid", conn.identityData.XUID))
+	}
+	conn.logHandler.addAttrs(attrs...)
+}
@@ -0,0 +1,69 @@
+package minecraft
+
+import (
+	"context"
+	"net"
+)
+
+// loginPool is a bounded pool of goroutines that verify the login requests of connections of a Listener.
+// Verifying a login chain and deriving the shared secret for encryption are expensive ECDSA operations, so
+// the pool limits the amount of logins verified at the same time. A flood of login requests then cannot
+// starve the goroutines of connections that are already playing of CPU time.
+type loginPool struct {
+	jobs  chan loginJob
+	close <-chan struct{}
+}
+
+// loginJob is a single login verification submitted to a loginPool.
+type loginJob struct {
+	f   func() error
+	res chan error
+}
+
+// newLoginPool creates a loginPool with n workers. The workers stop once the close channel passed is closed.
+func newLoginPool(n int, close <-chan struct{}) *loginPool {
+	p := &loginPool{jobs: make(chan loginJob), close: close}
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// work runs jobs submitted to the loginPool until it is closed.
+func (p *loginPool) work() {
+	for {
+		select {
+		case <-p.close:
+			return
+		case job := <-p.jobs:
+			job.res <- job.f()
+		}
+	}
+}
+
+// run runs f on one of the workers of the loginPool and returns its error. run blocks until f returns. If
+// either the pool or the connection, of which the close channel is passed, is closed before f is run,
+// net.ErrClosed is returned without running f. If either is closed while f is running, the context passed to
+// f is cancelled, after which f should return as soon as possible. run does not return before f does, so
+// that f never touches the connection after run returned.
+func (p *loginPool) run(f func(ctx context.Context) error, connClosed <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.close:
+		case <-connClosed:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	res := make(chan error, 1)
+	select {
+	case <-ctx.Done():
+		return net.ErrClosed
+	case p.jobs <- loginJob{f: func() error { return f(ctx) }, res: res}:
+	}
+	// Once a worker accepted the job, it always runs it to completion, so res is always written to.
+	return <-res
+}
@@ -0,0 +1,172 @@
+// Package maps implements helpers to draw images on the maps shown to players, which are updated using the
+// ClientBoundMapItemData packet. A map shows a texture of Size by Size pixels that may hold any colour, so
+// maps are suitable to show images, or animations when updated repeatedly, for example in item frames.
+//
+// A Canvas holds the texture of a single map and produces the packets that update it on the client. It keeps
+// track of the pixels changed since the last update, so that drawing the frames of an animation only sends the
+// area of each frame that changed. Images larger than a single map may be shown on a grid of maps, each with
+// its own Canvas, as returned by Grid.
+//
+// Maps are shown to a player by giving it a 'minecraft:filled_map' item with the NBT returned by ItemNBT. The
+// client then sends a MapInfoRequest packet, to which the server should respond with the packet returned by
+// Canvas.Full.
+package maps
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"image"
+	"image/color"
+)
+
+// Size is the width and height in pixels of the texture of a map.
+const Size = 128
+
+// Canvas holds the texture and decorations of a single map. A Canvas is not safe for concurrent use.
+type Canvas struct {
+	id     int64
+	pixels [Size * Size]color.RGBA
+
+	// dirty is the area of the texture changed since the last update.
+	dirty image.Rectangle
+
+	decorationsChanged bool
+	decorations        []protocol.MapDecoration
+	trackedObjects     []protocol.MapTrackedObject
+}
+
+// NewCanvas returns a Canvas for the map with the ID passed. The texture of the Canvas is fully transparent.
+func NewCanvas(id int64) *Canvas {
+	return &Canvas{id: id}
+}
+
+// ID returns the ID of the map of the Canvas.
+func (c *Canvas) ID() int64 {
+	return c.id
+}
+
+// At returns the colour of the pixel at the position passed. Transparent black is returned for positions
+// outside the texture.
+func (c *Canvas) At(x, y int) color.RGBA {
+	if !image.Pt(x, y).In(image.Rect(0, 0, Size, Size)) {
+		return color.RGBA{}
+	}
+	return c.pixels[y*Size+x]
+}
+
+// Set sets the colour of the pixel at the position passed. Positions outside the texture are ignored.
+func (c *Canvas) Set(x, y int, col color.RGBA) {
+	if !image.Pt(x, y).In(image.Rect(0, 0, Size, Size)) {
+		return
+	}
+	if c.pixels[y*Size+x] == col {
+		return
+	}
+	c.pixels[y*Size+x] = col
+	c.dirty = c.dirty.Union(image.Rect(x, y, x+1, y+1))
+}
+
+// Draw draws the image passed onto the texture of the Canvas, such that the point sp of the image is drawn at
+// the top-left corner of the texture, similar to draw.Draw. Parts of the image outside the texture are not
+// drawn and pixels of the texture not covered by the image are left unchanged. Images larger than Size pixels
+// may be shown on multiple maps by drawing them with an sp returned by Grid.
+// Colours are converted to non-premultiplied RGBA, so translucent pixels of the image replace the pixels of
+// the texture rather than being blended with them.
+func (c *Canvas) Draw(img image.Image, sp image.Point) {
+	r := img.Bounds().Intersect(image.Rectangle{Min: sp, Max: sp.Add(image.Pt(Size, Size))})
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			col := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			c.Set(x-sp.X, y-sp.Y, color.RGBA(col))
+		}
+	}
+}
+
+// Fill sets all pixels of the texture to the colour passed.
+func (c *Canvas) Fill(col color.RGBA) {
+	for y := 0; y < Size; y++ {
+		for x := 0; x < Size; x++ {
+			c.Set(x, y, col)
+		}
+	}
+}
+
+// SetDecorations sets the decorations and tracked objects shown on the map. They replace all decorations and
+// tracked objects set before and are sent with the next update.
+func (c *Canvas) SetDecorations(decorations []protocol.MapDecoration, trackedObjects []protocol.MapTrackedObject) {
+	c.decorations, c.trackedObjects = decorations, trackedObjects
+	c.decorationsChanged = true
+}
+
+// Flush returns a packet that updates the pixels and decorations of the map changed since the last call to
+// Flush or Full. The texture area updated is the smallest rectangle holding all pixels changed. If nothing
+// changed, Flush returns nil.
+func (c *Canvas) Flush() *packet.ClientBoundMapItemData {
+	if c.dirty.Empty() && !c.decorationsChanged {
+		return nil
+	}
+	pk := c.packet(0)
+	if !c.dirty.Empty() {
+		c.texture(pk, c.dirty)
+	}
+	if c.decorationsChanged {
+		c.decorate(pk)
+	}
+	c.dirty, c.decorationsChanged = image.Rectangle{}, false
+	return pk
+}
+
+// Full returns a packet that updates the full texture and all decorations of the map, such as sent in
+// response to a MapInfoRequest packet or to players that have not seen the map before. The changes tracked
+// for Flush are reset.
+func (c *Canvas) Full() *packet.ClientBoundMapItemData {
+	pk := c.packet(packet.MapUpdateFlagInitialisation)
+	pk.MapsIncludedIn = []int64{c.id}
+	c.texture(pk, image.Rect(0, 0, Size, Size))
+	c.decorate(pk)
+	c.dirty, c.decorationsChanged = image.Rectangle{}, false
+	return pk
+}
+
+// packet returns a ClientBoundMapItemData packet for the map of the Canvas with the update flags passed. The
+// map is marked as locked so that the client does not draw the terrain around it over the texture.
+func (c *Canvas) packet(flags uint32) *packet.ClientBoundMapItemData {
+	return &packet.ClientBoundMapItemData{MapID: c.id, UpdateFlags: flags, LockedMap: true}
+}
+
+// texture adds the pixels of the area of the texture passed to the packet passed.
+func (c *Canvas) texture(pk *packet.ClientBoundMapItemData, r image.Rectangle) {
+	pk.UpdateFlags |= packet.MapUpdateFlagTexture
+	pk.XOffset, pk.YOffset = int32(r.Min.X), int32(r.Min.Y)
+	pk.Width, pk.Height = int32(r.Dx()), int32(r.Dy())
+	pk.Pixels = make([]color.RGBA, 0, r.Dx()*r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		pk.Pixels = append(pk.Pixels, c.pixels[y*Size+r.Min.X:y*Size+r.Max.X]...)
+	}
+}
+
+// decorate adds the decorations and tracked objects of the Canvas to the packet passed.
+func (c *Canvas) decorate(pk *packet.ClientBoundMapItemData) {
+	pk.UpdateFlags |= packet.MapUpdateFlagDecoration
+	pk.Decorations, pk.TrackedObjects = c.decorations, c.trackedObjects
+}
+
+// Grid returns the points of the image with the bounds passed at which the maps of a grid showing the full
+// image should start drawing, indexed as points[row][column]. Each point may be passed to Canvas.Draw of the
+// map at that position in the grid.
+func Grid(bounds image.Rectangle) [][]image.Point {
+	columns, rows := (bounds.Dx()+Size-1)/Size, (bounds.Dy()+Size-1)/Size
+	points := make([][]image.Point, rows)
+	for row := range points {
+		points[row] = make([]image.Point, columns)
+		for column := range points[row] {
+			points[row][column] = bounds.Min.Add(image.Pt(column*Size, row*Size))
+		}
+	}
+	return points
+}
+
+// ItemNBT returns the NBT of a 'minecraft:filled_map' item showing the map with the ID passed.
+func ItemNBT(id int64) map[string]any {
+	return map[string]any{"map_uuid": id}
+}
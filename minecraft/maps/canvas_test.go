@@ -0,0 +1,66 @@
+package maps
+
+import (
+	"bytes"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestCanvasFlush(t *testing.T) {
+	c := NewCanvas(1)
+	if pk := c.Flush(); pk != nil {
+		t.Fatalf("expected no update for unchanged canvas, got %#v", pk)
+	}
+	red := color.RGBA{R: 0xff, A: 0xff}
+	img := image.NewRGBA(image.Rect(10, 10, 20, 20))
+	img.Set(12, 13, red)
+	img.Set(14, 15, red)
+	c.Draw(img, image.Pt(10, 10))
+
+	pk := c.Flush()
+	if pk == nil || pk.UpdateFlags != packet.MapUpdateFlagTexture {
+		t.Fatalf("expected texture update, got %#v", pk)
+	}
+	if pk.XOffset != 2 || pk.YOffset != 3 || pk.Width != 3 || pk.Height != 3 {
+		t.Fatalf("unexpected update area: offset (%v, %v), size %vx%v", pk.XOffset, pk.YOffset, pk.Width, pk.Height)
+	}
+	if pk.Pixels[0] != red || pk.Pixels[len(pk.Pixels)-1] != red || pk.Pixels[1] != (color.RGBA{}) {
+		t.Fatalf("unexpected pixels %v", pk.Pixels)
+	}
+	if pk := c.Flush(); pk != nil {
+		t.Fatalf("expected no update after flush, got %#v", pk)
+	}
+}
+
+func TestCanvasFull(t *testing.T) {
+	c := NewCanvas(5)
+	c.Fill(color.RGBA{B: 0xff, A: 0xff})
+	c.SetDecorations([]protocol.MapDecoration{{Type: protocol.MapDecorationTypeMarkerRed, X: 4, Y: 8, Label: "x"}}, nil)
+	pk := c.Full()
+	if len(pk.Pixels) != Size*Size {
+		t.Fatalf("expected %v pixels, got %v", Size*Size, len(pk.Pixels))
+	}
+
+	buf := bytes.NewBuffer(nil)
+	pk.Marshal(protocol.NewWriter(buf, 0))
+	read := &packet.ClientBoundMapItemData{}
+	read.Marshal(protocol.NewReader(buf, 0, false))
+	if !reflect.DeepEqual(read.Pixels, pk.Pixels) || !reflect.DeepEqual(read.Decorations, pk.Decorations) {
+		t.Fatalf("packet changed after encoding")
+	}
+	if c.Flush() != nil {
+		t.Fatalf("expected no update after full update")
+	}
+}
+
+func TestGrid(t *testing.T) {
+	points := Grid(image.Rect(0, 0, Size*2+1, Size))
+	want := [][]image.Point{{image.Pt(0, 0), image.Pt(Size, 0), image.Pt(Size*2, 0)}}
+	if !reflect.DeepEqual(points, want) {
+		t.Fatalf("got %v, want %v", points, want)
+	}
+}
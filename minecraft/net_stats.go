@@ -0,0 +1,109 @@
+package minecraft
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// NetworkStats holds statistics of the network connection of a Conn, as returned by Conn.NetworkStats. Bytes
+// are counted as passed to and from the underlying net.Conn, so after compression and encryption. The RakNet
+// layer of the connection does not expose its MTU, send queue or resends, so these are not included.
+type NetworkStats struct {
+	// Latency is the latency of the connection, as measured by the underlying net.Conn. It is zero if the
+	// net.Conn does not measure its latency.
+	Latency time.Duration
+	// BatchesSent and BatchesReceived are the amount of packet batches written to and read from the
+	// connection.
+	BatchesSent, BatchesReceived uint64
+	// BytesSent and BytesReceived are the amount of bytes of all batches written to and read from the
+	// connection.
+	BytesSent, BytesReceived uint64
+	// BufferedBytes is the amount of bytes of packets written to the Conn that are waiting for the next
+	// flush to be sent.
+	BufferedBytes int64
+}
+
+// NetworkStats returns statistics of the network connection of the Conn.
+func (conn *Conn) NetworkStats() NetworkStats {
+	s := NetworkStats{
+		BatchesSent:     conn.stats.batchesSent.Load(),
+		BatchesReceived: conn.stats.batchesReceived.Load(),
+		BytesSent:       conn.stats.bytesSent.Load(),
+		BytesReceived:   conn.stats.bytesReceived.Load(),
+		BufferedBytes:   conn.bufferedBytes.Load(),
+	}
+	if c, ok := conn.conn.(interface{ Latency() time.Duration }); ok {
+		s.Latency = c.Latency()
+	}
+	return s
+}
+
+// connStats holds the counters of the NetworkStats of a Conn.
+type connStats struct {
+	batchesSent, batchesReceived atomic.Uint64
+	bytesSent, bytesReceived     atomic.Uint64
+}
+
+// writer returns an io.Writer that counts the batches written to w. Every call to Write must write exactly
+// one batch, as done by packet.Encoder.
+func (s *connStats) writer(w io.Writer) io.Writer {
+	return statsWriter{w: w, s: s}
+}
+
+// reader returns an io.Reader that counts the batches read from r. If r reads packets one at a time through
+// a ReadPacket method, as RakNet connections do, the io.Reader returned does too, so that a packet.Decoder
+// reads from it the same way it would from r.
+func (s *connStats) reader(r io.Reader) io.Reader {
+	if pr, ok := r.(interface{ ReadPacket() ([]byte, error) }); ok {
+		return statsPacketReader{statsReader: statsReader{r: r, s: s}, pr: pr}
+	}
+	return statsReader{r: r, s: s}
+}
+
+// statsWriter is an io.Writer that counts the batches written through it.
+type statsWriter struct {
+	w io.Writer
+	s *connStats
+}
+
+// Write ...
+func (w statsWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.s.batchesSent.Add(1)
+	w.s.bytesSent.Add(uint64(n))
+	return n, err
+}
+
+// statsReader is an io.Reader that counts the bytes read through it. Batches are not delimited in a stream,
+// so every Read is counted as a batch.
+type statsReader struct {
+	r io.Reader
+	s *connStats
+}
+
+// Read ...
+func (r statsReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.s.batchesReceived.Add(1)
+		r.s.bytesReceived.Add(uint64(n))
+	}
+	return n, err
+}
+
+// statsPacketReader is a statsReader that also counts the packets read through ReadPacket.
+type statsPacketReader struct {
+	statsReader
+	pr interface{ ReadPacket() ([]byte, error) }
+}
+
+// ReadPacket ...
+func (r statsPacketReader) ReadPacket() ([]byte, error) {
+	b, err := r.pr.ReadPacket()
+	if err == nil {
+		r.s.batchesReceived.Add(1)
+		r.s.bytesReceived.Add(uint64(len(b)))
+	}
+	return b, err
+}
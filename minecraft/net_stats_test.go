@@ -0,0 +1,43 @@
+package minecraft_test
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"testing"
+	"time"
+)
+
+// TestNetworkStats checks if the NetworkStats of both ends of a connection count the batches written by one
+// end and read by the other, and if packets that are not yet flushed are reported as buffered.
+func TestNetworkStats(t *testing.T) {
+	client, server, err := minecraft.Pipe(minecraft.PipeConfig{Dialer: minecraft.Dialer{FlushRate: -1}})
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer server.Close()
+	defer client.Close()
+
+	if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: "Hello"}); err != nil {
+		t.Fatalf("write packet: %v", err)
+	}
+	if stats := client.NetworkStats(); stats.BufferedBytes == 0 || stats.BatchesSent != 0 {
+		t.Fatalf("expected packet to be buffered before flushing, got %+v", stats)
+	}
+	if err := client.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if err := server.SetReadDeadline(time.Now().Add(time.Second * 5)); err != nil {
+		t.Fatalf("set read deadline: %v", err)
+	}
+	if _, err := server.ReadPacket(); err != nil {
+		t.Fatalf("read packet: %v", err)
+	}
+
+	sent, received := client.NetworkStats(), server.NetworkStats()
+	if sent.BatchesSent != 1 || sent.BufferedBytes != 0 {
+		t.Fatalf("expected a single batch sent and nothing buffered, got %+v", sent)
+	}
+	if received.BatchesReceived != 1 || received.BytesReceived != sent.BytesSent {
+		t.Fatalf("expected the batch sent to be received, sent %+v, received %+v", sent, received)
+	}
+}
@@ -0,0 +1,118 @@
+package minecraft
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// packCache persists the resource packs downloaded by a client in a directory, so that they do not have to
+// be downloaded again when reconnecting to a server. Packs are stored in files named after their UUID,
+// version and checksum, like the vanilla client caches packs.
+type packCache struct {
+	dir string
+}
+
+// load loads the pack described by the info passed from the cache. False is returned if the pack was not
+// cached or if the cached pack does not match the pack described.
+func (c packCache) load(info protocol.TexturePackInfo) (*resource.Pack, bool) {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, c.prefix(info.UUID.String(), info.Version)+"*.mcpack"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		pack, err := resource.ReadBytes(data)
+		if err != nil {
+			continue
+		}
+		sum := pack.Checksum()
+		if pack.UUID() != info.UUID || pack.Version() != info.Version || uint64(pack.Len()) != info.Size || !strings.HasSuffix(path, "_"+hex.EncodeToString(sum[:])+".mcpack") {
+			// The cached pack was changed or corrupted after it was stored.
+			continue
+		}
+		return pack.WithContentKey(info.ContentKey), true
+	}
+	return nil, false
+}
+
+// loadEarlier loads the most recently cached version of the pack described by the info passed, other than
+// the version described. It is used to request a delta from the server. False is returned if no other
+// version of the pack was cached.
+func (c packCache) loadEarlier(info protocol.TexturePackInfo) (*resource.Pack, bool) {
+	paths, _ := filepath.Glob(filepath.Join(c.dir, info.UUID.String()+"_*.mcpack"))
+	var (
+		latest  *resource.Pack
+		modTime time.Time
+	)
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil || stat.ModTime().Before(modTime) || strings.HasPrefix(filepath.Base(path), c.prefix(info.UUID.String(), info.Version)) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		pack, err := resource.ReadBytes(data)
+		if err != nil {
+			continue
+		}
+		sum := pack.Checksum()
+		if pack.UUID() != info.UUID || !strings.HasSuffix(path, "_"+hex.EncodeToString(sum[:])+".mcpack") {
+			// The cached pack was changed or corrupted after it was stored.
+			continue
+		}
+		latest, modTime = pack, stat.ModTime()
+	}
+	return latest, latest != nil
+}
+
+// store stores the pack passed in the cache, replacing any other pack with the same UUID and version.
+func (c packCache) store(pack *resource.Pack) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("create pack cache directory: %w", err)
+	}
+	data := make([]byte, pack.Len())
+	if _, err := pack.ReadAt(data, 0); err != nil {
+		return fmt.Errorf("read pack: %w", err)
+	}
+	prefix := c.prefix(pack.UUID().String(), pack.Version())
+	old, _ := filepath.Glob(filepath.Join(c.dir, prefix+"*.mcpack"))
+
+	sum := pack.Checksum()
+	path := filepath.Join(c.dir, prefix+hex.EncodeToString(sum[:])+".mcpack")
+	// Write to a temporary file first, so that a pack is never cached partially.
+	temp, err := os.CreateTemp(c.dir, prefix+"*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+		return fmt.Errorf("write pack: %w", err)
+	}
+	for _, p := range old {
+		if p != path {
+			_ = os.Remove(p)
+		}
+	}
+	return nil
+}
+
+// prefix returns the prefix of the names of the files that a pack with the UUID and version passed is
+// stored in.
+func (c packCache) prefix(id, version string) string {
+	return id + "_" + version + "_"
+}
@@ -0,0 +1,81 @@
+package minecraft
+
+import (
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// packDeltaSuffix is appended to the UUID in the ResourcePackDataInfo packet if the data sent for the pack
+// is a delta rather than the pack itself.
+const packDeltaSuffix = "_delta"
+
+// packDeltas holds the earlier versions of resource packs that a Listener can send deltas against, and the
+// deltas computed so far. It is shared by all connections of the Listener, so that each delta is only
+// computed once.
+//
+// Deltas are an extension of the resource pack download sequence that only a Listener and Dialer of
+// gophertunnel take part in. A Listener with PreviousResourcePacks announces support with a claim in the JWT
+// of the ServerToClientHandshake packet, which vanilla clients ignore. A Dialer that has an earlier version
+// of a pack cached then appends the checksum of that version to the pack in PacksToDownload. If the Listener
+// holds that version, it sends a delta produced by resource.Diff instead of the pack, and the full pack
+// otherwise.
+type packDeltas struct {
+	previous []*resource.Pack
+
+	mu     sync.Mutex
+	deltas map[[2][32]byte]*packDelta
+}
+
+// packDelta is a delta between two versions of a pack, computed once it is first needed.
+type packDelta struct {
+	once sync.Once
+	data []byte
+}
+
+// newPackDeltas returns packDeltas that compute deltas against the earlier versions of packs passed.
+func newPackDeltas(previous []*resource.Pack) *packDeltas {
+	return &packDeltas{previous: previous, deltas: make(map[[2][32]byte]*packDelta)}
+}
+
+// base returns the earlier version of the pack passed with the checksum passed, encoded as hex. False is
+// returned if no such version is held.
+func (d *packDeltas) base(pack *resource.Pack, checksum string) (*resource.Pack, bool) {
+	for _, p := range d.previous {
+		sum := p.Checksum()
+		if p.UUID() == pack.UUID() && hex.EncodeToString(sum[:]) == checksum {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// delta returns the delta that turns the pack from into the pack to, computing it if it was not computed
+// before.
+func (d *packDeltas) delta(from, to *resource.Pack) []byte {
+	key := [2][32]byte{from.Checksum(), to.Checksum()}
+	d.mu.Lock()
+	delta, ok := d.deltas[key]
+	if !ok {
+		delta = &packDelta{}
+		d.deltas[key] = delta
+	}
+	d.mu.Unlock()
+
+	delta.once.Do(func() {
+		delta.data = resource.Diff(from, to)
+	})
+	return delta.data
+}
+
+// parsePackRequest parses an entry of the PacksToDownload of a ResourcePackClientResponse packet. It returns
+// the 'UUID_Version' of the pack requested and, if the client requested a delta, the checksum of the version
+// of the pack that the client has cached.
+func parsePackRequest(s string) (pack, base string) {
+	if i := strings.LastIndexByte(s, '_'); i != -1 && strings.Count(s, "_") == 2 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
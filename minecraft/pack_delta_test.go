@@ -0,0 +1,110 @@
+package minecraft_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"math/rand"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestResourcePackDelta checks if a client that has an earlier version of a resource pack cached only
+// downloads the changes to the pack from a Listener that holds that version, and downloads the full pack if
+// the Listener does not.
+func TestResourcePackDelta(t *testing.T) {
+	id := uuid.New()
+	v1, v2, v3 := deltaTestPack(t, id, 1), deltaTestPack(t, id, 2), deltaTestPack(t, id, 3)
+
+	dir := t.TempDir()
+	// The first connection caches the first version of the pack.
+	if n := dialDeltaTest(t, dir, []*resource.Pack{v1}, nil); n < uint64(v1.Len()) {
+		t.Fatalf("expected full pack of %v bytes to be downloaded, got %v bytes", v1.Len(), n)
+	}
+	if n := dialDeltaTest(t, dir, []*resource.Pack{v2}, []*resource.Pack{v1}); n >= uint64(v2.Len())/2 {
+		t.Fatalf("expected delta to be downloaded, got %v bytes of a %v byte pack", n, v2.Len())
+	}
+	// The second version is now cached, but the Listener only holds the first version, so the full pack is
+	// downloaded.
+	if n := dialDeltaTest(t, dir, []*resource.Pack{v3}, []*resource.Pack{v1}); n < uint64(v3.Len()) {
+		t.Fatalf("expected full pack of %v bytes to be downloaded, got %v bytes", v3.Len(), n)
+	}
+}
+
+// dialDeltaTest connects to a Listener with the resource packs passed using a Dialer that caches packs in
+// the directory passed. It checks that the client ends up with the packs of the Listener and returns the
+// amount of bytes of resource pack data received.
+func dialDeltaTest(t *testing.T, dir string, packs, previous []*resource.Pack) uint64 {
+	t.Helper()
+	l, err := minecraft.ListenConfig{
+		AuthenticationDisabled: true,
+		ResourcePacks:          packs,
+		PreviousResourcePacks:  previous,
+	}.Listen("raknet", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		if err := c.(*minecraft.Conn).StartGameContext(ctx, minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+			return
+		}
+		<-ctx.Done()
+	}()
+
+	var received atomic.Uint64
+	d := minecraft.Dialer{ResourcePackCacheDir: dir}
+	d.PacketFunc = func(h packet.Header, payload []byte, _, _ net.Addr) {
+		if h.PacketID == packet.IDResourcePackChunkData {
+			received.Add(uint64(len(payload)))
+		}
+	}
+	client, err := d.DialContext(ctx, "raknet", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	if got := client.ResourcePacks(); len(got) != 1 || got[0].Checksum() != packs[0].Checksum() {
+		t.Fatalf("resource pack was not downloaded correctly")
+	}
+	return received.Load()
+}
+
+// deltaTestPack returns version n of a resource pack with the UUID passed. The versions only differ in their
+// manifest and a small file, so that the delta between two versions is much smaller than the pack.
+func deltaTestPack(t *testing.T, id uuid.UUID, n int) *resource.Pack {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	manifest := fmt.Sprintf(`{"format_version": 2, "header": {"name": "delta", "uuid": %q, "version": [1, 0, %v], "min_engine_version": [1, 21, 0]}, "modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]}`, id, n, uuid.NewMD5(id, []byte("resources")))
+	f, _ := w.Create("manifest.json")
+	_, _ = f.Write([]byte(manifest))
+
+	data := make([]byte, 512*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	f, _ = w.CreateHeader(&zip.FileHeader{Name: "data.bin", Method: zip.Store})
+	_, _ = f.Write(data)
+	f, _ = w.Create("version.txt")
+	_, _ = fmt.Fprintf(f, "version %v", n)
+	_ = w.Close()
+
+	pack, err := resource.ReadBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	return pack
+}
@@ -0,0 +1,127 @@
+package minecraft_test
+
+import (
+	"context"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestResourcePackURLDownload verifies that resource packs offered on a URL are downloaded from it, that
+// oversized downloads are aborted in favour of downloading the pack from the server, and that a stalled
+// download is bounded by the ResourcePackDownloadTimeout.
+func TestResourcePackURLDownload(t *testing.T) {
+	tests := []struct {
+		name string
+		// serve writes the response of the URL of the pack, which holds the content passed.
+		serve    func(w http.ResponseWriter, r *http.Request, content []byte)
+		timeout  time.Duration
+		fromURL  bool
+		dialFail bool
+	}{
+		{
+			name:    "url",
+			serve:   func(w http.ResponseWriter, _ *http.Request, content []byte) { _, _ = w.Write(content) },
+			fromURL: true,
+		},
+		{
+			name: "oversized",
+			serve: func(w http.ResponseWriter, _ *http.Request, content []byte) {
+				_, _ = w.Write(content)
+				_, _ = w.Write(make([]byte, 1024*1024))
+			},
+		},
+		{
+			name: "stalled",
+			serve: func(w http.ResponseWriter, r *http.Request, content []byte) {
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			},
+			timeout:  time.Millisecond * 500,
+			dialFail: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pack := chaosTestPack(t)
+			content := make([]byte, pack.Len())
+			_, _ = pack.ReadAt(content, 0)
+
+			var serving atomic.Bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !serving.Load() {
+					_, _ = w.Write(content)
+					return
+				}
+				test.serve(w, r, content)
+			}))
+			defer srv.Close()
+			urlPack, err := resource.ReadURL(srv.URL)
+			if err != nil {
+				t.Fatalf("read pack from URL: %v", err)
+			}
+			serving.Store(true)
+
+			l, err := minecraft.ListenConfig{
+				AuthenticationDisabled: true,
+				ResourcePacks:          []*resource.Pack{urlPack},
+				EncryptionDisabledFunc: func(net.Addr) bool { return true },
+			}.Listen("raknet", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			defer l.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+			var chunksRequested atomic.Bool
+			go func() {
+				c, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer c.Close()
+				if err := c.(*minecraft.Conn).StartGameContext(ctx, minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+					return
+				}
+				<-ctx.Done()
+			}()
+
+			d := minecraft.Dialer{DownloadResourcePacksFromURL: true, ResourcePackDownloadTimeout: test.timeout}
+			d.PacketFunc = func(h packet.Header, _ []byte, _, _ net.Addr) {
+				if h.PacketID == packet.IDResourcePackDataInfo {
+					chunksRequested.Store(true)
+				}
+			}
+			start := time.Now()
+			client, err := d.DialContext(ctx, "raknet", l.Addr().String())
+			if test.dialFail {
+				if err == nil {
+					_ = client.Close()
+					t.Fatalf("expected dial to fail")
+				}
+				if d := time.Since(start); d > time.Second*5 {
+					t.Fatalf("dial took %v, expected it to be bounded by the download timeout", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer client.Close()
+			packs := client.ResourcePacks()
+			if len(packs) != 1 || packs[0].Checksum() != pack.Checksum() {
+				t.Fatalf("resource pack was not downloaded correctly")
+			}
+			if chunksRequested.Load() == test.fromURL {
+				t.Fatalf("expected pack downloaded from URL: %v, but chunks requested from server: %v", test.fromURL, chunksRequested.Load())
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"net"
 )
 
 // packetData holds the data of a Minecraft packet.
@@ -23,13 +24,17 @@ func parseData(data []byte, conn *Conn) (*packetData, error) {
 		// we return to reading a new packet.
 		return nil, fmt.Errorf("read packet header: %w", err)
 	}
-	if conn.packetFunc != nil {
-		// The packet func was set, so we call it.
-		conn.packetFunc(*header, buf.Bytes(), conn.RemoteAddr(), conn.LocalAddr())
-	}
+	conn.observe(*header, buf.Bytes(), conn.RemoteAddr(), conn.LocalAddr())
 	return &packetData{h: header, full: data, payload: buf}, nil
 }
 
+// packetObserver is a function added to a Conn using Conn.AddPacketObserver. It is called for packets with
+// one of the IDs in ids, or for all packets if ids is empty.
+type packetObserver struct {
+	f   func(header packet.Header, payload []byte, src, dst net.Addr)
+	ids []uint32
+}
+
 type unknownPacketError struct {
 	id uint32
 }
@@ -0,0 +1,379 @@
+package minecraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/clock"
+	"github.com/sandertv/gophertunnel/minecraft/internal"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// PipeConfig holds the settings of a pair of Conns created using Pipe.
+type PipeConfig struct {
+	// Login specifies if the Conns go through the complete login sequence before Pipe returns, as they would
+	// over a real network, using ListenConfig and Dialer for the server and client side respectively. If
+	// false, the login sequence is skipped: The Conns are considered logged in and spawned as soon as they
+	// are created, without any packets being exchanged and without encryption being enabled.
+	Login bool
+	// ListenConfig is used to create the server side Conn if Login is true. If Login is false, only its
+	// ErrorLog, FlushRate, Clock, ReadBatches, AllowUnknownPackets and AllowInvalidPackets fields are used.
+	ListenConfig ListenConfig
+	// Dialer is used to create the client side Conn if Login is true. If Login is false, only its ErrorLog,
+	// FlushRate, Clock, ReadBatches, IdentityData and ClientData fields are used, and the IdentityData and
+	// ClientData are set to both Conns.
+	Dialer Dialer
+	// GameData is the GameData that the server side Conn starts the game with. If Login is false, it is set
+	// as the GameData of both Conns.
+	GameData GameData
+}
+
+// Pipe creates a pair of Conns that are connected to each other through an in-memory transport, so that
+// packet handling may be tested without a RakNet connection. client behaves as a Conn returned by
+// Dialer.Dial and server as a Conn accepted by a Listener. Packets are delivered in the order that they are
+// written, and a Conn that is closed closes the other Conn as well.
+// Unless PipeConfig.Login is true, no packets are exchanged before Pipe returns, meaning the first packet
+// read from either Conn is the first packet written to the other Conn.
+func Pipe(cfg PipeConfig) (client, server *Conn, err error) {
+	if cfg.Login {
+		return pipeLogin(cfg)
+	}
+	c, s := newPipeConns(pipeAddr("client"), pipeAddr("server"))
+
+	if cfg.Dialer.ErrorLog == nil {
+		cfg.Dialer.ErrorLog = slog.New(internal.DiscardHandler{})
+	}
+	if cfg.ListenConfig.ErrorLog == nil {
+		cfg.ListenConfig.ErrorLog = slog.New(internal.DiscardHandler{})
+	}
+	if cfg.Dialer.FlushRate == 0 {
+		cfg.Dialer.FlushRate = time.Second / 20
+	}
+	if cfg.ListenConfig.FlushRate == 0 {
+		cfg.ListenConfig.FlushRate = time.Second / 20
+	}
+	if cfg.Dialer.Clock == nil {
+		cfg.Dialer.Clock = clock.System
+	}
+	if cfg.ListenConfig.Clock == nil {
+		cfg.ListenConfig.Clock = clock.System
+	}
+	defaultIdentityData(&cfg.Dialer.IdentityData)
+	defaultClientData(s.LocalAddr().String(), cfg.Dialer.IdentityData.DisplayName, &cfg.Dialer.ClientData)
+
+	client = newConn(c, nil, cfg.Dialer.ErrorLog.With("src", "dialer"), proto{}, cfg.Dialer.Clock, cfg.Dialer.FlushRate, false, cfg.Dialer.ReadBatches)
+	client.pool = client.proto.Packets(false)
+
+	server = newConn(s, nil, cfg.ListenConfig.ErrorLog.With("src", "listener"), proto{}, cfg.ListenConfig.Clock, cfg.ListenConfig.FlushRate, true, cfg.ListenConfig.ReadBatches)
+	server.pool = server.proto.Packets(true)
+	server.clientProtocol = protocol.CurrentProtocol
+	server.disconnectOnUnknownPacket = !cfg.ListenConfig.AllowUnknownPackets
+	server.disconnectOnInvalidPacket = !cfg.ListenConfig.AllowInvalidPackets
+
+	for _, conn := range [...]*Conn{client, server} {
+		conn.identityData = cfg.Dialer.IdentityData
+		conn.clientData = cfg.Dialer.ClientData
+		conn.gameData = cfg.GameData
+		for _, item := range cfg.GameData.Items {
+			if item.Name == "minecraft:shield" {
+				conn.shieldID.Store(int32(item.RuntimeID))
+			}
+		}
+		conn.loggedIn = true
+		close(conn.spawn)
+		conn.logIdentity()
+		go readPipe(conn)
+	}
+	return client, server, nil
+}
+
+// pipeLogin creates a pair of Conns for Pipe that go through the complete login sequence over the pipe
+// network.
+func pipeLogin(cfg PipeConfig) (client, server *Conn, err error) {
+	var (
+		addr string
+		l    *Listener
+	)
+	for {
+		// The address must be resolvable as a UDP address, because it ends up in the client data sent by the
+		// Dialer, which is validated by the Listener.
+		addr = fmt.Sprintf("127.0.0.1:%v", pipeID.Add(1)%65535+1)
+		if l, err = cfg.ListenConfig.Listen("pipe", addr); !errors.Is(err, errPipeAddrInUse) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("pipe: %w", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	accepted := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			server = c.(*Conn)
+			err = server.StartGameContext(ctx, cfg.GameData)
+		}
+		if err != nil {
+			cancel()
+		}
+		accepted <- err
+	}()
+	if client, err = cfg.Dialer.DialContext(ctx, "pipe", addr); err == nil {
+		err = client.DoSpawnContext(ctx)
+	}
+	if err != nil {
+		// Make sure the Listener stops accepting if dialing failed.
+		_ = l.Close()
+	}
+	if serverErr := <-accepted; serverErr != nil {
+		err = serverErr
+	}
+	if err != nil {
+		if client != nil {
+			_ = client.Close()
+		}
+		if server != nil {
+			_ = server.Close()
+		}
+		return nil, nil, fmt.Errorf("pipe: %w", err)
+	}
+	return client, server, nil
+}
+
+// readPipe reads packets from the pipe of a Conn created using Pipe until the Conn is closed.
+func readPipe(conn *Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	conn.setPhase(phasePlay)
+	for {
+		packets, err := conn.dec.Decode()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				conn.log.Error(err.Error())
+			}
+			return
+		}
+		if conn.readBatches {
+			if err := conn.receiveMultiple(packets); err != nil {
+				conn.log.Error(err.Error())
+				return
+			}
+			continue
+		}
+		for _, data := range packets {
+			if err := conn.receive(data); err != nil {
+				conn.log.Error(err.Error())
+				return
+			}
+		}
+	}
+}
+
+// pipeID is used to give every pair of Conns created using Pipe with Login set to true a unique address
+// on the pipe network.
+var pipeID atomic.Uint64
+
+// errPipeAddrInUse is returned when listening on an address of the pipe network that is already in use.
+var errPipeAddrInUse = errors.New("address already in use")
+
+// pipeNetwork is a Network that connects Dialers and Listeners in the same process through an in-memory
+// transport. It is registered under the "pipe" ID.
+type pipeNetwork struct{}
+
+var (
+	pipeMu        sync.Mutex
+	pipeListeners = map[string]*pipeListener{}
+)
+
+// DialContext connects to the pipeListener listening on the address passed.
+func (pipeNetwork) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	pipeMu.Lock()
+	l, ok := pipeListeners[address]
+	pipeMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("dial pipe: no listener on %v", address)
+	}
+	c, s := newPipeConns(pipeAddr(fmt.Sprintf("%v-client-%v", address, pipeID.Add(1))), pipeAddr(address))
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case l.conns <- s:
+		return c, nil
+	}
+}
+
+// PingContext always returns an error: pipeListeners do not respond to pings.
+func (pipeNetwork) PingContext(context.Context, string) ([]byte, error) {
+	return nil, errors.New("ping pipe: not supported")
+}
+
+// Listen creates a pipeListener on the address passed.
+func (pipeNetwork) Listen(address string) (NetworkListener, error) {
+	pipeMu.Lock()
+	defer pipeMu.Unlock()
+	if _, ok := pipeListeners[address]; ok {
+		return nil, fmt.Errorf("listen pipe: %v: %w", address, errPipeAddrInUse)
+	}
+	l := &pipeListener{addr: address, conns: make(chan net.Conn), closed: make(chan struct{})}
+	pipeListeners[address] = l
+	return l, nil
+}
+
+// Compression returns packet.FlateCompression.
+func (pipeNetwork) Compression(net.Conn) packet.Compression { return packet.FlateCompression }
+
+// pipeListener is the NetworkListener of the pipeNetwork.
+type pipeListener struct {
+	addr   string
+	conns  chan net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+// Accept waits for a connection dialed to the pipeListener.
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case c := <-l.conns:
+		return c, nil
+	}
+}
+
+// Close closes the pipeListener and frees its address. Connections accepted by the pipeListener remain
+// open.
+func (l *pipeListener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+		pipeMu.Lock()
+		delete(pipeListeners, l.addr)
+		pipeMu.Unlock()
+	})
+	return nil
+}
+
+// Addr returns the address of the pipeListener.
+func (l *pipeListener) Addr() net.Addr { return pipeAddr(l.addr) }
+
+// ID always returns 0.
+func (l *pipeListener) ID() int64 { return 0 }
+
+// PongData is a no-op.
+func (l *pipeListener) PongData([]byte) {}
+
+// pipeAddr is the net.Addr of one end of a pipe.
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn is one end of an in-memory transport created using newPipeConns. Unlike a net.Pipe, a pipeConn
+// preserves the boundaries of the data written to it: Every call to Write results in exactly one packet
+// returned by ReadPacket on the other end.
+type pipeConn struct {
+	laddr, raddr pipeAddr
+
+	in  <-chan []byte
+	out chan<- []byte
+	// remaining holds data of a packet not yet returned by Read, because the slice passed to Read was too
+	// short to hold it.
+	remaining []byte
+
+	once   *sync.Once
+	closed chan struct{}
+}
+
+// newPipeConns creates the two ends of an in-memory transport. Closing either end closes both.
+func newPipeConns(a, b pipeAddr) (*pipeConn, *pipeConn) {
+	ab, ba := make(chan []byte, 64), make(chan []byte, 64)
+	once, closed := &sync.Once{}, make(chan struct{})
+	return &pipeConn{laddr: a, raddr: b, in: ba, out: ab, once: once, closed: closed},
+		&pipeConn{laddr: b, raddr: a, in: ab, out: ba, once: once, closed: closed}
+}
+
+// ReadPacket reads the data of a single call to Write on the other end of the pipe. Data written before the
+// pipe was closed may still be read after it was closed.
+func (c *pipeConn) ReadPacket() ([]byte, error) {
+	select {
+	case data := <-c.in:
+		return data, nil
+	case <-c.closed:
+		select {
+		case data := <-c.in:
+			return data, nil
+		default:
+			return nil, net.ErrClosed
+		}
+	}
+}
+
+// Read reads data written on the other end of the pipe into b.
+func (c *pipeConn) Read(b []byte) (int, error) {
+	if len(c.remaining) == 0 {
+		data, err := c.ReadPacket()
+		if err != nil {
+			return 0, err
+		}
+		c.remaining = data
+	}
+	n := copy(b, c.remaining)
+	c.remaining = c.remaining[n:]
+	return n, nil
+}
+
+// Write writes a copy of b to the pipe, so that it may be read on the other end.
+func (c *pipeConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	case c.out <- append([]byte(nil), b...):
+		return len(b), nil
+	}
+}
+
+// Close closes both ends of the pipe.
+func (c *pipeConn) Close() error {
+	c.once.Do(func() {
+		close(c.closed)
+	})
+	return nil
+}
+
+// LocalAddr ...
+func (c *pipeConn) LocalAddr() net.Addr { return c.laddr }
+
+// RemoteAddr ...
+func (c *pipeConn) RemoteAddr() net.Addr { return c.raddr }
+
+// SetDeadline is a no-op.
+func (c *pipeConn) SetDeadline(time.Time) error { return nil }
+
+// SetReadDeadline is a no-op.
+func (c *pipeConn) SetReadDeadline(time.Time) error { return nil }
+
+// SetWriteDeadline is a no-op.
+func (c *pipeConn) SetWriteDeadline(time.Time) error { return nil }
+
+// init registers the pipe network.
+func init() {
+	RegisterNetwork("pipe", func(*slog.Logger) Network { return pipeNetwork{} })
+}
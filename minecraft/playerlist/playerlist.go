@@ -0,0 +1,165 @@
+// Package playerlist implements the player list shown in the pause menu of clients connected through a
+// minecraft.Listener. Entries are built from the identity and client data of connections, so that the skins
+// and XUIDs of players are mirrored to other players without filling out the skin fields by hand.
+package playerlist
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/skin"
+	"slices"
+	"sync"
+)
+
+// Entry builds a PlayerListEntry for the player connected through the Conn passed, using its identity data,
+// client data and entity unique ID. An error is returned if the skin in the client data could not be
+// decoded.
+func Entry(conn *minecraft.Conn) (protocol.PlayerListEntry, error) {
+	return EntryFromData(conn.IdentityData(), conn.ClientData(), conn.GameData().EntityUniqueID)
+}
+
+// EntryFromData builds a PlayerListEntry from the identity data and client data passed, for a player with the
+// entity unique ID passed. An error is returned if the identity has an invalid UUID or if the skin in the
+// client data could not be decoded.
+func EntryFromData(identity login.IdentityData, client login.ClientData, entityUniqueID int64) (protocol.PlayerListEntry, error) {
+	id, err := uuid.Parse(identity.Identity)
+	if err != nil {
+		return protocol.PlayerListEntry{}, fmt.Errorf("parse identity UUID: %w", err)
+	}
+	s, err := skin.FromClientData(client)
+	if err != nil {
+		return protocol.PlayerListEntry{}, fmt.Errorf("decode skin: %w", err)
+	}
+	return protocol.PlayerListEntry{
+		UUID:           id,
+		EntityUniqueID: entityUniqueID,
+		Username:       identity.DisplayName,
+		XUID:           identity.XUID,
+		PlatformChatID: client.PlatformOnlineID,
+		BuildPlatform:  int32(client.DeviceOS),
+		Skin:           s.Protocol(),
+	}, nil
+}
+
+// List is a player list that may be shown to any number of connections. Entries added to or removed from the
+// List are sent to all connections that it is shown to.
+// A List is safe for concurrent use.
+type List struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]protocol.PlayerListEntry
+	order   []uuid.UUID
+	viewers map[*minecraft.Conn]struct{}
+}
+
+// New creates an empty List that is not shown to any connections.
+func New() *List {
+	return &List{entries: make(map[uuid.UUID]protocol.PlayerListEntry), viewers: make(map[*minecraft.Conn]struct{})}
+}
+
+// Add adds the entries passed to the List and sends them to all connections that the List is shown to. An
+// entry with the same UUID as an entry already in the List replaces it.
+func (l *List) Add(entries ...protocol.PlayerListEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range entries {
+		if _, ok := l.entries[e.UUID]; !ok {
+			l.order = append(l.order, e.UUID)
+		}
+		l.entries[e.UUID] = e
+	}
+	l.broadcast(&packet.PlayerList{ActionType: packet.PlayerListActionAdd, Entries: entries})
+}
+
+// AddConn adds an entry for the player connected through the Conn passed to the List. It is a shorthand for
+// calling Entry and Add.
+func (l *List) AddConn(conn *minecraft.Conn) error {
+	e, err := Entry(conn)
+	if err != nil {
+		return err
+	}
+	l.Add(e)
+	return nil
+}
+
+// Remove removes the entries with the UUIDs passed from the List and from all connections that the List is
+// shown to.
+func (l *List) Remove(ids ...uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]protocol.PlayerListEntry, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := l.entries[id]; !ok {
+			continue
+		}
+		delete(l.entries, id)
+		entries = append(entries, protocol.PlayerListEntry{UUID: id})
+	}
+	if len(entries) == 0 {
+		return
+	}
+	l.order = slices.DeleteFunc(l.order, func(id uuid.UUID) bool {
+		_, ok := l.entries[id]
+		return !ok
+	})
+	l.broadcast(&packet.PlayerList{ActionType: packet.PlayerListActionRemove, Entries: entries})
+}
+
+// Entries returns all entries in the List in the order in which they were added.
+func (l *List) Entries() []protocol.PlayerListEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]protocol.PlayerListEntry, 0, len(l.order))
+	for _, id := range l.order {
+		entries = append(entries, l.entries[id])
+	}
+	return entries
+}
+
+// Show shows the List to the connection passed by sending it all entries currently in the List. Entries
+// added or removed afterward are sent to the connection until Hide is called.
+func (l *List) Show(conn *minecraft.Conn) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.viewers[conn] = struct{}{}
+	if len(l.order) == 0 {
+		return nil
+	}
+	entries := make([]protocol.PlayerListEntry, 0, len(l.order))
+	for _, id := range l.order {
+		entries = append(entries, l.entries[id])
+	}
+	return conn.WritePacket(&packet.PlayerList{ActionType: packet.PlayerListActionAdd, Entries: entries})
+}
+
+// Hide stops sending changes of the List to the connection passed and removes all entries of the List from
+// it.
+func (l *List) Hide(conn *minecraft.Conn) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.viewers[conn]; !ok {
+		return nil
+	}
+	delete(l.viewers, conn)
+	if len(l.order) == 0 {
+		return nil
+	}
+	entries := make([]protocol.PlayerListEntry, 0, len(l.order))
+	for _, id := range l.order {
+		entries = append(entries, protocol.PlayerListEntry{UUID: id})
+	}
+	return conn.WritePacket(&packet.PlayerList{ActionType: packet.PlayerListActionRemove, Entries: entries})
+}
+
+// broadcast writes the packet passed to all connections that the List is shown to. Connections that fail to
+// write the packet, generally because they were closed, stop being viewers of the List.
+func (l *List) broadcast(pk packet.Packet) {
+	for conn := range l.viewers {
+		if err := conn.WritePacket(pk); err != nil {
+			delete(l.viewers, conn)
+		}
+	}
+}
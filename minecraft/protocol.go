@@ -38,7 +38,8 @@ type Protocol interface {
 	// ConvertFromLatest converts a packet.Packet of the most recent Protocol to a slice of packet.Packets of this
 	// specific Protocol. ConvertFromLatest must be synonymous to ConvertToLatest, in that it should convert any
 	// packet.Packet to the correct one from the packet.Pool returned through a call to Packets if its payload or ID was
-	// changed in this Protocol compared to the latest one.
+	// changed in this Protocol compared to the latest one. ConvertFromLatest may be called concurrently for
+	// packets written to the same Conn by different goroutines.
 	ConvertFromLatest(pk packet.Packet, conn *Conn) []packet.Packet
 }
 
@@ -37,6 +37,18 @@ const (
 	AbilityBaseWalkSpeed = 0.1
 )
 
+const (
+	// AbilitiesVisitor are the abilities of players with the visitor permission level. Visitors may only
+	// walk around and look at the world.
+	AbilitiesVisitor = 0
+	// AbilitiesMember are the abilities of players with the member permission level, who may build, mine
+	// and interact with the world.
+	AbilitiesMember = AbilityBuild | AbilityMine | AbilityDoorsAndSwitches | AbilityOpenContainers | AbilityAttackPlayers | AbilityAttackMobs
+	// AbilitiesOperator are the abilities of players with the operator permission level, who may also run
+	// operator commands and teleport.
+	AbilitiesOperator = AbilitiesMember | AbilityOperatorCommands | AbilityTeleport
+)
+
 // AbilityData represents various data about the abilities of a player, such as ability layers or permissions.
 type AbilityData struct {
 	// EntityUniqueID is a unique identifier of the player. It appears it is not required to fill this field
@@ -52,6 +64,33 @@ type AbilityData struct {
 	Layers []AbilityLayer
 }
 
+// AbilityPreset returns the AbilityData of a player with one of the vanilla permission levels, which are the
+// PermissionLevelVisitor, PermissionLevelMember and PermissionLevelOperator constants in the packet package.
+// The AbilityData has a single base layer holding the abilities of the permission level, to which other
+// abilities, such as AbilityMayFly, may be added using AbilityLayer.Set. Any other permission level gets the
+// abilities of a member.
+func AbilityPreset(entityUniqueID int64, permissionLevel byte) AbilityData {
+	abilities, commandPermissions := uint32(AbilitiesMember), byte(0)
+	switch permissionLevel {
+	case 0:
+		abilities = AbilitiesVisitor
+	case 2:
+		abilities, commandPermissions = AbilitiesOperator, 1
+	}
+	layer := NewAbilityLayer(AbilityLayerTypeBase)
+	for ability := uint32(AbilityBuild); ability < AbilityCount; ability <<= 1 {
+		if ability != AbilityFlySpeed && ability != AbilityWalkSpeed {
+			layer.Set(ability, abilities&ability != 0)
+		}
+	}
+	return AbilityData{
+		EntityUniqueID:     entityUniqueID,
+		PlayerPermissions:  permissionLevel,
+		CommandPermissions: commandPermissions,
+		Layers:             []AbilityLayer{layer},
+	}
+}
+
 // Marshal encodes/decodes an AbilityData.
 func (x *AbilityData) Marshal(r IO) {
 	r.Int64(&x.EntityUniqueID)
@@ -84,3 +123,42 @@ func (x *AbilityLayer) Marshal(r IO) {
 	r.Float32(&x.FlySpeed)
 	r.Float32(&x.WalkSpeed)
 }
+
+// NewAbilityLayer returns an AbilityLayer of the type passed with the base fly and walk speed and no other
+// abilities set.
+func NewAbilityLayer(layerType uint16) AbilityLayer {
+	return AbilityLayer{
+		Type:      layerType,
+		Abilities: AbilityFlySpeed | AbilityWalkSpeed,
+		Values:    AbilityFlySpeed | AbilityWalkSpeed,
+		FlySpeed:  AbilityBaseFlySpeed,
+		WalkSpeed: AbilityBaseWalkSpeed,
+	}
+}
+
+// Set sets the ability passed, which is one of the Ability constants, in the layer and enables or disables
+// it. Abilities that are set override the value of the ability in layers below it.
+func (x *AbilityLayer) Set(ability uint32, enabled bool) {
+	x.Abilities |= ability
+	if enabled {
+		x.Values |= ability
+	} else {
+		x.Values &^= ability
+	}
+}
+
+// Unset removes the ability passed from the layer, so that its value is taken from layers below it.
+func (x *AbilityLayer) Unset(ability uint32) {
+	x.Abilities &^= ability
+	x.Values &^= ability
+}
+
+// IsSet checks if the ability passed is set in the layer, regardless of whether it is enabled.
+func (x AbilityLayer) IsSet(ability uint32) bool {
+	return x.Abilities&ability != 0
+}
+
+// Enabled checks if the ability passed is set and enabled in the layer.
+func (x AbilityLayer) Enabled(ability uint32) bool {
+	return x.Abilities&x.Values&ability != 0
+}
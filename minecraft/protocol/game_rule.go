@@ -1,11 +1,114 @@
 package protocol
 
+import "math"
+
 // GameRule contains game rule data.
 type GameRule struct {
-	// Name is the name of the game rule.
+	// Name is the name of the game rule. It is typically one of the GameRule constants below.
 	Name string
 	// CanBeModifiedByPlayer specifies if the game rule can be modified by the player through the in-game UI.
 	CanBeModifiedByPlayer bool
-	// Value is the new value of the game rule. This is either a bool, uint32 or float32.
+	// Value is the new value of the game rule. This is either a bool, uint32 or float32. Values of the type
+	// int, int32 and float64 are also accepted and converted when written, as long as integer values fit in a
+	// uint32.
 	Value any
 }
+
+// The names of the game rules that are present in vanilla Minecraft. These may be used as the Name of a
+// GameRule.
+const (
+	GameRuleCommandBlockOutput        = "commandblockoutput"
+	GameRuleCommandBlocksEnabled      = "commandblocksenabled"
+	GameRuleDoDaylightCycle           = "dodaylightcycle"
+	GameRuleDoEntityDrops             = "doentitydrops"
+	GameRuleDoFireTick                = "dofiretick"
+	GameRuleDoImmediateRespawn        = "doimmediaterespawn"
+	GameRuleDoInsomnia                = "doinsomnia"
+	GameRuleDoMobLoot                 = "domobloot"
+	GameRuleDoMobSpawning             = "domobspawning"
+	GameRuleDoTileDrops               = "dotiledrops"
+	GameRuleDoWeatherCycle            = "doweathercycle"
+	GameRuleDrowningDamage            = "drowningdamage"
+	GameRuleFallDamage                = "falldamage"
+	GameRuleFireDamage                = "firedamage"
+	GameRuleFreezeDamage              = "freezedamage"
+	GameRuleFunctionCommandLimit      = "functioncommandlimit"
+	GameRuleKeepInventory             = "keepinventory"
+	GameRuleMaxCommandChainLength     = "maxcommandchainlength"
+	GameRuleMobGriefing               = "mobgriefing"
+	GameRuleNaturalRegeneration       = "naturalregeneration"
+	GameRulePlayersSleepingPercentage = "playerssleepingpercentage"
+	GameRuleProjectilesCanBreakBlocks = "projectilescanbreakblocks"
+	GameRulePVP                       = "pvp"
+	GameRuleRandomTickSpeed           = "randomtickspeed"
+	GameRuleRecipesUnlock             = "recipesunlock"
+	GameRuleRespawnBlocksExplode      = "respawnblocksexplode"
+	GameRuleSendCommandFeedback       = "sendcommandfeedback"
+	GameRuleShowBorderEffect          = "showbordereffect"
+	GameRuleShowCoordinates           = "showcoordinates"
+	GameRuleShowDaysPlayed            = "showdaysplayed"
+	GameRuleShowDeathMessages         = "showdeathmessages"
+	GameRuleShowRecipeMessages        = "showrecipemessages"
+	GameRuleShowTags                  = "showtags"
+	GameRuleSpawnRadius               = "spawnradius"
+	GameRuleTNTExplodes               = "tntexplodes"
+	GameRuleTNTExplosionDropDecay     = "tntexplosiondropdecay"
+	GameRuleExperimentalGameplay      = "experimentalgameplay"
+)
+
+// BoolGameRule returns a GameRule with the name passed that holds a bool value. If modifiable is true, the
+// player is able to change the game rule through the in-game UI.
+func BoolGameRule(name string, value, modifiable bool) GameRule {
+	return GameRule{Name: name, Value: value, CanBeModifiedByPlayer: modifiable}
+}
+
+// IntGameRule returns a GameRule with the name passed that holds an integer value. If modifiable is true, the
+// player is able to change the game rule through the in-game UI.
+func IntGameRule(name string, value uint32, modifiable bool) GameRule {
+	return GameRule{Name: name, Value: value, CanBeModifiedByPlayer: modifiable}
+}
+
+// FloatGameRule returns a GameRule with the name passed that holds a float value. If modifiable is true, the
+// player is able to change the game rule through the in-game UI.
+func FloatGameRule(name string, value float32, modifiable bool) GameRule {
+	return GameRule{Name: name, Value: value, CanBeModifiedByPlayer: modifiable}
+}
+
+// Bool returns the value of the GameRule as a bool. False is returned as second value if the GameRule does
+// not hold a bool value.
+func (x GameRule) Bool() (bool, bool) {
+	v, ok := x.Value.(bool)
+	return v, ok
+}
+
+// Int returns the value of the GameRule as a uint32. False is returned as second value if the GameRule does
+// not hold an integer value or if the value held does not fit in a uint32.
+func (x GameRule) Int() (uint32, bool) {
+	switch v := x.Value.(type) {
+	case uint32:
+		return v, true
+	case int:
+		if v < 0 || int64(v) > math.MaxUint32 {
+			return 0, false
+		}
+		return uint32(v), true
+	case int32:
+		if v < 0 {
+			return 0, false
+		}
+		return uint32(v), true
+	}
+	return 0, false
+}
+
+// Float returns the value of the GameRule as a float32. False is returned as second value if the GameRule
+// does not hold a float value.
+func (x GameRule) Float() (float32, bool) {
+	switch v := x.Value.(type) {
+	case float32:
+		return v, true
+	case float64:
+		return float32(v), true
+	}
+	return 0, false
+}
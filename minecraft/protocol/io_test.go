@@ -0,0 +1,182 @@
+package protocol_test
+
+import (
+	"bytes"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"math"
+	"testing"
+)
+
+// TestPrimitivesRoundTrip writes varints, strings and byte slices using a Writer and checks if a Reader reads
+// back the same values.
+func TestPrimitivesRoundTrip(t *testing.T) {
+	u32s := []uint32{0, 1, 0x7f, 0x80, 0x3fff, 0x4000, math.MaxUint32}
+	i32s := []int32{0, 1, -1, 63, -64, 64, math.MaxInt32, math.MinInt32}
+	u64s := []uint64{0, 0x7f, 0x80, math.MaxUint32 + 1, math.MaxUint64}
+	i64s := []int64{0, -1, math.MaxInt64, math.MinInt64}
+	strs := []string{"", "a", "gophertunnel", string(make([]byte, 300))}
+
+	buf := new(bytes.Buffer)
+	w := protocol.NewWriter(buf, 0)
+	for i := range u32s {
+		w.Varuint32(&u32s[i])
+	}
+	for i := range i32s {
+		w.Varint32(&i32s[i])
+	}
+	for i := range u64s {
+		w.Varuint64(&u64s[i])
+	}
+	for i := range i64s {
+		w.Varint64(&i64s[i])
+	}
+	for i := range strs {
+		w.String(&strs[i])
+		b := []byte(strs[i])
+		w.ByteSlice(&b)
+	}
+
+	r := protocol.NewReader(buf, 0, false)
+	for _, want := range u32s {
+		var v uint32
+		if r.Varuint32(&v); v != want {
+			t.Errorf("Varuint32: expected %v, got %v", want, v)
+		}
+	}
+	for _, want := range i32s {
+		var v int32
+		if r.Varint32(&v); v != want {
+			t.Errorf("Varint32: expected %v, got %v", want, v)
+		}
+	}
+	for _, want := range u64s {
+		var v uint64
+		if r.Varuint64(&v); v != want {
+			t.Errorf("Varuint64: expected %v, got %v", want, v)
+		}
+	}
+	for _, want := range i64s {
+		var v int64
+		if r.Varint64(&v); v != want {
+			t.Errorf("Varint64: expected %v, got %v", want, v)
+		}
+	}
+	for _, want := range strs {
+		var s string
+		var b []byte
+		if r.String(&s); s != want {
+			t.Errorf("String: expected %q, got %q", want, s)
+		}
+		if r.ByteSlice(&b); string(b) != want {
+			t.Errorf("ByteSlice: expected %q, got %q", want, b)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected all bytes to be read, %v left", buf.Len())
+	}
+}
+
+// TestStringTruncated checks if reading a string with a length larger than the remaining data panics
+// instead of returning a partially filled string.
+func TestStringTruncated(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{0xff, 0xff, 0xff, 0xff, 0x07, 'a'})
+	defer func() {
+		if recover() == nil {
+			t.Error("expected reading a truncated string to panic")
+		}
+	}()
+	var s string
+	protocol.NewReader(buf, 0, false).String(&s)
+}
+
+// BenchmarkWriterVaruint32 measures writing a varuint32 of three bytes.
+func BenchmarkWriterVaruint32(b *testing.B) {
+	buf := new(bytes.Buffer)
+	w := protocol.NewWriter(buf, 0)
+	v := uint32(1 << 20)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		w.Varuint32(&v)
+	}
+}
+
+// BenchmarkReaderVaruint32 measures reading a varuint32 of three bytes.
+func BenchmarkReaderVaruint32(b *testing.B) {
+	data := []byte{0x80, 0x80, 0x40}
+	buf := new(bytes.Buffer)
+	r := protocol.NewReader(buf, 0, false)
+	var v uint32
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.Write(data)
+		r.Varuint32(&v)
+	}
+}
+
+// BenchmarkWriterVarint64 measures writing a varint64 of five bytes.
+func BenchmarkWriterVarint64(b *testing.B) {
+	buf := new(bytes.Buffer)
+	w := protocol.NewWriter(buf, 0)
+	v := int64(-1 << 30)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		w.Varint64(&v)
+	}
+}
+
+// BenchmarkWriterString measures writing a short string.
+func BenchmarkWriterString(b *testing.B) {
+	buf := new(bytes.Buffer)
+	w := protocol.NewWriter(buf, 0)
+	s := "minecraft:stone"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		w.String(&s)
+	}
+}
+
+// BenchmarkReaderString measures reading a short string.
+func BenchmarkReaderString(b *testing.B) {
+	data := append([]byte{15}, "minecraft:stone"...)
+	buf := new(bytes.Buffer)
+	r := protocol.NewReader(buf, 0, false)
+	var s string
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		buf.Write(data)
+		r.String(&s)
+	}
+}
+
+// TestGameRuleInt checks if GameRule.Int converts int and int32 values that fit in a uint32 and rejects
+// values that do not.
+func TestGameRuleInt(t *testing.T) {
+	for _, test := range []struct {
+		value any
+		want  uint32
+		ok    bool
+	}{
+		{value: uint32(math.MaxUint32), want: math.MaxUint32, ok: true},
+		{value: 20, want: 20, ok: true},
+		{value: int32(3), want: 3, ok: true},
+		{value: -1, ok: false},
+		{value: int32(math.MinInt32), ok: false},
+		{value: math.MaxUint32 + 1, ok: false},
+		{value: true, ok: false},
+	} {
+		v, ok := protocol.GameRule{Name: "test", Value: test.value}.Int()
+		if ok != test.ok || v != test.want {
+			t.Errorf("Int() of %T(%v): expected (%v, %v), got (%v, %v)", test.value, test.value, test.want, test.ok, v, ok)
+		}
+	}
+}
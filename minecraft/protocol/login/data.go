@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 	"golang.org/x/text/language"
 	"net"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -68,6 +70,46 @@ func (data IdentityData) Validate() error {
 	return nil
 }
 
+const (
+	// UIProfileClassic is the UIProfile of a client using the 'Classic' UI, which is typically used on
+	// desktop devices.
+	UIProfileClassic = iota
+	// UIProfilePocket is the UIProfile of a client using the 'Pocket' UI, which is typically used on mobile
+	// devices.
+	UIProfilePocket
+)
+
+const (
+	// GUIScaleDefault is the GUIScale of a client that uses the default GUI scale.
+	GUIScaleDefault = 0
+	// GUIScaleSmaller is the GUIScale of a client that uses a GUI scale one step smaller than the default.
+	GUIScaleSmaller = -1
+	// GUIScaleSmallest is the GUIScale of a client that uses a GUI scale two steps smaller than the default.
+	GUIScaleSmallest = -2
+)
+
+const (
+	// ArmSizeWide is the ArmSize of a skin with wide arms, which is generally used for male skins.
+	ArmSizeWide = "wide"
+	// ArmSizeSlim is the ArmSize of a skin with slim arms, which is generally used for female skins.
+	ArmSizeSlim = "slim"
+)
+
+const (
+	// MemoryTierUndetermined is the MemoryTier of a client of which the memory tier could not be determined.
+	MemoryTierUndetermined = iota
+	// MemoryTierSuperLow is the MemoryTier of a client with less than ~1.5GB of memory.
+	MemoryTierSuperLow
+	// MemoryTierLow is the MemoryTier of a client with less than ~2GB of memory.
+	MemoryTierLow
+	// MemoryTierMid is the MemoryTier of a client with less than ~4GB of memory.
+	MemoryTierMid
+	// MemoryTierHigh is the MemoryTier of a client with less than ~8GB of memory.
+	MemoryTierHigh
+	// MemoryTierSuperHigh is the MemoryTier of a client with more than ~8GB of memory.
+	MemoryTierSuperHigh
+)
+
 // ClientData is a container of client specific data of a Login packet. It holds data such as the skin of a
 // player, but also its language code and device information.
 type ClientData struct {
@@ -88,23 +130,23 @@ type ClientData struct {
 	// ClientRandomID is a random client ID number generated for the client. It usually remains consistent
 	// through sessions and through game restarts.
 	ClientRandomID int64 `json:"ClientRandomId"`
-	// CurrentInputMode is the input mode used by the client. It is 1 for mobile and win10, but is different
-	// for console input.
+	// CurrentInputMode is the input mode used by the client. It is one of the packet.InputMode constants.
 	CurrentInputMode int
-	// DefaultInputMode is the default input mode used by the device.
+	// DefaultInputMode is the default input mode used by the device. It is one of the packet.InputMode
+	// constants.
 	DefaultInputMode int
 	// DeviceModel is a string indicating the device model used by the player. At the moment, it appears that
 	// this name is always '(Standard system devices) System devices'.
 	DeviceModel string
-	// DeviceOS is a numerical ID indicating the OS of the device.
+	// DeviceOS is a numerical ID indicating the OS of the device. It is one of the protocol.DeviceOS
+	// constants.
 	DeviceOS protocol.DeviceOS
 	// DeviceID is usually a UUID specific to the device. A different user will have the same UUID for this.
 	// DeviceID is not guaranteed to always be a UUID. It is a base64 encoded string under some circumstances.
 	DeviceID string `json:"DeviceId"`
 	// GameVersion is the game version of the player that attempted to join, for example '1.11.0'.
 	GameVersion string
-	// GUIScale is the GUI scale of the player. It is by default 0, and is otherwise -1 or -2 for a smaller
-	// GUI scale than usual.
+	// GUIScale is the GUI scale of the player. It is one of the GUIScale constants.
 	GUIScale int `json:"GuiScale"`
 	// IsEditorMode is a value to dictate if the player is in editor mode.
 	IsEditorMode bool
@@ -167,8 +209,7 @@ type ClientData struct {
 	// SkinColour is a hex representation (including #) of the base colour of the skin. An example of the
 	// colour sent here is '#b37b62'.
 	SkinColour string `json:"SkinColor"`
-	// ArmSize is the size of the arms of the player's model. This is either 'wide' (generally for male skins)
-	// or 'slim' (generally for female skins).
+	// ArmSize is the size of the arms of the player's model. This is either ArmSizeWide or ArmSizeSlim.
 	ArmSize string
 	// PersonaPieces is a list of all persona pieces that the skin is composed of.
 	PersonaPieces []PersonaPiece
@@ -184,7 +225,7 @@ type ClientData struct {
 	// Although this field is obviously here for a reason, allowing this is too dangerous and should never be
 	// done.
 	ThirdPartyNameOnly bool
-	// UIProfile is the UI profile used. For the 'Pocket' UI, this is 1. For the 'Classic' UI, this is 0.
+	// UIProfile is the UI profile used. It is either UIProfileClassic or UIProfilePocket.
 	UIProfile int
 	// TrustedSkin is a boolean indicating if the skin the client is using is trusted.
 	TrustedSkin bool
@@ -195,15 +236,9 @@ type ClientData struct {
 	CompatibleWithClientSideChunkGen bool
 	// MaxViewDistance is the highest render distance that the client's hardware can handle.
 	MaxViewDistance int
-	// MemoryTier is the tier of memory that the client's hardware has. This is a number between 0 and 5. The
-	// full calculation of this tier is currently unknown but the following is a rough estimate from a
-	// developer at Mojang:
-	// 0 - Undetermined
-	// 1 - Super Low, less than ~1.5GB of memory
-	// 2 - Low, less than ~2GB of memory
-	// 3 - Mid, less than ~4GB of memory
-	// 4 - High, less than ~8GB of memory
-	// 5 - Super High, more than ~8GB of memory
+	// MemoryTier is the tier of memory that the client's hardware has. It is one of the MemoryTier
+	// constants. The full calculation of this tier is currently unknown, so the amounts of memory documented
+	// on the constants are a rough estimate from a developer at Mojang.
 	MemoryTier int
 	// PlatformType is the type of platform the client is running.
 	PlatformType int
@@ -286,70 +321,190 @@ type SkinAnimation struct {
 // dots.
 var checkVersion = regexp.MustCompile("[0-9.]").MatchString
 
-// Validate validates the client data. It returns an error if any of the fields checked did not carry a valid
-// value.
+// ClientDataLimits holds limits on the size of the skin related fields of ClientData. Skins are by far the
+// largest part of the client data, and oversized skins are a common way of crashing servers or other clients,
+// so ClientData.ValidateLimits rejects client data exceeding these limits before decoding the skin.
+// Fields left zero are set to their default value.
+type ClientDataLimits struct {
+	// MaxSkinWidth and MaxSkinHeight are the maximum dimensions of the skin image. If zero, a maximum of
+	// 512x512 is used.
+	MaxSkinWidth, MaxSkinHeight int
+	// MaxCapeWidth and MaxCapeHeight are the maximum dimensions of the cape image. If zero, a maximum of
+	// 256x256 is used.
+	MaxCapeWidth, MaxCapeHeight int
+	// MaxGeometrySize is the maximum size in bytes of the decoded SkinGeometry JSON. If zero, a maximum of 2
+	// MiB is used.
+	MaxGeometrySize int
+	// MaxAnimations is the maximum amount of skin animations. If zero, a maximum of 16 is used.
+	MaxAnimations int
+	// MaxAnimationFrames is the maximum amount of frames of a single skin animation. The image of an
+	// animation may be at most MaxSkinWidth wide and MaxSkinHeight*MaxAnimationFrames high. If zero, a
+	// maximum of 64 is used.
+	MaxAnimationFrames int
+}
+
+// withDefaults returns the ClientDataLimits with all zero fields set to their default value.
+func (l ClientDataLimits) withDefaults() ClientDataLimits {
+	setDefault := func(v *int, def int) {
+		if *v == 0 {
+			*v = def
+		}
+	}
+	setDefault(&l.MaxSkinWidth, 512)
+	setDefault(&l.MaxSkinHeight, 512)
+	setDefault(&l.MaxCapeWidth, 256)
+	setDefault(&l.MaxCapeHeight, 256)
+	setDefault(&l.MaxGeometrySize, 2<<20)
+	setDefault(&l.MaxAnimations, 16)
+	setDefault(&l.MaxAnimationFrames, 64)
+	return l
+}
+
+// FieldError is returned by ClientData.Validate and ClientData.ValidateLimits if a field of the ClientData
+// carries an invalid value.
+type FieldError struct {
+	// Field is the name of the field of ClientData that was invalid, such as 'SkinData'. For fields of
+	// skin animations, it holds the index of the animation, such as 'AnimatedImageData[1].Image'.
+	Field string
+	// Err describes why the value of the field was invalid.
+	Err error
+}
+
+// Error ...
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("%v: %v", err.Field, err.Err)
+}
+
+// Unwrap returns FieldError.Err.
+func (err *FieldError) Unwrap() error {
+	return err.Err
+}
+
+// fieldErr returns a *FieldError for the field passed with an error formatted using the format and arguments
+// passed.
+func fieldErr(field, format string, a ...any) error {
+	return &FieldError{Field: field, Err: fmt.Errorf(format, a...)}
+}
+
+// Validate validates the client data using the default ClientDataLimits. It returns an error if any of the
+// fields checked did not carry a valid value. The error returned is a *FieldError.
 func (data ClientData) Validate() error {
-	if data.DeviceOS <= 0 || data.DeviceOS > 15 {
-		return fmt.Errorf("DeviceOS must carry a value between 1 and 15, but got %v", data.DeviceOS)
+	return data.ValidateLimits(ClientDataLimits{})
+}
+
+// ValidateLimits validates the client data like Validate, but rejects skins exceeding the ClientDataLimits
+// passed. The dimensions and encoded sizes of images are checked before any of them are decoded.
+func (data ClientData) ValidateLimits(limits ClientDataLimits) error {
+	limits = limits.withDefaults()
+	if data.DeviceOS < protocol.DeviceAndroid || data.DeviceOS > protocol.DeviceLinux {
+		return fieldErr("DeviceOS", "must carry a value between %v and %v, but got %v", protocol.DeviceAndroid, protocol.DeviceLinux, data.DeviceOS)
+	}
+	// An input mode of 0 is sent by clients that did not determine their input mode yet.
+	if data.CurrentInputMode < 0 || data.CurrentInputMode > packet.InputModeMotionController {
+		return fieldErr("CurrentInputMode", "must be between 0-%v, but got %v", packet.InputModeMotionController, data.CurrentInputMode)
+	}
+	if data.DefaultInputMode < 0 || data.DefaultInputMode > packet.InputModeMotionController {
+		return fieldErr("DefaultInputMode", "must be between 0-%v, but got %v", packet.InputModeMotionController, data.DefaultInputMode)
+	}
+	if data.GUIScale < GUIScaleSmallest || data.GUIScale > GUIScaleDefault {
+		return fieldErr("GUIScale", "must be between %v-%v, but got %v", GUIScaleSmallest, GUIScaleDefault, data.GUIScale)
+	}
+	if data.MemoryTier < MemoryTierUndetermined || data.MemoryTier > MemoryTierSuperHigh {
+		return fieldErr("MemoryTier", "must be between %v-%v, but got %v", MemoryTierUndetermined, MemoryTierSuperHigh, data.MemoryTier)
+	}
+	if data.ArmSize != "" && data.ArmSize != ArmSizeWide && data.ArmSize != ArmSizeSlim {
+		return fieldErr("ArmSize", "must be %q, %q or empty, but got %q", ArmSizeWide, ArmSizeSlim, data.ArmSize)
 	}
 	if !checkVersion(data.GameVersion) {
-		return fmt.Errorf("GameVersion must only contain dots and numbers, but got %v", data.GameVersion)
+		return fieldErr("GameVersion", "must only contain dots and numbers, but got %v", data.GameVersion)
 	}
 	if _, err := language.Parse(strings.Replace(data.LanguageCode, "_", "-", 1)); err != nil {
-		return fmt.Errorf("LanguageCode must be a valid BCP-47 ISO language code, but got %v", data.LanguageCode)
+		return fieldErr("LanguageCode", "must be a valid BCP-47 ISO language code, but got %v", data.LanguageCode)
 	}
 	if _, err := uuid.Parse(data.PlatformOfflineID); err != nil && len(data.PlatformOfflineID) != 0 {
-		return fmt.Errorf("PlatformOfflineID must be parseable as a valid UUID or empty, but got %v", data.PlatformOfflineID)
+		return fieldErr("PlatformOfflineID", "must be parseable as a valid UUID or empty, but got %v", data.PlatformOfflineID)
 	}
 	if _, err := strconv.ParseUint(data.PlatformOnlineID, 10, 64); err != nil && len(data.PlatformOnlineID) != 0 {
-		return fmt.Errorf("PlatformOnlineID must be parseable as an int64 or empty, but got %v", data.PlatformOnlineID)
+		return fieldErr("PlatformOnlineID", "must be parseable as an int64 or empty, but got %v", data.PlatformOnlineID)
 	}
 	if _, err := uuid.Parse(data.SelfSignedID); err != nil {
-		return fmt.Errorf("SelfSignedID must be parseable as a valid UUID, but got %v", data.SelfSignedID)
+		return fieldErr("SelfSignedID", "must be parseable as a valid UUID, but got %v", data.SelfSignedID)
 	}
 	if _, err := net.ResolveUDPAddr("udp", data.ServerAddress); err != nil {
-		return fmt.Errorf("ServerAddress must be resolveable as a UDP address, but got %v", data.ServerAddress)
+		return fieldErr("ServerAddress", "must be resolveable as a UDP address, but got %v", data.ServerAddress)
+	}
+	if err := checkDimensions(data.SkinImageWidth, data.SkinImageHeight, limits.MaxSkinWidth, limits.MaxSkinHeight); err != nil {
+		return &FieldError{Field: "SkinImageWidth", Err: err}
 	}
 	if err := base64DecLength(data.SkinData, data.SkinImageHeight*data.SkinImageWidth*4); err != nil {
-		return fmt.Errorf("SkinData is invalid: %w", err)
+		return &FieldError{Field: "SkinData", Err: err}
+	}
+	if err := checkDimensions(data.CapeImageWidth, data.CapeImageHeight, limits.MaxCapeWidth, limits.MaxCapeHeight); err != nil {
+		return &FieldError{Field: "CapeImageWidth", Err: err}
 	}
 	if err := base64DecLength(data.CapeData, data.CapeImageHeight*data.CapeImageWidth*4); err != nil {
-		return fmt.Errorf("CapeData is invalid: %w", err)
+		return &FieldError{Field: "CapeData", Err: err}
 	}
 	for _, s := range data.PlayFabID {
 		if (s < '0' || s > '9') && (s < 'a' || s > 'f') {
-			return fmt.Errorf("PlayFabID must consist of hex characters, got %v", data.PlayFabID)
+			return fieldErr("PlayFabID", "must consist of hex characters, got %v", data.PlayFabID)
 		}
 	}
-	for _, anim := range data.AnimatedImageData {
+	if len(data.AnimatedImageData) > limits.MaxAnimations {
+		return fieldErr("AnimatedImageData", "at most %v animations are allowed, got %v", limits.MaxAnimations, len(data.AnimatedImageData))
+	}
+	for i, anim := range data.AnimatedImageData {
+		if anim.Frames < 0 || anim.Frames > float64(limits.MaxAnimationFrames) {
+			return fieldErr(fmt.Sprintf("AnimatedImageData[%v].Frames", i), "must be between 0 and %v, got %v", limits.MaxAnimationFrames, anim.Frames)
+		}
+		if err := checkDimensions(anim.ImageWidth, anim.ImageHeight, limits.MaxSkinWidth, limits.MaxSkinHeight*limits.MaxAnimationFrames); err != nil {
+			return &FieldError{Field: fmt.Sprintf("AnimatedImageData[%v].ImageWidth", i), Err: err}
+		}
 		if err := base64DecLength(anim.Image, anim.ImageHeight*anim.ImageWidth*4); err != nil {
-			return fmt.Errorf("invalid animated image data: %w", err)
+			return &FieldError{Field: fmt.Sprintf("AnimatedImageData[%v].Image", i), Err: err}
 		}
 		if anim.Type < 0 || anim.Type > 3 {
-			return fmt.Errorf("invalid animation type: %v", anim.Type)
+			return fieldErr(fmt.Sprintf("AnimatedImageData[%v].Type", i), "must be between 0-3, but got %v", anim.Type)
 		}
 	}
+	if n := base64.StdEncoding.DecodedLen(len(data.SkinGeometry)); n > limits.MaxGeometrySize+2 {
+		return fieldErr("SkinGeometry", "decoded size of %v bytes exceeds maximum of %v bytes", n, limits.MaxGeometrySize)
+	}
 	if geomData, err := base64.StdEncoding.DecodeString(data.SkinGeometry); err != nil {
-		return fmt.Errorf("SkinGeometry was not a valid base64 string: %w", err)
+		return fieldErr("SkinGeometry", "not a valid base64 string: %w", err)
+	} else if len(geomData) > limits.MaxGeometrySize {
+		return fieldErr("SkinGeometry", "decoded size of %v bytes exceeds maximum of %v bytes", len(geomData), limits.MaxGeometrySize)
 	} else if len(geomData) != 0 {
 		m := make(map[string]any)
 		if err := json.Unmarshal(geomData, &m); err != nil {
-			return fmt.Errorf("SkinGeometry base64 decoded was not a valid JSON string: %w", err)
+			return fieldErr("SkinGeometry", "base64 decoded was not a valid JSON string: %w", err)
 		}
 	}
 	b, err := base64.StdEncoding.DecodeString(data.SkinResourcePatch)
 	if err != nil {
-		return fmt.Errorf("SkinResourcePatch was not a valid base64 string: %w", err)
+		return fieldErr("SkinResourcePatch", "not a valid base64 string: %w", err)
 	}
 	m := make(map[string]any)
 	if err := json.Unmarshal(b, &m); err != nil {
-		return fmt.Errorf("SkinResourcePatch base64 decoded was not a valid JSON string: %w", err)
+		return fieldErr("SkinResourcePatch", "base64 decoded was not a valid JSON string: %w", err)
 	}
 	if data.SkinID == "" {
-		return fmt.Errorf("SkinID must not be an empty string")
+		return fieldErr("SkinID", "must not be an empty string")
 	}
 	if data.UIProfile < 0 || data.UIProfile > 2 {
-		return fmt.Errorf("UIProfile must be between 0-2, but got %v", data.UIProfile)
+		return fieldErr("UIProfile", "must be between 0-2, but got %v", data.UIProfile)
+	}
+	return nil
+}
+
+// checkDimensions checks if the width and height passed are not negative and do not exceed the maximum width
+// and height passed.
+func checkDimensions(width, height, maxWidth, maxHeight int) error {
+	if width < 0 || height < 0 {
+		return fmt.Errorf("image dimensions must not be negative, got %vx%v", width, height)
+	}
+	if width > maxWidth || height > maxHeight {
+		return fmt.Errorf("image dimensions %vx%v exceed maximum of %vx%v", width, height, maxWidth, maxHeight)
 	}
 	return nil
 }
@@ -357,6 +512,11 @@ func (data ClientData) Validate() error {
 // base64DecLength decodes the base64 data passed and checks if its length is one of the valid lengths
 // passed. If either of these checks fails, an error is returned.
 func base64DecLength(base64Data string, validLengths ...int) error {
+	// Reject data that is too large before decoding it. DecodedLen may exceed the actual decoded length by
+	// up to 2 bytes due to padding.
+	if n := base64.StdEncoding.DecodedLen(len(base64Data)); n > slices.Max(validLengths)+2 {
+		return fmt.Errorf("invalid size: got at least %v, expected one of %v", n-2, validLengths)
+	}
 	data, err := base64.StdEncoding.DecodeString(base64Data)
 	if err != nil {
 		return fmt.Errorf("decode base64 data: %w", err)
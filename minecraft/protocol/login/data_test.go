@@ -0,0 +1,68 @@
+package login
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"testing"
+)
+
+// testClientData returns client data that passes ClientData.Validate.
+func testClientData() ClientData {
+	return ClientData{
+		DeviceOS:          protocol.DeviceAndroid,
+		GameVersion:       "1.21.0",
+		LanguageCode:      "en_US",
+		SelfSignedID:      uuid.NewString(),
+		ServerAddress:     "127.0.0.1:19132",
+		SkinID:            "Custom",
+		SkinImageWidth:    64,
+		SkinImageHeight:   64,
+		SkinData:          base64.StdEncoding.EncodeToString(make([]byte, 64*64*4)),
+		SkinResourcePatch: base64.StdEncoding.EncodeToString([]byte(`{"geometry": {"default": "geometry.humanoid.custom"}}`)),
+		CurrentInputMode:  packet.InputModeTouch,
+		DefaultInputMode:  packet.InputModeTouch,
+		GUIScale:          GUIScaleSmaller,
+		MemoryTier:        MemoryTierHigh,
+		ArmSize:           ArmSizeSlim,
+		UIProfile:         UIProfilePocket,
+	}
+}
+
+// TestClientDataValidate checks if ClientData.Validate rejects values of the enum fields of the client data
+// that are not one of their constants.
+func TestClientDataValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(data *ClientData)
+		field  string
+	}{
+		{name: "valid", modify: func(*ClientData) {}},
+		{name: "device os", modify: func(data *ClientData) { data.DeviceOS = protocol.DeviceLinux + 1 }, field: "DeviceOS"},
+		{name: "current input mode", modify: func(data *ClientData) { data.CurrentInputMode = packet.InputModeMotionController + 1 }, field: "CurrentInputMode"},
+		{name: "default input mode", modify: func(data *ClientData) { data.DefaultInputMode = -1 }, field: "DefaultInputMode"},
+		{name: "gui scale", modify: func(data *ClientData) { data.GUIScale = GUIScaleSmallest - 1 }, field: "GUIScale"},
+		{name: "memory tier", modify: func(data *ClientData) { data.MemoryTier = MemoryTierSuperHigh + 1 }, field: "MemoryTier"},
+		{name: "arm size", modify: func(data *ClientData) { data.ArmSize = "thick" }, field: "ArmSize"},
+		{name: "empty arm size", modify: func(data *ClientData) { data.ArmSize = "" }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := testClientData()
+			test.modify(&data)
+			err := data.Validate()
+			var fieldErr *FieldError
+			if test.field == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &fieldErr) || fieldErr.Field != test.field {
+				t.Fatalf("expected error for field %v, got %v", test.field, err)
+			}
+		})
+	}
+}
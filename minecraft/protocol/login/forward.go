@@ -0,0 +1,161 @@
+package login
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"slices"
+	"time"
+)
+
+// forwardClaims holds the claims of the token that a proxy appends to the chain of a login request it
+// forwards to a backend server.
+type forwardClaims struct {
+	jwt.Claims
+
+	// IdentityPublicKey holds the serialised public key of the proxy's connection to the backend. The client
+	// data and the handshake are verified with this key rather than the key of the original client.
+	IdentityPublicKey string `json:"identityPublicKey"`
+	// ChainHash is the base64 encoded SHA-256 hash of the last token of the original chain. It binds the
+	// forwarding token to that chain, so that it cannot be appended to any other chain.
+	ChainHash string `json:"chainHash"`
+}
+
+// forwardValidity is the duration for which a forwarding token is valid after it was issued.
+const forwardValidity = time.Minute * 5
+
+// EncodeForwarded creates a login request that forwards the chain of the original login request passed, as
+// obtained from a client connecting to a proxy, to a backend server. Because the proxy does not hold the
+// private key of the client, it cannot sign the client data or complete the handshake with the key in the
+// original chain. Instead, EncodeForwarded appends a token to the chain, signed by the forwardingKey of the
+// proxy, that hands over the identity to the sessionKey passed. The client data is signed with the
+// sessionKey, which must also be the key used for the encryption of the connection to the backend.
+// The chain of the original request is left untouched, so that a backend using a ForwardVerifier that
+// trusts the public key of the forwardingKey can still verify that the identity was signed by Mojang.
+func EncodeForwarded(loginRequest []byte, data ClientData, forwardingKey, sessionKey *ecdsa.PrivateKey) ([]byte, error) {
+	req, err := parseLoginRequest(loginRequest)
+	if err != nil {
+		return nil, fmt.Errorf("parse login request: %w", err)
+	}
+	if len(req.Chain) != 1 && len(req.Chain) != 3 {
+		return nil, fmt.Errorf("forward login request: unexpected chain length %v", len(req.Chain))
+	}
+	forwardSigner, err := jose.NewSigner(jose.SigningKey{Key: forwardingKey, Algorithm: jose.ES384}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{"x5u": MarshalPublicKey(&forwardingKey.PublicKey)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create forwarding signer: %w", err)
+	}
+	t := time.Now()
+	forwardJWT, err := jwt.Signed(forwardSigner).Claims(forwardClaims{
+		Claims: jwt.Claims{
+			IssuedAt:  jwt.NewNumericDate(t),
+			NotBefore: jwt.NewNumericDate(t.Add(-time.Minute)),
+			Expiry:    jwt.NewNumericDate(t.Add(forwardValidity)),
+		},
+		IdentityPublicKey: MarshalPublicKey(&sessionKey.PublicKey),
+		ChainHash:         chainHash(req.Chain),
+	}).CompactSerialize()
+	if err != nil {
+		return nil, fmt.Errorf("sign forwarding token: %w", err)
+	}
+
+	sessionSigner, err := jose.NewSigner(jose.SigningKey{Key: sessionKey, Algorithm: jose.ES384}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{"x5u": MarshalPublicKey(&sessionKey.PublicKey)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create session signer: %w", err)
+	}
+	rawToken, err := jwt.Signed(sessionSigner).Claims(data).CompactSerialize()
+	if err != nil {
+		return nil, fmt.Errorf("sign client data: %w", err)
+	}
+	return encodeRequest(&request{Chain: append(slices.Clone(req.Chain), forwardJWT), RawToken: rawToken}), nil
+}
+
+// chainHash returns the base64 encoded SHA-256 hash of the last token in the chain passed.
+func chainHash(c []string) string {
+	sum := sha256.Sum256([]byte(c[len(c)-1]))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ForwardVerifier is an Authenticator used by backend servers behind a proxy that forwards login requests
+// using EncodeForwarded. It verifies the original chain using its Verifier and the forwarding token appended
+// by the proxy using the ForwarderKeys, so that the backend can still check if players were authenticated with
+// XBOX Live.
+type ForwardVerifier struct {
+	// Verifier verifies the original chain of forwarded login requests, and of direct login requests if
+	// AllowDirect is true.
+	Verifier
+	// ForwarderKeys is the set of public keys of the proxies trusted to forward login requests. Forwarded
+	// requests signed by any other key are rejected.
+	ForwarderKeys []*ecdsa.PublicKey
+	// AllowDirect specifies if login requests that were not forwarded by a proxy are accepted. If false,
+	// only forwarded login requests are accepted.
+	AllowDirect bool
+}
+
+// VerifyChain verifies the chain passed. If the chain was forwarded, the original chain is verified using the
+// Verifier of the ForwardVerifier, after which the forwarding token is verified using the ForwarderKeys. The
+// public key in the AuthResult returned is then the session key of the proxy.
+func (v ForwardVerifier) VerifyChain(chain []string) (AuthResult, error) {
+	if !forwarded(chain) {
+		if !v.AllowDirect {
+			return AuthResult{}, fmt.Errorf("verify chain: login request was not forwarded by a proxy")
+		}
+		return v.Verifier.VerifyChain(chain)
+	}
+	original := chain[:len(chain)-1]
+	res, err := v.Verifier.VerifyChain(original)
+	if err != nil {
+		return res, err
+	}
+
+	tok, err := ParseToken(chain[len(chain)-1])
+	if err != nil {
+		return res, fmt.Errorf("parse forwarding token: %w", err)
+	}
+	//lint:ignore S1005 Double assignment is done explicitly to prevent panics.
+	raw, _ := tok.Headers[0].ExtraHeaders["x5u"]
+	forwarderKey := new(ecdsa.PublicKey)
+	if err := parseAsKey(raw, forwarderKey); err != nil {
+		return res, fmt.Errorf("parse forwarding token x5u: %w", err)
+	}
+	if !slices.ContainsFunc(v.ForwarderKeys, func(k *ecdsa.PublicKey) bool { return k != nil && k.Equal(forwarderKey) }) {
+		return res, fmt.Errorf("verify forwarding token: forwarder key is not trusted")
+	}
+	var c forwardClaims
+	if err := tok.Claims(forwarderKey, &c); err != nil {
+		return res, fmt.Errorf("verify forwarding token: %w", err)
+	}
+	if err := c.Validate(jwt.Expected{Time: time.Now()}); err != nil {
+		return res, fmt.Errorf("validate forwarding token: %w", err)
+	}
+	if c.ChainHash != chainHash(original) {
+		return res, fmt.Errorf("validate forwarding token: token was not issued for this chain")
+	}
+	sessionKey := new(ecdsa.PublicKey)
+	if err := ParsePublicKey(c.IdentityPublicKey, sessionKey); err != nil {
+		return res, fmt.Errorf("parse forwarding token identity public key: %w", err)
+	}
+	res.PublicKey = sessionKey
+	res.Chain.claims(c.Claims)
+	return res, nil
+}
+
+// DecodeIdentity decodes the IdentityData from the original chain of the chain passed.
+func (v ForwardVerifier) DecodeIdentity(chain []string) (IdentityData, error) {
+	if forwarded(chain) {
+		chain = chain[:len(chain)-1]
+	}
+	return v.Verifier.DecodeIdentity(chain)
+}
+
+// forwarded checks if the chain passed had a forwarding token appended to it using EncodeForwarded. Chains
+// sent by clients are either 1 or 3 tokens long, so a forwarded chain is 2 or 4 tokens long.
+func forwarded(chain []string) bool {
+	return len(chain) == 2 || len(chain) == 4
+}
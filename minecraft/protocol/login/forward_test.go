@@ -0,0 +1,68 @@
+package login
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+)
+
+// TestForwardVerifier checks if a ForwardVerifier only accepts forwarded requests signed by a trusted proxy
+// for the chain they were issued for, and hands over the identity to the session key of the proxy.
+func TestForwardVerifier(t *testing.T) {
+	root, proxy, session := newKey(t), newKey(t), newKey(t)
+	v := ForwardVerifier{
+		Verifier:      Verifier{RootKeys: []*ecdsa.PublicKey{&root.PublicKey}, Issuer: testIssuer},
+		ForwarderKeys: []*ecdsa.PublicKey{&proxy.PublicKey},
+	}
+	forward := func(request []byte, key *ecdsa.PrivateKey) []string {
+		forwarded, err := EncodeForwarded(request, ClientData{}, key, session)
+		if err != nil {
+			t.Fatalf("forward login request: %v", err)
+		}
+		return chainOf(t, forwarded)
+	}
+	request := issueRequest(t, root, testIdentity(), time.Hour)
+	offline := testIdentity()
+	offline.XUID, offline.TitleID = "", ""
+
+	// A forwarding token issued for one chain must not be accepted for any other chain.
+	mismatched := chainOf(t, issueRequest(t, root, testIdentity(), time.Hour))
+	mismatched = append(mismatched, forward(request, proxy)[3])
+
+	tests := []struct {
+		name          string
+		v             ForwardVerifier
+		chain         []string
+		authenticated bool
+		forwarded     bool
+		err           bool
+	}{
+		{name: "forwarded", v: v, chain: forward(request, proxy), authenticated: true, forwarded: true},
+		{name: "forwarded self-signed", v: v, chain: forward(EncodeOffline(offline, ClientData{}, newKey(t)), proxy), forwarded: true},
+		{name: "untrusted proxy", v: v, chain: forward(request, newKey(t)), err: true},
+		{name: "mismatched chain", v: v, chain: mismatched, err: true},
+		{name: "expired chain", v: v, chain: forward(issueRequest(t, root, testIdentity(), -time.Minute), proxy), err: true},
+		{name: "direct", v: v, chain: chainOf(t, request), err: true},
+		{name: "direct allowed", v: ForwardVerifier{Verifier: v.Verifier, ForwarderKeys: v.ForwarderKeys, AllowDirect: true}, chain: chainOf(t, request), authenticated: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.v.VerifyChain(test.chain)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error: %v, got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if res.XBOXLiveAuthenticated != test.authenticated {
+				t.Fatalf("expected authenticated: %v, got %v", test.authenticated, res.XBOXLiveAuthenticated)
+			}
+			if res.PublicKey.Equal(&session.PublicKey) != test.forwarded {
+				t.Fatalf("expected session key of proxy in auth result: %v", test.forwarded)
+			}
+			if _, err := test.v.DecodeIdentity(test.chain); err != nil {
+				t.Fatalf("decode identity: %v", err)
+			}
+		})
+	}
+}
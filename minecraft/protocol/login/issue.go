@@ -0,0 +1,58 @@
+package login
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"time"
+)
+
+// IssueChain issues a login chain for the identity passed, signed by the issuerKey passed under the name of
+// the issuer. It allows closed ecosystems, such as internal test realms with a custom launcher, to use signed
+// identities without relying on Mojang: A Verifier with the public key of the issuerKey in its RootKeys and
+// the issuer as its Issuer considers players joining with such a chain authenticated.
+// The chain returned has the same structure as the chains issued by Mojang and may be passed to Encode,
+// along with the private key of which the public key clientKey passed is the public key. As with chains
+// issued by Mojang, the IdentityData must have an XUID and title ID set for the player to be considered
+// authenticated.
+func IssueChain(issuerKey *ecdsa.PrivateKey, issuer string, identity IdentityData, clientKey *ecdsa.PublicKey, validity time.Duration) (string, error) {
+	issuerKeyData := MarshalPublicKey(&issuerKey.PublicKey)
+	signer, err := jose.NewSigner(jose.SigningKey{Key: issuerKey, Algorithm: jose.ES384}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{"x5u": issuerKeyData},
+	})
+	if err != nil {
+		return "", fmt.Errorf("create signer: %w", err)
+	}
+	t := time.Now()
+	claims := jwt.Claims{
+		Issuer:    issuer,
+		IssuedAt:  jwt.NewNumericDate(t),
+		NotBefore: jwt.NewNumericDate(t.Add(-time.Minute)),
+		Expiry:    jwt.NewNumericDate(t.Add(validity)),
+	}
+	// The first token is signed by the issuer and hands over to the issuer key itself, just like the first
+	// token of a Mojang chain. The second token holds the identity data and hands over to the client key.
+	caJWT, err := jwt.Signed(signer).Claims(identityPublicKeyClaims{
+		Claims:               claims,
+		IdentityPublicKey:    issuerKeyData,
+		CertificateAuthority: true,
+	}).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("sign certificate authority token: %w", err)
+	}
+	identityJWT, err := jwt.Signed(signer).Claims(identityClaims{
+		Claims:            claims,
+		ExtraData:         identity,
+		IdentityPublicKey: MarshalPublicKey(clientKey),
+	}).CompactSerialize()
+	if err != nil {
+		return "", fmt.Errorf("sign identity token: %w", err)
+	}
+	data, err := json.Marshal(request{Chain: chain{caJWT, identityJWT}})
+	if err != nil {
+		return "", fmt.Errorf("encode chain: %w", err)
+	}
+	return string(data), nil
+}
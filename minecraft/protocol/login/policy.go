@@ -0,0 +1,77 @@
+package login
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrBannedDevice is returned (wrapped) by Policy.Check if the device of a player is banned.
+	ErrBannedDevice = errors.New("device is banned")
+	// ErrTitleNotAllowed is returned (wrapped) by Policy.Check if a player joined using a title (a version
+	// of the game on a specific platform) that is not allowed.
+	ErrTitleNotAllowed = errors.New("title is not allowed")
+)
+
+// Policy holds checks applied to players after their login request was parsed and verified. A zero Policy
+// allows all players.
+type Policy struct {
+	// MinimumIssuedAt is the earliest time at which a login chain may have been issued. Chains issued
+	// before this time are rejected with an error wrapping ErrStaleChain. It may be used to force all
+	// players to log in again after, for example, a security incident. If zero, chains are not checked.
+	MinimumIssuedAt time.Time
+	// MaxChainAge is the maximum age of a login chain at the time of checking, as returned by
+	// ChainInfo.Age. Older chains are rejected with an error wrapping ErrStaleChain. If zero, the age of
+	// chains is not checked.
+	MaxChainAge time.Duration
+	// BannedDevices is called with the device ID of every player. If it returns true, the player is rejected
+	// with an error wrapping ErrBannedDevice. DeviceIDs may be used to create a function from a list of
+	// device IDs. Note that the device ID is part of the client data, which is not signed by Mojang, so a
+	// modified client may send any device ID. If nil, devices are not checked.
+	BannedDevices func(deviceID string) bool
+	// TitleIDs is a list of title IDs that players may join with. Players logged in with XBOX Live with any
+	// other title ID are rejected with an error wrapping ErrTitleNotAllowed. Players not logged in with XBOX
+	// Live have no title ID and are not checked. If empty, all titles are allowed.
+	TitleIDs []string
+	// Skin specifies how skins that fail ClientData.ValidateSkin are handled. Unlike the other checks, it is
+	// not applied by Check, as SkinStrip modifies the client data: SkinPolicy.Apply must be called with the
+	// client data instead. A Listener does so after calling Check. The zero value, SkinAllow, accepts all
+	// skins.
+	Skin SkinPolicy
+}
+
+// Check checks the identity data, client data and AuthResult of a player, as returned by Parse, against the
+// Policy. An error is returned if the player should not be allowed to join.
+func (p Policy) Check(iData IdentityData, cData ClientData, res AuthResult) error {
+	t := time.Now()
+	if !p.MinimumIssuedAt.IsZero() && !res.Chain.IssuedAt.IsZero() && res.Chain.IssuedAt.Before(p.MinimumIssuedAt) {
+		return fmt.Errorf("check login: %w: issued at %v, before %v", ErrStaleChain, res.Chain.IssuedAt, p.MinimumIssuedAt)
+	}
+	if p.MaxChainAge > 0 {
+		if age := res.Chain.Age(t); age > p.MaxChainAge {
+			return fmt.Errorf("check login: %w: issued %v ago, maximum age is %v", ErrStaleChain, age.Round(time.Second), p.MaxChainAge)
+		}
+	}
+	if p.BannedDevices != nil && p.BannedDevices(cData.DeviceID) {
+		return fmt.Errorf("check login: %w: %v", ErrBannedDevice, cData.DeviceID)
+	}
+	if len(p.TitleIDs) != 0 && res.XBOXLiveAuthenticated && !slices.Contains(p.TitleIDs, iData.TitleID) {
+		return fmt.Errorf("check login: %w: %v", ErrTitleNotAllowed, iData.TitleID)
+	}
+	return nil
+}
+
+// DeviceIDs returns a function that may be used as Policy.BannedDevices, which returns true for any of the
+// device IDs passed.
+func DeviceIDs(ids ...string) func(deviceID string) bool {
+	m := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		m[id] = struct{}{}
+	}
+	return func(deviceID string) bool {
+		_, ok := m[deviceID]
+		return ok
+	}
+}
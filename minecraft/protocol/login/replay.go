@@ -0,0 +1,119 @@
+package login
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrReplayed is returned (wrapped) by CheckReplay if a login request was already used before.
+var ErrReplayed = errors.New("login request was replayed")
+
+// ReplayCache records the login requests seen by a server, so that a login request captured from one
+// connection cannot be replayed on another. MemoryReplayCache implements ReplayCache for a single process,
+// but it may be implemented using a shared store, such as Redis, so that all servers of a cluster reject
+// requests seen by any of them.
+type ReplayCache interface {
+	// Seen records the key passed until the expiry time passed and reports if the key was already recorded
+	// and had not yet expired. Seen must check and record the key atomically.
+	Seen(key string, expiry time.Time) (bool, error)
+}
+
+// DefaultReplayWindow is the maximum duration for which CheckReplay records a login request if no window is
+// passed. Requests are never recorded beyond the expiry of their chain.
+const DefaultReplayWindow = time.Hour
+
+// CheckReplay checks the login request passed against the ReplayCache passed and records it. The request is
+// recorded until the chain in the AuthResult, as returned by Parse, expires, but for no longer than the
+// window passed, or DefaultReplayWindow if the window is zero. The window should be the maximum age of
+// chains accepted (see Verifier.MaxAge), so that a request is recorded for as long as its chain is accepted.
+// An error wrapping ErrReplayed is returned if the same request was seen before.
+// Requests are identified by the token holding the client data, which is signed anew every time a client
+// connects, so that clients may reconnect with the same chain. CheckReplay should only be called for
+// requests of which the chain was authenticated: Self-signed requests may be created freely, so recording
+// them protects against nothing while filling the ReplayCache.
+func CheckReplay(c ReplayCache, request []byte, res AuthResult, window time.Duration) error {
+	req, err := parseLoginRequest(request)
+	if err != nil {
+		return fmt.Errorf("parse login request: %w", err)
+	}
+	sum := sha256.Sum256([]byte(req.RawToken))
+	if window <= 0 {
+		window = DefaultReplayWindow
+	}
+	expiry := time.Now().Add(window)
+	if exp := res.Chain.Expiry; !exp.IsZero() && exp.Before(expiry) {
+		expiry = exp
+	}
+	seen, err := c.Seen(base64.StdEncoding.EncodeToString(sum[:]), expiry)
+	if err != nil {
+		return fmt.Errorf("check replay: %w", err)
+	}
+	if seen {
+		return fmt.Errorf("check replay: %w", ErrReplayed)
+	}
+	return nil
+}
+
+// DefaultReplayCacheSize is the maximum amount of keys recorded by a MemoryReplayCache with a zero MaxKeys.
+const DefaultReplayCacheSize = 1 << 16
+
+// MemoryReplayCache is a ReplayCache that keeps the keys it records in memory. It records at most MaxKeys
+// keys: Expired keys are removed first and, if the cache is still full, the keys recorded earliest are
+// evicted. The zero value of a MemoryReplayCache is ready to use.
+type MemoryReplayCache struct {
+	// MaxKeys is the maximum amount of keys recorded. If zero, DefaultReplayCacheSize is used.
+	MaxKeys int
+
+	mu   sync.Mutex
+	keys map[string]time.Time
+	// order holds the keys recorded in the order in which they were recorded, starting at index head.
+	order []replayKey
+	head  int
+}
+
+// replayKey is a key recorded by a MemoryReplayCache along with its expiry.
+type replayKey struct {
+	key    string
+	expiry time.Time
+}
+
+// Seen ...
+func (c *MemoryReplayCache) Seen(key string, expiry time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := time.Now()
+	if c.keys == nil {
+		c.keys = make(map[string]time.Time)
+	}
+	if exp, ok := c.keys[key]; ok && t.Before(exp) {
+		return true, nil
+	}
+	maxKeys := c.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultReplayCacheSize
+	}
+	// Keys are recorded for a bounded window, so the keys recorded earliest are typically the first to
+	// expire.
+	for c.head < len(c.order) && (len(c.keys) >= maxKeys || t.After(c.order[c.head].expiry)) {
+		k := c.order[c.head]
+		c.order[c.head] = replayKey{}
+		c.head++
+		// The key may have been recorded again after it expired, in which case the later entry in order
+		// removes it.
+		if exp, ok := c.keys[k.key]; ok && exp.Equal(k.expiry) {
+			delete(c.keys, k.key)
+		}
+	}
+	if c.head > len(c.order)/2 {
+		c.order = append(c.order[:0], c.order[c.head:]...)
+		c.head = 0
+	}
+	c.keys[key] = expiry
+	c.order = append(c.order, replayKey{key: key, expiry: expiry})
+	return false, nil
+}
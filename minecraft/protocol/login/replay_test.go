@@ -0,0 +1,112 @@
+package login
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestCheckReplay checks if CheckReplay rejects login requests that were already seen, while accepting new
+// requests and requests of which the record expired.
+func TestCheckReplay(t *testing.T) {
+	root := newKey(t)
+	request := issueRequest(t, root, testIdentity(), time.Hour)
+	res, err := Verifier{RootKeys: []*ecdsa.PublicKey{&root.PublicKey}, Issuer: testIssuer}.VerifyChain(chainOf(t, request))
+	if err != nil {
+		t.Fatalf("verify chain: %v", err)
+	}
+	expired := res
+	expired.Chain.Expiry = time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name     string
+		requests [][]byte
+		res      AuthResult
+		window   time.Duration
+		replayed bool
+	}{
+		{name: "replayed", requests: [][]byte{request, request}, res: res, replayed: true},
+		{name: "new requests", requests: [][]byte{request, issueRequest(t, root, testIdentity(), time.Hour)}, res: res},
+		{name: "expired chain", requests: [][]byte{request, request}, res: expired},
+		{name: "window passed", requests: [][]byte{request, request}, res: res, window: time.Nanosecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &MemoryReplayCache{}
+			var err error
+			for _, req := range test.requests {
+				if err = CheckReplay(c, req, test.res, test.window); err != nil {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if errors.Is(err, ErrReplayed) != test.replayed {
+				t.Fatalf("expected replayed: %v, got %v", test.replayed, err)
+			}
+		})
+	}
+}
+
+// TestCheckReplayWindow checks if CheckReplay records requests for no longer than the window passed, even if
+// the chain of the request expires later.
+func TestCheckReplayWindow(t *testing.T) {
+	request := issueRequest(t, newKey(t), testIdentity(), time.Hour)
+	res := AuthResult{Chain: ChainInfo{Expiry: time.Now().Add(time.Hour * 24 * 365)}}
+
+	for _, window := range []time.Duration{0, time.Minute} {
+		c := &recordingCache{}
+		if err := CheckReplay(c, request, res, window); err != nil {
+			t.Fatalf("check replay: %v", err)
+		}
+		max := window
+		if max == 0 {
+			max = DefaultReplayWindow
+		}
+		if d := time.Until(c.expiry); d > max {
+			t.Fatalf("request recorded for %v, expected at most %v", d, max)
+		}
+	}
+}
+
+// recordingCache is a ReplayCache that records the expiry of the last key passed.
+type recordingCache struct {
+	expiry time.Time
+}
+
+// Seen ...
+func (c *recordingCache) Seen(_ string, expiry time.Time) (bool, error) {
+	c.expiry = expiry
+	return false, nil
+}
+
+// TestMemoryReplayCacheBounded checks if a MemoryReplayCache never holds more than MaxKeys keys, evicting the
+// keys recorded earliest, and removes expired keys.
+func TestMemoryReplayCacheBounded(t *testing.T) {
+	c := &MemoryReplayCache{MaxKeys: 100}
+	expiry := time.Now().Add(time.Hour)
+	for i := 0; i < 1000; i++ {
+		if seen, _ := c.Seen(fmt.Sprint(i), expiry); seen {
+			t.Fatalf("key %v seen before it was recorded", i)
+		}
+		if len(c.keys) > c.MaxKeys {
+			t.Fatalf("cache holds %v keys, expected at most %v", len(c.keys), c.MaxKeys)
+		}
+	}
+	if seen, _ := c.Seen("999", expiry); !seen {
+		t.Fatalf("key recorded last was evicted")
+	}
+	if seen, _ := c.Seen("0", expiry); seen {
+		t.Fatalf("key recorded first was not evicted")
+	}
+
+	c = &MemoryReplayCache{}
+	for i := 0; i < 100; i++ {
+		_, _ = c.Seen(fmt.Sprint(i), time.Now().Add(-time.Second))
+	}
+	_, _ = c.Seen("new", expiry)
+	if len(c.keys) != 1 {
+		t.Fatalf("cache holds %v keys, expected expired keys to be removed", len(c.keys))
+	}
+}
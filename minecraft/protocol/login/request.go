@@ -3,13 +3,17 @@ package login
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -39,102 +43,287 @@ func init() {
 // mojangKey holds the parsed Mojang ecdsa.PublicKey.
 var mojangKey = new(ecdsa.PublicKey)
 
+// MojangPublicKey returns the public key that Mojang signs the login chains of players authenticated with
+// XBOX Live with. It is the only root key trusted by a zero Verifier.
+func MojangPublicKey() *ecdsa.PublicKey {
+	return mojangKey
+}
+
+// ErrStaleChain is returned (wrapped) by Verifier.Parse if the login chain of a request is older than the
+// Verifier.MaxAge.
+var ErrStaleChain = errors.New("login chain is stale")
+
 // AuthResult is returned by a call to Parse. It holds the ecdsa.PublicKey of the client and a bool that
 // indicates if the player was logged in with XBOX Live.
 type AuthResult struct {
 	PublicKey             *ecdsa.PublicKey
 	XBOXLiveAuthenticated bool
+	// Chain holds information about the certificate chain of the login request, such as when it was issued
+	// and when it expires.
+	Chain ChainInfo
 }
 
-// Parse parses and verifies the login request passed. The AuthResult returned holds the ecdsa.PublicKey that
-// was parsed (which is used for encryption) and a bool specifying if the request was authenticated by XBOX
-// Live.
-// Parse returns IdentityData and ClientData, of which IdentityData cannot under any circumstance be edited by
-// the client. Rather, it is obtained from an authentication endpoint. The ClientData can, however, be edited
-// freely by the client.
+// ChainInfo holds information about the certificate chain of a login request.
+type ChainInfo struct {
+	// Length is the amount of tokens in the chain. It is 1 for self-signed chains and 3 for chains obtained
+	// from the authentication endpoint.
+	Length int
+	// Issuer is the issuer of the token holding the identity data of the player. It is 'Mojang' for chains
+	// obtained from the authentication endpoint and empty for self-signed chains.
+	Issuer string
+	// IssuedAt and NotBefore are the issuance time and the start of the validity of the token holding the
+	// identity data of the player. Either may be zero if the token did not specify it.
+	IssuedAt, NotBefore time.Time
+	// Expiry is the earliest expiry of all tokens in the chain. The chain is no longer valid after this time.
+	Expiry time.Time
+	// ExtraData holds all claims found in the extraData of the token holding the identity data of the
+	// player, including those not decoded into IdentityData. As these claims are signed as part of the
+	// chain, they may be trusted if the chain was signed by a trusted root key.
+	ExtraData map[string]any
+	// RootKey is the trusted root key that signed the chain. It is nil if the chain was not signed by any of
+	// the trusted root keys, in which case the player was not authenticated with XBOX Live.
+	RootKey *ecdsa.PublicKey
+}
+
+// Age returns the time passed since the chain was issued at the time t passed. If the identity token of the
+// chain did not specify an issuance time, the start of its validity is used instead.
+func (info ChainInfo) Age(t time.Time) time.Duration {
+	if !info.IssuedAt.IsZero() {
+		return t.Sub(info.IssuedAt)
+	}
+	return t.Sub(info.NotBefore)
+}
+
+// claims updates the ChainInfo with the claims of a token in the chain. The expiry of the ChainInfo is set
+// to the expiry of c if it is earlier than the current one.
+func (info *ChainInfo) claims(c jwt.Claims) {
+	if exp := c.Expiry.Time(); !exp.IsZero() && (info.Expiry.IsZero() || exp.Before(info.Expiry)) {
+		info.Expiry = exp
+	}
+}
+
+// Verifier parses and verifies login requests. It validates the certificate chain of a request against a set
+// of trusted root keys. The zero value of a Verifier trusts only the key returned by MojangPublicKey and does
+// not limit the age of chains.
+type Verifier struct {
+	// RootKeys is the set of public keys trusted to sign login chains. A chain signed by any of these keys
+	// is considered authenticated with XBOX Live. Multiple keys may be set to keep accepting chains signed
+	// by an old root key while a new one is rolled out. If empty, only MojangPublicKey is trusted.
+	RootKeys []*ecdsa.PublicKey
+	// MaxAge is the maximum age of a login chain, measured from the time it was issued (see ChainInfo.Age).
+	// Requests with an older chain are rejected with an error wrapping ErrStaleChain. If zero, only the
+	// expiry of the tokens in the chain is validated.
+	MaxAge time.Duration
+	// Issuer is the issuer that the tokens of a chain signed by one of the RootKeys must carry. It may be
+	// set, along with RootKeys, to accept chains issued using IssueChain by a custom authentication service
+	// rather than by Mojang. If empty, 'Mojang' is used.
+	Issuer string
+}
+
+// Parse is a shorthand for Verifier{}.Parse(request). See Verifier.Parse for details.
 func Parse(request []byte) (IdentityData, ClientData, AuthResult, error) {
+	return Verifier{}.Parse(request)
+}
+
+// Authenticator verifies the certificate chain of a login request and decodes the identity of the player
+// from it. It may be implemented to trust custom root keys, check players against an allow-list or consult a
+// third-party authentication service. Verifier is the default implementation of Authenticator.
+// The methods of an Authenticator may be called from multiple goroutines simultaneously.
+type Authenticator interface {
+	// VerifyChain verifies the certificate chain passed, which holds the compact serialised JWTs of the
+	// chain in order. It returns an AuthResult holding the public key that the client data and handshake of
+	// the client are verified with, which is typically the identity public key of the last token in the
+	// chain. An error is returned if the chain could not be verified, in which case the login is rejected.
+	VerifyChain(chain []string) (AuthResult, error)
+	// DecodeIdentity decodes the IdentityData of the player from the certificate chain passed. It is only
+	// called after VerifyChain returned successfully for the same chain.
+	DecodeIdentity(chain []string) (IdentityData, error)
+}
+
+// ParseWith parses the login request passed, like Parse, but verifies its chain and decodes the identity of
+// the player using the Authenticator passed. The client data of the request is verified using the public
+// key in the AuthResult returned by Authenticator.VerifyChain and validated using the ClientDataLimits
+// passed.
+func ParseWith(request []byte, a Authenticator, limits ClientDataLimits) (IdentityData, ClientData, AuthResult, error) {
 	var (
 		iData IdentityData
 		cData ClientData
-		res   AuthResult
-		key   = &ecdsa.PublicKey{}
 	)
 	req, err := parseLoginRequest(request)
 	if err != nil {
-		return iData, cData, res, fmt.Errorf("parse login request: %w", err)
+		return iData, cData, AuthResult{}, fmt.Errorf("parse login request: %w", err)
 	}
-	tok, err := jwt.ParseSigned(req.Chain[0])
+	res, err := a.VerifyChain(req.Chain)
 	if err != nil {
-		return iData, cData, res, fmt.Errorf("parse token 0: %w", err)
+		return iData, cData, res, err
+	}
+	if res.PublicKey == nil {
+		return iData, cData, res, fmt.Errorf("verify chain: no public key in auth result")
 	}
+	if iData, err = a.DecodeIdentity(req.Chain); err != nil {
+		return iData, cData, res, fmt.Errorf("decode identity: %w", err)
+	}
+
+	// Copy the key so that the key in the AuthResult is not changed if the client data token holds an
+	// identityPublicKey field.
+	key := *res.PublicKey
+	if err := parseFullClaim(req.RawToken, &key, &cData); err != nil {
+		return iData, cData, res, fmt.Errorf("parse client data: %w", err)
+	}
+	if strings.Count(cData.ServerAddress, ":") > 1 && cData.ServerAddress[0] != '[' {
+		// IPv6: We can't net.ResolveUDPAddr this directly, because Mojang does
+		// not always put [] around the IP if it isn't added by the player in
+		// the External Server adding screen. We'll have to do this manually:
+		ind := strings.LastIndex(cData.ServerAddress, ":")
+		cData.ServerAddress = "[" + cData.ServerAddress[:ind] + "]" + cData.ServerAddress[ind:]
+	}
+	if err := cData.ValidateLimits(limits); err != nil {
+		return iData, cData, res, fmt.Errorf("validate client data: %w", err)
+	}
+	return iData, cData, res, nil
+}
+
+// Parse parses and verifies the login request passed, validating the chain against the root keys of the
+// Verifier and rejecting chains older than Verifier.MaxAge. The AuthResult returned holds the ecdsa.PublicKey
+// that was parsed (which is used for encryption) and a bool specifying if the request was authenticated by
+// XBOX Live.
+// Parse returns IdentityData and ClientData, of which IdentityData cannot under any circumstance be edited by
+// the client. Rather, it is obtained from an authentication endpoint. The ClientData can, however, be edited
+// freely by the client.
+func (v Verifier) Parse(request []byte) (IdentityData, ClientData, AuthResult, error) {
+	return ParseWith(request, v, ClientDataLimits{})
+}
 
+// VerifyChain verifies the chain passed against the root keys of the Verifier. Chains of a single,
+// self-signed token are accepted, but are not XBOXLiveAuthenticated. Chains older than Verifier.MaxAge are
+// rejected with an error wrapping ErrStaleChain.
+func (v Verifier) VerifyChain(chain []string) (AuthResult, error) {
+	var (
+		res  AuthResult
+		info ChainInfo
+		key  = &ecdsa.PublicKey{}
+	)
+	if len(chain) == 0 {
+		return res, fmt.Errorf("verify chain: JWT chain must be at least 1 token long")
+	}
+	tok, err := ParseToken(chain[0])
+	if err != nil {
+		return res, fmt.Errorf("parse token 0: %w", err)
+	}
 	// The first token holds the client's public key in the x5u (it's self signed).
 	//lint:ignore S1005 Double assignment is done explicitly to prevent panics.
 	raw, _ := tok.Headers[0].ExtraHeaders["x5u"]
 	if err := parseAsKey(raw, key); err != nil {
-		return iData, cData, res, fmt.Errorf("parse x5u: %w", err)
+		return res, fmt.Errorf("parse x5u: %w", err)
 	}
 
 	var identityClaims identityClaims
 	var authenticated bool
-	t, iss := time.Now(), "Mojang"
+	t, iss := time.Now(), v.Issuer
+	if iss == "" {
+		iss = "Mojang"
+	}
 
-	switch len(req.Chain) {
+	switch len(chain) {
 	case 1:
 		// Player was not authenticated with XBOX Live, meaning the one token in here is self-signed.
-		if err := parseFullClaim(req.Chain[0], key, &identityClaims); err != nil {
-			return iData, cData, res, err
+		if err := parseFullClaim(chain[0], key, &identityClaims); err != nil {
+			return res, err
 		}
 		if err := identityClaims.Validate(jwt.Expected{Time: t}); err != nil {
-			return iData, cData, res, fmt.Errorf("validate token 0: %w", err)
+			return res, fmt.Errorf("validate token 0: %w", err)
 		}
 	case 3:
 		// Player was (or should be) authenticated with XBOX Live, meaning the chain is exactly 3 tokens
 		// long.
 		var c jwt.Claims
-		if err := parseFullClaim(req.Chain[0], key, &c); err != nil {
-			return iData, cData, res, fmt.Errorf("parse token 0: %w", err)
+		if err := parseFullClaim(chain[0], key, &c); err != nil {
+			return res, fmt.Errorf("parse token 0: %w", err)
 		}
 		if err := c.Validate(jwt.Expected{Time: t}); err != nil {
-			return iData, cData, res, fmt.Errorf("validate token 0: %w", err)
+			return res, fmt.Errorf("validate token 0: %w", err)
 		}
-		authenticated = bytes.Equal(key.X.Bytes(), mojangKey.X.Bytes()) && bytes.Equal(key.Y.Bytes(), mojangKey.Y.Bytes())
+		info.claims(c)
+		info.RootKey = v.rootKey(key)
+		authenticated = info.RootKey != nil
 
-		if err := parseFullClaim(req.Chain[1], key, &c); err != nil {
-			return iData, cData, res, fmt.Errorf("parse token 1: %w", err)
+		c = jwt.Claims{}
+		if err := parseFullClaim(chain[1], key, &c); err != nil {
+			return res, fmt.Errorf("parse token 1: %w", err)
 		}
 		if err := c.Validate(jwt.Expected{Time: t, Issuer: iss}); err != nil {
-			return iData, cData, res, fmt.Errorf("validate token 1: %w", err)
+			return res, fmt.Errorf("validate token 1: %w", err)
 		}
-		if err := parseFullClaim(req.Chain[2], key, &identityClaims); err != nil {
-			return iData, cData, res, fmt.Errorf("parse token 2: %w", err)
+		info.claims(c)
+		if err := parseFullClaim(chain[2], key, &identityClaims); err != nil {
+			return res, fmt.Errorf("parse token 2: %w", err)
 		}
 		if err := identityClaims.Validate(jwt.Expected{Time: t, Issuer: iss}); err != nil {
-			return iData, cData, res, fmt.Errorf("validate token 2: %w", err)
+			return res, fmt.Errorf("validate token 2: %w", err)
 		}
 		if authenticated != (identityClaims.ExtraData.XUID != "") {
-			return iData, cData, res, fmt.Errorf("identity data must have an XUID when logged into XBOX Live only")
+			return res, fmt.Errorf("identity data must have an XUID when logged into XBOX Live only")
 		}
 		if authenticated != (identityClaims.ExtraData.TitleID != "") {
-			return iData, cData, res, fmt.Errorf("identity data must have a title ID when logged into XBOX Live only")
+			return res, fmt.Errorf("identity data must have a title ID when logged into XBOX Live only")
 		}
 	default:
-		return iData, cData, res, fmt.Errorf("unexpected login chain length %v", len(req.Chain))
+		return res, fmt.Errorf("unexpected login chain length %v", len(chain))
 	}
-	if err := parseFullClaim(req.RawToken, key, &cData); err != nil {
-		return iData, cData, res, fmt.Errorf("parse client data: %w", err)
+	info.Length = len(chain)
+	info.Issuer = identityClaims.Issuer
+	info.IssuedAt, info.NotBefore = identityClaims.IssuedAt.Time(), identityClaims.NotBefore.Time()
+	info.claims(identityClaims.Claims)
+	info.ExtraData = extraData(chain[len(chain)-1])
+	if v.MaxAge > 0 {
+		if age := info.Age(t); age > v.MaxAge {
+			return res, fmt.Errorf("validate chain: %w: issued %v ago, maximum age is %v", ErrStaleChain, age.Round(time.Second), v.MaxAge)
+		}
 	}
-	if strings.Count(cData.ServerAddress, ":") > 1 && cData.ServerAddress[0] != '[' {
-		// IPv6: We can't net.ResolveUDPAddr this directly, because Mojang does
-		// not always put [] around the IP if it isn't added by the player in
-		// the External Server adding screen. We'll have to do this manually:
-		ind := strings.LastIndex(cData.ServerAddress, ":")
-		cData.ServerAddress = "[" + cData.ServerAddress[:ind] + "]" + cData.ServerAddress[ind:]
+
+	return AuthResult{PublicKey: key, XBOXLiveAuthenticated: authenticated, Chain: info}, nil
+}
+
+// DecodeIdentity decodes the IdentityData from the last token of the chain passed. The signature of the
+// token is not verified again: DecodeIdentity must only be called with a chain that was verified using
+// Verifier.VerifyChain.
+func (v Verifier) DecodeIdentity(chain []string) (IdentityData, error) {
+	if len(chain) == 0 {
+		return IdentityData{}, fmt.Errorf("JWT chain must be at least 1 token long")
 	}
-	if err := cData.Validate(); err != nil {
-		return iData, cData, res, fmt.Errorf("validate client data: %w", err)
+	tok, err := ParseToken(chain[len(chain)-1])
+	if err != nil {
+		return IdentityData{}, fmt.Errorf("parse token %v: %w", len(chain)-1, err)
+	}
+	var c identityClaims
+	if err := tok.UnsafeClaimsWithoutVerification(&c); err != nil {
+		return IdentityData{}, fmt.Errorf("read claims of token %v: %w", len(chain)-1, err)
+	}
+	return c.ExtraData, nil
+}
+
+// extraData returns the raw extraData claims of the token passed. The token must already have been verified.
+func extraData(token string) map[string]any {
+	var c struct {
+		ExtraData map[string]any `json:"extraData"`
+	}
+	if tok, err := ParseToken(token); err == nil {
+		_ = tok.UnsafeClaimsWithoutVerification(&c)
+	}
+	return c.ExtraData
+}
+
+// rootKey returns the trusted root key of the Verifier that is equal to the key passed, or nil if the key is
+// not trusted.
+func (v Verifier) rootKey(key *ecdsa.PublicKey) *ecdsa.PublicKey {
+	roots := v.RootKeys
+	if len(roots) == 0 {
+		roots = []*ecdsa.PublicKey{mojangKey}
+	}
+	if i := slices.IndexFunc(roots, func(root *ecdsa.PublicKey) bool { return root != nil && root.Equal(key) }); i != -1 {
+		return roots[i]
 	}
-	return identityClaims.ExtraData, cData, AuthResult{PublicKey: key, XBOXLiveAuthenticated: authenticated}, nil
+	return nil
 }
 
 // parseLoginRequest parses the structure of a login request from the data passed and returns it.
@@ -158,7 +347,7 @@ func parseLoginRequest(requestData []byte) (*request, error) {
 // if the claim holds an identityPublicKey field.
 // The value v passed is decoded into when reading the claims.
 func parseFullClaim(claim string, key *ecdsa.PublicKey, v any) error {
-	tok, err := jwt.ParseSigned(claim)
+	tok, err := ParseToken(claim)
 	if err != nil {
 		return fmt.Errorf("error parsing signed token: %w", err)
 	}
@@ -175,6 +364,32 @@ func parseFullClaim(claim string, key *ecdsa.PublicKey, v any) error {
 	return nil
 }
 
+// ParseToken parses a JWT in compact serialisation signed by one of the keys in a login chain or by the
+// server during the handshake. Unlike jwt.ParseSigned, ParseToken validates the headers of the token: It
+// must carry exactly one signature, made using ES384, and may not hold critical headers or an embedded key,
+// as neither is ever used in login chains. The signature of the token is not verified by ParseToken: It must
+// still be verified by obtaining the claims of the token using the expected key.
+func ParseToken(s string) (*jwt.JSONWebToken, error) {
+	tok, err := jwt.ParseSigned(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tok.Headers) != 1 {
+		return nil, fmt.Errorf("expected exactly 1 signature, got %v", len(tok.Headers))
+	}
+	h := tok.Headers[0]
+	if h.Algorithm != string(jose.ES384) {
+		return nil, fmt.Errorf("unexpected signature algorithm %q: expected %v", h.Algorithm, jose.ES384)
+	}
+	if h.JSONWebKey != nil {
+		return nil, fmt.Errorf("unexpected embedded jwk header")
+	}
+	if _, ok := h.ExtraHeaders[jose.HeaderKey("crit")]; ok {
+		return nil, fmt.Errorf("unsupported critical headers")
+	}
+	return tok, nil
+}
+
 // parseAsKey parses the base64 encoded ecdsa.PublicKey held in k as a public key and sets it to the variable
 // pub passed.
 func parseAsKey(k any, pub *ecdsa.PublicKey) error {
@@ -337,3 +552,11 @@ func MarshalPublicKey(key *ecdsa.PublicKey) string {
 	data, _ := x509.MarshalPKIXPublicKey(key)
 	return base64.StdEncoding.EncodeToString(data)
 }
+
+// PublicKeyFingerprint returns the fingerprint of an ecdsa.PublicKey: The hex encoded SHA-256 hash of its
+// PKIX, ASN.1 DER form. It may be used to pin the key of a server.
+func PublicKeyFingerprint(key *ecdsa.PublicKey) string {
+	data, _ := x509.MarshalPKIXPublicKey(key)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
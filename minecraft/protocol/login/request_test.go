@@ -0,0 +1,108 @@
+package login
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// testIssuer is the issuer of the chains issued by tests.
+const testIssuer = "Test"
+
+// newKey generates a new private key of the kind used to sign login chains.
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+// testIdentity returns the identity data of a player authenticated with XBOX Live.
+func testIdentity() IdentityData {
+	return IdentityData{XUID: "2535400000000000", Identity: uuid.NewString(), DisplayName: "Player", TitleID: "896928775"}
+}
+
+// issueRequest issues a chain for the identity passed using the root key passed and returns a login request
+// holding it, signed by a new client key.
+func issueRequest(t *testing.T, root *ecdsa.PrivateKey, identity IdentityData, validity time.Duration) []byte {
+	t.Helper()
+	client := newKey(t)
+	c, err := IssueChain(root, testIssuer, identity, &client.PublicKey, validity)
+	if err != nil {
+		t.Fatalf("issue chain: %v", err)
+	}
+	return Encode(c, ClientData{}, client)
+}
+
+// chainOf returns the chain of the login request passed.
+func chainOf(t *testing.T, request []byte) []string {
+	t.Helper()
+	req, err := parseLoginRequest(request)
+	if err != nil {
+		t.Fatalf("parse login request: %v", err)
+	}
+	return req.Chain
+}
+
+// TestVerifyChain checks if Verifier.VerifyChain only authenticates chains signed by one of its root keys and
+// rejects expired, stale and malformed chains.
+func TestVerifyChain(t *testing.T) {
+	root, other := newKey(t), newKey(t)
+	v := Verifier{RootKeys: []*ecdsa.PublicKey{&newKey(t).PublicKey, &root.PublicKey}, Issuer: testIssuer}
+
+	offline := testIdentity()
+	offline.XUID, offline.TitleID = "", ""
+	tamper := chainOf(t, issueRequest(t, root, testIdentity(), time.Hour))
+	tamper[2] = chainOf(t, issueRequest(t, other, testIdentity(), time.Hour))[2]
+
+	tests := []struct {
+		name          string
+		v             Verifier
+		chain         []string
+		authenticated bool
+		err           bool
+		stale         bool
+	}{
+		{name: "trusted root", v: v, chain: chainOf(t, issueRequest(t, root, testIdentity(), time.Hour)), authenticated: true},
+		{name: "self-signed", v: v, chain: chainOf(t, EncodeOffline(offline, ClientData{}, newKey(t)))},
+		{name: "self-signed with XUID", v: v, chain: chainOf(t, EncodeOffline(testIdentity(), ClientData{}, newKey(t)))},
+		{name: "untrusted root", v: v, chain: chainOf(t, issueRequest(t, other, testIdentity(), time.Hour)), err: true},
+		{name: "untrusted root without XUID", v: v, chain: chainOf(t, issueRequest(t, other, offline, time.Hour))},
+		{name: "mojang root only", v: Verifier{Issuer: testIssuer}, chain: chainOf(t, issueRequest(t, root, testIdentity(), time.Hour)), err: true},
+		{name: "wrong issuer", v: Verifier{RootKeys: v.RootKeys}, chain: chainOf(t, issueRequest(t, root, testIdentity(), time.Hour)), err: true},
+		{name: "expired", v: v, chain: chainOf(t, issueRequest(t, root, testIdentity(), -time.Minute)), err: true},
+		{name: "stale", v: Verifier{RootKeys: v.RootKeys, Issuer: testIssuer, MaxAge: time.Nanosecond}, chain: chainOf(t, issueRequest(t, root, testIdentity(), time.Hour)), err: true, stale: true},
+		{name: "tampered", v: v, chain: tamper, err: true},
+		{name: "empty", v: v, chain: nil, err: true},
+		{name: "two tokens", v: v, chain: chainOf(t, issueRequest(t, root, testIdentity(), time.Hour))[1:], err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := test.v.VerifyChain(test.chain)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error: %v, got %v", test.err, err)
+			}
+			if errors.Is(err, ErrStaleChain) != test.stale {
+				t.Fatalf("expected stale chain error: %v, got %v", test.stale, err)
+			}
+			if err != nil {
+				return
+			}
+			if res.XBOXLiveAuthenticated != test.authenticated {
+				t.Fatalf("expected authenticated: %v, got %v", test.authenticated, res.XBOXLiveAuthenticated)
+			}
+			if test.authenticated && !res.Chain.RootKey.Equal(&root.PublicKey) {
+				t.Fatalf("chain info does not hold the root key that signed the chain")
+			}
+			if res.PublicKey == nil || res.Chain.Expiry.IsZero() {
+				t.Fatalf("auth result is missing the public key or expiry of the chain")
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package login
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidSkin is returned (wrapped) by SkinPolicy.Apply if the skin in the client data of a player is
+// rejected.
+var ErrInvalidSkin = errors.New("invalid skin")
+
+// SkinPolicy specifies how skins that fail ClientData.ValidateSkin are handled. Crafted resource patches,
+// persona pieces and flags are commonly used to crash other clients that the skin of a player is sent to.
+type SkinPolicy int
+
+const (
+	// SkinAllow accepts all skins that pass ClientData.ValidateLimits without further checks.
+	SkinAllow SkinPolicy = iota
+	// SkinStrip removes the invalid parts of a skin using ClientData.StripSkin, so that the player may still
+	// join with what remains of it.
+	SkinStrip
+	// SkinReject rejects players whose skin fails ClientData.ValidateSkin.
+	SkinReject
+)
+
+// Apply applies the SkinPolicy to the client data passed. For SkinStrip, the invalid parts of the skin are
+// removed from the client data. For SkinReject, an error wrapping ErrInvalidSkin is returned if the skin is
+// invalid.
+func (p SkinPolicy) Apply(data *ClientData) error {
+	switch p {
+	case SkinStrip:
+		data.StripSkin()
+	case SkinReject:
+		if err := data.ValidateSkin(); err != nil {
+			return fmt.Errorf("check skin: %w: %w", ErrInvalidSkin, err)
+		}
+	}
+	return nil
+}
+
+// defaultResourcePatch is the resource patch used by StripSkin to replace invalid resource patches. It points
+// to the geometry of the classic Steve skin, which is built into the game.
+const defaultResourcePatch = `{"geometry":{"default":"geometry.humanoid.custom"}}`
+
+var (
+	// checkGeometryName checks if a geometry name in a resource patch is valid.
+	checkGeometryName = regexp.MustCompile(`^geometry\.[A-Za-z0-9_.:\-]{1,128}$`).MatchString
+	// checkTintColour checks if a persona piece tint colour is a hex colour of at most 8 digits.
+	checkTintColour = regexp.MustCompile(`^#[0-9a-fA-F]{1,8}$`).MatchString
+)
+
+// ValidateSkin performs checks on the skin in the client data that ValidateLimits does not perform. It
+// verifies that the resource patch points to validly named geometry, that the persona pieces and their tint
+// colours carry valid IDs and types and that the persona, premium and trusted flags are consistent with the
+// rest of the skin. ValidateLimits should be called first. The error returned is a *FieldError.
+func (data ClientData) ValidateSkin() error {
+	if err := validateResourcePatch(data.SkinResourcePatch); err != nil {
+		return &FieldError{Field: "SkinResourcePatch", Err: err}
+	}
+	types := make(map[string]struct{}, len(data.PersonaPieces))
+	for i, piece := range data.PersonaPieces {
+		if err := piece.validate(); err != nil {
+			return &FieldError{Field: fmt.Sprintf("PersonaPieces[%v]", i), Err: err}
+		}
+		types[piece.PieceType] = struct{}{}
+	}
+	for i, tint := range data.PieceTintColours {
+		if err := tint.validate(types); err != nil {
+			return &FieldError{Field: fmt.Sprintf("PieceTintColours[%v]", i), Err: err}
+		}
+	}
+	if data.PersonaSkin != (len(data.PersonaPieces) != 0) {
+		return fieldErr("PersonaSkin", "must be set if and only if persona pieces are present, got %v with %v pieces", data.PersonaSkin, len(data.PersonaPieces))
+	}
+	if data.CapeOnClassicSkin && (data.PersonaSkin || data.CapeData == "") {
+		return fieldErr("CapeOnClassicSkin", "must only be set for classic skins with a cape")
+	}
+	if data.TrustedSkin && !data.PremiumSkin && !data.PersonaSkin {
+		return fieldErr("TrustedSkin", "must only be set for premium or persona skins")
+	}
+	return nil
+}
+
+// StripSkin removes the parts of the skin in the client data that would fail ValidateSkin. Invalid resource
+// patches are replaced with one pointing to the classic Steve geometry, invalid persona pieces and tint
+// colours are removed and flags inconsistent with the rest of the skin are cleared. The skin image itself is
+// left untouched.
+func (data *ClientData) StripSkin() {
+	if validateResourcePatch(data.SkinResourcePatch) != nil {
+		data.SkinResourcePatch = base64.StdEncoding.EncodeToString([]byte(defaultResourcePatch))
+		data.SkinGeometry = ""
+	}
+	types := make(map[string]struct{}, len(data.PersonaPieces))
+	pieces := data.PersonaPieces[:0]
+	for _, piece := range data.PersonaPieces {
+		if piece.validate() == nil {
+			pieces = append(pieces, piece)
+			types[piece.PieceType] = struct{}{}
+		}
+	}
+	data.PersonaPieces = pieces
+	tints := data.PieceTintColours[:0]
+	for _, tint := range data.PieceTintColours {
+		if tint.validate(types) == nil {
+			tints = append(tints, tint)
+		}
+	}
+	data.PieceTintColours = tints
+
+	data.PersonaSkin = len(data.PersonaPieces) != 0
+	if !data.PersonaSkin {
+		data.PieceTintColours = nil
+	}
+	if data.PersonaSkin || data.CapeData == "" {
+		data.CapeOnClassicSkin = false
+	}
+	if !data.PremiumSkin && !data.PersonaSkin {
+		data.TrustedSkin = false
+	}
+}
+
+// validateResourcePatch checks if the base64 encoded resource patch passed is a JSON object that maps
+// geometry names, including a default one, to valid geometry identifiers.
+func validateResourcePatch(s string) error {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("not a valid base64 string: %w", err)
+	}
+	var patch struct {
+		Geometry map[string]string `json:"geometry"`
+	}
+	if err := json.Unmarshal(b, &patch); err != nil {
+		return fmt.Errorf("geometry must map names to identifiers: %w", err)
+	}
+	if _, ok := patch.Geometry["default"]; !ok {
+		return errors.New("must have a default geometry")
+	}
+	for k, v := range patch.Geometry {
+		if !checkGeometryName(v) {
+			return fmt.Errorf("invalid %v geometry name %q", k, v)
+		}
+	}
+	return nil
+}
+
+// validate checks if the PersonaPiece has a valid piece ID, pack ID, product ID and piece type.
+func (piece PersonaPiece) validate() error {
+	if _, err := uuid.Parse(piece.PieceID); err != nil {
+		return fmt.Errorf("PieceID must be a UUID, got %q", piece.PieceID)
+	}
+	if _, err := uuid.Parse(piece.PackID); err != nil {
+		return fmt.Errorf("PackID must be a UUID, got %q", piece.PackID)
+	}
+	if _, err := uuid.Parse(piece.ProductID); err != nil && piece.ProductID != "" {
+		return fmt.Errorf("ProductID must be a UUID or empty, got %q", piece.ProductID)
+	}
+	if !strings.HasPrefix(piece.PieceType, "persona_") {
+		return fmt.Errorf("PieceType must start with persona_, got %q", piece.PieceType)
+	}
+	return nil
+}
+
+// validate checks if the PersonaPieceTintColour has valid colours and concerns one of the piece types
+// passed.
+func (tint PersonaPieceTintColour) validate(types map[string]struct{}) error {
+	if _, ok := types[tint.PieceType]; !ok {
+		return fmt.Errorf("PieceType %q does not match any persona piece", tint.PieceType)
+	}
+	for _, c := range tint.Colours {
+		if !checkTintColour(c) {
+			return fmt.Errorf("invalid colour %q", c)
+		}
+	}
+	return nil
+}
@@ -66,7 +66,7 @@ type ClientBoundMapItemData struct {
 	// YOffset is the Y offset in pixels at which the updated texture area starts. From this Y, the updated
 	// texture will extend exactly Height pixels up.
 	YOffset int32
-	// Pixels is a list of pixel colours for the new texture of the map. It is indexed as Pixels[y*height + x].
+	// Pixels is a list of pixel colours for the new texture of the map. It is indexed as Pixels[y*Width + x].
 	Pixels []color.RGBA
 }
 
@@ -20,6 +20,13 @@ type Compression interface {
 	Decompress(compressed []byte) ([]byte, error)
 }
 
+// bufferDecompressor is implemented by Compressions that are able to decompress data into a buffer that is
+// reused between calls, which is used by a Decoder with ReuseBuffers enabled.
+type bufferDecompressor interface {
+	// decompressInto decompresses the data passed and appends the decompressed data to the buffer.
+	decompressInto(buf *bytes.Buffer, compressed []byte) error
+}
+
 var (
 	// NopCompression is an empty implementation that does not compress data.
 	NopCompression nopCompression
@@ -120,6 +127,22 @@ func (flateCompression) Decompress(compressed []byte) ([]byte, error) {
 	return decompressed.Bytes(), nil
 }
 
+// decompressInto ...
+func (flateCompression) decompressInto(buf *bytes.Buffer, compressed []byte) error {
+	c := flateDecompressPool.Get().(io.ReadCloser)
+	defer flateDecompressPool.Put(c)
+
+	if err := c.(flate.Resetter).Reset(bytes.NewReader(compressed), nil); err != nil {
+		return fmt.Errorf("reset flate: %w", err)
+	}
+	_ = c.Close()
+
+	if _, err := buf.ReadFrom(c); err != nil {
+		return fmt.Errorf("decompress flate: %w", err)
+	}
+	return nil
+}
+
 // EncodeCompression ...
 func (snappyCompression) EncodeCompression() uint16 {
 	return CompressionAlgorithmSnappy
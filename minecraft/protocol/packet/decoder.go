@@ -26,6 +26,11 @@ type Decoder struct {
 	encryption Encryption
 
 	checkPacketLimit bool
+
+	// reuse specifies if decompressed and packets are reused between calls to DecodeBatch.
+	reuse        bool
+	decompressed bytes.Buffer
+	packets      [][]byte
 }
 
 // packetReader is used to read packets immediately instead of copying them in a buffer first. This is a
@@ -63,6 +68,17 @@ func (decoder *Decoder) SetCompression(method Compression) {
 	decoder.compressionMethod = method
 }
 
+// ReuseBuffers makes the Decoder reuse the buffer that batches are decompressed into and the slice that
+// packets are returned in, so that decoding a batch no longer allocates once the buffers have grown to the
+// size of the batches received. Packets returned by Decode and DecodeBatch, and the slice holding them, are
+// then only valid until the next call to either of them: Packets that must be retained longer must be copied,
+// for example using ClonePackets. ReuseBuffers is therefore only useful for callers that handle every packet
+// of a batch before decoding the next one, such as proxies and packet capture tools. A buffer that grew
+// beyond 1 MiB to fit a large batch is released before the next batch is decoded.
+func (decoder *Decoder) ReuseBuffers() {
+	decoder.reuse = true
+}
+
 // DisableBatchPacketLimit disables the check that limits the number of packets allowed in a single packet
 // batch. This should typically be called for Decoders decoding from a server connection.
 func (decoder *Decoder) DisableBatchPacketLimit() {
@@ -80,7 +96,21 @@ const (
 // Decode decodes one 'packet' from the io.Reader passed in NewDecoder(), producing a slice of packets that it
 // held and an error if not successful.
 func (decoder *Decoder) Decode() (packets [][]byte, err error) {
-	var data []byte
+	data, err := decoder.Read()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeBatch(data)
+}
+
+// Read reads one raw 'packet' from the io.Reader passed in NewDecoder(), without decrypting or decompressing
+// it. The data returned may be passed to DecodeBatch to obtain the packets it holds. The data returned is
+// only valid until the next call to Read.
+func (decoder *Decoder) Read() ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
 	if decoder.pr == nil {
 		var n int
 		n, err = decoder.r.Read(decoder.buf)
@@ -91,6 +121,14 @@ func (decoder *Decoder) Decode() (packets [][]byte, err error) {
 	if err != nil {
 		return nil, fmt.Errorf("read batch: %w", err)
 	}
+	return data, nil
+}
+
+// DecodeBatch decrypts and decompresses the raw 'packet' passed, as returned by Read, producing a slice of
+// packets that it held and an error if not successful. Because decryption depends on the batches that were
+// decrypted before, DecodeBatch must be called with batches in the order that they were read and must not be
+// called concurrently.
+func (decoder *Decoder) DecodeBatch(data []byte) (packets [][]byte, err error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -115,18 +153,21 @@ func (decoder *Decoder) Decode() (packets [][]byte, err error) {
 			if !ok {
 				return nil, fmt.Errorf("decompress batch: unknown compression algorithm %v", data[0])
 			}
-			data, err = compression.Decompress(data[1:])
+			data, err = decoder.decompressWith(compression, data[1:])
 			if err != nil {
 				return nil, fmt.Errorf("decompress batch: %w", err)
 			}
 		}
 	} else if decoder.compressionMethod != nil {
-		data, err = decoder.compressionMethod.Decompress(data)
+		data, err = decoder.decompressWith(decoder.compressionMethod, data)
 		if err != nil {
 			return nil, fmt.Errorf("error decompressing packet: %v", err)
 		}
 	}
 
+	if decoder.reuse {
+		packets = decoder.packets[:0]
+	}
 	b := bytes.NewBuffer(data)
 	for b.Len() != 0 {
 		var length uint32
@@ -135,8 +176,47 @@ func (decoder *Decoder) Decode() (packets [][]byte, err error) {
 		}
 		packets = append(packets, b.Next(int(length)))
 	}
+	if decoder.reuse {
+		decoder.packets = packets
+	}
 	if len(packets) > maximumInBatch && decoder.checkPacketLimit {
 		return nil, fmt.Errorf("decode batch: number of packets %v exceeds max=%v", len(packets), maximumInBatch)
 	}
 	return packets, nil
 }
+
+// decompressWith decompresses the data passed using the Compression passed. If ReuseBuffers was called and
+// the Compression supports it, the data is decompressed into the buffer of the Decoder.
+func (decoder *Decoder) decompressWith(compression Compression, data []byte) ([]byte, error) {
+	if c, ok := compression.(bufferDecompressor); ok && decoder.reuse {
+		if decoder.decompressed.Cap() > maxRetainedBuffer {
+			// A single large batch should not keep its buffer allocated for the rest of the connection.
+			decoder.decompressed = bytes.Buffer{}
+		}
+		decoder.decompressed.Reset()
+		if err := c.decompressInto(&decoder.decompressed, data); err != nil {
+			return nil, err
+		}
+		return decoder.decompressed.Bytes(), nil
+	}
+	return compression.Decompress(data)
+}
+
+// maxRetainedBuffer is the maximum capacity of the decompression buffer that a Decoder with ReuseBuffers
+// enabled keeps between batches. Buffers that grew larger are released before the next batch.
+const maxRetainedBuffer = 1024 * 1024
+
+// ClonePackets returns a copy of the packets passed, as returned by Decoder.Decode, that remains valid after
+// the next call to Decode. All packets are copied into a single allocation.
+func ClonePackets(packets [][]byte) [][]byte {
+	n := 0
+	for _, pk := range packets {
+		n += len(pk)
+	}
+	buf, clones := make([]byte, 0, n), make([][]byte, len(packets))
+	for i, pk := range packets {
+		buf = append(buf, pk...)
+		clones[i] = buf[len(buf)-len(pk):]
+	}
+	return clones
+}
@@ -0,0 +1,36 @@
+package packet
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestDecoderReuseBuffersShrink checks if a Decoder with ReuseBuffers enabled releases a decompression
+// buffer that grew to fit a large batch, rather than keeping it for the rest of the connection.
+func TestDecoderReuseBuffersShrink(t *testing.T) {
+	batch := func(size int) []byte {
+		buf := bytes.NewBuffer(nil)
+		enc := NewEncoder(buf)
+		enc.EnableCompression(FlateCompression, false)
+		if err := enc.Encode([][]byte{make([]byte, size)}); err != nil {
+			t.Fatalf("encode batch: %v", err)
+		}
+		return buf.Bytes()
+	}
+	dec := NewDecoder(nil)
+	dec.EnableCompression()
+	dec.ReuseBuffers()
+
+	for _, size := range []int{maxRetainedBuffer * 4, 100} {
+		packets, err := dec.DecodeBatch(batch(size))
+		if err != nil {
+			t.Fatalf("decode batch of %v bytes: %v", size, err)
+		}
+		if len(packets) != 1 || len(packets[0]) != size {
+			t.Fatalf("decoded batch does not hold the packet of %v bytes", size)
+		}
+	}
+	if c := dec.decompressed.Cap(); c > maxRetainedBuffer {
+		t.Fatalf("decompression buffer of %v bytes retained after large batch, expected at most %v", c, maxRetainedBuffer)
+	}
+}
@@ -0,0 +1,209 @@
+package packet
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+const (
+	// dumpMaxElements is the maximum amount of elements of a slice or array shown in a dump. Any elements
+	// after it are omitted.
+	dumpMaxElements = 32
+	// dumpMaxDepth is the maximum depth of nested structs, slices and maps shown in a dump.
+	dumpMaxDepth = 8
+	// dumpRowSize is the amount of bytes shown on a single row of a hex dump.
+	dumpRowSize = 16
+)
+
+// Dump renders the Packet passed as a string for debugging purposes. The string holds a breakdown of all
+// fields of the packet, followed by an annotated hex dump of the payload of the packet as written to the
+// network, using the shield ID passed. For an Unknown packet, the fields are not known and only the hex dump
+// of its payload is produced.
+func Dump(pk Packet, shieldID int32) (s string) {
+	buf := bytes.NewBuffer(nil)
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				s = dump(pk, buf.Bytes(), -1, fmt.Errorf("encode packet %T: %v", pk, err))
+			}
+		}()
+		pk.Marshal(protocol.NewWriter(buf, shieldID))
+	}()
+	if s != "" {
+		return s
+	}
+	return dump(pk, buf.Bytes(), -1, nil)
+}
+
+// DumpPayload decodes the payload of a packet with the ID passed using the Pool passed and renders it as a
+// string for debugging purposes, similarly to Dump. DumpPayload is meant for payloads of packets that could
+// not be decoded, such as those passed to a function added with Conn.AddPacketObserver: Rather than failing,
+// it renders the fields that could be decoded and annotates the offset in the hex dump at which decoding
+// stopped, along with the error that was encountered. If the ID is not present in the Pool, the payload is
+// rendered as an Unknown packet.
+func DumpPayload(pool Pool, id uint32, payload []byte, shieldID int32) string {
+	var pk Packet = &Unknown{PacketID: id}
+	if f, ok := pool[id]; ok {
+		pk = f()
+	}
+	r := bytes.NewBuffer(payload)
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("decode packet %T: %v", pk, recovered)
+			}
+		}()
+		pk.Marshal(protocol.NewReader(r, shieldID, false))
+		if r.Len() != 0 {
+			return fmt.Errorf("decode packet %T: %v unread bytes left", pk, r.Len())
+		}
+		return nil
+	}()
+	offset := -1
+	if err != nil {
+		offset = len(payload) - r.Len()
+	}
+	return dump(pk, payload, offset, err)
+}
+
+// dump renders a Packet and its payload. If offset is not -1, the byte at that offset in the payload is
+// marked as the position at which decoding stopped. If err is not nil, it is added to the dump.
+func dump(pk Packet, payload []byte, offset int, err error) string {
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "%v (ID=%v/0x%x, %v bytes)\n", reflect.TypeOf(pk).Elem().Name(), pk.ID(), pk.ID(), len(payload))
+	if err != nil {
+		_, _ = fmt.Fprintf(b, "Error: %v\n", err)
+	}
+	if _, ok := pk.(*Unknown); !ok {
+		b.WriteString("Fields:\n")
+		v := reflect.ValueOf(pk).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			dumpValue(b, v.Type().Field(i).Name, v.Field(i), 1)
+		}
+	}
+	b.WriteString("Payload:\n")
+	dumpHex(b, payload, offset)
+	return b.String()
+}
+
+// dumpValue writes a line for the reflect.Value passed with the name passed, and a line for each of its
+// fields or elements if it is a struct, slice, array or map.
+func dumpValue(b *strings.Builder, name string, v reflect.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			_, _ = fmt.Fprintf(b, "%v%v %v = nil\n", indent, name, v.Type())
+			return
+		}
+		v = v.Elem()
+	}
+	if depth > dumpMaxDepth {
+		_, _ = fmt.Fprintf(b, "%v%v %v = ...\n", indent, name, v.Type())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.NumField() == 0 || !v.Type().Field(0).IsExported() {
+			// Structs without exported fields, such as protocol.Optional, are written as a single value.
+			_, _ = fmt.Fprintf(b, "%v%v %v = %+v\n", indent, name, v.Type(), v.Interface())
+			return
+		}
+		_, _ = fmt.Fprintf(b, "%v%v %v\n", indent, name, v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				dumpValue(b, v.Type().Field(i).Name, v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			_, _ = fmt.Fprintf(b, "%v%v %v (len=%v) = 0x%x\n", indent, name, v.Type(), v.Len(), truncateBytes(v))
+			return
+		}
+		if v.Len() == 0 || isScalar(v.Type().Elem()) && v.Len() <= dumpMaxElements {
+			_, _ = fmt.Fprintf(b, "%v%v %v (len=%v) = %v\n", indent, name, v.Type(), v.Len(), v.Interface())
+			return
+		}
+		_, _ = fmt.Fprintf(b, "%v%v %v (len=%v)\n", indent, name, v.Type(), v.Len())
+		for i := 0; i < min(v.Len(), dumpMaxElements); i++ {
+			dumpValue(b, fmt.Sprintf("[%v]", i), v.Index(i), depth+1)
+		}
+		if v.Len() > dumpMaxElements {
+			_, _ = fmt.Fprintf(b, "%v  ... %v more\n", indent, v.Len()-dumpMaxElements)
+		}
+	default:
+		_, _ = fmt.Fprintf(b, "%v%v %v = %+v\n", indent, name, v.Type(), v.Interface())
+	}
+}
+
+// isScalar checks if values of the reflect.Type passed are written on a single line in a dump.
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Pointer:
+		return false
+	}
+	return true
+}
+
+// truncateBytes returns the bytes of the byte slice or array passed, truncated to at most dumpRowSize *
+// dumpMaxElements bytes.
+func truncateBytes(v reflect.Value) []byte {
+	n := min(v.Len(), dumpRowSize*dumpMaxElements)
+	data := make([]byte, n)
+	reflect.Copy(reflect.ValueOf(data), v.Slice(0, n))
+	return data
+}
+
+// dumpHex writes a hex dump of the data passed to the strings.Builder, with rows of dumpRowSize bytes
+// prefixed with their offset and followed by their printable ASCII characters. If offset is not -1, the byte
+// at that offset is marked on the line below its row.
+func dumpHex(b *strings.Builder, data []byte, offset int) {
+	if len(data) == 0 {
+		b.WriteString("  (empty)\n")
+	}
+	for row := 0; row < len(data); row += dumpRowSize {
+		line := data[row:min(row+dumpRowSize, len(data))]
+		_, _ = fmt.Fprintf(b, "  %04x  ", row)
+		for i := 0; i < dumpRowSize; i++ {
+			if i < len(line) {
+				_, _ = fmt.Fprintf(b, "%02x ", line[i])
+			} else {
+				b.WriteString("   ")
+			}
+			if i == dumpRowSize/2-1 {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(" |")
+		for _, c := range line {
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		b.WriteString("|\n")
+		if offset >= row && offset < row+dumpRowSize {
+			dumpMarker(b, offset)
+		}
+	}
+	if offset == len(data) && offset != 0 {
+		_, _ = fmt.Fprintf(b, "  decoding stopped at end of payload (offset 0x%04x)\n", offset)
+	}
+}
+
+// dumpMarker writes a marker pointing at the byte at the offset passed, as written on the row above by
+// dumpHex.
+func dumpMarker(b *strings.Builder, offset int) {
+	col := offset % dumpRowSize
+	pad := 8 + col*3
+	if col >= dumpRowSize/2 {
+		pad++
+	}
+	_, _ = fmt.Fprintf(b, "%v^^ decoding stopped here (offset 0x%04x)\n", strings.Repeat(" ", pad), offset)
+}
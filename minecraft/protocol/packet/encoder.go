@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/sandertv/gophertunnel/minecraft/internal"
 )
@@ -17,6 +18,15 @@ type Encoder struct {
 	oldCompression bool
 
 	encryption Encryption
+
+	// run is the function that batches are compressed with if parallel compression is enabled. prev is
+	// closed once the batch last passed to Encode is written.
+	run  func(f func())
+	prev chan struct{}
+
+	pending sync.WaitGroup
+	errMu   sync.Mutex
+	err     error
 }
 
 // NewEncoder returns a new Encoder for the io.Writer passed. Each final packet produced by the Encoder is
@@ -37,46 +47,107 @@ func (encoder *Encoder) EnableCompression(compression Compression, oldCompressio
 	encoder.oldCompression = oldCompression
 }
 
+// EnableParallelCompression makes the Encoder compress batches asynchronously. Each batch passed to Encode
+// is compressed in a function passed to run, which may call it on another goroutine, such as one of a pool of
+// workers. Batches are still encrypted and written in the order that Encode was called. Once enabled, Encode
+// returns as soon as the batch is handed off to run, and errors writing a batch are returned by the next call
+// to Encode or Wait. run must call the function passed to it eventually, and must not block indefinitely on
+// functions passed to it earlier.
+func (encoder *Encoder) EnableParallelCompression(run func(f func())) {
+	encoder.run = run
+}
+
+// Wait waits until all batches passed to Encode are written and returns the first error that occurred while
+// writing them, if any. Wait returns immediately if parallel compression is not enabled.
+func (encoder *Encoder) Wait() error {
+	encoder.pending.Wait()
+	return encoder.loadErr()
+}
+
 // Encode encodes the packets passed. It writes all of them as a single packet which is  compressed and
 // optionally encrypted.
 func (encoder *Encoder) Encode(packets [][]byte) error {
 	buf := internal.BufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		// Reset the buffer, so we can return it to the buffer pool safely.
-		buf.Reset()
-		internal.BufferPool.Put(buf)
-	}()
 
 	l := make([]byte, 5)
 	for _, packet := range packets {
 		// Each packet is prefixed with a varuint32 specifying the length of the packet.
 		if err := writeVaruint32(buf, uint32(len(packet)), l); err != nil {
+			releaseBuffer(buf)
 			return fmt.Errorf("encode batch: write packet length: %w", err)
 		}
 		if _, err := buf.Write(packet); err != nil {
+			releaseBuffer(buf)
 			return fmt.Errorf("encode batch: write packet payload: %w", err)
 		}
 	}
+	if encoder.run == nil {
+		defer releaseBuffer(buf)
+		data, err := compressBatch(buf.Bytes(), encoder.compression, encoder.oldCompression)
+		if err != nil {
+			return err
+		}
+		return encoder.write(data, encoder.encryption)
+	}
+	if err := encoder.loadErr(); err != nil {
+		releaseBuffer(buf)
+		return err
+	}
+
+	// The compression and encryption are captured here, so that enabling either of them after calling Encode
+	// does not affect batches that are still being compressed.
+	compression, oldCompression, encryption := encoder.compression, encoder.oldCompression, encoder.encryption
+	prev, done := encoder.prev, make(chan struct{})
+	encoder.prev = done
+
+	encoder.pending.Add(1)
+	encoder.run(func() {
+		defer encoder.pending.Done()
+		defer close(done)
 
-	data := buf.Bytes()
+		data, err := compressBatch(buf.Bytes(), compression, oldCompression)
+		releaseBuffer(buf)
+		if prev != nil {
+			<-prev
+		}
+		if err == nil && encoder.loadErr() == nil {
+			err = encoder.write(data, encryption)
+		}
+		if err != nil {
+			encoder.errMu.Lock()
+			if encoder.err == nil {
+				encoder.err = err
+			}
+			encoder.errMu.Unlock()
+		}
+	})
+	return nil
+}
+
+// compressBatch compresses the encoded packets in data using the Compression passed, if not nil, and
+// prepends the batch header to the result.
+func compressBatch(data []byte, compression Compression, oldCompression bool) ([]byte, error) {
 	prepend := []byte{header}
-	if encoder.compression != nil {
-		if !encoder.oldCompression {
-			prepend = append(prepend, byte(encoder.compression.EncodeCompression()))
+	if compression != nil {
+		if !oldCompression {
+			prepend = append(prepend, byte(compression.EncodeCompression()))
 		}
 
 		var err error
-		data, err = encoder.compression.Compress(data)
+		data, err = compression.Compress(data)
 		if err != nil {
-			return fmt.Errorf("compress batch: %w", err)
+			return nil, fmt.Errorf("compress batch: %w", err)
 		}
 	}
+	return append(prepend, data...), nil
+}
 
-	data = append(prepend, data...)
-	if encoder.encryption != nil {
+// write optionally encrypts the batch passed and writes it to the io.Writer of the Encoder.
+func (encoder *Encoder) write(data []byte, encryption Encryption) error {
+	if encryption != nil {
 		// If the encryption session is not nil, encryption is enabled, meaning we should encrypt the
 		// compressed data of this packet.
-		data = encoder.encryption.Encrypt(data)
+		data = encryption.Encrypt(data)
 	}
 	if _, err := encoder.w.Write(data); err != nil {
 		return fmt.Errorf("write batch: %w", err)
@@ -84,6 +155,20 @@ func (encoder *Encoder) Encode(packets [][]byte) error {
 	return nil
 }
 
+// loadErr returns the first error that occurred while writing a batch compressed in parallel.
+func (encoder *Encoder) loadErr() error {
+	encoder.errMu.Lock()
+	defer encoder.errMu.Unlock()
+	return encoder.err
+}
+
+// releaseBuffer resets the buffer passed and returns it to the internal.BufferPool.
+func releaseBuffer(buf *bytes.Buffer) {
+	// Reset the buffer, so we can return it to the buffer pool safely.
+	buf.Reset()
+	internal.BufferPool.Put(buf)
+}
+
 // writeVaruint32 writes a uint32 to the destination buffer passed with a size of 1-5 bytes. It uses byte
 // slice b in order to prevent allocations.
 func writeVaruint32(dst io.Writer, x uint32, b []byte) error {
@@ -1,12 +1,13 @@
 package packet
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/binary"
 	"fmt"
+	"hash"
 )
 
 // Encryption represents an interface for encrypting, decrypting, and verifying batches of data.
@@ -24,11 +25,16 @@ type Encryption interface {
 // ctr holds an encryption session with several fields required to encryption and/or decrypt incoming
 // packets. It may be initialised using secret key bytes computed using the shared secret produced with a
 // private and a public ECDSA key.
+// The cipher.Stream of a ctr is created from the block of crypto/aes, so that it XORs whole batches using
+// the hardware accelerated implementation of AES-CTR where available. The hash used to compute checksums is
+// re-used between batches, so that encrypting or decrypting a batch does not allocate.
 type ctr struct {
 	sendCounter uint64
 	buf         [8]byte
+	sum         [sha256.Size]byte
 	keyBytes    []byte
 	stream      cipher.Stream
+	hash        hash.Hash
 }
 
 // NewCTREncryption returns a new CTR encryption 'session' using the secret key bytes passed. The session has its cipher
@@ -37,23 +43,13 @@ func NewCTREncryption(keyBytes []byte) Encryption {
 	block, _ := aes.NewCipher(keyBytes[:])
 	first12 := append([]byte(nil), keyBytes[:12]...)
 	stream := cipher.NewCTR(block, append(first12, 0, 0, 0, 2))
-	return &ctr{keyBytes: keyBytes, stream: stream}
+	return &ctr{keyBytes: keyBytes, stream: stream, hash: sha256.New()}
 }
 
 // Encrypt ...
 func (c *ctr) Encrypt(data []byte) []byte {
-	// We first write the current send counter to a buffer and use it to produce a packet checksum.
-	binary.LittleEndian.PutUint64(c.buf[:], c.sendCounter)
-	c.sendCounter++
-
-	// We produce a hash existing of the send counter, packet data and key bytes.
-	hash := sha256.New()
-	hash.Write(c.buf[:])
-	hash.Write(data[1:])
-	hash.Write(c.keyBytes)
-
 	// We add the first 8 bytes of the checksum to the data and encryption it.
-	data = append(data, hash.Sum(nil)[:8]...)
+	data = append(data, c.checksum(data[1:])...)
 
 	c.stream.XORKeyStream(data[1:], data[1:])
 	return data
@@ -70,21 +66,26 @@ func (c *ctr) Verify(data []byte) error {
 		return fmt.Errorf("encrypted packet must be at least 8 bytes long, got %v", len(data))
 	}
 	sum := data[len(data)-8:]
+	ourSum := c.checksum(data[:len(data)-8])
 
+	// Finally we check if the original sum was equal to the sum we just produced.
+	if subtle.ConstantTimeCompare(sum, ourSum) != 1 {
+		return fmt.Errorf("invalid checksum of packet %v: expected %x, got %x", c.sendCounter-1, ourSum, sum)
+	}
+	return nil
+}
+
+// checksum produces the packet checksum of the data passed and increments the send counter. The slice
+// returned is only valid until the next call to checksum.
+func (c *ctr) checksum(data []byte) []byte {
 	// We first write the current send counter to a buffer and use it to produce a packet checksum.
 	binary.LittleEndian.PutUint64(c.buf[:], c.sendCounter)
 	c.sendCounter++
 
 	// We produce a hash existing of the send counter, packet data and key bytes.
-	hash := sha256.New()
-	hash.Write(c.buf[:])
-	hash.Write(data[:len(data)-8])
-	hash.Write(c.keyBytes)
-	ourSum := hash.Sum(nil)[:8]
-
-	// Finally we check if the original sum was equal to the sum we just produced.
-	if !bytes.Equal(sum, ourSum) {
-		return fmt.Errorf("invalid checksum of packet %v: expected %x, got %x", c.sendCounter-1, ourSum, sum)
-	}
-	return nil
+	c.hash.Reset()
+	c.hash.Write(c.buf[:])
+	c.hash.Write(data)
+	c.hash.Write(c.keyBytes)
+	return c.hash.Sum(c.sum[:0])[:8]
 }
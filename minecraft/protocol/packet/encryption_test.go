@@ -0,0 +1,73 @@
+package packet_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"testing"
+)
+
+// batchSizes are the sizes of batches that encryption is benchmarked with.
+var batchSizes = []int{64, 1024, 64 * 1024}
+
+// TestCTREncryptionRoundTrip checks if batches encrypted using CTR encryption are decrypted and verified by
+// a session with the same key, including when the data passed has spare capacity.
+func TestCTREncryptionRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	enc, dec := packet.NewCTREncryption(key), packet.NewCTREncryption(key)
+	for i, size := range []int{1, 15, 16, 17, 1000, 70000} {
+		data := make([]byte, size, size+i*4)
+		for j := range data {
+			data[j] = byte(j)
+		}
+		want := append([]byte(nil), data[1:]...)
+
+		encrypted := enc.Encrypt(data)
+		encrypted = encrypted[1:]
+		dec.Decrypt(encrypted)
+		if err := dec.Verify(encrypted); err != nil {
+			t.Fatalf("batch %v of size %v: %v", i, size, err)
+		}
+		if !bytes.Equal(encrypted[:len(encrypted)-8], want) {
+			t.Fatalf("batch %v of size %v: decrypted data does not match", i, size)
+		}
+	}
+}
+
+// BenchmarkCTREncrypt measures encrypting batches of different sizes.
+func BenchmarkCTREncrypt(b *testing.B) {
+	for _, size := range batchSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			enc := packet.NewCTREncryption(bytes.Repeat([]byte{7}, 32))
+			data := make([]byte, size, size+8)
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				enc.Encrypt(data[:size])
+			}
+		})
+	}
+}
+
+// BenchmarkCTRRoundTrip measures encrypting a batch and decrypting and verifying it again using a second
+// session, for batches of different sizes.
+func BenchmarkCTRRoundTrip(b *testing.B) {
+	for _, size := range batchSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			key := bytes.Repeat([]byte{7}, 32)
+			enc, dec := packet.NewCTREncryption(key), packet.NewCTREncryption(key)
+			data := make([]byte, size, size+8)
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				encrypted := enc.Encrypt(data[:size])[1:]
+				dec.Decrypt(encrypted)
+				if err := dec.Verify(encrypted); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package packet
+
+import (
+	"bytes"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Marshalled is the pre-serialised form of a Packet, as returned by Marshal. It may be written to any
+// amount of connections without the Packet being encoded again for every one of them, which is useful for
+// servers broadcasting the same packet to many players. A Marshalled is immutable and safe for concurrent
+// use.
+type Marshalled struct {
+	pk       Packet
+	shieldID int32
+	data     []byte
+	// hdrLen is the length of the Header at the start of data.
+	hdrLen int
+}
+
+// Marshal encodes the Packet passed using the latest protocol and returns its pre-serialised form. Item
+// stacks in the packet are written assuming a shield ID of 0. MarshalShieldID should be used for packets
+// holding item stacks instead. The Packet passed must not be modified after calling Marshal.
+func Marshal(pk Packet) *Marshalled {
+	return MarshalShieldID(pk, 0)
+}
+
+// MarshalShieldID encodes the Packet passed just like Marshal. Unlike Marshal, it writes item stacks using
+// the shield ID passed, which should be the shield ID of the connections that it is written to.
+func MarshalShieldID(pk Packet, shieldID int32) *Marshalled {
+	buf := bytes.NewBuffer(make([]byte, 0, SizeShieldID(pk, shieldID)))
+	hdr := Header{PacketID: pk.ID()}
+	_ = hdr.Write(buf)
+	hdrLen := buf.Len()
+	pk.Marshal(protocol.NewWriter(buf, shieldID))
+	return &Marshalled{pk: pk, shieldID: shieldID, data: buf.Bytes(), hdrLen: hdrLen}
+}
+
+// Packet returns the Packet that was marshalled. It must not be modified.
+func (m *Marshalled) Packet() Packet {
+	return m.pk
+}
+
+// ShieldID returns the shield ID that item stacks in the Packet were written with.
+func (m *Marshalled) ShieldID() int32 {
+	return m.shieldID
+}
+
+// Bytes returns the serialised Packet, including its Header. The slice returned must not be modified.
+func (m *Marshalled) Bytes() []byte {
+	return m.data
+}
+
+// Payload returns the serialised Packet without its Header. The slice returned must not be modified.
+func (m *Marshalled) Payload() []byte {
+	return m.data[m.hdrLen:]
+}
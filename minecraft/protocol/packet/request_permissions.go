@@ -13,7 +13,8 @@ type RequestPermissions struct {
 	// PermissionLevel is the current permission level of the player. This is one of the constants that may be found
 	// in the AdventureSettings packet.
 	PermissionLevel uint8
-	// RequestedPermissions contains the requested permission flags.
+	// RequestedPermissions contains the requested permission flags. These are the protocol.AbilityBuild to
+	// protocol.AbilityTeleport constants, which together make up protocol.AbilitiesOperator.
 	RequestedPermissions uint16
 }
 
@@ -0,0 +1,43 @@
+package packet
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Size returns the exact size in bytes that the Packet passed occupies when it is encoded, including the
+// varuint32 Header in front of it. The size does not include the length prefix the packet gets in a batch,
+// nor does it take compression or encryption into account.
+// Size may be used to budget the bandwidth of a batch before it is built, for example to decide if low
+// priority packets should be deferred to a later tick.
+func Size(pk Packet) int {
+	return SizeShieldID(pk, 0)
+}
+
+// SizeShieldID returns the exact size in bytes that the Packet passed occupies when encoded, just like Size.
+// Unlike Size, it writes item stacks using the shield ID passed, which results in the exact size for item
+// stacks holding a shield.
+func SizeShieldID(pk Packet, shieldID int32) int {
+	c := &byteCounter{}
+	hdr := Header{PacketID: pk.ID()}
+	_ = hdr.Write(c)
+	pk.Marshal(protocol.NewWriter(c, shieldID))
+	return c.n
+}
+
+// byteCounter implements the io.Writer and io.ByteWriter interfaces. Instead of storing the data written, it
+// only counts the amount of bytes that were written to it.
+type byteCounter struct {
+	n int
+}
+
+// Write adds the length of b to the byte count.
+func (c *byteCounter) Write(b []byte) (n int, err error) {
+	c.n += len(b)
+	return len(b), nil
+}
+
+// WriteByte adds one to the byte count.
+func (c *byteCounter) WriteByte(byte) error {
+	c.n++
+	return nil
+}
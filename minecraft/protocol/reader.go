@@ -24,6 +24,9 @@ type Reader struct {
 		io.Reader
 		io.ByteReader
 	}
+	// buf is the underlying source if it is a *bytes.Buffer, which is the case for packets read from a
+	// connection. Primitives are read from it directly to avoid the cost of calls through the interface.
+	buf           *bytes.Buffer
 	shieldID      int32
 	limitsEnabled bool
 }
@@ -33,7 +36,41 @@ func NewReader(r interface {
 	io.Reader
 	io.ByteReader
 }, shieldID int32, enableLimits bool) *Reader {
-	return &Reader{r: r, shieldID: shieldID, limitsEnabled: enableLimits}
+	buf, _ := r.(*bytes.Buffer)
+	return &Reader{r: r, buf: buf, shieldID: shieldID, limitsEnabled: enableLimits}
+}
+
+// readByte reads a single byte from the underlying buffer.
+func (r *Reader) readByte() byte {
+	var (
+		b   byte
+		err error
+	)
+	if r.buf != nil {
+		b, err = r.buf.ReadByte()
+	} else {
+		b, err = r.r.ReadByte()
+	}
+	if err != nil {
+		r.panic(err)
+	}
+	return b
+}
+
+// read reads exactly l bytes from the underlying buffer. The slice returned may point into the underlying
+// buffer, so it must be copied before the next read.
+func (r *Reader) read(l int) []byte {
+	if r.buf != nil {
+		if l > r.buf.Len() {
+			r.panic(io.ErrUnexpectedEOF)
+		}
+		return r.buf.Next(l)
+	}
+	data := make([]byte, l)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		r.panic(err)
+	}
+	return data
 }
 
 type Reads interface {
@@ -51,11 +88,7 @@ func (r *Reader) LimitsEnabled() bool {
 
 // Uint8 reads a uint8 from the underlying buffer.
 func (r *Reader) Uint8(x *uint8) {
-	var err error
-	*x, err = r.r.ReadByte()
-	if err != nil {
-		r.panic(err)
-	}
+	*x = r.readByte()
 }
 
 // Int8 reads an int8 from the underlying buffer.
@@ -67,10 +100,7 @@ func (r *Reader) Int8(x *int8) {
 
 // Bool reads a bool from the underlying buffer.
 func (r *Reader) Bool(x *bool) {
-	u, err := r.r.ReadByte()
-	if err != nil {
-		r.panic(err)
-	}
+	u := r.readByte()
 	*x = *(*bool)(unsafe.Pointer(&u))
 }
 
@@ -85,11 +115,10 @@ func (r *Reader) StringUTF(x *string) {
 	if l > math.MaxInt16 {
 		r.panic(errStringTooLong)
 	}
-	data := make([]byte, l)
-	if _, err := r.r.Read(data); err != nil {
-		r.panic(err)
+	if l < 0 {
+		r.panicf("negative string length %v", l)
 	}
-	*x = *(*string)(unsafe.Pointer(&data))
+	*x = string(r.read(l))
 }
 
 // String reads a string from the underlying buffer.
@@ -100,11 +129,7 @@ func (r *Reader) String(x *string) {
 	if l > math.MaxInt32 {
 		r.panic(errStringTooLong)
 	}
-	data := make([]byte, l)
-	if _, err := r.r.Read(data); err != nil {
-		r.panic(err)
-	}
-	*x = *(*string)(unsafe.Pointer(&data))
+	*x = string(r.read(l))
 }
 
 // ByteSlice reads a byte slice from the underlying buffer, similarly to String.
@@ -115,9 +140,9 @@ func (r *Reader) ByteSlice(x *[]byte) {
 	if l > math.MaxInt32 {
 		r.panic(errStringTooLong)
 	}
-	data := make([]byte, l)
-	if _, err := r.r.Read(data); err != nil {
-		r.panic(err)
+	data := r.read(l)
+	if r.buf != nil {
+		data = append(make([]byte, 0, l), data...)
 	}
 	*x = data
 }
@@ -244,19 +269,13 @@ func (r *Reader) NBTList(m *[]any, encoding nbt.Encoding) {
 
 // UUID reads a uuid.UUID from the underlying buffer.
 func (r *Reader) UUID(x *uuid.UUID) {
-	b := make([]byte, 16)
-	if _, err := r.r.Read(b); err != nil {
-		r.panic(err)
-	}
+	b := r.read(16)
 
 	// The UUIDs we read are Little Endian, but the uuid library is based on Big Endian UUIDs, so we need to
-	// reverse the two int64s the UUID is composed of, then reverse their bytes too.
-	b = append(b[8:], b[:8]...)
-	var arr [16]byte
-	for i, j := 0, 15; i < j; i, j = i+1, j-1 {
-		arr[i], arr[j] = b[j], b[i]
+	// reverse the bytes of the two int64s the UUID is composed of.
+	for i := 0; i < 8; i++ {
+		x[i], x[8+i] = b[7-i], b[15-i]
 	}
-	*x = arr
 }
 
 // PlayerInventoryAction reads a PlayerInventoryAction.
@@ -668,80 +687,59 @@ var errBitsetOverflow = errors.New("bitset overflows size")
 
 // Varint64 reads up to 10 bytes from the underlying buffer into an int64.
 func (r *Reader) Varint64(x *int64) {
-	var ux uint64
-	for i := 0; i < 70; i += 7 {
-		b, err := r.r.ReadByte()
-		if err != nil {
-			r.panic(err)
-		}
-
-		ux |= uint64(b&0x7f) << i
-		if b&0x80 == 0 {
-			*x = int64(ux >> 1)
-			if ux&1 != 0 {
-				*x = ^*x
-			}
-			return
-		}
+	ux := r.varuint(10)
+	*x = int64(ux >> 1)
+	if ux&1 != 0 {
+		*x = ^*x
 	}
-	r.panic(errVarIntOverflow)
 }
 
 // Varuint64 reads up to 10 bytes from the underlying buffer into a uint64.
 func (r *Reader) Varuint64(x *uint64) {
-	var v uint64
-	for i := 0; i < 70; i += 7 {
-		b, err := r.r.ReadByte()
-		if err != nil {
-			r.panic(err)
-		}
-
-		v |= uint64(b&0x7f) << i
-		if b&0x80 == 0 {
-			*x = v
-			return
-		}
-	}
-	r.panic(errVarIntOverflow)
+	*x = r.varuint(10)
 }
 
 // Varint32 reads up to 5 bytes from the underlying buffer into an int32.
 func (r *Reader) Varint32(x *int32) {
-	var ux uint32
-	for i := 0; i < 35; i += 7 {
-		b, err := r.r.ReadByte()
-		if err != nil {
-			r.panic(err)
-		}
-
-		ux |= uint32(b&0x7f) << i
-		if b&0x80 == 0 {
-			*x = int32(ux >> 1)
-			if ux&1 != 0 {
-				*x = ^*x
-			}
-			return
-		}
+	ux := uint32(r.varuint(5))
+	*x = int32(ux >> 1)
+	if ux&1 != 0 {
+		*x = ^*x
 	}
-	r.panic(errVarIntOverflow)
 }
 
 // Varuint32 reads up to 5 bytes from the underlying buffer into a uint32.
 func (r *Reader) Varuint32(x *uint32) {
-	var v uint32
-	for i := 0; i < 35; i += 7 {
-		b, err := r.r.ReadByte()
-		if err != nil {
-			r.panic(err)
-		}
+	*x = uint32(r.varuint(5))
+}
 
-		v |= uint32(b&0x7f) << i
+// varuint reads a varuint of up to n bytes from the underlying buffer. If the underlying buffer is a
+// *bytes.Buffer, the varuint is decoded from its unread bytes directly.
+func (r *Reader) varuint(n int) uint64 {
+	var v uint64
+	if r.buf != nil {
+		b := r.buf.Bytes()
+		for i := 0; i < n; i++ {
+			if i == len(b) {
+				r.panic(io.EOF)
+			}
+			v |= uint64(b[i]&0x7f) << (i * 7)
+			if b[i]&0x80 == 0 {
+				r.buf.Next(i + 1)
+				return v
+			}
+		}
+		r.panic(errVarIntOverflow)
+	}
+	for i := 0; i < n; i++ {
+		b := r.readByte()
+		v |= uint64(b&0x7f) << (i * 7)
 		if b&0x80 == 0 {
-			*x = v
-			return
+			return v
 		}
 	}
 	r.panic(errVarIntOverflow)
+	return 0
 }
 
 // panicf panics with the format and values passed and assigns the error created to the Reader.
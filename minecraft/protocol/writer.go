@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/google/uuid"
@@ -23,6 +24,9 @@ type Writer struct {
 		io.Writer
 		io.ByteWriter
 	}
+	// buf is the underlying destination if it is a *bytes.Buffer, which is the case for packets written to a
+	// connection. Primitives are written to it directly to avoid the cost of calls through the interface.
+	buf      *bytes.Buffer
 	shieldID int32
 }
 
@@ -31,7 +35,8 @@ func NewWriter(w interface {
 	io.Writer
 	io.ByteWriter
 }, shieldID int32) *Writer {
-	return &Writer{w: w, shieldID: shieldID}
+	buf, _ := w.(*bytes.Buffer)
+	return &Writer{w: w, buf: buf, shieldID: shieldID}
 }
 
 // Uint8 writes a uint8 to the underlying buffer.
@@ -53,14 +58,14 @@ func (w *Writer) Bool(x *bool) {
 func (w *Writer) StringUTF(x *string) {
 	l := int16(len(*x))
 	w.Int16(&l)
-	_, _ = w.w.Write([]byte(*x))
+	w.writeString(*x)
 }
 
 // String writes a string, prefixed with a varuint32, to the underlying buffer.
 func (w *Writer) String(x *string) {
 	l := uint32(len(*x))
 	w.Varuint32(&l)
-	_, _ = w.w.Write([]byte(*x))
+	w.writeString(*x)
 }
 
 // ByteSlice writes a []byte, prefixed with a varuint32, to the underlying buffer.
@@ -151,11 +156,17 @@ func (w *Writer) VarRGBA(x *color.RGBA) {
 
 // UUID writes a UUID to the underlying buffer.
 func (w *Writer) UUID(x *uuid.UUID) {
-	b := append((*x)[8:], (*x)[:8]...)
-	for i, j := 0, 15; i < j; i, j = i+1, j-1 {
-		b[i], b[j] = b[j], b[i]
+	// The uuid library is based on Big Endian UUIDs, so the bytes of the two int64s that the UUID is
+	// composed of are reversed to write them as Little Endian.
+	var b [16]byte
+	for i := 0; i < 8; i++ {
+		b[i], b[8+i] = x[7-i], x[15-i]
+	}
+	if w.buf != nil {
+		_, _ = w.buf.Write(b[:])
+		return
 	}
-	_, _ = w.w.Write(b)
+	_, _ = w.w.Write(b[:])
 }
 
 // PlayerInventoryAction writes a PlayerInventoryAction.
@@ -182,21 +193,20 @@ func (w *Writer) GameRule(x *GameRule) {
 	w.String(&x.Name)
 	w.Bool(&x.CanBeModifiedByPlayer)
 
-	switch v := x.Value.(type) {
-	case bool:
+	if v, ok := x.Bool(); ok {
 		id := uint32(1)
 		w.Varuint32(&id)
 		w.Bool(&v)
-	case uint32:
+	} else if v, ok := x.Int(); ok {
 		id := uint32(2)
 		w.Varuint32(&id)
 		w.Varuint32(&v)
-	case float32:
+	} else if v, ok := x.Float(); ok {
 		id := uint32(3)
 		w.Varuint32(&id)
 		w.Float32(&v)
-	default:
-		w.UnknownEnumOption(fmt.Sprintf("%T", v), "game rule type")
+	} else {
+		w.UnknownEnumOption(fmt.Sprintf("%T", x.Value), "game rule type")
 	}
 }
 
@@ -501,21 +511,12 @@ func (w *Writer) Varint64(x *int64) {
 	if u < 0 {
 		ux = ^ux
 	}
-	for ux >= 0x80 {
-		_ = w.w.WriteByte(byte(ux) | 0x80)
-		ux >>= 7
-	}
-	_ = w.w.WriteByte(byte(ux))
+	w.varuint(ux)
 }
 
 // Varuint64 writes a uint64 as 1-10 bytes to the underlying buffer.
 func (w *Writer) Varuint64(x *uint64) {
-	u := *x
-	for u >= 0x80 {
-		_ = w.w.WriteByte(byte(u) | 0x80)
-		u >>= 7
-	}
-	_ = w.w.WriteByte(byte(u))
+	w.varuint(*x)
 }
 
 // Varint32 writes an int32 as 1-5 bytes to the underlying buffer.
@@ -525,21 +526,12 @@ func (w *Writer) Varint32(x *int32) {
 	if u < 0 {
 		ux = ^ux
 	}
-	for ux >= 0x80 {
-		_ = w.w.WriteByte(byte(ux) | 0x80)
-		ux >>= 7
-	}
-	_ = w.w.WriteByte(byte(ux))
+	w.varuint(uint64(ux))
 }
 
 // Varuint32 writes a uint32 as 1-5 bytes to the underlying buffer.
 func (w *Writer) Varuint32(x *uint32) {
-	u := *x
-	for u >= 0x80 {
-		_ = w.w.WriteByte(byte(u) | 0x80)
-		u >>= 7
-	}
-	_ = w.w.WriteByte(byte(u))
+	w.varuint(uint64(*x))
 }
 
 // NBT writes a map as NBT to the underlying buffer using the encoding passed.
@@ -575,3 +567,26 @@ func (w *Writer) InvalidValue(value any, forField, reason string) {
 func (w *Writer) panicf(format string, a ...any) {
 	panic(fmt.Errorf(format, a...))
 }
+
+// varuint writes a uint64 as 1-10 bytes to the underlying buffer. Values that fit in a uint32 take up at most
+// 5 bytes.
+func (w *Writer) varuint(u uint64) {
+	if w.buf != nil {
+		_, _ = w.buf.Write(binary.AppendUvarint(w.buf.AvailableBuffer(), u))
+		return
+	}
+	for u >= 0x80 {
+		_ = w.w.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	_ = w.w.WriteByte(byte(u))
+}
+
+// writeString writes the bytes of a string to the underlying buffer without converting it to a []byte.
+func (w *Writer) writeString(s string) {
+	if w.buf != nil {
+		_, _ = w.buf.WriteString(s)
+		return
+	}
+	_, _ = io.WriteString(w.w, s)
+}
@@ -0,0 +1,218 @@
+package minecraft_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	// relayLatencyBudget is the maximum average time that a round trip from client to server and back through
+	// an in-process proxy may take.
+	relayLatencyBudget = time.Millisecond * 2
+	// relayAllocBudget is the maximum average amount of allocations that a single round trip from client to
+	// server and back through an in-process proxy may take, summed across all connections involved.
+	relayAllocBudget = 200
+	// relayRoundTrips is the amount of round trips measured.
+	relayRoundTrips = 500
+	// relayLatencyEnv is the environment variable that must be set to a non-empty value for the latency
+	// budget to be enforced. Wall clock time depends too much on the load of the machine running the tests
+	// to enforce it by default, so it is meant to be set only on machines dedicated to measuring it.
+	relayLatencyEnv = "GOPHERTUNNEL_RELAY_LATENCY_BUDGET"
+)
+
+// TestProxyRelayBudget runs a client, proxy and server in-process and verifies that relaying packets through
+// the proxy stays within the allocation budget and, if relayLatencyEnv is set, within the latency budget.
+func TestProxyRelayBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping relay budget test in short mode")
+	}
+	client, closeFn := relaySetup(t)
+	defer closeFn()
+
+	// Warm up buffer pools and other lazily allocated state before measuring.
+	for i := 0; i < 50; i++ {
+		relayRoundTrip(t, client)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < relayRoundTrips; i++ {
+		relayRoundTrip(t, client)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	latency := elapsed / relayRoundTrips
+	allocs := (after.Mallocs - before.Mallocs) / relayRoundTrips
+	t.Logf("relay round trip: %v, %v allocs", latency, allocs)
+	if latency > relayLatencyBudget && !raceEnabled && os.Getenv(relayLatencyEnv) != "" {
+		t.Errorf("relay round trip took %v on average, budget is %v", latency, relayLatencyBudget)
+	}
+	if allocs > relayAllocBudget && !raceEnabled {
+		t.Errorf("relay round trip took %v allocations on average, budget is %v", allocs, relayAllocBudget)
+	}
+}
+
+// BenchmarkProxyRelay measures a single round trip from client to server and back through an in-process
+// proxy.
+func BenchmarkProxyRelay(b *testing.B) {
+	client, closeFn := relaySetup(b)
+	defer closeFn()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		relayRoundTrip(b, client)
+	}
+}
+
+// relayRoundTrip writes a packet to the client connection passed and waits for the server to echo it back.
+func relayRoundTrip(tb testing.TB, client *minecraft.Conn) {
+	if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, Message: "ping"}); err != nil {
+		tb.Fatalf("write packet: %v", err)
+	}
+	if err := client.Flush(); err != nil {
+		tb.Fatalf("flush: %v", err)
+	}
+	for {
+		pk, err := client.ReadPacket()
+		if err != nil {
+			tb.Fatalf("read packet: %v", err)
+		}
+		if text, ok := pk.(*packet.Text); ok && text.Message == "ping" {
+			return
+		}
+	}
+}
+
+// relaySetup starts a server and a proxy in front of it, both listening on the in-memory pipe network, and
+// returns a client connected to the proxy. The server echoes every Text packet it receives.
+func relaySetup(tb testing.TB) (*minecraft.Conn, func()) {
+	// The addresses must be valid UDP addresses, as the client data sent during login is validated.
+	id := relayID.Add(1)
+	serverAddr, proxyAddr := fmt.Sprintf("127.0.0.1:%v", 20000+id*2), fmt.Sprintf("127.0.0.1:%v", 20001+id*2)
+
+	cfg := minecraft.ListenConfig{AuthenticationDisabled: true}
+	server, err := cfg.Listen("pipe", serverAddr)
+	if err != nil {
+		tb.Fatalf("listen server: %v", err)
+	}
+	proxy, err := cfg.Listen("pipe", proxyAddr)
+	if err != nil {
+		tb.Fatalf("listen proxy: %v", err)
+	}
+
+	go func() {
+		for {
+			c, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go relayEcho(c.(*minecraft.Conn))
+		}
+	}()
+	go func() {
+		for {
+			c, err := proxy.Accept()
+			if err != nil {
+				return
+			}
+			go relayProxy(c.(*minecraft.Conn), serverAddr)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	client, err := minecraft.Dialer{}.DialContext(ctx, "pipe", proxyAddr)
+	if err != nil {
+		tb.Fatalf("dial proxy: %v", err)
+	}
+	if err := client.DoSpawnContext(ctx); err != nil {
+		tb.Fatalf("spawn: %v", err)
+	}
+	return client, func() {
+		_ = client.Close()
+		_ = proxy.Close()
+		_ = server.Close()
+	}
+}
+
+// relayEcho spawns the connection passed and writes back every Text packet it receives.
+func relayEcho(conn *minecraft.Conn) {
+	defer conn.Close()
+	if err := conn.StartGame(minecraft.GameData{EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+		return
+	}
+	_ = conn.Flush()
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			return
+		}
+		if text, ok := pk.(*packet.Text); ok {
+			_ = conn.WritePacket(text)
+			_ = conn.Flush()
+		}
+	}
+}
+
+// relayProxy connects the connection passed to the server at the address passed and relays packets between
+// the two in both directions.
+func relayProxy(conn *minecraft.Conn, addr string) {
+	defer conn.Close()
+	serverConn, err := minecraft.Dialer{
+		ClientData:   conn.ClientData(),
+		IdentityData: conn.IdentityData(),
+	}.Dial("pipe", addr)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+
+	var g sync.WaitGroup
+	g.Add(2)
+	go func() {
+		defer g.Done()
+		_ = conn.StartGame(serverConn.GameData())
+		_ = conn.Flush()
+	}()
+	go func() {
+		defer g.Done()
+		_ = serverConn.DoSpawn()
+	}()
+	g.Wait()
+
+	go relay(serverConn, conn)
+	relay(conn, serverConn)
+}
+
+// relay reads packets from src and writes them to dst until either connection is closed.
+func relay(src, dst *minecraft.Conn) {
+	defer src.Close()
+	defer dst.Close()
+	for {
+		pk, err := src.ReadPacket()
+		if err != nil {
+			return
+		}
+		if err := dst.WritePacket(pk); err != nil {
+			return
+		}
+		if err := dst.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// relayID is used to give every relay setup its own addresses on the pipe network.
+var relayID atomic.Int32
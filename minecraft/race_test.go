@@ -0,0 +1,7 @@
+//go:build race
+
+package minecraft_test
+
+// raceEnabled is true if the race detector is enabled. Budgets are not enforced when it is, as it slows down
+// execution considerably.
+const raceEnabled = true
@@ -2,6 +2,7 @@ package minecraft
 
 import (
 	"context"
+	"log"
 	"log/slog"
 	"net"
 
@@ -9,29 +10,70 @@ import (
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
 
-// RakNet is an implementation of a RakNet v10 Network.
+// RakNet is an implementation of a RakNet v10 Network. The RakNet registered as the "raknet" network uses the
+// default settings of go-raknet. A RakNet with different settings may be registered under another ID (or
+// under "raknet" to replace the default) using RegisterNetwork, after which the ID may be passed to
+// Dialer.Dial and ListenConfig.Listen:
+//
+//	minecraft.RegisterNetwork("raknet", func(l *slog.Logger) minecraft.Network {
+//		return minecraft.RakNet{ErrorLog: l, ProtocolVersions: []byte{10}}
+//	})
 type RakNet struct {
-	l *slog.Logger
+	// ErrorLog is the logger that errors from decoding RakNet packets are logged to. If nil, errors are
+	// logged to stderr.
+	ErrorLog *slog.Logger
+	// ProtocolVersion is the RakNet protocol version used to dial connections. If zero, the latest version
+	// is used.
+	ProtocolVersion byte
+	// ProtocolVersions holds RakNet protocol versions accepted by listeners in addition to the latest
+	// version, for example to accept clients of older versions of the game.
+	ProtocolVersions []byte
+	// UpstreamDialer, if non-nil, is used to open the UDP connections of dialed connections, for example to
+	// bind to a specific local address or to route traffic through a tunnel.
+	UpstreamDialer raknet.UpstreamDialer
+	// UpstreamPacketListener, if non-nil, is used to open the UDP socket that listeners read from, for
+	// example to set socket buffer sizes on links with a high bandwidth-delay product.
+	UpstreamPacketListener raknet.UpstreamPacketListener
 }
 
 // DialContext ...
 func (r RakNet) DialContext(ctx context.Context, address string) (net.Conn, error) {
-	return raknet.DialContext(ctx, address)
+	return raknet.Dialer{
+		ProtocolVersion: r.ProtocolVersion,
+		ErrorLog:        r.errorLog(),
+		UpstreamDialer:  r.UpstreamDialer,
+	}.DialContext(ctx, address)
 }
 
 // PingContext ...
 func (r RakNet) PingContext(ctx context.Context, address string) (response []byte, err error) {
-	return raknet.PingContext(ctx, address)
+	return raknet.Dialer{
+		ProtocolVersion: r.ProtocolVersion,
+		ErrorLog:        r.errorLog(),
+		UpstreamDialer:  r.UpstreamDialer,
+	}.PingContext(ctx, address)
 }
 
 // Listen ...
 func (r RakNet) Listen(address string) (NetworkListener, error) {
-	return raknet.Listen(address)
+	return raknet.ListenConfig{
+		ProtocolVersions:       r.ProtocolVersions,
+		ErrorLog:               r.errorLog(),
+		UpstreamPacketListener: r.UpstreamPacketListener,
+	}.Listen(address)
 }
 
 func (RakNet) Compression(net.Conn) packet.Compression { return packet.FlateCompression }
 
+// errorLog returns the ErrorLog of the RakNet as a log.Logger for go-raknet, or nil if ErrorLog is nil.
+func (r RakNet) errorLog() *log.Logger {
+	if r.ErrorLog == nil {
+		return nil
+	}
+	return slog.NewLogLogger(r.ErrorLog.Handler(), slog.LevelError)
+}
+
 // init registers the RakNet network.
 func init() {
-	RegisterNetwork("raknet", func(l *slog.Logger) Network { return RakNet{l: l} })
+	RegisterNetwork("raknet", func(l *slog.Logger) Network { return RakNet{ErrorLog: l} })
 }
@@ -0,0 +1,98 @@
+package resource
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReadAddon compiles all packs found in the unzipped addon directory at the path passed. Every directory in
+// the tree holding a manifest.json, such as the behaviour and resource pack directories of an addon, is
+// compiled into an in-memory pack and validated. The packs returned are ordered using OrderByDependencies and
+// an error is returned if any of their dependencies is not found within the addon.
+func ReadAddon(path string) ([]*Pack, error) {
+	var packs []*Pack
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(p, "manifest.json")); err != nil {
+			return nil
+		}
+		pack, err := compile(p)
+		if err != nil {
+			return fmt.Errorf("compile %v: %w", p, err)
+		}
+		packs = append(packs, pack)
+		// Directories within a pack are part of that pack, so we don't search them for other packs.
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read addon: %w", err)
+	}
+	if len(packs) == 0 {
+		return nil, fmt.Errorf("read addon: no packs found in %v", path)
+	}
+	if err := CheckDependencies(packs...); err != nil {
+		return nil, fmt.Errorf("read addon: %w", err)
+	}
+	return OrderByDependencies(packs)
+}
+
+// WatchAddon watches the addon directory at the path passed for changes, checking the files in it every
+// interval. When files are added, removed or changed, the addon is compiled again using ReadAddon once no
+// more changes are made for an interval, and f is called with the result, so that a development server can reload the packs. WatchAddon blocks until the
+// context passed is cancelled, after which the context's error is returned.
+func WatchAddon(ctx context.Context, path string, interval time.Duration, f func(packs []*Pack, err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, _ := addonState(path)
+	changed := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		state, err := addonState(path)
+		if err != nil {
+			f(nil, fmt.Errorf("watch addon: %w", err))
+			continue
+		}
+		if state != last {
+			// Wait until the files have not changed for a full interval, so that an editor writing multiple
+			// files does not lead to reloading multiple times.
+			last, changed = state, true
+			continue
+		}
+		if changed {
+			changed = false
+			f(ReadAddon(path))
+		}
+	}
+}
+
+// addonState returns a hash of the names, sizes and modification times of all files in the directory at the
+// path passed, which changes whenever a file in the directory is changed.
+func addonState(path string) ([32]byte, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%v\x00%v\x00%v\x00", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum, err
+}
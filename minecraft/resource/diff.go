@@ -0,0 +1,177 @@
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPatchMismatch is returned (wrapped) by Patch if a delta does not apply to the pack passed, or if the pack
+// produced does not match the pack that the delta was created for.
+var ErrPatchMismatch = errors.New("delta does not match pack")
+
+const (
+	// deltaMagic is written at the start of every delta produced by Diff.
+	deltaMagic = "GTPD"
+	// deltaBlockSize is the size of the blocks of the old pack that Diff attempts to find in the new pack.
+	deltaBlockSize = 1024
+
+	deltaOpCopy   = 0
+	deltaOpInsert = 1
+)
+
+// Diff computes a binary delta between the archives of two versions of a pack, typically an old version that
+// a client has cached and the current version. Applying the delta to the pack from using Patch produces the
+// pack to. Because most files in an archive are compressed independently, the delta of a pack in which only
+// a few files changed is much smaller than the pack itself.
+// Minecraft clients cannot apply deltas themselves, so Diff and Patch are meant for applications that control
+// both ends of a connection and transfer deltas over a channel of their own, falling back to the full pack
+// if patching fails.
+func Diff(from, to *Pack) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 256))
+	buf.WriteString(deltaMagic)
+	oldSum, newSum := from.Checksum(), to.Checksum()
+	buf.Write(oldSum[:])
+	buf.Write(newSum[:])
+	writeUvarint(buf, uint64(len(to.content)))
+
+	src, dst := from.content, to.content
+	blocks := make(map[uint32][]int, len(src)/deltaBlockSize)
+	for off := 0; off+deltaBlockSize <= len(src); off += deltaBlockSize {
+		h := newRollingHash(src[off : off+deltaBlockSize])
+		blocks[h.sum()] = append(blocks[h.sum()], off)
+	}
+
+	literal := 0
+	flush := func(end int) {
+		if end > literal {
+			buf.WriteByte(deltaOpInsert)
+			writeUvarint(buf, uint64(end-literal))
+			buf.Write(dst[literal:end])
+		}
+	}
+	i := 0
+	var h rollingHash
+	if len(dst) >= deltaBlockSize {
+		h = newRollingHash(dst[:deltaBlockSize])
+	}
+	for i+deltaBlockSize <= len(dst) {
+		match := -1
+		for _, off := range blocks[h.sum()] {
+			if bytes.Equal(src[off:off+deltaBlockSize], dst[i:i+deltaBlockSize]) {
+				match = off
+				break
+			}
+		}
+		if match == -1 {
+			if i+deltaBlockSize < len(dst) {
+				h.roll(dst[i], dst[i+deltaBlockSize])
+			}
+			i++
+			continue
+		}
+		// Extend the match as far as possible beyond the block found.
+		n := deltaBlockSize
+		for match+n < len(src) && i+n < len(dst) && src[match+n] == dst[i+n] {
+			n++
+		}
+		flush(i)
+		buf.WriteByte(deltaOpCopy)
+		writeUvarint(buf, uint64(match))
+		writeUvarint(buf, uint64(n))
+		i += n
+		literal = i
+		if i+deltaBlockSize <= len(dst) {
+			h = newRollingHash(dst[i : i+deltaBlockSize])
+		}
+	}
+	flush(len(dst))
+	return buf.Bytes()
+}
+
+// Patch applies a delta produced by Diff to the old pack passed and returns the new pack. An error wrapping
+// ErrPatchMismatch is returned if the delta was not created for the old pack or if the result does not match
+// the checksum of the new pack.
+func Patch(old *Pack, delta []byte) (*Pack, error) {
+	r := bytes.NewReader(delta)
+	header := make([]byte, len(deltaMagic)+64)
+	if _, err := r.Read(header); err != nil || string(header[:len(deltaMagic)]) != deltaMagic {
+		return nil, fmt.Errorf("patch pack: invalid delta header")
+	}
+	var oldSum, newSum [32]byte
+	copy(oldSum[:], header[len(deltaMagic):])
+	copy(newSum[:], header[len(deltaMagic)+32:])
+	if oldSum != old.Checksum() {
+		return nil, fmt.Errorf("patch pack: %w: delta was created for a different version", ErrPatchMismatch)
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil || size > uint64(len(old.content))+uint64(len(delta)) {
+		return nil, fmt.Errorf("patch pack: invalid delta size")
+	}
+	out := make([]byte, 0, size)
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		switch op {
+		case deltaOpCopy:
+			off, err1 := binary.ReadUvarint(r)
+			n, err2 := binary.ReadUvarint(r)
+			if err1 != nil || err2 != nil || off > uint64(len(old.content)) || n > uint64(len(old.content))-off {
+				return nil, fmt.Errorf("patch pack: invalid copy operation")
+			}
+			out = append(out, old.content[off:off+n]...)
+		case deltaOpInsert:
+			n, err := binary.ReadUvarint(r)
+			if err != nil || n > uint64(r.Len()) {
+				return nil, fmt.Errorf("patch pack: invalid insert operation")
+			}
+			start := len(delta) - r.Len()
+			out = append(out, delta[start:start+int(n)]...)
+			_, _ = r.Seek(int64(n), io.SeekCurrent)
+		default:
+			return nil, fmt.Errorf("patch pack: unknown operation %v", op)
+		}
+		if uint64(len(out)) > size {
+			return nil, fmt.Errorf("patch pack: %w: result exceeds size %v", ErrPatchMismatch, size)
+		}
+	}
+	if sha256.Sum256(out) != newSum {
+		return nil, fmt.Errorf("patch pack: %w: checksum of result does not match", ErrPatchMismatch)
+	}
+	return ReadBytes(out)
+}
+
+// writeUvarint writes x to buf as a uvarint.
+func writeUvarint(buf *bytes.Buffer, x uint64) {
+	buf.Write(binary.AppendUvarint(nil, x))
+}
+
+// rollingHash is a weak checksum over a window of bytes that can be moved forward by a single byte cheaply,
+// like the one used by rsync.
+type rollingHash struct {
+	a, b uint32
+	n    uint32
+}
+
+// newRollingHash computes the rollingHash of the window passed.
+func newRollingHash(window []byte) rollingHash {
+	h := rollingHash{n: uint32(len(window))}
+	for i, c := range window {
+		h.a += uint32(c)
+		h.b += uint32(len(window)-i) * uint32(c)
+	}
+	return h
+}
+
+// roll moves the window of the rollingHash forward by one byte, removing out and adding in.
+func (h *rollingHash) roll(out, in byte) {
+	h.a = h.a - uint32(out) + uint32(in)
+	h.b = h.b - h.n*uint32(out) + h.a
+}
+
+// sum returns the checksum of the current window.
+func (h rollingHash) sum() uint32 {
+	return (h.a & 0xffff) | (h.b << 16)
+}
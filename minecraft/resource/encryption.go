@@ -0,0 +1,88 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidContentKey is returned (wrapped) by ReadEncryptedPath if the content key passed cannot decrypt the
+// resource pack.
+var ErrInvalidContentKey = errors.New("invalid content key")
+
+const (
+	// contentsMagic is the magic number found in the header of the contents.json file of an encrypted pack.
+	contentsMagic = 0x9bcfb9fc
+	// contentsHeaderSize is the size of the header of the contents.json file of an encrypted pack. The
+	// encrypted data follows directly after it.
+	contentsHeaderSize = 0x100
+)
+
+// ReadEncryptedPath reads an encrypted resource pack, such as a marketplace .mcpack, from the path passed and
+// sets its content key to the key passed, so that the pack may be served to clients through a Listener. The
+// key, typically shipped alongside the pack, is 32 bytes long. ReadEncryptedPath verifies that the key
+// decrypts the contents.json of the pack and returns an error wrapping ErrInvalidContentKey if it does not.
+func ReadEncryptedPath(path, key string) (*Pack, error) {
+	pack, err := ReadPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pack.verifyContentKey(key); err != nil {
+		return nil, fmt.Errorf("verify content key: %w", err)
+	}
+	return pack.WithContentKey(key), nil
+}
+
+// verifyContentKey verifies that the content key passed decrypts the contents.json of the pack.
+func (pack *Pack) verifyContentKey(key string) error {
+	if len(key) != 32 {
+		return fmt.Errorf("%w: key must be 32 bytes long, got %v", ErrInvalidContentKey, len(key))
+	}
+	r, err := zip.NewReader(bytes.NewReader(pack.content), int64(len(pack.content)))
+	if err != nil {
+		return fmt.Errorf("open zip reader: %w", err)
+	}
+	f, err := packReader{Reader: r}.find("contents.json")
+	if err != nil {
+		return fmt.Errorf("pack is not encrypted: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read contents.json: %w", err)
+	}
+	if len(data) < contentsHeaderSize || binary.LittleEndian.Uint32(data[4:]) != contentsMagic {
+		return fmt.Errorf("pack is not encrypted: contents.json has no encryption header")
+	}
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidContentKey, err)
+	}
+	contents := data[contentsHeaderSize:]
+	decryptCFB8(block, []byte(key[:aes.BlockSize]), contents)
+	if !json.Valid(contents) {
+		return ErrInvalidContentKey
+	}
+	return nil
+}
+
+// decryptCFB8 decrypts the data passed in place using AES in 8-bit cipher feedback mode, which is used to
+// encrypt the files of resource packs. The standard library only implements full block CFB.
+func decryptCFB8(block cipher.Block, iv, data []byte) {
+	shift, out := make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+	copy(shift, iv)
+	for i, c := range data {
+		block.Encrypt(out, shift)
+		data[i] = c ^ out[0]
+		copy(shift, shift[1:])
+		shift[aes.BlockSize-1] = c
+	}
+}
@@ -0,0 +1,35 @@
+package resource
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// FS returns an fs.FS holding the files of the resource pack, so that they may be inspected using functions
+// such as fs.ReadFile, fs.ReadDir and fs.WalkDir:
+//
+//	fsys, _ := pack.FS()
+//	geometry, err := fs.ReadFile(fsys, "models/entity/player.geo.json")
+//
+// The root of the fs.FS is the directory holding the manifest.json of the pack, even if the archive holds the
+// pack in a subdirectory. The files of encrypted packs are returned as they are stored in the archive,
+// meaning they remain encrypted.
+func (pack *Pack) FS() (fs.FS, error) {
+	r, err := zip.NewReader(bytes.NewReader(pack.content), int64(len(pack.content)))
+	if err != nil {
+		return nil, fmt.Errorf("open zip reader: %w", err)
+	}
+	for _, f := range r.File {
+		if path.Base(f.Name) != "manifest.json" {
+			continue
+		}
+		if dir := path.Dir(f.Name); dir != "." {
+			return fs.Sub(r, dir)
+		}
+		break
+	}
+	return r, nil
+}
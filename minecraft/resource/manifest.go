@@ -56,11 +56,26 @@ type Module struct {
 	Version [3]int `json:"version"`
 }
 
+// Module types that may be found in the Type field of a Module.
+const (
+	ModuleResources     = "resources"
+	ModuleData          = "data"
+	ModuleClientData    = "client_data"
+	ModuleInterface     = "interface"
+	ModuleWorldTemplate = "world_template"
+	ModuleScript        = "script"
+	ModuleJavaScript    = "javascript"
+	ModuleSkinPack      = "skin_pack"
+)
+
 // Dependency describes a pack that this pack depends on in order to work.
 type Dependency struct {
 	// UUID is the unique identifier of the pack that this pack depends on. It needs to be the exact same UUID
 	// that the pack has defined in the header section of it's manifest file.
-	UUID string `json:"uuid"`
+	UUID string `json:"uuid,omitempty"`
+	// ModuleName is the name of a script module that the pack depends on, such as '@minecraft/server'. It is
+	// set instead of UUID for dependencies on script modules.
+	ModuleName string `json:"module_name,omitempty"`
 	// Version is the specific version of the pack that the pack depends on. Should match the version the
 	// other pack has in its manifest file.
 	Version [3]int `json:"version"`
@@ -3,11 +3,14 @@ package resource
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/muhammadmuzzammil1998/jsonc"
 	"io"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -15,6 +18,10 @@ import (
 	"strings"
 )
 
+// ErrChecksumMismatch is returned (wrapped) by ReadURLChecksum if the checksum of the resource pack downloaded
+// does not match the checksum expected.
+var ErrChecksumMismatch = errors.New("resource pack checksum mismatch")
+
 // Pack is a container of a resource pack parsed from a directory or a .zip archive (or .mcpack). It holds
 // methods that may be used to get information about the resource pack.
 type Pack struct {
@@ -25,9 +32,9 @@ type Pack struct {
 	// downloadURL is the URL that the resource pack can be downloaded from. If the string is empty, then the
 	// resource pack will be downloaded over RakNet rather than HTTP.
 	downloadURL string
-	// content is a bytes.Reader that contains the full content of the zip file. It is used to send the full
-	// data to a client.
-	content *bytes.Reader
+	// content holds the full content of the zip file. It is used to send the full data to a client. It is
+	// shared between all connections and never modified.
+	content []byte
 	// contentKey is the key used to encrypt the files. The client uses this to decrypt the resource pack if encrypted.
 	// If nothing is encrypted, this field can be left as an empty string.
 	contentKey string
@@ -50,7 +57,27 @@ func ReadPath(path string) (*Pack, error) {
 // zip archive where the manifest.json file is inside a subdirectory rather than the root itself. If the resource
 // pack is not a valid zip or there is no manifest.json file, an error is returned.
 func ReadURL(url string) (*Pack, error) {
-	resp, err := http.Get(url)
+	return ReadURLContext(context.Background(), url, 0)
+}
+
+// ErrPackTooLarge is returned (wrapped) by ReadURLContext if the resource pack downloaded exceeds the maximum
+// size passed.
+var ErrPackTooLarge = errors.New("resource pack too large")
+
+// maxURLPreallocation is the maximum amount of bytes allocated for a resource pack downloaded from a URL
+// before any of its data is read, regardless of the Content-Length reported by the server.
+const maxURLPreallocation = 1024 * 1024 * 16
+
+// ReadURLContext downloads and compiles a resource pack like ReadURL, but cancels the download when the
+// context passed is done. If maxSize is non-zero, the download fails with an error wrapping ErrPackTooLarge
+// once more than maxSize bytes were received, so that a server cannot make the caller allocate an unbounded
+// amount of memory.
+func ReadURLContext(ctx context.Context, url string, maxSize uint64) (*Pack, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download resource pack: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("download resource pack: %w", err)
 	}
@@ -58,7 +85,23 @@ func ReadURL(url string) (*Pack, error) {
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("download resource pack: %v (%d)", resp.Status, resp.StatusCode)
 	}
-	pack, err := Read(resp.Body)
+	if maxSize > 0 && resp.ContentLength > 0 && uint64(resp.ContentLength) > maxSize {
+		return nil, fmt.Errorf("download resource pack: %w: %v bytes, maximum is %v", ErrPackTooLarge, resp.ContentLength, maxSize)
+	}
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		// Read one byte more than the maximum to find out if the pack exceeds it.
+		body = io.LimitReader(resp.Body, int64(min(maxSize, math.MaxInt64-1))+1)
+	}
+	// The Content-Length is reported by the server, so it is not trusted to allocate the full buffer upfront.
+	buf := bytes.NewBuffer(make([]byte, 0, min(max(resp.ContentLength, 0), maxURLPreallocation)))
+	if _, err := buf.ReadFrom(body); err != nil {
+		return nil, fmt.Errorf("download resource pack: %w", err)
+	}
+	if maxSize > 0 && uint64(buf.Len()) > maxSize {
+		return nil, fmt.Errorf("download resource pack: %w: more than %v bytes", ErrPackTooLarge, maxSize)
+	}
+	pack, err := ReadBytes(buf.Bytes())
 	if err != nil {
 		return nil, err
 	}
@@ -66,6 +109,21 @@ func ReadURL(url string) (*Pack, error) {
 	return pack, nil
 }
 
+// ReadURLChecksum downloads and compiles a resource pack like ReadURL, but also verifies that the SHA256
+// checksum of the pack downloaded matches the checksum passed, as returned by Pack.Checksum. An error
+// wrapping ErrChecksumMismatch is returned if it does not, so that a pack that was tampered with or changed
+// on a CDN is not served to clients.
+func ReadURLChecksum(url string, checksum [32]byte) (*Pack, error) {
+	pack, err := ReadURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if pack.checksum != checksum {
+		return nil, fmt.Errorf("download resource pack: %w: expected %x, got %x", ErrChecksumMismatch, checksum, pack.checksum)
+	}
+	return pack, nil
+}
+
 // MustReadPath compiles a resource pack found at the path passed. The resource pack must either be a zip
 // archive (extension does not matter, could be .zip or .mcpack), or a directory containing a resource pack.
 // In the case of a directory, the directory is compiled into an archive and the pack is parsed from that.
@@ -92,23 +150,33 @@ func MustReadURL(url string) *Pack {
 	return pack
 }
 
-// Read parses an archived resource pack written to a raw byte slice passed. The data must be a valid
-// zip archive and contain a pack manifest in order for the function to succeed.
-// Read saves the data to a temporary archive.
+// Read parses an archived resource pack from the io.Reader passed. The data must be a valid zip archive and
+// contain a pack manifest in order for the function to succeed. The data is read into memory once and kept
+// there to be sent to clients.
 func Read(r io.Reader) (*Pack, error) {
-	temp, err := createTempFile()
+	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("create temp zip archive: %w", err)
+		return nil, fmt.Errorf("read resource pack: %w", err)
 	}
-	_, _ = io.Copy(temp, r)
-	if err := temp.Close(); err != nil {
-		return nil, fmt.Errorf("close temp zip archive: %w", err)
+	return ReadBytes(data)
+}
+
+// ReadBytes parses an archived resource pack from the raw bytes passed. The data must be a valid zip archive
+// and contain a pack manifest in order for the function to succeed. The Pack returned holds on to the data
+// without copying it, so the data must not be modified afterwards.
+func ReadBytes(data []byte) (*Pack, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open zip reader: %w", err)
+	}
+	manifest, err := readManifest(r)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
 	}
-	pack, parseErr := ReadPath(temp.Name())
-	if err := os.Remove(temp.Name()); err != nil {
-		return nil, fmt.Errorf("remove temp zip archive: %w", err)
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("validate manifest: %w", err)
 	}
-	return pack, parseErr
+	return &Pack{manifest: manifest, checksum: sha256.Sum256(data), content: data}, nil
 }
 
 // Name returns the name of the resource pack.
@@ -138,6 +206,18 @@ func (pack *Pack) Modules() []Module {
 	return pack.manifest.Modules
 }
 
+// ModulesOfType returns all modules of the resource pack with the type passed, such as ModuleResources or
+// ModuleData.
+func (pack *Pack) ModulesOfType(typ string) []Module {
+	var modules []Module
+	for _, module := range pack.manifest.Modules {
+		if module.Type == typ {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
 // Dependencies returns all dependency resource packs that must be loaded in order for this resource pack to
 // function correctly.
 func (pack *Pack) Dependencies() []Dependency {
@@ -148,7 +228,7 @@ func (pack *Pack) Dependencies() []Dependency {
 // scripts in them.
 func (pack *Pack) HasScripts() bool {
 	for _, module := range pack.manifest.Modules {
-		if module.Type == "client_data" {
+		if module.Type == ModuleClientData {
 			// The module has the client_data type, meaning it holds client scripts.
 			return true
 		}
@@ -160,7 +240,7 @@ func (pack *Pack) HasScripts() bool {
 // 'client_data', meaning they contain behaviours (or scripts).
 func (pack *Pack) HasBehaviours() bool {
 	for _, module := range pack.manifest.Modules {
-		if module.Type == "client_data" || module.Type == "data" {
+		if module.Type == ModuleClientData || module.Type == ModuleData {
 			// The module has the client_data or data type, meaning it holds behaviours.
 			return true
 		}
@@ -172,7 +252,7 @@ func (pack *Pack) HasBehaviours() bool {
 // textures in them.
 func (pack *Pack) HasTextures() bool {
 	for _, module := range pack.manifest.Modules {
-		if module.Type == "resources" {
+		if module.Type == ModuleResources {
 			// The module has the resources type, meaning it holds textures.
 			return true
 		}
@@ -200,7 +280,7 @@ func (pack *Pack) Checksum() [32]byte {
 
 // Len returns the total length in bytes of the content of the archive that contained the resource pack.
 func (pack *Pack) Len() int {
-	return pack.content.Len()
+	return len(pack.content)
 }
 
 // DataChunkCount returns the amount of chunks the data of the resource pack is split into if each chunk has
@@ -227,7 +307,29 @@ func (pack *Pack) ContentKey() string {
 // ReadAt reads len(b) bytes from the resource pack's archive data at offset off and copies it into b. The
 // amount of bytes read n is returned.
 func (pack *Pack) ReadAt(b []byte, off int64) (n int, err error) {
-	return pack.content.ReadAt(b, off)
+	if off < 0 {
+		return 0, fmt.Errorf("read resource pack: negative offset %v", off)
+	}
+	if off >= int64(len(pack.content)) {
+		return 0, io.EOF
+	}
+	n = copy(b, pack.content[off:])
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Chunk returns up to n bytes of the resource pack's archive data, starting at offset off. Unlike ReadAt,
+// Chunk does not copy the data: The slice returned points into the data shared by all users of the Pack and
+// must not be modified. It allows serving the same chunk to many clients without allocating for each of
+// them. An empty slice is returned if off is out of range.
+func (pack *Pack) Chunk(off int64, n int) []byte {
+	if off < 0 || off >= int64(len(pack.content)) {
+		return nil
+	}
+	end := min(off+int64(n), int64(len(pack.content)))
+	return pack.content[off:end:end]
 }
 
 // WithContentKey creates a copy of the pack and sets the encryption key to the key provided, after which the
@@ -237,6 +339,15 @@ func (pack Pack) WithContentKey(key string) *Pack {
 	return &pack
 }
 
+// WithDownloadURL creates a copy of the pack and sets the URL that clients may download it from to the URL
+// passed, after which the new Pack is returned. The URL, typically pointing to a CDN, must serve the exact
+// archive of the pack. Clients that fail to download the pack from the URL request it from the server
+// instead, so the content of the pack is still sent in chunks to those clients.
+func (pack Pack) WithDownloadURL(url string) *Pack {
+	pack.downloadURL = url
+	return &pack
+}
+
 // Manifest returns the manifest found in the manifest.json of the resource pack. It contains information
 // about the pack such as its name.
 func (pack *Pack) Manifest() Manifest {
@@ -256,46 +367,26 @@ func compile(path string) (*Pack, error) {
 		return nil, fmt.Errorf("open resource pack path: %w", err)
 	}
 	if info.IsDir() {
-		temp, err := createTempArchive(path)
-		if err != nil {
+		// The directory is compiled into a zip archive in memory, as we need to send compressed zip data to
+		// the client.
+		buf := bytes.NewBuffer(nil)
+		if err := writeArchive(buf, path); err != nil {
 			return nil, err
 		}
-		// We set the path to the temp zip archive we just made.
-		path = temp.Name()
-
-		// Make sure we close the temp file and remove it at the end. We don't need to keep it, as we read all
-		// the content in a byte slice.
-		_ = temp.Close()
-		defer func() {
-			_ = os.Remove(temp.Name())
-		}()
-	}
-	// First we read the manifest to ensure that it exists and is valid.
-	manifest, err := readManifest(path)
-	if err != nil {
-		return nil, fmt.Errorf("read manifest: %w", err)
+		return ReadBytes(buf.Bytes())
 	}
-
-	// Then we read the entire content of the zip archive into a byte slice and compute the SHA256 checksum
-	// and a reader.
+	// Read the entire content of the zip archive into a byte slice, after which the manifest is read from it
+	// and the SHA256 checksum is computed.
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read resource pack file content: %w", err)
 	}
-	checksum := sha256.Sum256(content)
-	contentReader := bytes.NewReader(content)
-
-	return &Pack{manifest: manifest, checksum: checksum, content: contentReader}, nil
+	return ReadBytes(content)
 }
 
-// createTempArchive creates a zip archive from the files in the path passed and writes it to a temporary
-// file, which is returned when successful.
-func createTempArchive(path string) (*os.File, error) {
-	temp, err := createTempFile()
-	if err != nil {
-		return nil, err
-	}
-	writer := zip.NewWriter(temp)
+// writeArchive creates a zip archive from the files in the path passed and writes it to the io.Writer passed.
+func writeArchive(w io.Writer, path string) error {
+	writer := zip.NewWriter(w)
 	if err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -323,46 +414,27 @@ func createTempArchive(path string) (*os.File, error) {
 		if err != nil {
 			return fmt.Errorf("create new zip file: %w", err)
 		}
-		file, err := os.Open(filePath)
+		data, err := os.ReadFile(filePath)
 		if err != nil {
-			return fmt.Errorf("open resource pack file %v: %w", filePath, err)
+			return fmt.Errorf("read resource pack file %v: %w", filePath, err)
 		}
-		data, _ := io.ReadAll(file)
 		// Write the original content into the 'zip file' so that we write compressed data to the file.
 		if _, err := f.Write(data); err != nil {
 			return fmt.Errorf("write file data to zip: %w", err)
 		}
-		_ = file.Close()
 		return nil
 	}); err != nil {
-		return nil, fmt.Errorf("build zip archive: %w", err)
+		return fmt.Errorf("build zip archive: %w", err)
 	}
-	_ = writer.Close()
-	return temp, nil
-}
-
-// createTempFile attempts to create a temporary file and returns it.
-func createTempFile() (*os.File, error) {
-	// We've got a directory which we need to load. Provided we need to send compressed zip data to the
-	// client, we compile it to a zip archive in a temporary file.
-
-	// Note that we explicitly do not handle the error here. If the user config
-	// dir cannot be found, 'dir' will be an empty string. os.CreateTemp will
-	// then use the default temporary file directory, which might succeed in
-	// this case.
-	dir, _ := os.UserConfigDir()
-	_ = os.MkdirAll(dir, os.ModePerm)
-
-	temp, err := os.CreateTemp(dir, "temp_resource_pack-*.mcpack")
-	if err != nil {
-		return nil, fmt.Errorf("create temp resource pack file: %w", err)
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("build zip archive: %w", err)
 	}
-	return temp, nil
+	return nil
 }
 
 // packReader wraps around a zip.Reader to provide file finding functionality.
 type packReader struct {
-	*zip.ReadCloser
+	*zip.Reader
 }
 
 // find attempts to find a file in a zip reader. If found, it returns an Open()ed reader of the file that may
@@ -382,17 +454,10 @@ func (reader packReader) find(fileName string) (io.ReadCloser, error) {
 	return nil, fmt.Errorf("'%v' not found in zip", fileName)
 }
 
-// readManifest reads the manifest from the resource pack located at the path passed. If not found in the root
-// of the resource pack, it will also attempt to find it deeper down into the archive.
-func readManifest(path string) (*Manifest, error) {
-	r, err := zip.OpenReader(path)
-	if err != nil {
-		return nil, fmt.Errorf("open zip reader: %w", err)
-	}
-	reader := packReader{ReadCloser: r}
-	defer func() {
-		_ = r.Close()
-	}()
+// readManifest reads the manifest from the resource pack archive passed. If not found in the root of the
+// resource pack, it will also attempt to find it deeper down into the archive.
+func readManifest(r *zip.Reader) (*Manifest, error) {
+	reader := packReader{Reader: r}
 
 	// Try to find the manifest file in the zip.
 	manifestFile, err := reader.find("manifest.json")
@@ -0,0 +1,191 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+)
+
+// ManifestError is an error found in a single field of a Manifest by Manifest.Validate.
+type ManifestError struct {
+	// Field is the path of the field in the manifest.json that the error was found in, for example
+	// 'modules[0].type'.
+	Field string
+	// Err is the error found in the field.
+	Err error
+}
+
+// Error ...
+func (err *ManifestError) Error() string {
+	return fmt.Sprintf("%v: %v", err.Field, err.Err)
+}
+
+// Unwrap ...
+func (err *ManifestError) Unwrap() error {
+	return err.Err
+}
+
+// manifestErr returns a *ManifestError for the field passed with an error formatted using the format and
+// arguments passed.
+func manifestErr(field, format string, a ...any) error {
+	return &ManifestError{Field: field, Err: fmt.Errorf(format, a...)}
+}
+
+// moduleTypes is a list of all module types that a pack may have.
+var moduleTypes = []string{ModuleResources, ModuleData, ModuleClientData, ModuleInterface, ModuleWorldTemplate, ModuleScript, ModuleJavaScript, ModuleSkinPack}
+
+// capabilities is a list of all capabilities that a pack may make use of.
+var capabilities = []Capability{"experimental_custom_ui", "chemistry", "raytraced", "pbr", "script_eval", "editorExtension"}
+
+// Validate checks the Manifest for errors that would lead clients to reject the pack, such as missing or
+// malformed UUIDs, negative version numbers and unknown module types. All errors found are returned joined
+// together, each being a *ManifestError that may be obtained using errors.As. Validate is called for every
+// pack read by the resource package.
+func (m Manifest) Validate() error {
+	var errs []error
+	if m.FormatVersion < 1 || m.FormatVersion > 3 {
+		errs = append(errs, manifestErr("format_version", "unsupported format version %v", m.FormatVersion))
+	}
+	if m.Header.Name == "" {
+		errs = append(errs, manifestErr("header.name", "name must not be empty"))
+	}
+	if m.Header.UUID == uuid.Nil {
+		errs = append(errs, manifestErr("header.uuid", "UUID must be set"))
+	}
+	if err := checkVersion(m.Header.Version); err != nil {
+		errs = append(errs, &ManifestError{Field: "header.version", Err: err})
+	}
+	if err := checkVersion(m.Header.MinimumGameVersion); err != nil {
+		errs = append(errs, &ManifestError{Field: "header.min_engine_version", Err: err})
+	}
+
+	if len(m.Modules) == 0 {
+		errs = append(errs, manifestErr("modules", "pack must have at least one module"))
+	}
+	seen := map[uuid.UUID]struct{}{m.Header.UUID: {}}
+	for i, module := range m.Modules {
+		field := fmt.Sprintf("modules[%v]", i)
+		if id, err := uuid.Parse(module.UUID); err != nil {
+			errs = append(errs, manifestErr(field+".uuid", "invalid UUID %q: %w", module.UUID, err))
+		} else if _, ok := seen[id]; ok {
+			errs = append(errs, manifestErr(field+".uuid", "UUID %v is not unique within the pack", id))
+		} else {
+			seen[id] = struct{}{}
+		}
+		if !slices.Contains(moduleTypes, module.Type) {
+			errs = append(errs, manifestErr(field+".type", "unknown module type %q", module.Type))
+		}
+		if err := checkVersion(module.Version); err != nil {
+			errs = append(errs, &ManifestError{Field: field + ".version", Err: err})
+		}
+	}
+	for i, dep := range m.Dependencies {
+		field := fmt.Sprintf("dependencies[%v]", i)
+		if dep.ModuleName != "" {
+			// Script module dependencies are resolved by the game itself.
+			continue
+		}
+		if id, err := uuid.Parse(dep.UUID); err != nil {
+			errs = append(errs, manifestErr(field+".uuid", "invalid UUID %q: %w", dep.UUID, err))
+		} else if id == m.Header.UUID {
+			errs = append(errs, manifestErr(field+".uuid", "pack must not depend on itself"))
+		}
+		if err := checkVersion(dep.Version); err != nil {
+			errs = append(errs, &ManifestError{Field: field + ".version", Err: err})
+		}
+	}
+	for i, c := range m.Capabilities {
+		if !slices.Contains(capabilities, c) {
+			errs = append(errs, manifestErr(fmt.Sprintf("capabilities[%v]", i), "unknown capability %q", c))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkVersion checks if the version passed is a valid semantic version, meaning none of its numbers are
+// negative.
+func checkVersion(v [3]int) error {
+	if v[0] < 0 || v[1] < 0 || v[2] < 0 {
+		return fmt.Errorf("invalid version %v.%v.%v: numbers must not be negative", v[0], v[1], v[2])
+	}
+	return nil
+}
+
+// ErrMissingDependency is returned (wrapped) by CheckDependencies if a pack depends on a pack that is not
+// present.
+var ErrMissingDependency = errors.New("missing dependency")
+
+// CheckDependencies checks if the dependencies of all packs passed are resolved by the other packs passed,
+// meaning that for every dependency, a pack with the same UUID and version is present. Clients refuse to
+// apply packs of which dependencies are missing, so a server should call CheckDependencies with the packs
+// it sends. An error wrapping ErrMissingDependency is returned for every dependency that is not resolved.
+func CheckDependencies(packs ...*Pack) error {
+	present := make(map[string][3]int, len(packs))
+	for _, pack := range packs {
+		present[pack.UUID().String()] = pack.manifest.Header.Version
+	}
+	var errs []error
+	for _, pack := range packs {
+		for _, dep := range pack.Dependencies() {
+			if dep.ModuleName != "" {
+				continue
+			}
+			v, ok := present[dep.UUID]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%v: %w: pack %v is not present", pack, ErrMissingDependency, dep.UUID))
+			} else if v != dep.Version {
+				errs = append(errs, fmt.Errorf("%v: %w: pack %v has version %v.%v.%v, %v.%v.%v required", pack, ErrMissingDependency, dep.UUID, v[0], v[1], v[2], dep.Version[0], dep.Version[1], dep.Version[2]))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ErrDependencyCycle is returned (wrapped) by OrderByDependencies if packs depend on each other in a cycle.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// OrderByDependencies returns the packs passed ordered so that every pack comes before the packs that it
+// depends on. This is the order in which packs must be sent in a ResourcePackStack, where packs earlier in
+// the stack take priority over packs later in the stack. Packs that do not depend on each other keep the order
+// in which they were passed. Dependencies on packs not passed are ignored. An error wrapping
+// ErrDependencyCycle is returned if the packs depend on each other in a cycle.
+func OrderByDependencies(packs []*Pack) ([]*Pack, error) {
+	index := make(map[string]int, len(packs))
+	for i, pack := range packs {
+		index[pack.UUID().String()] = i
+	}
+	// remaining[i] holds the amount of packs depending on pack i that have not yet been placed. Pack i may
+	// only be placed once all of them have been placed.
+	remaining := make([]int, len(packs))
+	for i, pack := range packs {
+		for _, dep := range pack.Dependencies() {
+			if j, ok := index[dep.UUID]; ok && j != i {
+				remaining[j]++
+			}
+		}
+	}
+	ordered := make([]*Pack, 0, len(packs))
+	placed := make([]bool, len(packs))
+	for len(ordered) < len(packs) {
+		// Place the first pack in the original order of which all dependents were already placed.
+		next := -1
+		for i := range packs {
+			if !placed[i] && remaining[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("order packs: %w", ErrDependencyCycle)
+		}
+		placed[next] = true
+		ordered = append(ordered, packs[next])
+		for _, dep := range packs[next].Dependencies() {
+			if j, ok := index[dep.UUID]; ok && j != next {
+				remaining[j]--
+			}
+		}
+	}
+	return ordered, nil
+}
@@ -15,6 +15,15 @@ type resourcePackQueue struct {
 	currentPack     *resource.Pack
 	currentOffset   uint64
 
+	// deltas, if non-nil, holds the earlier versions of packs that deltas may be sent against. bases holds
+	// the earlier version of each pack to download that the client has cached, if deltas holds it.
+	// currentDelta is the delta sent for the currentPack, or nil if the full pack is sent, and currentID is
+	// the UUID sent in the ResourcePackDataInfo packet of the currentPack.
+	deltas       *packDeltas
+	bases        map[string]*resource.Pack
+	currentDelta []byte
+	currentID    string
+
 	packAmount       int
 	downloadingPacks map[string]downloadingPack
 	awaitingPacks    map[string]*downloadingPack
@@ -28,13 +37,42 @@ type downloadingPack struct {
 	expectedIndex uint32
 	newFrag       chan []byte
 	contentKey    string
+
+	// base is the earlier version of the pack of which a delta was requested, or nil if no delta was
+	// requested. delta is true if the server sends a delta rather than the pack, in which case size is the
+	// size of the delta and fullSize that of the pack.
+	base     *resource.Pack
+	delta    bool
+	fullSize uint64
+}
+
+// parse parses the resource pack from the data downloaded. If the data is a delta, it is applied to the
+// earlier version of the pack, after which the result must match the checksum passed.
+func (pack *downloadingPack) parse(checksum []byte) (*resource.Pack, error) {
+	if !pack.delta {
+		p, err := resource.ReadBytes(pack.buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("invalid full resource pack data: %w", err)
+		}
+		return p, nil
+	}
+	p, err := resource.Patch(pack.base, pack.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("apply delta: %w", err)
+	}
+	if sum := p.Checksum(); !bytes.Equal(sum[:], checksum) || uint64(p.Len()) != pack.fullSize {
+		return nil, fmt.Errorf("apply delta: resulting pack does not match the pack sent")
+	}
+	return p, nil
 }
 
 // Request 'requests' all resource packs passed, provided they all exist in the resourcePackQueue. If not,
 // an error is returned.
 func (queue *resourcePackQueue) Request(packs []string) error {
 	queue.packsToDownload = make(map[string]*resource.Pack)
+	queue.bases = make(map[string]*resource.Pack)
 	for _, packUUID := range packs {
+		packUUID, base := parsePackRequest(packUUID)
 		found := false
 		for _, pack := range queue.packs {
 			// Mojang made some hack that merges the UUID with the version, so we need to combine that here
@@ -42,6 +80,11 @@ func (queue *resourcePackQueue) Request(packs []string) error {
 			id := pack.UUID().String()
 			if id+"_"+pack.Version() == packUUID {
 				queue.packsToDownload[id] = pack
+				if queue.deltas != nil && base != "" {
+					if b, ok := queue.deltas.base(pack, base); ok {
+						queue.bases[id] = b
+					}
+				}
 				found = true
 				break
 			}
@@ -61,7 +104,18 @@ func (queue *resourcePackQueue) NextPack() (pk *packet.ResourcePackDataInfo, ok
 
 		queue.currentPack = pack
 		queue.currentOffset = 0
+		queue.currentDelta = nil
+		queue.currentID = pack.UUID().String()
+		if base, ok := queue.bases[index]; ok {
+			// Only send the delta if it is actually smaller than the pack, which it might not be if most of
+			// the pack changed.
+			if delta := queue.deltas.delta(base, pack); len(delta) < pack.Len() {
+				queue.currentDelta = delta
+				queue.currentID += packDeltaSuffix
+			}
+		}
 		checksum := pack.Checksum()
+		size := queue.currentLen()
 
 		var packType byte
 		switch {
@@ -77,10 +131,10 @@ func (queue *resourcePackQueue) NextPack() (pk *packet.ResourcePackDataInfo, ok
 			packType = packet.ResourcePackTypeSkins
 		}
 		return &packet.ResourcePackDataInfo{
-			UUID:          pack.UUID().String(),
+			UUID:          queue.currentID,
 			DataChunkSize: packChunkSize,
-			ChunkCount:    uint32(pack.DataChunkCount(packChunkSize)),
-			Size:          uint64(pack.Len()),
+			ChunkCount:    uint32((size + packChunkSize - 1) / packChunkSize),
+			Size:          uint64(size),
 			Hash:          checksum[:],
 			PackType:      packType,
 		}, true
@@ -88,6 +142,28 @@ func (queue *resourcePackQueue) NextPack() (pk *packet.ResourcePackDataInfo, ok
 	return nil, false
 }
 
+// currentLen returns the size of the data sent for the current pack, which is either the pack or the delta
+// sent for it.
+func (queue *resourcePackQueue) currentLen() int {
+	if queue.currentDelta != nil {
+		return len(queue.currentDelta)
+	}
+	return queue.currentPack.Len()
+}
+
+// currentChunk returns the chunk of the data sent for the current pack that starts at the offset passed and
+// has at most the length passed.
+func (queue *resourcePackQueue) currentChunk(off int64, length int) []byte {
+	if queue.currentDelta == nil {
+		return queue.currentPack.Chunk(off, length)
+	}
+	if off < 0 || off >= int64(len(queue.currentDelta)) {
+		return nil
+	}
+	end := min(off+int64(length), int64(len(queue.currentDelta)))
+	return queue.currentDelta[off:end:end]
+}
+
 // AllDownloaded checks if all resource packs in the queue are downloaded.
 func (queue *resourcePackQueue) AllDownloaded() bool {
 	return len(queue.packsToDownload) == 0
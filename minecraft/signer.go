@@ -0,0 +1,126 @@
+package minecraft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/asn1"
+	"fmt"
+	"github.com/go-jose/go-jose/v3"
+	"log/slog"
+	"math/big"
+)
+
+// Signer holds the private key of one end of a connection. It signs the handshake of a connection and
+// computes the shared secret that the connection is encrypted with. Signer may be implemented to keep the
+// private key in an external key management service or hardware security module, so that it never enters
+// the memory of the process. PrivateKey is the default, in-memory implementation of Signer.
+// The key of a Signer must be on the P-384 curve. The methods of a Signer may be called from multiple
+// goroutines simultaneously.
+type Signer interface {
+	// Public returns the public key of the Signer.
+	Public() *ecdsa.PublicKey
+	// Sign signs the SHA-384 digest passed and returns the signature in ASN.1 DER form, like
+	// crypto.Signer does.
+	Sign(digest []byte) ([]byte, error)
+	// SharedSecret performs ECDH with the public key passed and returns the shared secret: The 48 byte,
+	// big-endian X coordinate of the shared point. The caller zeroes the slice returned after use.
+	SharedSecret(pub *ecdsa.PublicKey) ([]byte, error)
+}
+
+// PrivateKey is a Signer that holds an *ecdsa.PrivateKey in memory. Its String method does not print the
+// key, so that it cannot accidentally end up in logs.
+type PrivateKey struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewPrivateKey returns a PrivateKey holding the *ecdsa.PrivateKey passed. The key must be on the P-384
+// curve.
+func NewPrivateKey(key *ecdsa.PrivateKey) *PrivateKey {
+	return &PrivateKey{key: key}
+}
+
+// GeneratePrivateKey generates a new, random P-384 PrivateKey.
+func GeneratePrivateKey() (*PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate private key: %w", err)
+	}
+	return NewPrivateKey(key), nil
+}
+
+// Public ...
+func (k *PrivateKey) Public() *ecdsa.PublicKey {
+	return &k.key.PublicKey
+}
+
+// Sign ...
+func (k *PrivateKey) Sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, k.key, digest)
+}
+
+// SharedSecret ...
+func (k *PrivateKey) SharedSecret(pub *ecdsa.PublicKey) ([]byte, error) {
+	priv, err := k.key.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	// Converting the public key to an ecdh.PublicKey makes sure it is a valid point on the curve.
+	p, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return priv.ECDH(p)
+}
+
+// String returns a redacted representation of the PrivateKey.
+func (k *PrivateKey) String() string {
+	return "PrivateKey(REDACTED)"
+}
+
+// GoString returns a redacted representation of the PrivateKey.
+func (k *PrivateKey) GoString() string {
+	return k.String()
+}
+
+// LogValue returns a redacted representation of the PrivateKey.
+func (k *PrivateKey) LogValue() slog.Value {
+	return slog.StringValue(k.String())
+}
+
+// joseSigner implements jose.OpaqueSigner for a Signer, so that it may be used to sign JWTs using ES384.
+type joseSigner struct {
+	s Signer
+}
+
+// Public ...
+func (j joseSigner) Public() *jose.JSONWebKey {
+	return &jose.JSONWebKey{Key: j.s.Public(), Algorithm: string(jose.ES384)}
+}
+
+// Algs ...
+func (j joseSigner) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{jose.ES384}
+}
+
+// SignPayload signs the SHA-384 hash of the payload passed and returns the signature in the fixed size R || S
+// form that JWS uses.
+func (j joseSigner) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	if alg != jose.ES384 {
+		return nil, jose.ErrUnsupportedAlgorithm
+	}
+	digest := sha512.Sum384(payload)
+	der, err := j.s.Sign(digest[:])
+	if err != nil {
+		return nil, err
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	out := make([]byte, 96)
+	sig.R.FillBytes(out[:48])
+	sig.S.FillBytes(out[48:])
+	return out, nil
+}
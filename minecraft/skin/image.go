@@ -0,0 +1,87 @@
+package skin
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// ReadPNG reads a PNG image from the reader passed and returns it as an *image.RGBA, so that it may be used
+// as the Image or Cape of a Skin.
+func ReadPNG(r io.Reader) (*image.RGBA, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode png: %w", err)
+	}
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba, nil
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba, nil
+}
+
+// WritePNG writes the image passed, such as the Image or Cape of a Skin, to the writer passed as a PNG.
+func WritePNG(w io.Writer, img image.Image) error {
+	if err := png.Encode(w, img); err != nil {
+		return fmt.Errorf("encode png: %w", err)
+	}
+	return nil
+}
+
+// newImage creates an *image.RGBA with the dimensions passed from the RGBA ordered pixel data passed. An
+// error is returned if the length of the data does not match the dimensions.
+func newImage(field string, data []byte, width, height int) (*image.RGBA, error) {
+	if width < 0 || height < 0 || width*height*4 != len(data) {
+		return nil, fmt.Errorf("%v: expected %vx%v image (%v bytes), got %v bytes", field, width, height, width*height*4, len(data))
+	}
+	return &image.RGBA{Pix: data, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}, nil
+}
+
+// decodeImage decodes the base64 encoded RGBA ordered pixel data passed into an *image.RGBA with the
+// dimensions passed.
+func decodeImage(field, data string, width, height int) (*image.RGBA, error) {
+	b, err := decodeBase64(field, data)
+	if err != nil {
+		return nil, err
+	}
+	return newImage(field, b, width, height)
+}
+
+// pixels returns the dimensions and RGBA ordered pixel data of the image passed. If the image is nil, zero
+// dimensions and no data are returned.
+func pixels(img *image.RGBA) (width, height uint32, data []byte) {
+	if img == nil {
+		return 0, 0, nil
+	}
+	b := img.Bounds()
+	if img.Stride == b.Dx()*4 && b.Min == (image.Point{}) {
+		return uint32(b.Dx()), uint32(b.Dy()), img.Pix[:b.Dx()*b.Dy()*4]
+	}
+	// The image is a sub image, such as a frame of an Animation, so its rows are not contiguous.
+	data = make([]byte, 0, b.Dx()*b.Dy()*4)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		off := img.PixOffset(b.Min.X, y)
+		data = append(data, img.Pix[off:off+b.Dx()*4]...)
+	}
+	return uint32(b.Dx()), uint32(b.Dy()), data
+}
+
+// encodeImage returns the dimensions and base64 encoded RGBA ordered pixel data of the image passed.
+func encodeImage(img *image.RGBA) (width, height int, data string) {
+	w, h, b := pixels(img)
+	return int(w), int(h), base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeBase64 decodes the base64 data passed, returning an error mentioning the field passed if it fails.
+func decodeBase64(field, data string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: decode base64: %w", field, err)
+	}
+	return b, nil
+}
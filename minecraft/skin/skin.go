@@ -0,0 +1,249 @@
+// Package skin implements the skins of players in a decoded form. A Skin may be created from the ClientData
+// of a Login packet or from the protocol.Skin found in packets such as PlayerSkin and PlayerList, and be
+// converted back to either of them. The images of a Skin are regular images, so that they may be previewed,
+// exported to PNG or modified, for example by a proxy that rewrites the skins of players.
+package skin
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+	"image"
+)
+
+// Skin is the skin of a player, including its cape, geometry and animations.
+type Skin struct {
+	// ID is a unique ID of the skin, which is different for every skin.
+	ID string
+	// FullID is an ID that identifies the combination of skin and cape.
+	FullID string
+	// PlayFabID is the PlayFab ID of the player that the skin belongs to.
+	PlayFabID string
+	// Image is the texture of the skin.
+	Image *image.RGBA
+	// CapeID is the ID of the cape, or an empty string if the skin has no cape.
+	CapeID string
+	// Cape is the texture of the cape, or nil if the skin has no cape.
+	Cape *image.RGBA
+	// ResourcePatch is the JSON that points to the geometry used by the skin. GeometryName returns the name
+	// of the geometry that it points to.
+	ResourcePatch []byte
+	// Geometry is the JSON geometry data of the skin, holding the bones that the model of the skin consists
+	// of. It may be empty if the skin uses a geometry built into the game.
+	Geometry []byte
+	// GeometryVersion is the version of the game that the geometry was created for, such as '1.14.0'.
+	GeometryVersion string
+	// Animations holds the animations applied on top of the skin.
+	Animations []Animation
+	// AnimationData holds the JSON animation data of the skin.
+	AnimationData []byte
+	// ArmSize is the size of the arms of the skin, which is either 'wide' or 'slim'.
+	ArmSize string
+	// Colour is the base colour of the skin in hex notation, such as '#0', used for persona skins.
+	Colour string
+	// PersonaPieces holds the pieces that a persona skin consists of. It is empty for other skins.
+	PersonaPieces []protocol.PersonaPiece
+	// PieceTintColours holds the tint colours of the persona pieces of a persona skin.
+	PieceTintColours []protocol.PersonaPieceTintColour
+	// Premium specifies if the skin was obtained through the marketplace.
+	Premium bool
+	// Persona specifies if the skin was created using the in-game character creator.
+	Persona bool
+	// CapeOnClassicSkin specifies if the cape of the skin is part of a classic skin.
+	CapeOnClassicSkin bool
+	// Trusted specifies if the skin is trusted by the client.
+	Trusted bool
+	// OverrideAppearance specifies if the skin overrides the appearance of the player.
+	OverrideAppearance bool
+}
+
+// Animation is an animation applied on top of a Skin, such as blinking eyes.
+type Animation struct {
+	// Image holds all frames of the animation, stacked vertically. Frame returns a single frame.
+	Image *image.RGBA
+	// Type is the part of the skin that the animation covers: protocol.SkinAnimationHead,
+	// protocol.SkinAnimationBody32x32 or protocol.SkinAnimationBody128x128.
+	Type uint32
+	// Frames is the amount of frames in the animation.
+	Frames int
+	// Expression is the type of expression of the animation: protocol.ExpressionTypeLinear or
+	// protocol.ExpressionTypeBlinking.
+	Expression uint32
+}
+
+// Frame returns the frame of the animation with the index passed. Frame returns nil if the index is out of
+// range.
+func (a Animation) Frame(i int) *image.RGBA {
+	if i < 0 || i >= a.Frames || a.Image == nil {
+		return nil
+	}
+	b := a.Image.Bounds()
+	h := b.Dy() / a.Frames
+	return a.Image.SubImage(image.Rect(b.Min.X, b.Min.Y+i*h, b.Max.X, b.Min.Y+(i+1)*h)).(*image.RGBA)
+}
+
+// FromClientData creates a Skin from the skin fields of the ClientData passed. An error is returned if any of
+// the fields could not be decoded or if the size of an image does not match its dimensions.
+func FromClientData(d login.ClientData) (Skin, error) {
+	s := Skin{
+		ID:                 d.SkinID,
+		FullID:             d.SkinID + d.CapeID,
+		PlayFabID:          d.PlayFabID,
+		CapeID:             d.CapeID,
+		GeometryVersion:    d.SkinGeometryVersion,
+		ArmSize:            d.ArmSize,
+		Colour:             d.SkinColour,
+		Premium:            d.PremiumSkin,
+		Persona:            d.PersonaSkin,
+		CapeOnClassicSkin:  d.CapeOnClassicSkin,
+		Trusted:            d.TrustedSkin,
+		OverrideAppearance: d.OverrideSkin,
+	}
+	var err error
+	if s.Image, err = decodeImage("SkinData", d.SkinData, d.SkinImageWidth, d.SkinImageHeight); err != nil {
+		return Skin{}, err
+	}
+	if d.CapeData != "" {
+		if s.Cape, err = decodeImage("CapeData", d.CapeData, d.CapeImageWidth, d.CapeImageHeight); err != nil {
+			return Skin{}, err
+		}
+	}
+	if s.ResourcePatch, err = decodeBase64("SkinResourcePatch", d.SkinResourcePatch); err != nil {
+		return Skin{}, err
+	}
+	if s.Geometry, err = decodeBase64("SkinGeometry", d.SkinGeometry); err != nil {
+		return Skin{}, err
+	}
+	if s.AnimationData, err = decodeBase64("SkinAnimationData", d.SkinAnimationData); err != nil {
+		return Skin{}, err
+	}
+	if version, err := decodeBase64("SkinGeometryVersion", d.SkinGeometryVersion); err == nil {
+		s.GeometryVersion = string(version)
+	}
+	for i, a := range d.AnimatedImageData {
+		img, err := decodeImage(fmt.Sprintf("AnimatedImageData[%v].Image", i), a.Image, a.ImageWidth, a.ImageHeight)
+		if err != nil {
+			return Skin{}, err
+		}
+		s.Animations = append(s.Animations, Animation{Image: img, Type: uint32(a.Type), Frames: int(a.Frames), Expression: uint32(a.AnimationExpression)})
+	}
+	for _, p := range d.PersonaPieces {
+		s.PersonaPieces = append(s.PersonaPieces, protocol.PersonaPiece{
+			PieceID: p.PieceID, PieceType: p.PieceType, PackID: p.PackID, Default: p.Default, ProductID: p.ProductID,
+		})
+	}
+	for _, c := range d.PieceTintColours {
+		s.PieceTintColours = append(s.PieceTintColours, protocol.PersonaPieceTintColour{PieceType: c.PieceType, Colours: c.Colours[:]})
+	}
+	return s, nil
+}
+
+// ClientData sets the skin fields of the ClientData passed to those of the Skin. Other fields of the
+// ClientData are left untouched.
+func (s Skin) ClientData(d *login.ClientData) {
+	d.SkinID, d.PlayFabID, d.CapeID = s.ID, s.PlayFabID, s.CapeID
+	d.SkinImageWidth, d.SkinImageHeight, d.SkinData = encodeImage(s.Image)
+	d.CapeImageWidth, d.CapeImageHeight, d.CapeData = encodeImage(s.Cape)
+	d.SkinResourcePatch = base64.StdEncoding.EncodeToString(s.ResourcePatch)
+	d.SkinGeometry = base64.StdEncoding.EncodeToString(s.Geometry)
+	d.SkinGeometryVersion = base64.StdEncoding.EncodeToString([]byte(s.GeometryVersion))
+	d.SkinAnimationData = base64.StdEncoding.EncodeToString(s.AnimationData)
+	d.ArmSize, d.SkinColour = s.ArmSize, s.Colour
+	d.PremiumSkin, d.PersonaSkin, d.CapeOnClassicSkin = s.Premium, s.Persona, s.CapeOnClassicSkin
+	d.TrustedSkin, d.OverrideSkin = s.Trusted, s.OverrideAppearance
+
+	d.AnimatedImageData = make([]login.SkinAnimation, 0, len(s.Animations))
+	for _, a := range s.Animations {
+		w, h, data := encodeImage(a.Image)
+		d.AnimatedImageData = append(d.AnimatedImageData, login.SkinAnimation{
+			Frames: float64(a.Frames), Image: data, ImageWidth: w, ImageHeight: h, Type: int(a.Type), AnimationExpression: int(a.Expression),
+		})
+	}
+	d.PersonaPieces = make([]login.PersonaPiece, 0, len(s.PersonaPieces))
+	for _, p := range s.PersonaPieces {
+		d.PersonaPieces = append(d.PersonaPieces, login.PersonaPiece{
+			Default: p.Default, PackID: p.PackID, PieceID: p.PieceID, PieceType: p.PieceType, ProductID: p.ProductID,
+		})
+	}
+	d.PieceTintColours = make([]login.PersonaPieceTintColour, 0, len(s.PieceTintColours))
+	for _, c := range s.PieceTintColours {
+		tint := login.PersonaPieceTintColour{PieceType: c.PieceType}
+		copy(tint.Colours[:], c.Colours)
+		d.PieceTintColours = append(d.PieceTintColours, tint)
+	}
+}
+
+// FromProtocol creates a Skin from the protocol.Skin passed. An error is returned if the size of an image
+// does not match its dimensions.
+func FromProtocol(p protocol.Skin) (Skin, error) {
+	s := Skin{
+		ID:                 p.SkinID,
+		FullID:             p.FullID,
+		PlayFabID:          p.PlayFabID,
+		CapeID:             p.CapeID,
+		ResourcePatch:      p.SkinResourcePatch,
+		Geometry:           p.SkinGeometry,
+		GeometryVersion:    string(p.GeometryDataEngineVersion),
+		AnimationData:      p.AnimationData,
+		ArmSize:            p.ArmSize,
+		Colour:             p.SkinColour,
+		PersonaPieces:      p.PersonaPieces,
+		PieceTintColours:   p.PieceTintColours,
+		Premium:            p.PremiumSkin,
+		Persona:            p.PersonaSkin,
+		CapeOnClassicSkin:  p.PersonaCapeOnClassicSkin,
+		Trusted:            p.Trusted,
+		OverrideAppearance: p.OverrideAppearance,
+	}
+	var err error
+	if s.Image, err = newImage("SkinData", p.SkinData, int(p.SkinImageWidth), int(p.SkinImageHeight)); err != nil {
+		return Skin{}, err
+	}
+	if len(p.CapeData) != 0 {
+		if s.Cape, err = newImage("CapeData", p.CapeData, int(p.CapeImageWidth), int(p.CapeImageHeight)); err != nil {
+			return Skin{}, err
+		}
+	}
+	for i, a := range p.Animations {
+		img, err := newImage(fmt.Sprintf("Animations[%v].ImageData", i), a.ImageData, int(a.ImageWidth), int(a.ImageHeight))
+		if err != nil {
+			return Skin{}, err
+		}
+		s.Animations = append(s.Animations, Animation{Image: img, Type: a.AnimationType, Frames: int(a.FrameCount), Expression: a.ExpressionType})
+	}
+	return s, nil
+}
+
+// Protocol returns the protocol.Skin representation of the Skin, which may be sent in packets such as
+// PlayerSkin and PlayerList.
+func (s Skin) Protocol() protocol.Skin {
+	p := protocol.Skin{
+		SkinID:                    s.ID,
+		PlayFabID:                 s.PlayFabID,
+		SkinResourcePatch:         s.ResourcePatch,
+		SkinGeometry:              s.Geometry,
+		AnimationData:             s.AnimationData,
+		GeometryDataEngineVersion: []byte(s.GeometryVersion),
+		PremiumSkin:               s.Premium,
+		PersonaSkin:               s.Persona,
+		PersonaCapeOnClassicSkin:  s.CapeOnClassicSkin,
+		CapeID:                    s.CapeID,
+		FullID:                    s.FullID,
+		SkinColour:                s.Colour,
+		ArmSize:                   s.ArmSize,
+		PersonaPieces:             s.PersonaPieces,
+		PieceTintColours:          s.PieceTintColours,
+		Trusted:                   s.Trusted,
+		OverrideAppearance:        s.OverrideAppearance,
+	}
+	p.SkinImageWidth, p.SkinImageHeight, p.SkinData = pixels(s.Image)
+	p.CapeImageWidth, p.CapeImageHeight, p.CapeData = pixels(s.Cape)
+	for _, a := range s.Animations {
+		w, h, data := pixels(a.Image)
+		p.Animations = append(p.Animations, protocol.SkinAnimation{
+			ImageWidth: w, ImageHeight: h, ImageData: data, AnimationType: a.Type, FrameCount: float32(a.Frames), ExpressionType: a.Expression,
+		})
+	}
+	return p
+}
@@ -0,0 +1,113 @@
+package skin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"slices"
+)
+
+// validSizes holds the dimensions of skin images accepted by the client.
+var validSizes = []imageSize{{64, 32}, {64, 64}, {128, 64}, {128, 128}, {256, 128}, {256, 256}, {512, 256}, {512, 512}}
+
+// imageSize holds the width and height of an image.
+type imageSize struct{ w, h int }
+
+// Validate checks if the Skin may be sent to clients without causing problems. It checks the dimensions of
+// the skin, cape and animation images, the amount of frames of animations and whether the resource patch and
+// geometry are valid JSON. The errors found are combined into the error returned.
+func (s Skin) Validate() error {
+	var errs []error
+	if s.Image == nil {
+		errs = append(errs, errors.New("skin has no image"))
+	} else if b := s.Image.Bounds(); !slices.Contains(validSizes, imageSize{b.Dx(), b.Dy()}) {
+		errs = append(errs, fmt.Errorf("invalid skin size %vx%v", b.Dx(), b.Dy()))
+	}
+	if s.Cape != nil {
+		if b := s.Cape.Bounds(); b.Dx() != 64 || b.Dy() != 32 {
+			errs = append(errs, fmt.Errorf("invalid cape size %vx%v: must be 64x32", b.Dx(), b.Dy()))
+		}
+	}
+	for i, a := range s.Animations {
+		if err := a.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("animation %v: %w", i, err))
+		}
+	}
+	if len(s.ResourcePatch) != 0 && !json.Valid(s.ResourcePatch) {
+		errs = append(errs, errors.New("resource patch is not valid JSON"))
+	}
+	if len(s.Geometry) != 0 && !json.Valid(s.Geometry) {
+		errs = append(errs, errors.New("geometry is not valid JSON"))
+	}
+	if s.ArmSize != "" && s.ArmSize != "wide" && s.ArmSize != "slim" {
+		errs = append(errs, fmt.Errorf("invalid arm size %q", s.ArmSize))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks if the Animation has a valid type, frame count and image size.
+func (a Animation) validate() error {
+	if a.Type < protocol.SkinAnimationHead || a.Type > protocol.SkinAnimationBody128x128 {
+		return fmt.Errorf("invalid animation type %v", a.Type)
+	}
+	if a.Image == nil {
+		return errors.New("animation has no image")
+	}
+	if a.Frames <= 0 {
+		return fmt.Errorf("invalid frame count %v", a.Frames)
+	}
+	if b := a.Image.Bounds(); b.Dy()%a.Frames != 0 {
+		return fmt.Errorf("image height %v is not a multiple of frame count %v", b.Dy(), a.Frames)
+	}
+	return nil
+}
+
+// GeometryName returns the name of the geometry that the resource patch of the Skin points to, such as
+// 'geometry.humanoid.custom'.
+func (s Skin) GeometryName() (string, error) {
+	var patch struct {
+		Geometry struct {
+			Default string `json:"default"`
+		} `json:"geometry"`
+	}
+	if err := json.Unmarshal(s.ResourcePatch, &patch); err != nil {
+		return "", fmt.Errorf("decode resource patch: %w", err)
+	}
+	return patch.Geometry.Default, nil
+}
+
+// GeometryIdentifiers returns the identifiers of all geometries defined in the geometry data of the Skin.
+// Both the legacy format, in which geometries are keys of the root object, and the format used since
+// version 1.12.0, in which they are listed under 'minecraft:geometry', are supported.
+func (s Skin) GeometryIdentifiers() ([]string, error) {
+	if len(s.Geometry) == 0 {
+		return nil, nil
+	}
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(s.Geometry, &root); err != nil {
+		return nil, fmt.Errorf("decode geometry: %w", err)
+	}
+	var ids []string
+	if data, ok := root["minecraft:geometry"]; ok {
+		var geometries []struct {
+			Description struct {
+				Identifier string `json:"identifier"`
+			} `json:"description"`
+		}
+		if err := json.Unmarshal(data, &geometries); err != nil {
+			return nil, fmt.Errorf("decode minecraft:geometry: %w", err)
+		}
+		for _, g := range geometries {
+			ids = append(ids, g.Description.Identifier)
+		}
+		return ids, nil
+	}
+	for k := range root {
+		if k != "format_version" {
+			ids = append(ids, k)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
@@ -1,4 +1,5 @@
 // Package text has utility methods used for formatting text to display in Minecraft, and to convert these
 // colour codes into codes suitable for the command line. It also contains constants for each of the
-// Minecraft colours and formatting codes.
+// Minecraft colours and formatting codes, a RawText type for the JSON text components used by /tellraw,
+// and functions that build Text packets of the common text types.
 package text
@@ -0,0 +1,46 @@
+package text
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Message returns a Text packet that displays the message passed in the chat as is, without a source.
+func Message(message string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeRaw, Message: message}
+}
+
+// Chat returns a Text packet that displays a chat message sent by the source passed, such as a player name.
+// The XUID should be the XUID of the player that sent the message, or empty if the source is not a player.
+// Clients only show chat messages with an XUID if the player with that XUID is in their player list.
+func Chat(source, xuid, message string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeChat, SourceName: source, XUID: xuid, Message: message}
+}
+
+// Translation returns a Text packet that displays the translation with the key passed in the chat. The key
+// is typically prefixed with %, such as "%commands.op.success", and the parameters passed are substituted in
+// the translation.
+func Translation(key string, params ...string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeTranslation, NeedsTranslation: true, Message: key, Parameters: params}
+}
+
+// Popup returns a Text packet that displays the translation with the key passed above the hotbar, with the
+// parameters passed substituted in it. The key may also be a message displayed as is.
+func Popup(key string, params ...string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypePopup, NeedsTranslation: true, Message: key, Parameters: params}
+}
+
+// Tip returns a Text packet that displays the message passed above the hotbar.
+func Tip(message string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeTip, Message: message}
+}
+
+// System returns a Text packet that displays the message passed in the chat as a system message.
+func System(message string) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeSystem, Message: message}
+}
+
+// Object returns a Text packet that displays the RawText passed in the chat, as done by the /tellraw
+// command.
+func Object(r RawText) *packet.Text {
+	return &packet.Text{TextType: packet.TextTypeObject, Message: r.String()}
+}
@@ -0,0 +1,135 @@
+package text
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RawText is a JSON text component as used by the /tellraw and /titleraw commands and by Text packets of the
+// packet.TextTypeObject type, in the form {"rawtext":[...]}. The components of a RawText are displayed one
+// after another.
+type RawText struct {
+	RawText []Component `json:"rawtext"`
+}
+
+// Component is a single component of a RawText. Exactly one of Text, Translate, Selector and Score should be
+// set.
+type Component struct {
+	// Text is text displayed as is, which may hold formatting codes.
+	Text string `json:"text,omitempty"`
+	// Translate is the key of a translation displayed in the language of the client. The %s, %1 etc. in the
+	// translation are substituted with the components in With.
+	Translate string `json:"translate,omitempty"`
+	// With holds the parameters substituted in the translation of Translate.
+	With *RawText `json:"with,omitempty"`
+	// Selector is a target selector, such as @p, which is displayed as the names of the entities selected.
+	Selector string `json:"selector,omitempty"`
+	// Score is the score of an entity in a scoreboard objective.
+	Score *Score `json:"score,omitempty"`
+}
+
+// Score is a Component displaying the score of an entity in a scoreboard objective.
+type Score struct {
+	// Name is the name of the entity, or a target selector. The name * refers to the player viewing the
+	// text.
+	Name string `json:"name"`
+	// Objective is the name of the scoreboard objective.
+	Objective string `json:"objective"`
+}
+
+// Raw returns a RawText holding the components passed.
+func Raw(components ...Component) RawText {
+	return RawText{RawText: components}
+}
+
+// Plain returns a Component holding the text passed.
+func Plain(s string) Component {
+	return Component{Text: s}
+}
+
+// Translate returns a Component holding a translation with the key passed, substituted with the components
+// passed as parameters.
+func Translate(key string, with ...Component) Component {
+	c := Component{Translate: key}
+	if len(with) != 0 {
+		r := Raw(with...)
+		c.With = &r
+	}
+	return c
+}
+
+// ParseRawText parses a RawText from its JSON representation.
+func ParseRawText(s string) (RawText, error) {
+	var r RawText
+	if err := json.Unmarshal([]byte(s), &r); err != nil {
+		return r, fmt.Errorf("parse rawtext: %w", err)
+	}
+	return r, nil
+}
+
+// String returns the JSON representation of the RawText, as sent in a Text packet of the
+// packet.TextTypeObject type.
+func (r RawText) String() string {
+	if r.RawText == nil {
+		// The client expects an array, even if no components are present.
+		r.RawText = []Component{}
+	}
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	// Formatting codes and translation parameters would otherwise be escaped needlessly.
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(r)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Plain returns the RawText as plain text, concatenating the Text of all components. Translations are not
+// translated: Their key is written, followed by their parameters between brackets. Selectors and scores are
+// written as is, as they can only be resolved by the server.
+func (r RawText) Plain() string {
+	b := &strings.Builder{}
+	r.writePlain(b)
+	return b.String()
+}
+
+// writePlain writes the RawText as plain text to the strings.Builder passed.
+func (r RawText) writePlain(b *strings.Builder) {
+	for _, c := range r.RawText {
+		switch {
+		case c.Text != "":
+			b.WriteString(c.Text)
+		case c.Translate != "":
+			b.WriteString(c.Translate)
+			if c.With != nil {
+				b.WriteByte('[')
+				for i, param := range c.With.RawText {
+					if i != 0 {
+						b.WriteString(", ")
+					}
+					Raw(param).writePlain(b)
+				}
+				b.WriteByte(']')
+			}
+		case c.Selector != "":
+			b.WriteString(c.Selector)
+		case c.Score != nil:
+			b.WriteString(c.Score.Name + ":" + c.Score.Objective)
+		}
+	}
+}
+
+// UnmarshalJSON ...
+func (r *RawText) UnmarshalJSON(b []byte) error {
+	// The with field of a translation may also be an array of strings rather than a rawtext object.
+	var params []string
+	if err := json.Unmarshal(b, &params); err == nil {
+		r.RawText = make([]Component, len(params))
+		for i, param := range params {
+			r.RawText[i] = Plain(param)
+		}
+		return nil
+	}
+	type rawText RawText
+	return json.Unmarshal(b, (*rawText)(r))
+}
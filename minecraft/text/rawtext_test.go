@@ -0,0 +1,34 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRawText verifies that a RawText is encoded in the format used by /tellraw and may be parsed again,
+// including translations with parameters passed as an array of strings.
+func TestRawText(t *testing.T) {
+	r := Raw(Plain(Red+"Hi "), Translate("%chat.type.text", Plain("<name>"), Component{Selector: "@p"}))
+	const expected = `{"rawtext":[{"text":"§cHi "},{"translate":"%chat.type.text","with":{"rawtext":[{"text":"<name>"},{"selector":"@p"}]}}]}`
+	if s := r.String(); s != expected {
+		t.Fatalf("expected %v, got %v", expected, s)
+	}
+	parsed, err := ParseRawText(expected)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, r) {
+		t.Fatalf("expected %+v, got %+v", r, parsed)
+	}
+
+	parsed, err = ParseRawText(`{"rawtext":[{"translate":"commands.op.success","with":["Steve"]}]}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if s := parsed.Plain(); s != "commands.op.success[Steve]" {
+		t.Fatalf("expected plain text commands.op.success[Steve], got %v", s)
+	}
+	if s := Raw().String(); s != `{"rawtext":[]}` {
+		t.Fatalf("expected empty rawtext, got %v", s)
+	}
+}
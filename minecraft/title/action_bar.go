@@ -0,0 +1,82 @@
+package title
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/text"
+	"sync"
+	"time"
+)
+
+// ActionBar shows text above the hotbar of a single connection, limiting the rate at which it is updated.
+// Updating an action bar restarts its fade animation on the client, so updating it every tick, for example
+// to show a countdown or coordinates, makes it flicker and wastes bandwidth. ActionBar sends at most one
+// update per interval: Text set within the interval after an update is sent once the interval has passed,
+// with only the latest text being sent. An ActionBar is safe for concurrent use.
+type ActionBar struct {
+	conn     *minecraft.Conn
+	interval time.Duration
+
+	mu   sync.Mutex
+	text string
+	// sent is the text last sent, and last the time at which it was sent.
+	sent string
+	last time.Time
+	// timer is non-nil if an update is scheduled to be sent.
+	timer *time.Timer
+	err   error
+}
+
+// NewActionBar returns an ActionBar for the connection passed that is updated at most once per interval. An
+// interval of a tick (50 milliseconds) to a few ticks is usually suitable.
+func NewActionBar(conn *minecraft.Conn, interval time.Duration) *ActionBar {
+	return &ActionBar{conn: conn, interval: interval}
+}
+
+// Set sets the text of the ActionBar. It is sent immediately if the ActionBar was not updated within the
+// last interval, and otherwise once the interval has passed. Set is a no-op if the text is equal to the text
+// last sent. If sending a previous update failed, its error is returned.
+func (bar *ActionBar) Set(text string) error {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	if bar.err != nil {
+		return bar.err
+	}
+	bar.text = text
+	if bar.timer != nil {
+		// An update is already scheduled and will send the latest text.
+		return nil
+	}
+	if wait := bar.interval - time.Since(bar.last); wait > 0 {
+		bar.timer = time.AfterFunc(wait, func() {
+			bar.mu.Lock()
+			defer bar.mu.Unlock()
+			bar.timer = nil
+			bar.err = bar.send()
+		})
+		return nil
+	}
+	return bar.send()
+}
+
+// Text returns the text most recently set, which may not have been sent yet.
+func (bar *ActionBar) Text() string {
+	bar.mu.Lock()
+	defer bar.mu.Unlock()
+	return bar.text
+}
+
+// send sends the current text of the ActionBar if it differs from the text last sent. bar.mu must be held.
+func (bar *ActionBar) send() error {
+	if bar.text == bar.sent && !bar.last.IsZero() {
+		return nil
+	}
+	bar.sent, bar.last = bar.text, time.Now()
+	return bar.conn.WritePacket(&packet.SetTitle{ActionType: packet.TitleActionSetActionBar, Text: bar.text})
+}
+
+// Tip shows the message passed above the hotbar of the connection passed, using a Text packet rather than
+// the SetTitle packet. Unlike an ActionBar, tips are not rate limited.
+func Tip(conn *minecraft.Conn, message string) error {
+	return conn.WritePacket(text.Tip(message))
+}
@@ -0,0 +1,104 @@
+package title
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"sync"
+	"time"
+)
+
+// Queue shows titles to a single connection one after another: Every Title pushed is shown once the Title
+// before it has faded out completely. A Queue is safe for concurrent use.
+type Queue struct {
+	conn *minecraft.Conn
+
+	mu      sync.Mutex
+	pending []Title
+	// timer fires once the Title currently shown has faded out. It is nil if no Title is being shown.
+	timer *time.Timer
+}
+
+// NewQueue returns an empty Queue that shows titles to the connection passed.
+func NewQueue(conn *minecraft.Conn) *Queue {
+	return &Queue{conn: conn}
+}
+
+// Push adds a Title to the Queue. If no Title is currently being shown by the Queue, it is shown
+// immediately. If sending the Title fails, for example because the connection was closed, the error is
+// returned and all titles in the Queue are dropped.
+func (q *Queue) Push(t Title) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.timer != nil {
+		q.pending = append(q.pending, t)
+		return nil
+	}
+	return q.show(t)
+}
+
+// Len returns the amount of titles waiting to be shown, not including the Title currently shown.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Clear drops all titles waiting to be shown and removes the Title currently shown from the screen of the
+// connection.
+func (q *Queue) Clear() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stop()
+	return Clear(q.conn)
+}
+
+// Skip removes the Title currently shown and shows the next Title in the Queue, if any.
+func (q *Queue) Skip() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	if len(q.pending) == 0 {
+		return Clear(q.conn)
+	}
+	next := q.pending[0]
+	q.pending = q.pending[1:]
+	return q.show(next)
+}
+
+// show sends the Title passed and schedules the next Title to be shown once it has faded out. q.mu must be
+// held.
+func (q *Queue) show(t Title) error {
+	if err := Send(q.conn, t); err != nil {
+		q.stop()
+		return err
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(t.Duration(), func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if q.timer != timer {
+			// The Queue was cleared or skipped in the meantime.
+			return
+		}
+		q.timer = nil
+		if len(q.pending) == 0 {
+			return
+		}
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		_ = q.show(next)
+	})
+	q.timer = timer
+	return nil
+}
+
+// stop drops all titles in the Queue and stops the timer of the Title currently shown. q.mu must be held.
+func (q *Queue) stop() {
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	q.pending = nil
+}
@@ -0,0 +1,107 @@
+// Package title implements titles, subtitles and action bars shown to players connected through a
+// minecraft.Listener. It wraps around the SetTitle packet, which has several stateful quirks: Fade durations
+// persist on the client until they are changed or reset, a subtitle is only shown once a title is shown
+// after it, and a new title replaces the one on screen immediately. Titles are sent so that these quirks
+// do not have to be accounted for, and may be queued so that they are shown one after another.
+package title
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"time"
+)
+
+const (
+	// DefaultFadeIn, DefaultStay and DefaultFadeOut are the durations that the client uses for titles if no
+	// durations were set. They are also used by New.
+	DefaultFadeIn  = time.Second / 2
+	DefaultStay    = time.Second * 7 / 2
+	DefaultFadeOut = time.Second
+)
+
+// Title is a title with an optional subtitle, shown in the centre of the screen of a player. The durations of
+// a Title are sent with it, so that a Title is always shown for the same duration regardless of titles shown
+// before it. Durations are rounded down to ticks.
+type Title struct {
+	// Text is the text of the title. If empty, only the subtitle is shown.
+	Text string
+	// Subtitle is the text shown below the title, in a smaller font.
+	Subtitle string
+	// FadeIn is the time that the title takes to fade in.
+	FadeIn time.Duration
+	// Stay is the time that the title remains on screen once faded in.
+	Stay time.Duration
+	// FadeOut is the time that the title takes to fade out.
+	FadeOut time.Duration
+}
+
+// New returns a Title with the text passed and the default durations of the client.
+func New(text string) Title {
+	return Title{Text: text, FadeIn: DefaultFadeIn, Stay: DefaultStay, FadeOut: DefaultFadeOut}
+}
+
+// WithSubtitle returns a copy of the Title with the subtitle passed.
+func (t Title) WithSubtitle(subtitle string) Title {
+	t.Subtitle = subtitle
+	return t
+}
+
+// WithDurations returns a copy of the Title with the durations passed.
+func (t Title) WithDurations(fadeIn, stay, fadeOut time.Duration) Title {
+	t.FadeIn, t.Stay, t.FadeOut = fadeIn, stay, fadeOut
+	return t
+}
+
+// Duration returns the total time that the Title is visible for: Its fade in, stay and fade out durations
+// combined.
+func (t Title) Duration() time.Duration {
+	return t.FadeIn + t.Stay + t.FadeOut
+}
+
+// packets returns the SetTitle packets needed to show the Title.
+func (t Title) packets() []packet.Packet {
+	text := t.Text
+	if text == "" {
+		// The client only shows a subtitle when a title is set, so a blank title is shown instead.
+		text = " "
+	}
+	pks := []packet.Packet{&packet.SetTitle{
+		ActionType:      packet.TitleActionSetDurations,
+		FadeInDuration:  ticks(t.FadeIn),
+		RemainDuration:  ticks(t.Stay),
+		FadeOutDuration: ticks(t.FadeOut),
+	}}
+	// The subtitle must be sent before the title, as setting the title shows it along with the current
+	// subtitle. An empty subtitle is sent too, so that no subtitle of a previous title is shown.
+	return append(pks,
+		&packet.SetTitle{ActionType: packet.TitleActionSetSubtitle, Text: t.Subtitle},
+		&packet.SetTitle{ActionType: packet.TitleActionSetTitle, Text: text},
+	)
+}
+
+// Send shows the Title to the connection passed, replacing any title currently shown. To show multiple
+// titles one after another, use a Queue.
+func Send(conn *minecraft.Conn, t Title) error {
+	for _, pk := range t.packets() {
+		if err := conn.WritePacket(pk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear removes the title currently shown to the connection passed from its screen.
+func Clear(conn *minecraft.Conn) error {
+	return conn.WritePacket(&packet.SetTitle{ActionType: packet.TitleActionClear})
+}
+
+// Reset removes the title currently shown to the connection passed and resets its title durations to those of
+// the client.
+func Reset(conn *minecraft.Conn) error {
+	return conn.WritePacket(&packet.SetTitle{ActionType: packet.TitleActionReset})
+}
+
+// ticks converts a duration to ticks, as used in the SetTitle packet.
+func ticks(d time.Duration) int32 {
+	return int32(d / (time.Second / 20))
+}
@@ -0,0 +1,169 @@
+package minecraft
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// PacketTranslation describes how a single packet of the latest protocol differs from the same packet in an
+// older protocol implemented by a Translator. A packet for which no PacketTranslation is passed to a
+// Translator is assumed to be unchanged between the two protocols.
+type PacketTranslation struct {
+	// ID is the ID of the packet in the latest protocol.
+	ID uint32
+	// LegacyID is the ID of the packet in the older protocol. If left as 0, the packet has the same ID in
+	// both protocols.
+	LegacyID uint32
+	// Removed specifies if the packet does not exist in the older protocol at all. Removed packets are never
+	// decoded and are silently dropped when written to a Conn using the older protocol.
+	Removed bool
+	// New returns a new packet as it is encoded in the older protocol. It is used to decode the packet when
+	// it is read. The ID method of the packet returned must return the ID of the packet in the older
+	// protocol. If New is nil, the packet of the latest protocol is used for decoding.
+	New func() packet.Packet
+	// ToLatest converts a packet read from a Conn using the older protocol to packets of the latest
+	// protocol. The packet passed is either one returned by New, or a packet of the latest protocol if New
+	// is nil. If ToLatest is nil, the packet is returned as is.
+	ToLatest func(pk packet.Packet, conn *Conn) []packet.Packet
+	// FromLatest converts a packet of the latest protocol to packets of the older protocol, before they are
+	// written to a Conn using the older protocol. Returning no packets drops the packet. If FromLatest is
+	// nil, the packet is written as is, using LegacyID if it is set.
+	FromLatest func(pk packet.Packet, conn *Conn) []packet.Packet
+}
+
+// RemapID returns a PacketTranslation for a packet that is unchanged in an older protocol apart from its ID.
+func RemapID(id, legacyID uint32) PacketTranslation {
+	return PacketTranslation{ID: id, LegacyID: legacyID}
+}
+
+// RemovePacket returns a PacketTranslation for a packet that does not exist in an older protocol.
+func RemovePacket(id uint32) PacketTranslation {
+	return PacketTranslation{ID: id, Removed: true}
+}
+
+// Translator is a Protocol implementation that translates packets between the latest protocol and an older
+// protocol, based on a set of PacketTranslations. Translators may be added to ListenConfig.AcceptedProtocols
+// so that a single Listener accepts clients across several game versions, or set as Dialer.Protocol to
+// connect to a server running an older version.
+type Translator struct {
+	proto
+	id  int32
+	ver string
+
+	latest map[uint32]PacketTranslation
+	legacy map[uint32]PacketTranslation
+}
+
+// NewTranslator returns a Translator for the protocol with the ID and Minecraft version passed. The
+// PacketTranslations passed describe all packets that differ between the latest protocol and the protocol
+// of the Translator.
+func NewTranslator(id int32, ver string, translations ...PacketTranslation) *Translator {
+	t := &Translator{
+		id:     id,
+		ver:    ver,
+		latest: make(map[uint32]PacketTranslation, len(translations)),
+		legacy: make(map[uint32]PacketTranslation, len(translations)),
+	}
+	for _, tr := range translations {
+		t.latest[tr.ID] = tr
+		if !tr.Removed {
+			t.legacy[tr.legacyID()] = tr
+		}
+	}
+	return t
+}
+
+// ID returns the protocol ID passed to NewTranslator.
+func (t *Translator) ID() int32 {
+	return t.id
+}
+
+// Ver returns the Minecraft version passed to NewTranslator.
+func (t *Translator) Ver() string {
+	return t.ver
+}
+
+// Packets returns the packet.Pool of the latest protocol with all PacketTranslations applied to it. Packets
+// that are not sent in the direction specified by listener in the latest protocol are left out.
+func (t *Translator) Packets(listener bool) packet.Pool {
+	latest := t.proto.Packets(listener)
+	pool := make(packet.Pool, len(latest))
+	for id, f := range latest {
+		if _, ok := t.latest[id]; !ok {
+			pool[id] = f
+		}
+	}
+	for id, tr := range t.latest {
+		f, ok := latest[id]
+		if !ok || tr.Removed {
+			continue
+		}
+		legacyID := tr.legacyID()
+		switch {
+		case tr.New != nil:
+			pool[legacyID] = tr.New
+		case legacyID != id:
+			pool[legacyID] = func() packet.Packet { return &remappedPacket{Packet: f(), id: legacyID} }
+		default:
+			pool[id] = f
+		}
+	}
+	return pool
+}
+
+// ConvertToLatest converts a packet read using the protocol of the Translator to packets of the latest
+// protocol using the ToLatest function of its PacketTranslation.
+func (t *Translator) ConvertToLatest(pk packet.Packet, conn *Conn) []packet.Packet {
+	tr, ok := t.legacy[pk.ID()]
+	if r, remapped := pk.(*remappedPacket); remapped {
+		pk = r.Packet
+	}
+	if !ok || tr.ToLatest == nil {
+		return []packet.Packet{pk}
+	}
+	return tr.ToLatest(pk, conn)
+}
+
+// ConvertFromLatest converts a packet of the latest protocol to packets of the protocol of the Translator
+// using the FromLatest function of its PacketTranslation. Packets that still carry the ID of the latest
+// protocol after conversion are written with the LegacyID of the PacketTranslation.
+func (t *Translator) ConvertFromLatest(pk packet.Packet, conn *Conn) []packet.Packet {
+	tr, ok := t.latest[pk.ID()]
+	if !ok {
+		return []packet.Packet{pk}
+	}
+	if tr.Removed {
+		return nil
+	}
+	pks := []packet.Packet{pk}
+	if tr.FromLatest != nil {
+		pks = tr.FromLatest(pk, conn)
+	}
+	if legacyID := tr.legacyID(); legacyID != tr.ID {
+		for i, converted := range pks {
+			if converted.ID() == tr.ID {
+				pks[i] = &remappedPacket{Packet: converted, id: legacyID}
+			}
+		}
+	}
+	return pks
+}
+
+// legacyID returns the ID of the packet in the older protocol.
+func (tr PacketTranslation) legacyID() uint32 {
+	if tr.LegacyID == 0 {
+		return tr.ID
+	}
+	return tr.LegacyID
+}
+
+// remappedPacket wraps a packet of the latest protocol that has a different ID in the protocol of a
+// Translator.
+type remappedPacket struct {
+	packet.Packet
+	id uint32
+}
+
+// ID returns the ID of the packet in the protocol of the Translator.
+func (pk *remappedPacket) ID() uint32 {
+	return pk.id
+}
@@ -0,0 +1,176 @@
+// Package biomes implements the biome definitions sent to the client in the BiomeDefinitionList packet. The
+// client crashes if not all vanilla biomes are defined, so the vanilla definitions are embedded in the
+// package. Custom biomes may be added, and vanilla biomes overridden, by registering them to a Registry.
+package biomes
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Definition is the definition of a single biome.
+type Definition struct {
+	// Name is the name of the biome, such as 'plains'.
+	Name string
+	// Temperature is the temperature of the biome, which affects the colour of grass and foliage and whether
+	// it snows or rains in the biome.
+	Temperature float32
+	// Downfall is the amount of rain in the biome, which affects the colour of grass and foliage.
+	Downfall float32
+	// Extra holds any additional fields of the definition, such as those of custom biomes. It may be nil.
+	Extra map[string]any
+}
+
+//go:embed biome_definitions.nbt
+var vanillaData []byte
+
+// vanilla holds the definitions of all vanilla biomes, ordered by name.
+var vanilla []Definition
+
+func init() {
+	var m map[string]map[string]any
+	if err := nbt.Unmarshal(vanillaData, &m); err != nil {
+		panic(fmt.Errorf("decode vanilla biome definitions: %w", err))
+	}
+	for name, data := range m {
+		vanilla = append(vanilla, decodeDefinition(name, data))
+	}
+	sortDefinitions(vanilla)
+}
+
+// Vanilla returns the definitions of all vanilla biomes of the current protocol version, ordered by name.
+func Vanilla() []Definition {
+	return cloneDefinitions(vanilla)
+}
+
+// Registry holds the definitions of biomes sent to the client. A Registry may be set to
+// ListenConfig.BiomeDefinitions, so that each Listener may send its own set of biomes. A Registry is safe for
+// concurrent use, and definitions may be registered at any time: Connections that spawn afterwards are sent
+// the new definitions.
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[string]Definition
+	// encoded holds the encoded definitions returned by Packet. It is nil if the definitions were changed
+	// since they were last encoded.
+	encoded []byte
+}
+
+// NewRegistry returns a Registry holding the definitions of all vanilla biomes.
+func NewRegistry() *Registry {
+	r := &Registry{defs: make(map[string]Definition, len(vanilla))}
+	for _, d := range Vanilla() {
+		r.defs[d.Name] = d
+	}
+	return r
+}
+
+// Register registers the Definition passed, so that it is included in the packet returned by Packet. A
+// Definition with the same name, including that of a vanilla biome, is replaced.
+func (r *Registry) Register(d Definition) {
+	d.Extra = maps.Clone(d.Extra)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defs[d.Name] = d
+	r.encoded = nil
+}
+
+// Lookup returns the Definition of the biome with the name passed. If no such biome is registered, false is
+// returned.
+func (r *Registry) Lookup(name string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.defs[name]
+	d.Extra = maps.Clone(d.Extra)
+	return d, ok
+}
+
+// Definitions returns the definitions of all registered biomes, including the vanilla biomes, ordered by
+// name.
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Definition, 0, len(r.defs))
+	for _, d := range r.defs {
+		list = append(list, d)
+	}
+	sortDefinitions(list)
+	return cloneDefinitions(list)
+}
+
+// Packet returns a BiomeDefinitionList packet holding the definitions of all registered biomes. The
+// definitions are only encoded again if a biome was registered since the last call. The serialised
+// definitions of the packet are shared between calls and must not be modified.
+func (r *Registry) Packet() *packet.BiomeDefinitionList {
+	r.mu.RLock()
+	encoded := r.encoded
+	r.mu.RUnlock()
+	if encoded == nil {
+		r.mu.Lock()
+		if r.encoded == nil {
+			list := make([]Definition, 0, len(r.defs))
+			for _, d := range r.defs {
+				list = append(list, d)
+			}
+			r.encoded, _ = nbt.MarshalEncoding(Encode(list), nbt.NetworkLittleEndian)
+		}
+		encoded = r.encoded
+		r.mu.Unlock()
+	}
+	return &packet.BiomeDefinitionList{SerialisedBiomeDefinitions: encoded}
+}
+
+// Encode encodes the definitions passed to the map that is serialised in the BiomeDefinitionList packet. The
+// map returned may be set to ListenConfig.Biomes.
+func Encode(list []Definition) map[string]any {
+	m := make(map[string]any, len(list))
+	for _, d := range list {
+		data := make(map[string]any, len(d.Extra)+2)
+		maps.Copy(data, d.Extra)
+		data["temperature"] = d.Temperature
+		data["downfall"] = d.Downfall
+		m[d.Name] = data
+	}
+	return m
+}
+
+// cloneDefinitions returns a copy of the definitions passed, including their Extra maps.
+func cloneDefinitions(list []Definition) []Definition {
+	list = slices.Clone(list)
+	for i, d := range list {
+		list[i].Extra = maps.Clone(d.Extra)
+	}
+	return list
+}
+
+// sortDefinitions sorts the definitions passed by name.
+func sortDefinitions(list []Definition) {
+	slices.SortFunc(list, func(a, b Definition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// decodeDefinition decodes the Definition with the name passed from its NBT representation.
+func decodeDefinition(name string, data map[string]any) Definition {
+	d := Definition{Name: name}
+	for k, v := range data {
+		switch f, _ := v.(float32); k {
+		case "temperature":
+			d.Temperature = f
+		case "downfall":
+			d.Downfall = f
+		default:
+			if d.Extra == nil {
+				d.Extra = map[string]any{}
+			}
+			d.Extra[k] = v
+		}
+	}
+	return d
+}
@@ -0,0 +1,35 @@
+package biomes
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"testing"
+)
+
+// TestRegistry checks if a Registry holds the vanilla biomes, if biomes registered after Packet was called
+// are included in the next packet, and if registries do not share their definitions.
+func TestRegistry(t *testing.T) {
+	r, other := NewRegistry(), NewRegistry()
+	if len(r.Definitions()) != len(Vanilla()) || len(Vanilla()) == 0 {
+		t.Fatalf("expected registry to hold the %v vanilla biomes, got %v", len(Vanilla()), len(r.Definitions()))
+	}
+	r.Packet()
+	r.Register(Definition{Name: "custom:biome", Temperature: 0.5, Extra: map[string]any{"rain": uint8(1)}})
+	if _, ok := r.Lookup("custom:biome"); !ok {
+		t.Fatalf("expected registered biome to be found")
+	}
+	if _, ok := other.Lookup("custom:biome"); ok {
+		t.Fatalf("expected biome registered to one registry not to be found in another")
+	}
+	for _, test := range []struct {
+		r        *Registry
+		expected bool
+	}{{r: r, expected: true}, {r: other}} {
+		var m map[string]any
+		if err := nbt.Unmarshal(test.r.Packet().SerialisedBiomeDefinitions, &m); err != nil {
+			t.Fatalf("decode biomes: %v", err)
+		}
+		if _, ok := m["custom:biome"]; ok != test.expected {
+			t.Fatalf("expected custom biome in packet: %v, got %v", test.expected, ok)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Palette holds all block states of a single protocol version, indexed by their runtime ID. A Palette may be
+// used concurrently by multiple goroutines.
+type Palette struct {
+	states []State
+	ids    map[string]uint32
+	hashes map[uint32]uint32
+}
+
+// NewPalette creates a Palette from the block states passed. The states are ordered the way the client
+// orders them: sorted by the FNV-1 hash of their block name, with states of the same block kept in the order
+// in which they were passed. The runtime ID of a state is its index after sorting.
+func NewPalette(states []State) *Palette {
+	sorted := slices.Clone(states)
+	nameHashes := make(map[string]uint64)
+	for _, s := range sorted {
+		if _, ok := nameHashes[s.Name]; !ok {
+			h := fnv.New64()
+			_, _ = h.Write([]byte(s.Name))
+			nameHashes[s.Name] = h.Sum64()
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b State) int {
+		ha, hb := nameHashes[a.Name], nameHashes[b.Name]
+		switch {
+		case ha < hb:
+			return -1
+		case ha > hb:
+			return 1
+		}
+		return 0
+	})
+
+	p := &Palette{
+		states: sorted,
+		ids:    make(map[string]uint32, len(sorted)),
+		hashes: make(map[uint32]uint32, len(sorted)),
+	}
+	for i, s := range sorted {
+		p.ids[string(s.encode())] = uint32(i)
+		p.hashes[s.Hash()] = uint32(i)
+	}
+	return p
+}
+
+// ReadPalette reads a Palette from the reader passed. The data must be a sequence of block states encoded
+// as network little endian NBT compounds, the format of the canonical_block_states.nbt file that accompanies
+// every release of the game.
+func ReadPalette(r io.Reader) (*Palette, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read palette: %w", err)
+	}
+	buf := bytes.NewBuffer(data)
+	dec := nbt.NewDecoder(buf)
+	var states []State
+	for buf.Len() > 0 {
+		var s State
+		if err := dec.Decode(&s); err != nil {
+			return nil, fmt.Errorf("read block state %v: %w", len(states), err)
+		}
+		states = append(states, s)
+	}
+	return NewPalette(states), nil
+}
+
+// Len returns the amount of block states in the Palette.
+func (p *Palette) Len() int {
+	return len(p.states)
+}
+
+// States returns all block states in the Palette, ordered by runtime ID.
+func (p *Palette) States() []State {
+	return slices.Clone(p.states)
+}
+
+// State returns the block state with the runtime ID passed. If no state with this runtime ID exists, false
+// is returned.
+func (p *Palette) State(rid uint32) (State, bool) {
+	if rid >= uint32(len(p.states)) {
+		return State{}, false
+	}
+	return p.states[rid], true
+}
+
+// RuntimeID returns the runtime ID of the block state with the name and properties passed. If no such state
+// exists in the Palette, false is returned.
+func (p *Palette) RuntimeID(name string, properties map[string]any) (uint32, bool) {
+	rid, ok := p.ids[string(State{Name: name, Properties: properties}.encode())]
+	return rid, ok
+}
+
+// HashToRuntimeID returns the runtime ID of the block state with the network hash passed. If no state in the
+// Palette has this hash, false is returned.
+func (p *Palette) HashToRuntimeID(hash uint32) (uint32, bool) {
+	rid, ok := p.hashes[hash]
+	return rid, ok
+}
+
+// RuntimeIDToHash returns the network hash of the block state with the runtime ID passed. If no state with
+// this runtime ID exists, false is returned.
+func (p *Palette) RuntimeIDToHash(rid uint32) (uint32, bool) {
+	s, ok := p.State(rid)
+	if !ok {
+		return 0, false
+	}
+	return s.Hash(), true
+}
+
+// NetworkID returns the ID that the block state with the runtime ID passed is identified by over network. If
+// hashed is true, which is the case if StartGame has UseBlockNetworkIDHashes set, this is the hash of the
+// state. Otherwise, it is the runtime ID itself. If no state with the runtime ID exists, false is returned.
+func (p *Palette) NetworkID(rid uint32, hashed bool) (uint32, bool) {
+	if !hashed {
+		return rid, rid < uint32(len(p.states))
+	}
+	return p.RuntimeIDToHash(rid)
+}
+
+// FromNetworkID returns the runtime ID of the block state identified over network by the ID passed. It is
+// the inverse of NetworkID. If no state with the ID exists, false is returned.
+func (p *Palette) FromNetworkID(id uint32, hashed bool) (uint32, bool) {
+	if !hashed {
+		return id, id < uint32(len(p.states))
+	}
+	return p.HashToRuntimeID(id)
+}
+
+var (
+	palettesMu sync.RWMutex
+	palettes   = map[int32]*Palette{}
+)
+
+// Register registers the Palette passed for the protocol version passed, so that it may be obtained using
+// ForProtocol. A Palette previously registered for the same protocol version is replaced.
+func Register(protocolID int32, p *Palette) {
+	palettesMu.Lock()
+	defer palettesMu.Unlock()
+	palettes[protocolID] = p
+}
+
+// ForProtocol returns the Palette registered for the protocol version passed. If no Palette was registered
+// for it, false is returned.
+func ForProtocol(protocolID int32) (*Palette, bool) {
+	palettesMu.RLock()
+	defer palettesMu.RUnlock()
+	p, ok := palettes[protocolID]
+	return p, ok
+}
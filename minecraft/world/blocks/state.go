@@ -0,0 +1,94 @@
+// Package blocks implements the block state palettes used by Minecraft to refer to blocks over network. Every
+// block state, a block name combined with a set of properties, has a runtime ID that is its index in the
+// palette of the protocol version used, and a hash that is stable across protocol versions.
+// These IDs are found in packets such as UpdateBlock and in the sub chunks sent in LevelChunk packets.
+//
+// The package does not embed the palette of any protocol version. The canonical_block_states.nbt file of a
+// release must be read using ReadPalette and registered using Register before ForProtocol returns a Palette
+// for that version.
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// State is a single block state: the name of a block, such as 'minecraft:stone', combined with the values of
+// its properties. The values of properties are of the type uint8, int32 or string.
+type State struct {
+	// Name is the name of the block, including its namespace.
+	Name string `nbt:"name"`
+	// Properties holds the properties of the block state, such as 'facing_direction'. A bool property is
+	// represented as a uint8 that is either 0 or 1.
+	Properties map[string]any `nbt:"states"`
+	// Version is the block state version that the State was created for.
+	Version int32 `nbt:"version"`
+}
+
+// unknownHash is the hash of the 'minecraft:unknown' block. The client uses a fixed value for this block
+// rather than the hash of its state.
+const unknownHash = 0xfffffffe
+
+// Hash returns the network hash of the block state. It is the FNV-1a hash of the little endian NBT encoding
+// of the name and properties of the state, with properties ordered by name. The client expects these hashes
+// in place of runtime IDs if StartGame has UseBlockNetworkIDHashes set to true.
+func (s State) Hash() uint32 {
+	if s.Name == "minecraft:unknown" {
+		return unknownHash
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(s.encode())
+	return h.Sum32()
+}
+
+// Hash returns the network hash of the block state with the name and properties passed. It is equal to the
+// Hash of a State with the same name and properties.
+func Hash(name string, properties map[string]any) uint32 {
+	return State{Name: name, Properties: properties}.Hash()
+}
+
+// encode returns the little endian NBT encoding of the name and properties of the state, with the
+// properties ordered by name. The Version of the state is not included. Properties with values of an
+// unsupported type are encoded as strings.
+func (s State) encode() []byte {
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	// The nbt package encodes maps in random order, so the properties are encoded as a struct with a field
+	// for every property instead, which it encodes in the order of its fields.
+	fields := make([]reflect.StructField, len(keys))
+	values := make([]reflect.Value, len(keys))
+	for i, k := range keys {
+		v := s.Properties[k]
+		switch v.(type) {
+		case uint8, bool, int32, string:
+		default:
+			v = fmt.Sprint(v)
+		}
+		values[i] = reflect.ValueOf(v)
+		fields[i] = reflect.StructField{
+			Name: "P" + strconv.Itoa(i),
+			Type: values[i].Type(),
+			Tag:  reflect.StructTag("nbt:" + strconv.Quote(k)),
+		}
+	}
+	properties := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, v := range values {
+		properties.Field(i).Set(v)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+	_ = nbt.NewEncoderWithEncoding(buf, nbt.LittleEndian).Encode(struct {
+		Name       string `nbt:"name"`
+		Properties any    `nbt:"states"`
+	}{Name: s.Name, Properties: properties.Interface()})
+	return buf.Bytes()
+}
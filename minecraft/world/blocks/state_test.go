@@ -0,0 +1,28 @@
+package blocks
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"reflect"
+	"testing"
+)
+
+// TestStateEncode checks if the encoding of a State used for its Hash is valid little endian NBT holding the
+// name and properties of the state, and if the hash does not depend on the order of its properties.
+func TestStateEncode(t *testing.T) {
+	s := State{Name: "minecraft:test", Properties: map[string]any{"b": uint8(1), "a": int32(-5), "d": "north"}}
+	var m map[string]any
+	if err := nbt.UnmarshalEncoding(s.encode(), &m, nbt.LittleEndian); err != nil {
+		t.Fatalf("decode state: %v", err)
+	}
+	if expected := map[string]any{"name": s.Name, "states": s.Properties}; !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	for i := 0; i < 10; i++ {
+		if h := Hash(s.Name, map[string]any{"d": "north", "a": int32(-5), "b": uint8(1)}); h != s.Hash() {
+			t.Fatalf("expected hash %x, got %x", s.Hash(), h)
+		}
+	}
+	if h := (State{Name: "minecraft:unknown"}).Hash(); h != unknownHash {
+		t.Fatalf("expected hash %x for unknown block, got %x", unknownHash, h)
+	}
+}
@@ -0,0 +1,265 @@
+// Package world implements a cache of the world that a server sends to a client. A Cache is attached to a
+// Conn obtained using a minecraft.Dialer and keeps track of the chunks and block updates that the server
+// sends, so that bots can query the blocks around them, for example to find a path or to render a map.
+// Subpackages implement the block palettes, item tables and chunk encoding that the Cache is built on.
+package world
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/world/blocks"
+	"github.com/sandertv/gophertunnel/minecraft/world/chunk"
+	"net"
+	"sync"
+)
+
+// Cache is an in-memory store of the chunks of the world that a client is in. Chunks are added to the Cache
+// as the server sends them and are updated with the blocks changed afterward. The Cache holds the IDs of
+// blocks as they are sent over network: these are block hashes if the server set UseBlockNetworkIDHashes in
+// the StartGame packet and runtime IDs otherwise.
+// A Cache may be used concurrently by multiple goroutines.
+type Cache struct {
+	air uint32
+
+	mu        sync.RWMutex
+	dimension int32
+	ranges    map[int32]chunk.Range
+	chunks    map[protocol.ChunkPos]*chunk.Chunk
+	errs      []error
+}
+
+// NewCache creates an empty Cache for a world in the dimension passed. The network ID of air must be passed,
+// which is the ID that blocks missing from sub chunks sent by the server are assumed to have.
+func NewCache(air uint32, dimension int32) *Cache {
+	return &Cache{air: air, dimension: dimension, ranges: make(map[int32]chunk.Range), chunks: make(map[protocol.ChunkPos]*chunk.Chunk)}
+}
+
+// Attach creates a Cache for the Conn passed, which must have been obtained using a minecraft.Dialer, and
+// starts adding the chunks and block updates that the server sends to it. The ID of air is found using the
+// block palette registered in the blocks package for the protocol of the Conn, unless the server uses
+// block hashes. The packets read by the Conn are still returned by Conn.ReadPacket as usual.
+// The function returned stops updating the Cache.
+func Attach(conn *minecraft.Conn) (*Cache, func(), error) {
+	data := conn.GameData()
+	air := blocks.Hash("minecraft:air", nil)
+	if !data.UseBlockNetworkIDHashes {
+		palette, ok := blocks.ForProtocol(conn.Protocol().ID())
+		if !ok {
+			return nil, nil, fmt.Errorf("attach world cache: no block palette registered for protocol %v", conn.Protocol().ID())
+		}
+		if air, ok = palette.RuntimeID("minecraft:air", nil); !ok {
+			return nil, nil, fmt.Errorf("attach world cache: no air in block palette of protocol %v", conn.Protocol().ID())
+		}
+	}
+	c := NewCache(air, data.Dimension)
+
+	pool, remote := conn.Protocol().Packets(false), conn.RemoteAddr().String()
+	remove := conn.AddPacketObserver(func(header packet.Header, payload []byte, src, _ net.Addr) {
+		if src.String() != remote {
+			return
+		}
+		pkFunc, ok := pool[header.PacketID]
+		if !ok {
+			return
+		}
+		if err := c.decode(conn, pkFunc(), payload); err != nil {
+			c.mu.Lock()
+			c.errs = append(c.errs, err)
+			c.mu.Unlock()
+		}
+	}, packet.IDLevelChunk, packet.IDSubChunk, packet.IDUpdateBlock, packet.IDUpdateSubChunkBlocks, packet.IDChangeDimension, packet.IDDimensionData)
+	return c, remove, nil
+}
+
+// decode decodes the payload passed into pk using the Protocol of the Conn and handles the packets that it
+// converts to.
+func (c *Cache) decode(conn *minecraft.Conn, pk packet.Packet, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decode packet %T: %v", pk, r)
+		}
+	}()
+	pk.Marshal(conn.Protocol().NewReader(bytes.NewReader(payload), 0, false))
+	for _, converted := range conn.Protocol().ConvertToLatest(pk, conn) {
+		if err := c.Handle(converted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Errors returns the errors that occurred while handling packets read by a Conn that the Cache was attached
+// to, and clears them.
+func (c *Cache) Errors() []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	errs := c.errs
+	c.errs = nil
+	return errs
+}
+
+// Handle updates the Cache with the packet passed if it is a LevelChunk, SubChunk, UpdateBlock,
+// UpdateSubChunkBlocks, ChangeDimension or DimensionData packet. Other packets are ignored. Handle only needs to be called
+// for Caches that were not created using Attach.
+// Chunks sent with the client blob cache enabled are not supported and are ignored.
+func (c *Cache) Handle(pk packet.Packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch pk := pk.(type) {
+	case *packet.LevelChunk:
+		return c.handleLevelChunk(pk)
+	case *packet.SubChunk:
+		return c.handleSubChunk(pk)
+	case *packet.UpdateBlock:
+		c.setBlock(pk.Position, uint8(pk.Layer), pk.NewBlockRuntimeID)
+	case *packet.UpdateSubChunkBlocks:
+		for _, entry := range pk.Blocks {
+			c.setBlock(entry.BlockPos, 0, entry.BlockRuntimeID)
+		}
+		for _, entry := range pk.Extra {
+			c.setBlock(entry.BlockPos, 1, entry.BlockRuntimeID)
+		}
+	case *packet.ChangeDimension:
+		c.dimension = pk.Dimension
+		clear(c.chunks)
+	case *packet.DimensionData:
+		for _, def := range pk.Definitions {
+			if d, ok := DimensionByName(def.Name); ok {
+				c.ranges[d.ID] = chunk.Range{int(def.Range[0]), int(def.Range[1]) - 1}
+			}
+		}
+	}
+	return nil
+}
+
+// handleLevelChunk adds the chunk held by a LevelChunk packet to the Cache.
+func (c *Cache) handleLevelChunk(pk *packet.LevelChunk) error {
+	if pk.CacheEnabled || pk.Dimension != c.dimension {
+		return nil
+	}
+	r := c.dimensionRange()
+	switch pk.SubChunkCount {
+	case protocol.SubChunkRequestModeLimited, protocol.SubChunkRequestModeLimitless:
+		// The sub chunks will be sent separately in SubChunk packets.
+		c.chunks[pk.Position] = chunk.New(c.air, 0, r)
+		return nil
+	}
+	ch, err := chunk.Decode(c.air, pk.RawPayload, pk.SubChunkCount, r)
+	if err != nil {
+		return fmt.Errorf("handle chunk %v: %w", pk.Position, err)
+	}
+	c.chunks[pk.Position] = ch
+	return nil
+}
+
+// handleSubChunk adds the sub chunks held by a SubChunk packet to the chunks in the Cache.
+func (c *Cache) handleSubChunk(pk *packet.SubChunk) error {
+	if pk.CacheEnabled || pk.Dimension != c.dimension {
+		return nil
+	}
+	r := c.dimensionRange()
+	for _, entry := range pk.SubChunkEntries {
+		pos := protocol.ChunkPos{pk.Position.X() + int32(entry.Offset[0]), pk.Position.Z() + int32(entry.Offset[2])}
+		y := int(pk.Position.Y()+int32(entry.Offset[1])) << 4
+
+		ch, ok := c.chunks[pos]
+		if !ok {
+			ch = chunk.New(c.air, 0, r)
+			c.chunks[pos] = ch
+		}
+		switch entry.Result {
+		case protocol.SubChunkResultSuccess:
+			sub, err := chunk.DecodeSubChunk(c.air, entry.RawPayload)
+			if err != nil {
+				return fmt.Errorf("handle sub chunk %v at y=%v: %w", pos, y, err)
+			}
+			ch.SetSubChunk(y, sub)
+		case protocol.SubChunkResultSuccessAllAir:
+			ch.SetSubChunk(y, chunk.NewSubChunk(c.air))
+		}
+	}
+	return nil
+}
+
+// setBlock sets the block at the position passed if the chunk that it is in is present in the Cache.
+func (c *Cache) setBlock(pos protocol.BlockPos, layer uint8, id uint32) {
+	if ch, ok := c.chunks[chunkPos(pos)]; ok {
+		ch.SetBlock(uint8(pos.X()), int(pos.Y()), uint8(pos.Z()), layer, id)
+	}
+}
+
+// Dimension returns the dimension that the chunks in the Cache are in.
+func (c *Cache) Dimension() int32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dimension
+}
+
+// BlockAt returns the network ID of the block at the position passed. If the chunk that the position is in
+// is not present in the Cache, false is returned.
+func (c *Cache) BlockAt(pos protocol.BlockPos) (uint32, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ch, ok := c.chunks[chunkPos(pos)]
+	if !ok {
+		return 0, false
+	}
+	return ch.Block(uint8(pos.X()), int(pos.Y()), uint8(pos.Z()), 0), true
+}
+
+// HighestBlock returns the y value of the highest block at the x and z passed that is not air. If the
+// column holds only air, the lowest y value of the dimension minus one is returned. If the chunk that the
+// column is in is not present in the Cache, false is returned.
+func (c *Cache) HighestBlock(x, z int32) (int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ch, ok := c.chunks[protocol.ChunkPos{x >> 4, z >> 4}]
+	if !ok {
+		return 0, false
+	}
+	return ch.HighestBlock(uint8(x&0xf), uint8(z&0xf)), true
+}
+
+// Chunk returns the chunk at the position passed. If no chunk is present at this position, false is
+// returned. The chunk returned must not be modified.
+func (c *Cache) Chunk(pos protocol.ChunkPos) (*chunk.Chunk, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ch, ok := c.chunks[pos]
+	return ch, ok
+}
+
+// Len returns the amount of chunks in the Cache.
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.chunks)
+}
+
+// Forget removes the chunk at the position passed from the Cache, for example once it is out of the view
+// distance of the client.
+func (c *Cache) Forget(pos protocol.ChunkPos) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.chunks, pos)
+}
+
+// chunkPos returns the position of the chunk that the block position passed is in.
+func chunkPos(pos protocol.BlockPos) protocol.ChunkPos {
+	return protocol.ChunkPos{pos.X() >> 4, pos.Z() >> 4}
+}
+
+// dimensionRange returns the chunk.Range of the dimension that the Cache is in. Ranges sent by the server in
+// a DimensionData packet take precedence over those of the vanilla dimensions.
+func (c *Cache) dimensionRange() chunk.Range {
+	if r, ok := c.ranges[c.dimension]; ok {
+		return r
+	}
+	if d, ok := DimensionByID(c.dimension); ok {
+		return d.Range
+	}
+	return chunk.OverworldRange
+}
@@ -0,0 +1,92 @@
+package world_test
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/world"
+	"github.com/sandertv/gophertunnel/minecraft/world/chunk"
+	"testing"
+)
+
+const (
+	testAir   = 1
+	testStone = 2
+)
+
+// TestCacheLevelChunk checks if the blocks of chunks sent in LevelChunk packets, and of blocks updated
+// afterwards, are returned by the Cache.
+func TestCacheLevelChunk(t *testing.T) {
+	c := world.NewCache(testAir, packet.DimensionOverworld)
+
+	ch := chunk.New(testAir, 0, chunk.OverworldRange)
+	ch.SetBlock(15, 10, 1, 0, testStone)
+	if err := c.Handle(chunk.LevelChunk(ch, protocol.ChunkPos{-1, 2}, packet.DimensionOverworld)); err != nil {
+		t.Fatalf("handle level chunk: %v", err)
+	}
+	// Chunks of other dimensions are ignored.
+	if err := c.Handle(chunk.LevelChunk(ch, protocol.ChunkPos{0, 0}, packet.DimensionNether)); err != nil {
+		t.Fatalf("handle level chunk: %v", err)
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected 1 chunk in cache, got %v", c.Len())
+	}
+
+	if id, ok := c.BlockAt(protocol.BlockPos{-1, 10, 33}); !ok || id != testStone {
+		t.Fatalf("expected stone, got %v (%v)", id, ok)
+	}
+	if y, ok := c.HighestBlock(-1, 33); !ok || y != 10 {
+		t.Fatalf("expected highest block at y=10, got %v (%v)", y, ok)
+	}
+	if _, ok := c.BlockAt(protocol.BlockPos{0, 10, 33}); ok {
+		t.Fatalf("expected no block outside of cached chunks")
+	}
+
+	if err := c.Handle(&packet.UpdateBlock{Position: protocol.BlockPos{-1, 10, 33}, NewBlockRuntimeID: testAir}); err != nil {
+		t.Fatalf("handle update block: %v", err)
+	}
+	if id, _ := c.BlockAt(protocol.BlockPos{-1, 10, 33}); id != testAir {
+		t.Fatalf("expected block to be updated to air, got %v", id)
+	}
+
+	if err := c.Handle(&packet.ChangeDimension{Dimension: packet.DimensionNether}); err != nil {
+		t.Fatalf("handle change dimension: %v", err)
+	}
+	if c.Len() != 0 || c.Dimension() != packet.DimensionNether {
+		t.Fatalf("expected cache to be cleared after changing dimension, got %v chunks in dimension %v", c.Len(), c.Dimension())
+	}
+}
+
+// TestCacheSubChunk checks if the sub chunks sent in SubChunk packets are added to the chunks sent with
+// sub chunk requests enabled.
+func TestCacheSubChunk(t *testing.T) {
+	c := world.NewCache(testAir, packet.DimensionOverworld)
+	if err := c.Handle(&packet.LevelChunk{Position: protocol.ChunkPos{0, 0}, SubChunkCount: protocol.SubChunkRequestModeLimitless}); err != nil {
+		t.Fatalf("handle level chunk: %v", err)
+	}
+
+	// The payload of a chunk of which only the lowest sub chunk holds blocks starts with that sub chunk.
+	ch := chunk.New(testAir, 0, chunk.OverworldRange)
+	ch.SetBlock(3, -64, 4, 0, testStone)
+	payload, _ := chunk.Encode(ch)
+
+	err := c.Handle(&packet.SubChunk{
+		Dimension: packet.DimensionOverworld,
+		Position:  protocol.SubChunkPos{0, -4, 0},
+		SubChunkEntries: []protocol.SubChunkEntry{
+			{Offset: protocol.SubChunkOffset{0, 0, 0}, Result: protocol.SubChunkResultSuccess, RawPayload: payload},
+			{Offset: protocol.SubChunkOffset{0, 1, 0}, Result: protocol.SubChunkResultSuccessAllAir},
+			{Offset: protocol.SubChunkOffset{1, 0, 0}, Result: protocol.SubChunkResultSuccess, RawPayload: payload},
+		},
+	})
+	if err != nil {
+		t.Fatalf("handle sub chunk: %v", err)
+	}
+	for _, pos := range []protocol.BlockPos{{3, -64, 4}, {19, -64, 4}} {
+		if id, ok := c.BlockAt(pos); !ok || id != testStone {
+			t.Fatalf("expected stone at %v, got %v (%v)", pos, id, ok)
+		}
+	}
+	if id, _ := c.BlockAt(protocol.BlockPos{3, -48, 4}); id != testAir {
+		t.Fatalf("expected air, got %v", id)
+	}
+}
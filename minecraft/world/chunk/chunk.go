@@ -0,0 +1,217 @@
+// Package chunk implements the chunks of a Minecraft world as they are sent over network in the LevelChunk
+// packet. A Chunk holds the block runtime IDs and biome IDs of a 16 block wide column of a world, and may be
+// encoded to or decoded from the payload of a LevelChunk packet.
+package chunk
+
+// Range is the vertical range of a world, holding the lowest and highest y value in which blocks may be
+// placed.
+type Range [2]int
+
+var (
+	// OverworldRange is the Range of the overworld, which ranges from y=-64 to y=319.
+	OverworldRange = Range{-64, 319}
+	// NetherRange is the Range of the nether, which ranges from y=0 to y=127.
+	NetherRange = Range{0, 127}
+	// EndRange is the Range of the end, which ranges from y=0 to y=255.
+	EndRange = Range{0, 255}
+)
+
+// Min returns the lowest y value of the Range.
+func (r Range) Min() int {
+	return r[0]
+}
+
+// Max returns the highest y value of the Range.
+func (r Range) Max() int {
+	return r[1]
+}
+
+// subChunks returns the amount of sub chunks needed to cover the Range.
+func (r Range) subChunks() int {
+	return (r[1] - r[0] + 1) >> 4
+}
+
+// SubChunk is a 16x16x16 section of a Chunk. It holds one or more layers of blocks, where the first layer
+// holds the regular blocks and the second layer generally holds water that a block is waterlogged with.
+type SubChunk struct {
+	air    uint32
+	layers []*PalettedStorage
+}
+
+// NewSubChunk returns a SubChunk filled with air. The runtime ID of air must be passed.
+func NewSubChunk(air uint32) *SubChunk {
+	return &SubChunk{air: air}
+}
+
+// Empty checks if the SubChunk holds only air.
+func (s *SubChunk) Empty() bool {
+	for _, l := range s.layers {
+		if v, ok := l.Uniform(); !ok || v != s.air {
+			return false
+		}
+	}
+	return true
+}
+
+// Layers returns the layers of the SubChunk. The slice returned must not be modified.
+func (s *SubChunk) Layers() []*PalettedStorage {
+	return s.layers
+}
+
+// Layer returns the layer of the SubChunk with the index passed, creating it and any layers before it if
+// they do not yet exist.
+func (s *SubChunk) Layer(layer uint8) *PalettedStorage {
+	for uint8(len(s.layers)) <= layer {
+		s.layers = append(s.layers, NewPalettedStorage(s.air))
+	}
+	return s.layers[layer]
+}
+
+// Block returns the runtime ID of the block at the x, y and z position in the SubChunk on the layer passed.
+func (s *SubChunk) Block(x, y, z uint8, layer uint8) uint32 {
+	if uint8(len(s.layers)) <= layer {
+		return s.air
+	}
+	return s.layers[layer].At(x, y, z)
+}
+
+// SetBlock sets the runtime ID of the block at the x, y and z position in the SubChunk on the layer passed.
+func (s *SubChunk) SetBlock(x, y, z uint8, layer uint8, rid uint32) {
+	if uint8(len(s.layers)) <= layer && rid == s.air {
+		return
+	}
+	s.Layer(layer).Set(x, y, z, rid)
+}
+
+// Chunk is a 16 block wide column of a world, consisting of a SubChunk and a biome storage for every 16
+// blocks in the Range of the Chunk. Positions passed to methods of a Chunk are relative to the Chunk: x and
+// z are in the range 0-15, and y is in the Range of the Chunk.
+type Chunk struct {
+	air    uint32
+	r      Range
+	sub    []*SubChunk
+	biomes []*PalettedStorage
+}
+
+// New returns an empty Chunk with the Range passed. The runtime ID of air must be passed, and is used for
+// every block in the Chunk. The biome of every block in the Chunk is set to the biome ID passed.
+func New(air uint32, biome uint32, r Range) *Chunk {
+	n := r.subChunks()
+	c := &Chunk{air: air, r: r, sub: make([]*SubChunk, n), biomes: make([]*PalettedStorage, n)}
+	for i := range c.sub {
+		c.sub[i] = NewSubChunk(air)
+		c.biomes[i] = NewPalettedStorage(biome)
+	}
+	return c
+}
+
+// Range returns the Range of the Chunk.
+func (c *Chunk) Range() Range {
+	return c.r
+}
+
+// SubChunks returns the sub chunks of the Chunk, ordered from the bottom of the Chunk to the top. The slice
+// returned must not be modified.
+func (c *Chunk) SubChunks() []*SubChunk {
+	return c.sub
+}
+
+// SubChunk returns the SubChunk that holds the y value passed.
+func (c *Chunk) SubChunk(y int) *SubChunk {
+	return c.sub[c.subIndex(y)]
+}
+
+// SetSubChunk replaces the SubChunk that holds the y value passed. SetSubChunk has no effect if y is outside
+// the Range of the Chunk.
+func (c *Chunk) SetSubChunk(y int, s *SubChunk) {
+	if y < c.r[0] || y > c.r[1] {
+		return
+	}
+	c.sub[c.subIndex(y)] = s
+}
+
+// Block returns the runtime ID of the block at the position passed on the layer passed.
+func (c *Chunk) Block(x uint8, y int, z uint8, layer uint8) uint32 {
+	if y < c.r[0] || y > c.r[1] {
+		return c.air
+	}
+	return c.sub[c.subIndex(y)].Block(x, uint8(y&0xf), z, layer)
+}
+
+// SetBlock sets the runtime ID of the block at the position passed on the layer passed. SetBlock has no
+// effect if y is outside the Range of the Chunk.
+func (c *Chunk) SetBlock(x uint8, y int, z uint8, layer uint8, rid uint32) {
+	if y < c.r[0] || y > c.r[1] {
+		return
+	}
+	c.sub[c.subIndex(y)].SetBlock(x, uint8(y&0xf), z, layer, rid)
+}
+
+// Biome returns the biome ID at the position passed.
+func (c *Chunk) Biome(x uint8, y int, z uint8) uint32 {
+	if y < c.r[0] || y > c.r[1] {
+		return 0
+	}
+	return c.biomes[c.subIndex(y)].At(x, uint8(y&0xf), z)
+}
+
+// SetBiome sets the biome ID at the position passed. SetBiome has no effect if y is outside the Range of the
+// Chunk.
+func (c *Chunk) SetBiome(x uint8, y int, z uint8, biome uint32) {
+	if y < c.r[0] || y > c.r[1] {
+		return
+	}
+	c.biomes[c.subIndex(y)].Set(x, uint8(y&0xf), z, biome)
+}
+
+// MapBlocks replaces every block in the Chunk with the value returned by f for it. Only the palettes of the
+// sub chunks are changed, so f is called once for every distinct block in a layer. MapBlocks may be used to
+// convert a Chunk between runtime IDs and block hashes, for example using blocks.Palette.NetworkID.
+func (c *Chunk) MapBlocks(f func(rid uint32) uint32) {
+	for _, sub := range c.sub {
+		for _, l := range sub.layers {
+			for i, v := range l.palette {
+				l.palette[i] = f(v)
+			}
+		}
+	}
+	c.air = f(c.air)
+	for _, sub := range c.sub {
+		sub.air = c.air
+	}
+}
+
+// HeightMap returns the height map of the Chunk. It holds, for every column indexed by x<<4 | z, the y value
+// directly above the highest block in that column that is not air. Columns holding only air have the lowest
+// y value of the Range of the Chunk.
+func (c *Chunk) HeightMap() [256]int16 {
+	var m [256]int16
+	for x := uint8(0); x < 16; x++ {
+		for z := uint8(0); z < 16; z++ {
+			m[uint16(x)<<4|uint16(z)] = int16(c.HighestBlock(x, z) + 1)
+		}
+	}
+	return m
+}
+
+// HighestBlock returns the y value of the highest block in the column at the x and z passed that is not air.
+// If the column holds only air, the lowest y value of the Range of the Chunk minus one is returned.
+func (c *Chunk) HighestBlock(x, z uint8) int {
+	for i := len(c.sub) - 1; i >= 0; i-- {
+		sub := c.sub[i]
+		if sub.Empty() {
+			continue
+		}
+		for y := 15; y >= 0; y-- {
+			if sub.Block(x, uint8(y), z, 0) != c.air {
+				return c.r[0] + i<<4 + y
+			}
+		}
+	}
+	return c.r[0] - 1
+}
+
+// subIndex returns the index of the SubChunk that holds the y value passed.
+func (c *Chunk) subIndex(y int) int {
+	return (y - c.r[0]) >> 4
+}
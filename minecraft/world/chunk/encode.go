@@ -0,0 +1,225 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+const (
+	// subChunkVersion is the version of the sub chunk encoding written by Encode.
+	subChunkVersion = 9
+	// biomeCopyLast is the index size that, when found in the header of a biome storage, means that the
+	// biomes of the previous sub chunk are used.
+	biomeCopyLast = 0x7f
+)
+
+// Encode encodes the Chunk passed to the payload of a LevelChunk packet with the client blob cache disabled.
+// Sub chunks at the top of the Chunk that hold only air are left out. The amount of sub chunks that were
+// encoded is returned and should be set as the SubChunkCount of the LevelChunk packet.
+func Encode(c *Chunk) (payload []byte, subChunkCount uint32) {
+	count := len(c.sub)
+	for count > 0 && c.sub[count-1].Empty() {
+		count--
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	for i := 0; i < count; i++ {
+		encodeSubChunk(buf, c.sub[i], int8(i+c.r[0]>>4))
+	}
+	encodeBiomes(buf, c)
+	// Border block count.
+	buf.WriteByte(0)
+	return buf.Bytes(), uint32(count)
+}
+
+// LevelChunk returns a LevelChunk packet holding the Chunk passed at the position and in the dimension
+// passed.
+func LevelChunk(c *Chunk, pos protocol.ChunkPos, dimension int32) *packet.LevelChunk {
+	payload, count := Encode(c)
+	return &packet.LevelChunk{Position: pos, Dimension: dimension, SubChunkCount: count, RawPayload: payload}
+}
+
+// encodeSubChunk writes a SubChunk with the y index passed to buf.
+func encodeSubChunk(buf *bytes.Buffer, s *SubChunk, y int8) {
+	buf.Write([]byte{subChunkVersion, byte(len(s.layers)), byte(y)})
+	for _, l := range s.layers {
+		encodeStorage(buf, l)
+	}
+}
+
+// encodeBiomes writes the biome storages of every sub chunk in the Chunk to buf. Storages equal to that of
+// the sub chunk below are written as a reference to that storage.
+func encodeBiomes(buf *bytes.Buffer, c *Chunk) {
+	for i, b := range c.biomes {
+		if i > 0 && b.Equal(c.biomes[i-1]) {
+			buf.WriteByte(biomeCopyLast<<1 | 1)
+			continue
+		}
+		encodeStorage(buf, b)
+	}
+}
+
+// encodeStorage writes a PalettedStorage to buf using the network encoding.
+func encodeStorage(buf *bytes.Buffer, s *PalettedStorage) {
+	buf.WriteByte(s.bitsPerIndex<<1 | 1)
+	if s.bitsPerIndex == 0 {
+		writeVarint32(buf, int32(s.palette[0]))
+		return
+	}
+	_ = binary.Write(buf, binary.LittleEndian, s.words)
+	writeVarint32(buf, int32(len(s.palette)))
+	for _, v := range s.palette {
+		writeVarint32(buf, int32(v))
+	}
+}
+
+// Decode decodes the payload of a LevelChunk packet with the client blob cache disabled into a Chunk. The
+// runtime ID of air, the SubChunkCount of the LevelChunk packet and the Range of the dimension that the chunk
+// is in must be passed. Block entities following the biomes and border blocks are not decoded.
+func Decode(air uint32, payload []byte, subChunkCount uint32, r Range) (*Chunk, error) {
+	c := New(air, 0, r)
+	if int(subChunkCount) > len(c.sub) {
+		return nil, fmt.Errorf("decode chunk: sub chunk count %v exceeds %v sub chunks in range", subChunkCount, len(c.sub))
+	}
+	buf := bytes.NewBuffer(payload)
+	for i := 0; i < int(subChunkCount); i++ {
+		index, sub, err := decodeSubChunk(buf, air)
+		if err != nil {
+			return nil, fmt.Errorf("decode sub chunk %v: %w", i, err)
+		}
+		pos := i
+		if index != versionOneIndex {
+			if pos = int(index) - r[0]>>4; pos < 0 || pos >= len(c.sub) {
+				return nil, fmt.Errorf("decode sub chunk %v: y index %v out of range", i, index)
+			}
+		}
+		c.sub[pos] = sub
+	}
+	for i := range c.biomes {
+		if buf.Len() == 0 {
+			// Older versions of the game may not send biomes for every sub chunk.
+			break
+		}
+		b, err := decodeStorage(buf, true)
+		if err != nil {
+			return nil, fmt.Errorf("decode biomes of sub chunk %v: %w", i, err)
+		}
+		if b == nil {
+			if i == 0 {
+				return nil, fmt.Errorf("decode biomes of sub chunk %v: no biomes to copy", i)
+			}
+			b = c.biomes[i-1].clone()
+		}
+		c.biomes[i] = b
+	}
+	return c, nil
+}
+
+// DecodeSubChunk decodes a single sub chunk encoded using the network encoding, such as one found in a
+// SubChunk packet. The runtime ID of air must be passed.
+func DecodeSubChunk(air uint32, data []byte) (*SubChunk, error) {
+	_, sub, err := decodeSubChunk(bytes.NewBuffer(data), air)
+	if err != nil {
+		return nil, fmt.Errorf("decode sub chunk: %w", err)
+	}
+	return sub, nil
+}
+
+// versionOneIndex is returned by decodeSubChunk for sub chunks of versions that do not hold a y index.
+const versionOneIndex = -128
+
+// decodeSubChunk reads a SubChunk from buf, returning it along with its y index.
+func decodeSubChunk(buf *bytes.Buffer, air uint32) (int8, *SubChunk, error) {
+	ver, err := buf.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("read version: %w", err)
+	}
+	sub, index, layers := NewSubChunk(air), int8(versionOneIndex), byte(1)
+	switch ver {
+	case 1:
+	case 8, 9:
+		if layers, err = buf.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("read layer count: %w", err)
+		}
+		if ver == 9 {
+			y, err := buf.ReadByte()
+			if err != nil {
+				return 0, nil, fmt.Errorf("read y index: %w", err)
+			}
+			index = int8(y)
+		}
+	default:
+		return 0, nil, fmt.Errorf("unsupported sub chunk version %v", ver)
+	}
+	for i := byte(0); i < layers; i++ {
+		s, err := decodeStorage(buf, false)
+		if err != nil {
+			return 0, nil, fmt.Errorf("decode layer %v: %w", i, err)
+		}
+		sub.layers = append(sub.layers, s)
+	}
+	return index, sub, nil
+}
+
+// decodeStorage reads a PalettedStorage from buf using the network encoding. If biomes is true and the
+// storage refers to the storage of the previous sub chunk, nil is returned.
+func decodeStorage(buf *bytes.Buffer, biomes bool) (*PalettedStorage, error) {
+	header, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if header&1 != 1 {
+		return nil, fmt.Errorf("storage is not encoded with runtime IDs")
+	}
+	bits := header >> 1
+	if biomes && bits == biomeCopyLast {
+		return nil, nil
+	}
+	s := &PalettedStorage{bitsPerIndex: bits}
+	if bits == 0 {
+		v, err := binary.ReadVarint(buf)
+		if err != nil {
+			return nil, fmt.Errorf("read single value: %w", err)
+		}
+		s.palette = []uint32{uint32(v)}
+		return s, nil
+	}
+	if bitsNeeded(1<<bits) != bits {
+		return nil, fmt.Errorf("invalid index size %v", bits)
+	}
+	s.words = make([]uint32, wordCount(bits))
+	if err := binary.Read(buf, binary.LittleEndian, s.words); err != nil {
+		return nil, fmt.Errorf("read words: %w", err)
+	}
+	n, err := binary.ReadVarint(buf)
+	if err != nil {
+		return nil, fmt.Errorf("read palette size: %w", err)
+	}
+	if n <= 0 || n > 4096 {
+		return nil, fmt.Errorf("invalid palette size %v", n)
+	}
+	s.palette = make([]uint32, n)
+	for i := range s.palette {
+		v, err := binary.ReadVarint(buf)
+		if err != nil {
+			return nil, fmt.Errorf("read palette entry %v: %w", i, err)
+		}
+		s.palette[i] = uint32(v)
+	}
+	// Make sure every index in the storage points to a value in the palette.
+	for i := uint16(0); i < 4096; i++ {
+		if s.index(i) >= uint32(n) {
+			return nil, fmt.Errorf("index %v out of palette range", s.index(i))
+		}
+	}
+	return s, nil
+}
+
+// writeVarint32 writes a zigzag encoded varint32 to buf.
+func writeVarint32(buf *bytes.Buffer, x int32) {
+	var b [binary.MaxVarintLen32]byte
+	n := binary.PutVarint(b[:], int64(x))
+	buf.Write(b[:n])
+}
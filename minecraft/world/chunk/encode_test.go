@@ -0,0 +1,148 @@
+package chunk
+
+import (
+	"bytes"
+	"testing"
+)
+
+const (
+	testAir   = 1
+	testStone = 2
+	testWater = 3
+)
+
+// TestEncodeDecode checks if a Chunk encoded using Encode is decoded by Decode into a Chunk with the same
+// blocks and biomes.
+func TestEncodeDecode(t *testing.T) {
+	c := New(testAir, 5, OverworldRange)
+	c.SetBlock(0, -64, 0, 0, testStone)
+	c.SetBlock(15, 0, 7, 0, testStone)
+	c.SetBlock(3, 70, 12, 0, testStone)
+	c.SetBlock(3, 70, 12, 1, testWater)
+	// Use enough different values in one sub chunk for the storage to need more than a few bits per index.
+	for i := uint32(0); i < 40; i++ {
+		c.SetBlock(uint8(i%16), 100+int(i/16), 4, 0, 100+i)
+	}
+	c.SetBiome(8, 20, 8, 7)
+
+	payload, count := Encode(c)
+	if expected := uint32((102-OverworldRange.Min())>>4 + 1); count != expected {
+		t.Fatalf("expected %v sub chunks to be encoded, got %v", expected, count)
+	}
+	decoded, err := Decode(testAir, payload, count, OverworldRange)
+	if err != nil {
+		t.Fatalf("decode chunk: %v", err)
+	}
+	for y := OverworldRange.Min(); y <= OverworldRange.Max(); y++ {
+		for x := uint8(0); x < 16; x++ {
+			for z := uint8(0); z < 16; z++ {
+				for layer := uint8(0); layer < 2; layer++ {
+					if expected, got := c.Block(x, y, z, layer), decoded.Block(x, y, z, layer); expected != got {
+						t.Fatalf("block at (%v, %v, %v) on layer %v: expected %v, got %v", x, y, z, layer, expected, got)
+					}
+				}
+				if expected, got := c.Biome(x, y, z), decoded.Biome(x, y, z); expected != got {
+					t.Fatalf("biome at (%v, %v, %v): expected %v, got %v", x, y, z, expected, got)
+				}
+			}
+		}
+	}
+}
+
+// TestDecodeSubChunkVersions checks if sub chunks of version 1, 8 and 9 are decoded, and if sub chunks of
+// version 9 are placed at their y index rather than at their position in the payload.
+func TestDecodeSubChunkVersions(t *testing.T) {
+	blocks := NewPalettedStorage(testAir)
+	blocks.Set(1, 2, 3, testStone)
+	water := NewPalettedStorage(testAir)
+	water.Set(1, 2, 3, testWater)
+
+	for _, test := range []struct {
+		name   string
+		header []byte
+		layers []*PalettedStorage
+	}{
+		{name: "version 1", header: []byte{1}, layers: []*PalettedStorage{blocks}},
+		{name: "version 8", header: []byte{8, 2}, layers: []*PalettedStorage{blocks, water}},
+		{name: "version 9", header: []byte{9, 2, 0xfc}, layers: []*PalettedStorage{blocks, water}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(test.header)
+			for _, l := range test.layers {
+				encodeStorage(buf, l)
+			}
+			sub, err := DecodeSubChunk(testAir, buf.Bytes())
+			if err != nil {
+				t.Fatalf("decode sub chunk: %v", err)
+			}
+			for i, l := range test.layers {
+				if got := sub.Block(1, 2, 3, uint8(i)); got != l.At(1, 2, 3) {
+					t.Fatalf("expected block %v on layer %v, got %v", l.At(1, 2, 3), i, got)
+				}
+			}
+			if got := sub.Block(0, 0, 0, 0); got != testAir {
+				t.Fatalf("expected air, got %v", got)
+			}
+		})
+	}
+
+	// A version 9 sub chunk with y index -4 is the lowest sub chunk of the overworld, even if it is the
+	// second sub chunk in the payload.
+	buf := bytes.NewBuffer(nil)
+	encodeSubChunk(buf, NewSubChunk(testAir), 0)
+	buf.Write([]byte{9, 1, 0xfc})
+	encodeStorage(buf, blocks)
+	c, err := Decode(testAir, buf.Bytes(), 2, OverworldRange)
+	if err != nil {
+		t.Fatalf("decode chunk: %v", err)
+	}
+	if got := c.Block(1, -62, 3, 0); got != testStone {
+		t.Fatalf("expected sub chunk to be placed at its y index, got block %v", got)
+	}
+
+	if _, err := DecodeSubChunk(testAir, []byte{7, 1}); err == nil {
+		t.Fatalf("expected error decoding sub chunk of unsupported version")
+	}
+}
+
+// TestBiomeCopyLast checks if biome storages equal to that of the sub chunk below are encoded as a reference
+// to it, and if such references are decoded as a copy of the storage below.
+func TestBiomeCopyLast(t *testing.T) {
+	c := New(testAir, 5, NetherRange)
+	for y := 0; y < 32; y++ {
+		c.SetBiome(2, y, 2, 8)
+	}
+	payload, count := Encode(c)
+	if count != 0 {
+		t.Fatalf("expected no sub chunks to be encoded, got %v", count)
+	}
+	// The first two sub chunks have the same biomes, as do all sub chunks after them.
+	first := bytes.NewBuffer(nil)
+	encodeStorage(first, c.biomes[0])
+	third := bytes.NewBuffer(nil)
+	encodeStorage(third, c.biomes[2])
+	ref := []byte{biomeCopyLast<<1 | 1}
+	expected := bytes.Join([][]byte{first.Bytes(), ref, third.Bytes(), bytes.Repeat(ref, len(c.biomes)-3), {0}}, nil)
+	if !bytes.Equal(payload, expected) {
+		t.Fatalf("expected payload %x, got %x", expected, payload)
+	}
+
+	decoded, err := Decode(testAir, payload, count, NetherRange)
+	if err != nil {
+		t.Fatalf("decode chunk: %v", err)
+	}
+	for y := 0; y <= NetherRange.Max(); y++ {
+		if expected, got := c.Biome(2, y, 2), decoded.Biome(2, y, 2); expected != got {
+			t.Fatalf("biome at y=%v: expected %v, got %v", y, expected, got)
+		}
+	}
+	// Changing a copied storage must not change the storage it was copied from.
+	decoded.SetBiome(2, 16, 2, 9)
+	if got := decoded.Biome(2, 0, 2); got != 8 {
+		t.Fatalf("expected biome of lower sub chunk to be unchanged, got %v", got)
+	}
+
+	if _, err := Decode(testAir, ref, 0, NetherRange); err == nil {
+		t.Fatalf("expected error decoding a reference in the biomes of the first sub chunk")
+	}
+}
@@ -0,0 +1,143 @@
+package chunk
+
+import (
+	"slices"
+)
+
+// PalettedStorage is a storage of 4096 values, one for every block in a 16x16x16 sub chunk, that is
+// compressed using a palette. Every value is stored as an index into the palette, using the least amount of
+// bits needed to index every value in the palette.
+// PalettedStorages are used both for the block runtime IDs of a layer of a sub chunk and for the biome IDs of
+// a sub chunk.
+type PalettedStorage struct {
+	bitsPerIndex uint8
+	words        []uint32
+	palette      []uint32
+}
+
+// validBitsPerIndex holds the sizes that the client accepts for an index of a PalettedStorage.
+var validBitsPerIndex = []uint8{0, 1, 2, 3, 4, 5, 6, 8, 16}
+
+// NewPalettedStorage returns a PalettedStorage in which every value is set to the value passed.
+func NewPalettedStorage(v uint32) *PalettedStorage {
+	return &PalettedStorage{palette: []uint32{v}}
+}
+
+// At returns the value at the x, y and z position passed. Each of these values must be in the range 0-15.
+func (s *PalettedStorage) At(x, y, z uint8) uint32 {
+	if s.bitsPerIndex == 0 {
+		return s.palette[0]
+	}
+	return s.palette[s.index(storageOffset(x, y, z))]
+}
+
+// Set sets the value at the x, y and z position passed. Each of these values must be in the range 0-15. The
+// storage grows to a bigger index size if the value is not yet present in its palette.
+func (s *PalettedStorage) Set(x, y, z uint8, v uint32) {
+	i := slices.Index(s.palette, v)
+	if i == -1 {
+		i = len(s.palette)
+		s.palette = append(s.palette, v)
+		if needed := bitsNeeded(len(s.palette)); needed > s.bitsPerIndex {
+			s.resize(needed)
+		}
+	}
+	if s.bitsPerIndex == 0 {
+		return
+	}
+	s.setIndex(storageOffset(x, y, z), uint32(i))
+}
+
+// Palette returns the values in the palette of the storage. The slice returned must not be modified.
+func (s *PalettedStorage) Palette() []uint32 {
+	return s.palette
+}
+
+// Uniform checks if every value in the storage is the same, returning the value if so.
+func (s *PalettedStorage) Uniform() (uint32, bool) {
+	if len(s.palette) == 1 {
+		return s.palette[0], true
+	}
+	first := s.index(0)
+	for i := uint16(1); i < 4096; i++ {
+		if s.index(i) != first {
+			return 0, false
+		}
+	}
+	return s.palette[first], true
+}
+
+// Equal checks if the storage holds the same values as the storage passed.
+func (s *PalettedStorage) Equal(o *PalettedStorage) bool {
+	for i := uint16(0); i < 4096; i++ {
+		if s.valueAt(i) != o.valueAt(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a copy of the storage that may be modified without affecting the original.
+func (s *PalettedStorage) clone() *PalettedStorage {
+	return &PalettedStorage{bitsPerIndex: s.bitsPerIndex, words: slices.Clone(s.words), palette: slices.Clone(s.palette)}
+}
+
+// valueAt returns the value at the offset passed.
+func (s *PalettedStorage) valueAt(off uint16) uint32 {
+	if s.bitsPerIndex == 0 {
+		return s.palette[0]
+	}
+	return s.palette[s.index(off)]
+}
+
+// index returns the palette index stored at the offset passed.
+func (s *PalettedStorage) index(off uint16) uint32 {
+	if s.bitsPerIndex == 0 {
+		return 0
+	}
+	perWord := 32 / uint16(s.bitsPerIndex)
+	word, shift := off/perWord, (off%perWord)*uint16(s.bitsPerIndex)
+	return (s.words[word] >> shift) & (1<<s.bitsPerIndex - 1)
+}
+
+// setIndex sets the palette index stored at the offset passed.
+func (s *PalettedStorage) setIndex(off uint16, i uint32) {
+	perWord := 32 / uint16(s.bitsPerIndex)
+	word, shift := off/perWord, (off%perWord)*uint16(s.bitsPerIndex)
+	mask := uint32(1<<s.bitsPerIndex-1) << shift
+	s.words[word] = s.words[word]&^mask | i<<shift
+}
+
+// resize changes the amount of bits used for every index in the storage, keeping all values intact.
+func (s *PalettedStorage) resize(bits uint8) {
+	n := &PalettedStorage{bitsPerIndex: bits, words: make([]uint32, wordCount(bits)), palette: s.palette}
+	for i := uint16(0); i < 4096; i++ {
+		n.setIndex(i, s.index(i))
+	}
+	*s = *n
+}
+
+// storageOffset returns the offset of a value in a PalettedStorage. Values are ordered by x first, then z,
+// then y.
+func storageOffset(x, y, z uint8) uint16 {
+	return uint16(x&0xf)<<8 | uint16(z&0xf)<<4 | uint16(y&0xf)
+}
+
+// bitsNeeded returns the smallest valid index size that can index a palette of the size passed.
+func bitsNeeded(paletteSize int) uint8 {
+	for _, bits := range validBitsPerIndex {
+		if 1<<bits >= paletteSize {
+			return bits
+		}
+	}
+	return 16
+}
+
+// wordCount returns the amount of uint32 words needed to store 4096 indices of the size passed.
+func wordCount(bits uint8) int {
+	if bits == 0 {
+		return 0
+	}
+	perWord := 32 / int(bits)
+	return (4096 + perWord - 1) / perWord
+}
@@ -0,0 +1,167 @@
+// Package creative implements the content of the creative inventory, which must be sent to the client in a
+// CreativeContent packet during the login sequence. Content refers to items and blocks by name, so that the
+// same Content may be turned into a CreativeContent packet for any protocol version using the item registry
+// and block palette of that version.
+//
+// The package does not embed the vanilla creative inventory and does not implement crafting recipes. Content
+// may be captured from a CreativeContent packet sent by a vanilla server using FromPacket, stored using
+// Content.Write and registered using Register after reading it back with ReadContent.
+package creative
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/world/blocks"
+	"github.com/sandertv/gophertunnel/minecraft/world/items"
+	"io"
+	"sync"
+)
+
+// Item is a single item in the creative inventory. Its JSON representation matches the entries of the
+// creativeitems.json file commonly used to distribute the vanilla creative inventory.
+type Item struct {
+	// Name is the name of the item, such as 'minecraft:planks'.
+	Name string `json:"id"`
+	// Meta is the metadata value of the item.
+	Meta uint32 `json:"damage,omitempty"`
+	// BlockName is the name of the block that the item places, if it differs from Name.
+	BlockName string `json:"block_name,omitempty"`
+	// BlockStates holds the little endian NBT encoded properties of the block that the item places, or nil
+	// if the item does not place a block.
+	BlockStates []byte `json:"block_states,omitempty"`
+	// NBT holds the little endian NBT encoded data of the item, or nil if the item has no NBT data.
+	NBT []byte `json:"nbt_b64,omitempty"`
+}
+
+// Content holds all items in the creative inventory, in the order in which they are shown.
+type Content struct {
+	Items []Item
+}
+
+// ReadContent reads Content from the reader passed. The data must be a JSON array of Items, which is the
+// format produced by Content.Write.
+func ReadContent(r io.Reader) (*Content, error) {
+	c := &Content{}
+	if err := json.NewDecoder(r).Decode(&c.Items); err != nil {
+		return nil, fmt.Errorf("decode creative content: %w", err)
+	}
+	return c, nil
+}
+
+// Write writes the Content to the writer passed as a JSON array of Items. It may be used to store Content
+// obtained from a vanilla server using FromPacket, so that it can later be embedded.
+func (c *Content) Write(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(c.Items); err != nil {
+		return fmt.Errorf("encode creative content: %w", err)
+	}
+	return nil
+}
+
+// Packet builds a CreativeContent packet from the Content, using the item registry and block palette passed
+// to look up the runtime IDs of the items and the blocks they place. The palette may be nil if no item
+// places a block. An error is returned if an item or block could not be found.
+func (c *Content) Packet(reg *items.Registry, palette *blocks.Palette) (*packet.CreativeContent, error) {
+	pk := &packet.CreativeContent{Items: make([]protocol.CreativeItem, 0, len(c.Items))}
+	for i, it := range c.Items {
+		rid, ok := reg.RuntimeID(it.Name)
+		if !ok {
+			return nil, fmt.Errorf("build creative item %v: unknown item %v", i, it.Name)
+		}
+		stack := protocol.ItemStack{
+			ItemType: protocol.ItemType{NetworkID: int32(rid), MetadataValue: it.Meta},
+			Count:    1,
+		}
+		if it.BlockStates != nil {
+			if palette == nil {
+				return nil, fmt.Errorf("build creative item %v: no block palette for block item %v", i, it.Name)
+			}
+			var properties map[string]any
+			if err := nbt.UnmarshalEncoding(it.BlockStates, &properties, nbt.LittleEndian); err != nil {
+				return nil, fmt.Errorf("build creative item %v: decode block states: %w", i, err)
+			}
+			name := it.BlockName
+			if name == "" {
+				name = it.Name
+			}
+			block, ok := palette.RuntimeID(name, properties)
+			if !ok {
+				return nil, fmt.Errorf("build creative item %v: unknown block state of %v: %v", i, name, properties)
+			}
+			stack.BlockRuntimeID = int32(block)
+		}
+		if it.NBT != nil {
+			if err := nbt.UnmarshalEncoding(it.NBT, &stack.NBTData, nbt.LittleEndian); err != nil {
+				return nil, fmt.Errorf("build creative item %v: decode NBT: %w", i, err)
+			}
+		}
+		pk.Items = append(pk.Items, protocol.CreativeItem{CreativeItemNetworkID: uint32(i + 1), Item: stack})
+	}
+	return pk, nil
+}
+
+// FromPacket creates Content from a CreativeContent packet, for example one received from a vanilla server,
+// using the item registry and block palette of the protocol version that the packet was received with. The
+// palette may be nil, in which case the blocks that items place are not recorded.
+func FromPacket(pk *packet.CreativeContent, reg *items.Registry, palette *blocks.Palette) (*Content, error) {
+	c := &Content{Items: make([]Item, 0, len(pk.Items))}
+	for i, creativeItem := range pk.Items {
+		stack := creativeItem.Item
+		name, ok := reg.Name(int16(stack.NetworkID))
+		if !ok {
+			return nil, fmt.Errorf("read creative item %v: unknown runtime ID %v", i, stack.NetworkID)
+		}
+		it := Item{Name: name, Meta: stack.MetadataValue}
+		if palette != nil && stack.BlockRuntimeID != 0 {
+			state, ok := palette.State(uint32(stack.BlockRuntimeID))
+			if !ok {
+				return nil, fmt.Errorf("read creative item %v: unknown block runtime ID %v", i, stack.BlockRuntimeID)
+			}
+			if state.Name != name {
+				it.BlockName = state.Name
+			}
+			properties := state.Properties
+			if properties == nil {
+				properties = map[string]any{}
+			}
+			data, err := nbt.MarshalEncoding(properties, nbt.LittleEndian)
+			if err != nil {
+				return nil, fmt.Errorf("read creative item %v: encode block states: %w", i, err)
+			}
+			it.BlockStates = data
+		}
+		if len(stack.NBTData) != 0 {
+			data, err := nbt.MarshalEncoding(stack.NBTData, nbt.LittleEndian)
+			if err != nil {
+				return nil, fmt.Errorf("read creative item %v: encode NBT: %w", i, err)
+			}
+			it.NBT = data
+		}
+		c.Items = append(c.Items, it)
+	}
+	return c, nil
+}
+
+var (
+	contentMu sync.RWMutex
+	content   = map[int32]*Content{}
+)
+
+// Register registers the Content passed for the protocol version passed, so that it may be obtained using
+// ForProtocol. Content previously registered for the same protocol version is replaced.
+func Register(protocolID int32, c *Content) {
+	contentMu.Lock()
+	defer contentMu.Unlock()
+	content[protocolID] = c
+}
+
+// ForProtocol returns the Content registered for the protocol version passed. If no Content was registered
+// for it, false is returned.
+func ForProtocol(protocolID int32) (*Content, bool) {
+	contentMu.RLock()
+	defer contentMu.RUnlock()
+	c, ok := content[protocolID]
+	return c, ok
+}
@@ -0,0 +1,92 @@
+package world
+
+import (
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/world/chunk"
+)
+
+// Dimension is a dimension of a world, such as the overworld or the nether. Servers may change the height
+// range of a dimension by sending the Definition of the Dimension in a DimensionData packet before the
+// client enters it.
+type Dimension struct {
+	// ID is the ID of the dimension as sent in packets such as LevelChunk and ChangeDimension. It is one of
+	// the packet.Dimension constants.
+	ID int32
+	// Name is the name of the dimension, such as 'minecraft:overworld'.
+	Name string
+	// Range is the vertical range of blocks in the dimension.
+	Range chunk.Range
+	// Generator is the generator used for the dimension by the client. It is one of the protocol.Generator
+	// constants.
+	Generator int32
+}
+
+var (
+	// Overworld is the vanilla overworld, which ranges from y=-64 to y=319.
+	Overworld = Dimension{ID: packet.DimensionOverworld, Name: "minecraft:overworld", Range: chunk.OverworldRange, Generator: protocol.GeneratorOverworld}
+	// Nether is the vanilla nether, which ranges from y=0 to y=127.
+	Nether = Dimension{ID: packet.DimensionNether, Name: "minecraft:nether", Range: chunk.NetherRange, Generator: protocol.GeneratorNether}
+	// End is the vanilla end, which ranges from y=0 to y=255.
+	End = Dimension{ID: packet.DimensionEnd, Name: "minecraft:the_end", Range: chunk.EndRange, Generator: protocol.GeneratorEnd}
+)
+
+// DimensionByID returns the vanilla Dimension with the ID passed. If no dimension has this ID, false is
+// returned.
+func DimensionByID(id int32) (Dimension, bool) {
+	switch id {
+	case Overworld.ID:
+		return Overworld, true
+	case Nether.ID:
+		return Nether, true
+	case End.ID:
+		return End, true
+	}
+	return Dimension{}, false
+}
+
+// DimensionByName returns the vanilla Dimension with the name passed, such as 'minecraft:overworld'. If no
+// dimension has this name, false is returned.
+func DimensionByName(name string) (Dimension, bool) {
+	switch name {
+	case Overworld.Name:
+		return Overworld, true
+	case Nether.Name:
+		return Nether, true
+	case End.Name:
+		return End, true
+	}
+	return Dimension{}, false
+}
+
+// WithRange returns a copy of the Dimension with the height range passed, for example to make the overworld
+// taller or shorter. Both the lowest and highest y value plus one must be multiples of 16, as sub chunks
+// cannot be split. An error is returned if the Range does not meet these requirements.
+func (d Dimension) WithRange(r chunk.Range) (Dimension, error) {
+	if r.Min() >= r.Max() || r.Min()&0xf != 0 || (r.Max()+1)&0xf != 0 {
+		return Dimension{}, fmt.Errorf("dimension range %v must span whole sub chunks", r)
+	}
+	d.Range = r
+	return d, nil
+}
+
+// Definition returns the DimensionDefinition of the Dimension, as sent in the DimensionData packet. Note that
+// the maximum of its range is exclusive.
+func (d Dimension) Definition() protocol.DimensionDefinition {
+	return protocol.DimensionDefinition{
+		Name:      d.Name,
+		Range:     [2]int32{int32(d.Range.Min()), int32(d.Range.Max() + 1)},
+		Generator: d.Generator,
+	}
+}
+
+// DimensionData returns a DimensionData packet holding the definitions of the dimensions passed. It should
+// be sent to a client before it enters any of the dimensions, so that the client uses their height ranges.
+func DimensionData(dimensions ...Dimension) *packet.DimensionData {
+	pk := &packet.DimensionData{Definitions: make([]protocol.DimensionDefinition, 0, len(dimensions))}
+	for _, d := range dimensions {
+		pk.Definitions = append(pk.Definitions, d.Definition())
+	}
+	return pk
+}
@@ -0,0 +1,115 @@
+// Package entities implements the entity identifiers sent to the client in the AvailableActorIdentifiers
+// packet. The identifiers tell the client which entities exist on the server, including custom entities
+// defined in behaviour packs. Identifiers are added by registering them to a Registry.
+//
+// The package does not embed the vanilla identifier list. A Registry returned by NewRegistry is empty, and
+// no AvailableActorIdentifiers packet is sent for it until identifiers are registered. The list sent by a
+// vanilla server may be registered to send the vanilla entities.
+package entities
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Identifier identifies a single entity type.
+type Identifier struct {
+	// ID is the identifier of the entity, such as 'minecraft:zombie'.
+	ID string `nbt:"id"`
+	// RuntimeID is the legacy numerical ID of the entity. Custom entities generally use a unique value that
+	// is not used by any vanilla entity.
+	RuntimeID int32 `nbt:"rid"`
+	// BaseID is the identifier of the entity that the entity is based on, if any.
+	BaseID string `nbt:"bid"`
+	// HasSpawnEgg specifies if the entity has a spawn egg in the creative inventory.
+	HasSpawnEgg bool `nbt:"hasspawnegg"`
+	// Summonable specifies if the entity may be summoned using the /summon command.
+	Summonable bool `nbt:"summonable"`
+}
+
+// Registry holds the entity identifiers sent to the client. A Registry may be set to
+// ListenConfig.EntityIdentifiers, so that each Listener may send its own set of identifiers. A Registry is
+// safe for concurrent use, and identifiers may be registered at any time: Connections that spawn afterwards
+// are sent the new identifiers.
+type Registry struct {
+	mu          sync.RWMutex
+	identifiers map[string]Identifier
+	// encoded holds the encoded identifiers returned by Packet. It is nil if the identifiers were changed
+	// since they were last encoded.
+	encoded []byte
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{identifiers: make(map[string]Identifier)}
+}
+
+// Register registers the Identifier passed, so that it is included in the packet returned by Packet. An
+// Identifier with the same ID is replaced.
+func (r *Registry) Register(id Identifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identifiers[id.ID] = id
+	r.encoded = nil
+}
+
+// Lookup returns the Identifier of the entity with the ID passed. If no such entity is registered, false is
+// returned.
+func (r *Registry) Lookup(id string) (Identifier, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i, ok := r.identifiers[id]
+	return i, ok
+}
+
+// Identifiers returns all registered identifiers, ordered by ID.
+func (r *Registry) Identifiers() []Identifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sorted()
+}
+
+// sorted returns all registered identifiers, ordered by ID. r.mu must be held when sorted is called.
+func (r *Registry) sorted() []Identifier {
+	list := make([]Identifier, 0, len(r.identifiers))
+	for _, id := range r.identifiers {
+		list = append(list, id)
+	}
+	slices.SortFunc(list, func(a, b Identifier) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// Encode encodes the identifiers passed to the payload of an AvailableActorIdentifiers packet.
+func Encode(list []Identifier) []byte {
+	b, _ := nbt.MarshalEncoding(struct {
+		IDList []Identifier `nbt:"idlist"`
+	}{IDList: list}, nbt.NetworkLittleEndian)
+	return b
+}
+
+// Packet returns an AvailableActorIdentifiers packet holding all registered identifiers, or nil if no
+// identifiers are registered. The identifiers are only encoded again if an identifier was registered since
+// the last call. The serialised identifiers of the packet are shared between calls and must not be
+// modified.
+func (r *Registry) Packet() *packet.AvailableActorIdentifiers {
+	r.mu.RLock()
+	encoded, n := r.encoded, len(r.identifiers)
+	r.mu.RUnlock()
+	if n == 0 {
+		return nil
+	}
+	if encoded == nil {
+		r.mu.Lock()
+		if r.encoded == nil {
+			r.encoded = Encode(r.sorted())
+		}
+		encoded = r.encoded
+		r.mu.Unlock()
+	}
+	return &packet.AvailableActorIdentifiers{SerialisedEntityIdentifiers: encoded}
+}
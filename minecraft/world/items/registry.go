@@ -0,0 +1,155 @@
+// Package items implements the item tables used by Minecraft to refer to items over network. Every item has
+// a name, such as 'minecraft:stick', and a runtime ID that the item is identified by in packets after the
+// item table was sent in the StartGame packet. Custom items additionally have components, which are sent to
+// the client in the ItemComponent packet.
+//
+// The package does not embed the item table of any protocol version. The required_item_list.json file of a
+// release, or the Items field of a StartGame packet received from a vanilla server, must be turned into a
+// Registry using ReadRegistry or NewRegistry and registered using Register before ForProtocol returns a
+// Registry for that version.
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Registry holds the item table of a single protocol version, mapping item names to runtime IDs and back.
+// Custom items may be added to a Registry using RegisterCustom. A Registry may be used concurrently by
+// multiple goroutines.
+type Registry struct {
+	mu         sync.RWMutex
+	entries    []protocol.ItemEntry
+	ids        map[string]int16
+	names      map[int16]string
+	components map[string]map[string]any
+	next       int16
+}
+
+// NewRegistry creates a Registry holding the item entries passed. The entries typically originate from the
+// Items field of the StartGame packet or from ReadRegistry.
+func NewRegistry(entries []protocol.ItemEntry) *Registry {
+	r := &Registry{
+		ids:        make(map[string]int16, len(entries)),
+		names:      make(map[int16]string, len(entries)),
+		components: make(map[string]map[string]any),
+		next:       1,
+	}
+	for _, entry := range entries {
+		r.add(entry)
+	}
+	return r
+}
+
+// ReadRegistry reads a Registry from the reader passed. The data must be a JSON object mapping item names to
+// objects with a 'runtime_id' and 'component_based' field, the format of the required_item_list.json file
+// that accompanies every release of the game.
+func ReadRegistry(r io.Reader) (*Registry, error) {
+	var m map[string]struct {
+		RuntimeID      int16 `json:"runtime_id"`
+		ComponentBased bool  `json:"component_based"`
+	}
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode item table: %w", err)
+	}
+	entries := make([]protocol.ItemEntry, 0, len(m))
+	for name, item := range m {
+		entries = append(entries, protocol.ItemEntry{Name: name, RuntimeID: item.RuntimeID, ComponentBased: item.ComponentBased})
+	}
+	slices.SortFunc(entries, func(a, b protocol.ItemEntry) int {
+		return int(a.RuntimeID) - int(b.RuntimeID)
+	})
+	return NewRegistry(entries), nil
+}
+
+// add adds an entry to the Registry. The Registry must be locked when add is called.
+func (r *Registry) add(entry protocol.ItemEntry) {
+	r.entries = append(r.entries, entry)
+	r.ids[entry.Name] = entry.RuntimeID
+	r.names[entry.RuntimeID] = entry.Name
+	if entry.RuntimeID >= r.next {
+		r.next = entry.RuntimeID + 1
+	}
+}
+
+// RuntimeID returns the runtime ID of the item with the name passed. If no item with this name exists in the
+// Registry, false is returned.
+func (r *Registry) RuntimeID(name string) (int16, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rid, ok := r.ids[name]
+	return rid, ok
+}
+
+// Name returns the name of the item with the runtime ID passed. If no item with this runtime ID exists in the
+// Registry, false is returned.
+func (r *Registry) Name(rid int16) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.names[rid]
+	return name, ok
+}
+
+// Entries returns all entries in the Registry, including custom items, in the order they were added. The
+// entries returned may be set to GameData.Items to send the item table to a client.
+func (r *Registry) Entries() []protocol.ItemEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.entries)
+}
+
+// RegisterCustom registers a custom item with the name and components passed, assigning it the next free
+// runtime ID. The components are sent to the client in the packet returned by ItemComponent. An error is
+// returned if an item with the same name already exists in the Registry.
+func (r *Registry) RegisterCustom(name string, components map[string]any) (protocol.ItemEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ids[name]; ok {
+		return protocol.ItemEntry{}, fmt.Errorf("register custom item %v: item already exists", name)
+	}
+	entry := protocol.ItemEntry{Name: name, RuntimeID: r.next, ComponentBased: true}
+	r.add(entry)
+	r.components[name] = components
+	return entry, nil
+}
+
+// ItemComponent returns an ItemComponent packet holding the components of all custom items registered
+// using RegisterCustom. It should be sent to the client after the StartGame packet.
+func (r *Registry) ItemComponent() *packet.ItemComponent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pk := &packet.ItemComponent{Items: make([]protocol.ItemComponentEntry, 0, len(r.components))}
+	for _, entry := range r.entries {
+		if components, ok := r.components[entry.Name]; ok {
+			pk.Items = append(pk.Items, protocol.ItemComponentEntry{Name: entry.Name, Data: components})
+		}
+	}
+	return pk
+}
+
+var (
+	registriesMu sync.RWMutex
+	registries   = map[int32]*Registry{}
+)
+
+// Register registers the Registry passed for the protocol version passed, so that it may be obtained using
+// ForProtocol. A Registry previously registered for the same protocol version is replaced.
+func Register(protocolID int32, r *Registry) {
+	registriesMu.Lock()
+	defer registriesMu.Unlock()
+	registries[protocolID] = r
+}
+
+// ForProtocol returns the Registry registered for the protocol version passed. If no Registry was registered
+// for it, false is returned.
+func ForProtocol(protocolID int32) (*Registry, bool) {
+	registriesMu.RLock()
+	defer registriesMu.RUnlock()
+	r, ok := registries[protocolID]
+	return r, ok
+}
@@ -0,0 +1,160 @@
+// Package particles implements registries of the names of particle effects spawned using the
+// SpawnParticleEffect packet, and constants for commonly used vanilla particles. Particles are identified by
+// name rather than by ID, but the particles available differ between versions of the game, so a Registry
+// holds the names of the particles of a single protocol version. The Registry of the current protocol
+// version is registered by default, and registries of other versions may be added using Register.
+package particles
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"slices"
+	"sync"
+)
+
+// Names of commonly used vanilla particle effects.
+const (
+	BasicFlame         = "minecraft:basic_flame_particle"
+	BlueFlame          = "minecraft:blue_flame_particle"
+	CandleFlame        = "minecraft:candle_flame_particle"
+	BasicSmoke         = "minecraft:basic_smoke_particle"
+	CampfireSmoke      = "minecraft:campfire_smoke_particle"
+	CampfireTallSmoke  = "minecraft:campfire_tall_smoke_particle"
+	BasicCrit          = "minecraft:basic_crit_particle"
+	CriticalHit        = "minecraft:critical_hit_emitter"
+	Heart              = "minecraft:heart_particle"
+	VillagerHappy      = "minecraft:villager_happy"
+	VillagerAngry      = "minecraft:villager_angry"
+	Explosion          = "minecraft:explosion_particle"
+	LargeExplosion     = "minecraft:large_explosion"
+	HugeExplosion      = "minecraft:huge_explosion_emitter"
+	Lava               = "minecraft:lava_particle"
+	WaterSplash        = "minecraft:water_splash_particle"
+	Note               = "minecraft:note_particle"
+	EndRod             = "minecraft:endrod"
+	Totem              = "minecraft:totem_particle"
+	Portal             = "minecraft:portal_directional"
+	MobFlame           = "minecraft:mobflame_emitter"
+	RedstoneDust       = "minecraft:redstone_wire_dust_particle"
+	DragonBreathTrail  = "minecraft:dragon_breath_trail"
+	DragonBreathFire   = "minecraft:dragon_breath_fire"
+	EnchantingTable    = "minecraft:enchanting_table_particle"
+	EvokerSpell        = "minecraft:evoker_spell"
+	Ink                = "minecraft:ink_emitter"
+	SonicExplosion     = "minecraft:sonic_explosion"
+	Shriek             = "minecraft:shriek_particle"
+	SculkSoul          = "minecraft:sculk_soul_particle"
+	Soul               = "minecraft:soul_particle"
+	Conduit            = "minecraft:conduit_particle"
+	BubbleColumnUp     = "minecraft:bubble_column_up_particle"
+	BubbleColumnDown   = "minecraft:bubble_column_down_particle"
+	ElectricSpark      = "minecraft:electric_spark_particle"
+	Glow               = "minecraft:glow_particle"
+	Wax                = "minecraft:wax_particle"
+	CherryLeaves       = "minecraft:cherry_leaves_particle"
+	WindExplosion      = "minecraft:wind_explosion_emitter"
+	BreezeWindBurst    = "minecraft:breeze_wind_explosion_emitter"
+	EggDestroy         = "minecraft:egg_destroy_emitter"
+	EyeOfEnderDeath    = "minecraft:eye_of_ender_death_explode_particle"
+	SplashSpell        = "minecraft:splash_spell_emitter"
+	ObsidianGlowDust   = "minecraft:obsidian_glow_dust_particle"
+	WitherInvulnerable = "minecraft:wither_boss_invulnerable"
+	MyceliumDust       = "minecraft:mycelium_dust_particle"
+	DolphinMove        = "minecraft:dolphin_move_particle"
+	CropGrowth         = "minecraft:crop_growth_emitter"
+	SporeBlossom       = "minecraft:spore_blossom_ambient_particle"
+	HoneyDrip          = "minecraft:honey_drip_particle"
+	NectarDrip         = "minecraft:nectar_drip_particle"
+)
+
+// Registry holds the names of the particle effects of a single protocol version. A Registry is immutable
+// once created and may be used concurrently by multiple goroutines.
+type Registry struct {
+	names []string
+}
+
+// NewRegistry creates a Registry holding the particle names passed.
+func NewRegistry(names []string) *Registry {
+	r := &Registry{names: slices.Clone(names)}
+	slices.Sort(r.names)
+	r.names = slices.Compact(r.names)
+	return r
+}
+
+// Has checks if the Registry holds a particle with the name passed. Particles defined by resource packs are
+// not present in a Registry, so a particle not in the Registry may still be valid on a server with packs.
+func (r *Registry) Has(name string) bool {
+	_, ok := slices.BinarySearch(r.names, name)
+	return ok
+}
+
+// Names returns the names of all particles in the Registry in alphabetical order.
+func (r *Registry) Names() []string {
+	return slices.Clone(r.names)
+}
+
+// Spawn returns a SpawnParticleEffect packet spawning the particle with the name passed at the position
+// passed, like the Spawn function. An error is returned if the particle is not present in the Registry.
+func (r *Registry) Spawn(name string, pos mgl32.Vec3) (*packet.SpawnParticleEffect, error) {
+	if !r.Has(name) {
+		return nil, fmt.Errorf("unknown particle %q", name)
+	}
+	return Spawn(name, pos), nil
+}
+
+// Latest is the Registry of the current protocol version, holding the particles of the constants in this
+// package. These are not all vanilla particles of the current version: Particles missing may be spawned using
+// Spawn, or added by registering a Registry with more names.
+var Latest = NewRegistry([]string{
+	BasicFlame, BlueFlame, CandleFlame, BasicSmoke, CampfireSmoke, CampfireTallSmoke, BasicCrit, CriticalHit,
+	Heart, VillagerHappy, VillagerAngry, Explosion, LargeExplosion, HugeExplosion, Lava, WaterSplash, Note,
+	EndRod, Totem, Portal, MobFlame, RedstoneDust, DragonBreathTrail, DragonBreathFire, EnchantingTable,
+	EvokerSpell, Ink, SonicExplosion, Shriek, SculkSoul, Soul, Conduit, BubbleColumnUp, BubbleColumnDown,
+	ElectricSpark, Glow, Wax, CherryLeaves, WindExplosion, BreezeWindBurst, EggDestroy, EyeOfEnderDeath,
+	SplashSpell, ObsidianGlowDust, WitherInvulnerable, MyceliumDust, DolphinMove, CropGrowth, SporeBlossom,
+	HoneyDrip, NectarDrip,
+})
+
+var (
+	mu         sync.RWMutex
+	registries = map[int32]*Registry{protocol.CurrentProtocol: Latest}
+)
+
+// Register registers the Registry passed for the protocol version passed, replacing any Registry registered
+// for it before.
+func Register(protocolID int32, r *Registry) {
+	mu.Lock()
+	defer mu.Unlock()
+	registries[protocolID] = r
+}
+
+// ForProtocol returns the Registry registered for the protocol version passed. If no Registry was registered
+// for it, false is returned.
+func ForProtocol(protocolID int32) (*Registry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := registries[protocolID]
+	return r, ok
+}
+
+// Spawn returns a SpawnParticleEffect packet spawning the particle with the name passed at the position
+// passed in the overworld.
+func Spawn(name string, pos mgl32.Vec3) *packet.SpawnParticleEffect {
+	return &packet.SpawnParticleEffect{EntityUniqueID: -1, Position: pos, ParticleName: name}
+}
+
+// SpawnAttached returns a SpawnParticleEffect packet spawning the particle with the name passed attached to
+// the entity with the unique ID passed. The offset is relative to the position of the entity.
+func SpawnAttached(name string, entityUniqueID int64, offset mgl32.Vec3) *packet.SpawnParticleEffect {
+	return &packet.SpawnParticleEffect{EntityUniqueID: entityUniqueID, Position: offset, ParticleName: name}
+}
+
+// WithVariables returns a copy of the SpawnParticleEffect packet passed with the MoLang variables passed,
+// which must be encoded as JSON.
+func WithVariables(pk *packet.SpawnParticleEffect, variables []byte) *packet.SpawnParticleEffect {
+	c := *pk
+	c.MoLangVariables = protocol.Option(variables)
+	return &c
+}
@@ -0,0 +1,503 @@
+package sounds
+
+import "github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+
+// latestNames maps the names of all level sound events of the current protocol version to their IDs. The
+// names are those of the SoundEvent constants of the packet package, without the SoundEvent prefix.
+var latestNames = map[string]uint32{
+	"ItemUseOn":                          packet.SoundEventItemUseOn,
+	"Hit":                                packet.SoundEventHit,
+	"Step":                               packet.SoundEventStep,
+	"Fly":                                packet.SoundEventFly,
+	"Jump":                               packet.SoundEventJump,
+	"Break":                              packet.SoundEventBreak,
+	"Place":                              packet.SoundEventPlace,
+	"HeavyStep":                          packet.SoundEventHeavyStep,
+	"Gallop":                             packet.SoundEventGallop,
+	"Fall":                               packet.SoundEventFall,
+	"Ambient":                            packet.SoundEventAmbient,
+	"AmbientBaby":                        packet.SoundEventAmbientBaby,
+	"AmbientInWater":                     packet.SoundEventAmbientInWater,
+	"Breathe":                            packet.SoundEventBreathe,
+	"Death":                              packet.SoundEventDeath,
+	"DeathInWater":                       packet.SoundEventDeathInWater,
+	"DeathToZombie":                      packet.SoundEventDeathToZombie,
+	"Hurt":                               packet.SoundEventHurt,
+	"HurtInWater":                        packet.SoundEventHurtInWater,
+	"Mad":                                packet.SoundEventMad,
+	"Boost":                              packet.SoundEventBoost,
+	"Bow":                                packet.SoundEventBow,
+	"SquishBig":                          packet.SoundEventSquishBig,
+	"SquishSmall":                        packet.SoundEventSquishSmall,
+	"FallBig":                            packet.SoundEventFallBig,
+	"FallSmall":                          packet.SoundEventFallSmall,
+	"Splash":                             packet.SoundEventSplash,
+	"Fizz":                               packet.SoundEventFizz,
+	"Flap":                               packet.SoundEventFlap,
+	"Swim":                               packet.SoundEventSwim,
+	"Drink":                              packet.SoundEventDrink,
+	"Eat":                                packet.SoundEventEat,
+	"Takeoff":                            packet.SoundEventTakeoff,
+	"Shake":                              packet.SoundEventShake,
+	"Plop":                               packet.SoundEventPlop,
+	"Land":                               packet.SoundEventLand,
+	"Saddle":                             packet.SoundEventSaddle,
+	"Armor":                              packet.SoundEventArmor,
+	"ArmorPlace":                         packet.SoundEventArmorPlace,
+	"AddChest":                           packet.SoundEventAddChest,
+	"Throw":                              packet.SoundEventThrow,
+	"Attack":                             packet.SoundEventAttack,
+	"AttackNoDamage":                     packet.SoundEventAttackNoDamage,
+	"AttackStrong":                       packet.SoundEventAttackStrong,
+	"Warn":                               packet.SoundEventWarn,
+	"Shear":                              packet.SoundEventShear,
+	"Milk":                               packet.SoundEventMilk,
+	"Thunder":                            packet.SoundEventThunder,
+	"Explode":                            packet.SoundEventExplode,
+	"Fire":                               packet.SoundEventFire,
+	"Ignite":                             packet.SoundEventIgnite,
+	"Fuse":                               packet.SoundEventFuse,
+	"Stare":                              packet.SoundEventStare,
+	"Spawn":                              packet.SoundEventSpawn,
+	"Shoot":                              packet.SoundEventShoot,
+	"BreakBlock":                         packet.SoundEventBreakBlock,
+	"Launch":                             packet.SoundEventLaunch,
+	"Blast":                              packet.SoundEventBlast,
+	"LargeBlast":                         packet.SoundEventLargeBlast,
+	"Twinkle":                            packet.SoundEventTwinkle,
+	"Remedy":                             packet.SoundEventRemedy,
+	"Unfect":                             packet.SoundEventUnfect,
+	"LevelUp":                            packet.SoundEventLevelUp,
+	"BowHit":                             packet.SoundEventBowHit,
+	"BulletHit":                          packet.SoundEventBulletHit,
+	"ExtinguishFire":                     packet.SoundEventExtinguishFire,
+	"ItemFizz":                           packet.SoundEventItemFizz,
+	"ChestOpen":                          packet.SoundEventChestOpen,
+	"ChestClosed":                        packet.SoundEventChestClosed,
+	"ShulkerBoxOpen":                     packet.SoundEventShulkerBoxOpen,
+	"ShulkerBoxClosed":                   packet.SoundEventShulkerBoxClosed,
+	"EnderChestOpen":                     packet.SoundEventEnderChestOpen,
+	"EnderChestClosed":                   packet.SoundEventEnderChestClosed,
+	"PowerOn":                            packet.SoundEventPowerOn,
+	"PowerOff":                           packet.SoundEventPowerOff,
+	"Attach":                             packet.SoundEventAttach,
+	"Detach":                             packet.SoundEventDetach,
+	"Deny":                               packet.SoundEventDeny,
+	"Tripod":                             packet.SoundEventTripod,
+	"Pop":                                packet.SoundEventPop,
+	"DropSlot":                           packet.SoundEventDropSlot,
+	"Note":                               packet.SoundEventNote,
+	"Thorns":                             packet.SoundEventThorns,
+	"PistonIn":                           packet.SoundEventPistonIn,
+	"PistonOut":                          packet.SoundEventPistonOut,
+	"Portal":                             packet.SoundEventPortal,
+	"Water":                              packet.SoundEventWater,
+	"LavaPop":                            packet.SoundEventLavaPop,
+	"Lava":                               packet.SoundEventLava,
+	"Burp":                               packet.SoundEventBurp,
+	"BucketFillWater":                    packet.SoundEventBucketFillWater,
+	"BucketFillLava":                     packet.SoundEventBucketFillLava,
+	"BucketEmptyWater":                   packet.SoundEventBucketEmptyWater,
+	"BucketEmptyLava":                    packet.SoundEventBucketEmptyLava,
+	"EquipChain":                         packet.SoundEventEquipChain,
+	"EquipDiamond":                       packet.SoundEventEquipDiamond,
+	"EquipGeneric":                       packet.SoundEventEquipGeneric,
+	"EquipGold":                          packet.SoundEventEquipGold,
+	"EquipIron":                          packet.SoundEventEquipIron,
+	"EquipLeather":                       packet.SoundEventEquipLeather,
+	"EquipElytra":                        packet.SoundEventEquipElytra,
+	"Record13":                           packet.SoundEventRecord13,
+	"RecordCat":                          packet.SoundEventRecordCat,
+	"RecordBlocks":                       packet.SoundEventRecordBlocks,
+	"RecordChirp":                        packet.SoundEventRecordChirp,
+	"RecordFar":                          packet.SoundEventRecordFar,
+	"RecordMall":                         packet.SoundEventRecordMall,
+	"RecordMellohi":                      packet.SoundEventRecordMellohi,
+	"RecordStal":                         packet.SoundEventRecordStal,
+	"RecordStrad":                        packet.SoundEventRecordStrad,
+	"RecordWard":                         packet.SoundEventRecordWard,
+	"Record11":                           packet.SoundEventRecord11,
+	"RecordWait":                         packet.SoundEventRecordWait,
+	"RecordNull":                         packet.SoundEventRecordNull,
+	"Flop":                               packet.SoundEventFlop,
+	"GuardianCurse":                      packet.SoundEventGuardianCurse,
+	"MobWarning":                         packet.SoundEventMobWarning,
+	"MobWarningBaby":                     packet.SoundEventMobWarningBaby,
+	"Teleport":                           packet.SoundEventTeleport,
+	"ShulkerOpen":                        packet.SoundEventShulkerOpen,
+	"ShulkerClose":                       packet.SoundEventShulkerClose,
+	"Haggle":                             packet.SoundEventHaggle,
+	"HaggleYes":                          packet.SoundEventHaggleYes,
+	"HaggleNo":                           packet.SoundEventHaggleNo,
+	"HaggleIdle":                         packet.SoundEventHaggleIdle,
+	"ChorusGrow":                         packet.SoundEventChorusGrow,
+	"ChorusDeath":                        packet.SoundEventChorusDeath,
+	"Glass":                              packet.SoundEventGlass,
+	"PotionBrewed":                       packet.SoundEventPotionBrewed,
+	"CastSpell":                          packet.SoundEventCastSpell,
+	"PrepareAttackSpell":                 packet.SoundEventPrepareAttackSpell,
+	"PrepareSummon":                      packet.SoundEventPrepareSummon,
+	"PrepareWololo":                      packet.SoundEventPrepareWololo,
+	"Fang":                               packet.SoundEventFang,
+	"Charge":                             packet.SoundEventCharge,
+	"TakePicture":                        packet.SoundEventTakePicture,
+	"PlaceLeashKnot":                     packet.SoundEventPlaceLeashKnot,
+	"BreakLeashKnot":                     packet.SoundEventBreakLeashKnot,
+	"AmbientGrowl":                       packet.SoundEventAmbientGrowl,
+	"AmbientWhine":                       packet.SoundEventAmbientWhine,
+	"AmbientPant":                        packet.SoundEventAmbientPant,
+	"AmbientPurr":                        packet.SoundEventAmbientPurr,
+	"AmbientPurreow":                     packet.SoundEventAmbientPurreow,
+	"DeathMinVolume":                     packet.SoundEventDeathMinVolume,
+	"DeathMidVolume":                     packet.SoundEventDeathMidVolume,
+	"ImitateBlaze":                       packet.SoundEventImitateBlaze,
+	"ImitateCaveSpider":                  packet.SoundEventImitateCaveSpider,
+	"ImitateCreeper":                     packet.SoundEventImitateCreeper,
+	"ImitateElderGuardian":               packet.SoundEventImitateElderGuardian,
+	"ImitateEnderDragon":                 packet.SoundEventImitateEnderDragon,
+	"ImitateEnderman":                    packet.SoundEventImitateEnderman,
+	"ImitateEndermite":                   packet.SoundEventImitateEndermite,
+	"ImitateEvocationIllager":            packet.SoundEventImitateEvocationIllager,
+	"ImitateGhast":                       packet.SoundEventImitateGhast,
+	"ImitateHusk":                        packet.SoundEventImitateHusk,
+	"ImitateIllusionIllager":             packet.SoundEventImitateIllusionIllager,
+	"ImitateMagmaCube":                   packet.SoundEventImitateMagmaCube,
+	"ImitatePolarBear":                   packet.SoundEventImitatePolarBear,
+	"ImitateShulker":                     packet.SoundEventImitateShulker,
+	"ImitateSilverfish":                  packet.SoundEventImitateSilverfish,
+	"ImitateSkeleton":                    packet.SoundEventImitateSkeleton,
+	"ImitateSlime":                       packet.SoundEventImitateSlime,
+	"ImitateSpider":                      packet.SoundEventImitateSpider,
+	"ImitateStray":                       packet.SoundEventImitateStray,
+	"ImitateVex":                         packet.SoundEventImitateVex,
+	"ImitateVindicationIllager":          packet.SoundEventImitateVindicationIllager,
+	"ImitateWitch":                       packet.SoundEventImitateWitch,
+	"ImitateWither":                      packet.SoundEventImitateWither,
+	"ImitateWitherSkeleton":              packet.SoundEventImitateWitherSkeleton,
+	"ImitateWolf":                        packet.SoundEventImitateWolf,
+	"ImitateZombie":                      packet.SoundEventImitateZombie,
+	"ImitateZombiePigman":                packet.SoundEventImitateZombiePigman,
+	"ImitateZombieVillager":              packet.SoundEventImitateZombieVillager,
+	"EnderEyePlaced":                     packet.SoundEventEnderEyePlaced,
+	"EndPortalCreated":                   packet.SoundEventEndPortalCreated,
+	"AnvilUse":                           packet.SoundEventAnvilUse,
+	"BottleDragonBreath":                 packet.SoundEventBottleDragonBreath,
+	"PortalTravel":                       packet.SoundEventPortalTravel,
+	"TridentHit":                         packet.SoundEventTridentHit,
+	"TridentReturn":                      packet.SoundEventTridentReturn,
+	"TridentRiptide1":                    packet.SoundEventTridentRiptide1,
+	"TridentRiptide2":                    packet.SoundEventTridentRiptide2,
+	"TridentRiptide3":                    packet.SoundEventTridentRiptide3,
+	"TridentThrow":                       packet.SoundEventTridentThrow,
+	"TridentThunder":                     packet.SoundEventTridentThunder,
+	"TridentHitGround":                   packet.SoundEventTridentHitGround,
+	"Default":                            packet.SoundEventDefault,
+	"FletchingTableUse":                  packet.SoundEventFletchingTableUse,
+	"ElemConstructOpen":                  packet.SoundEventElemConstructOpen,
+	"IceBombHit":                         packet.SoundEventIceBombHit,
+	"BalloonPop":                         packet.SoundEventBalloonPop,
+	"LtReactionIceBomb":                  packet.SoundEventLtReactionIceBomb,
+	"LtReactionBleach":                   packet.SoundEventLtReactionBleach,
+	"LtReactionElephantToothpaste":       packet.SoundEventLtReactionElephantToothpaste,
+	"LtReactionElephantToothpaste2":      packet.SoundEventLtReactionElephantToothpaste2,
+	"LtReactionGlowStick":                packet.SoundEventLtReactionGlowStick,
+	"LtReactionGlowStick2":               packet.SoundEventLtReactionGlowStick2,
+	"LtReactionLuminol":                  packet.SoundEventLtReactionLuminol,
+	"LtReactionSalt":                     packet.SoundEventLtReactionSalt,
+	"LtReactionFertilizer":               packet.SoundEventLtReactionFertilizer,
+	"LtReactionFireball":                 packet.SoundEventLtReactionFireball,
+	"LtReactionMagnesiumSalt":            packet.SoundEventLtReactionMagnesiumSalt,
+	"LtReactionMiscFire":                 packet.SoundEventLtReactionMiscFire,
+	"LtReactionFire":                     packet.SoundEventLtReactionFire,
+	"LtReactionMiscExplosion":            packet.SoundEventLtReactionMiscExplosion,
+	"LtReactionMiscMystical":             packet.SoundEventLtReactionMiscMystical,
+	"LtReactionMiscMystical2":            packet.SoundEventLtReactionMiscMystical2,
+	"LtReactionProduct":                  packet.SoundEventLtReactionProduct,
+	"SparklerUse":                        packet.SoundEventSparklerUse,
+	"GlowStickUse":                       packet.SoundEventGlowStickUse,
+	"SparklerActive":                     packet.SoundEventSparklerActive,
+	"ConvertToDrowned":                   packet.SoundEventConvertToDrowned,
+	"BucketFillFish":                     packet.SoundEventBucketFillFish,
+	"BucketEmptyFish":                    packet.SoundEventBucketEmptyFish,
+	"BubbleColumnUpwards":                packet.SoundEventBubbleColumnUpwards,
+	"BubbleColumnDownwards":              packet.SoundEventBubbleColumnDownwards,
+	"BubblePop":                          packet.SoundEventBubblePop,
+	"BubbleUpInside":                     packet.SoundEventBubbleUpInside,
+	"BubbleDownInside":                   packet.SoundEventBubbleDownInside,
+	"HurtBaby":                           packet.SoundEventHurtBaby,
+	"DeathBaby":                          packet.SoundEventDeathBaby,
+	"StepBaby":                           packet.SoundEventStepBaby,
+	"SpawnBaby":                          packet.SoundEventSpawnBaby,
+	"Born":                               packet.SoundEventBorn,
+	"TurtleEggBreak":                     packet.SoundEventTurtleEggBreak,
+	"TurtleEggCrack":                     packet.SoundEventTurtleEggCrack,
+	"TurtleEggHatched":                   packet.SoundEventTurtleEggHatched,
+	"LayEgg":                             packet.SoundEventLayEgg,
+	"TurtleEggAttacked":                  packet.SoundEventTurtleEggAttacked,
+	"BeaconActivate":                     packet.SoundEventBeaconActivate,
+	"BeaconAmbient":                      packet.SoundEventBeaconAmbient,
+	"BeaconDeactivate":                   packet.SoundEventBeaconDeactivate,
+	"BeaconPower":                        packet.SoundEventBeaconPower,
+	"ConduitActivate":                    packet.SoundEventConduitActivate,
+	"ConduitAmbient":                     packet.SoundEventConduitAmbient,
+	"ConduitAttack":                      packet.SoundEventConduitAttack,
+	"ConduitDeactivate":                  packet.SoundEventConduitDeactivate,
+	"ConduitShort":                       packet.SoundEventConduitShort,
+	"Swoop":                              packet.SoundEventSwoop,
+	"BambooSaplingPlace":                 packet.SoundEventBambooSaplingPlace,
+	"PreSneeze":                          packet.SoundEventPreSneeze,
+	"Sneeze":                             packet.SoundEventSneeze,
+	"AmbientTame":                        packet.SoundEventAmbientTame,
+	"Scared":                             packet.SoundEventScared,
+	"ScaffoldingClimb":                   packet.SoundEventScaffoldingClimb,
+	"CrossbowLoadingStart":               packet.SoundEventCrossbowLoadingStart,
+	"CrossbowLoadingMiddle":              packet.SoundEventCrossbowLoadingMiddle,
+	"CrossbowLoadingEnd":                 packet.SoundEventCrossbowLoadingEnd,
+	"CrossbowShoot":                      packet.SoundEventCrossbowShoot,
+	"CrossbowQuickChargeStart":           packet.SoundEventCrossbowQuickChargeStart,
+	"CrossbowQuickChargeMiddle":          packet.SoundEventCrossbowQuickChargeMiddle,
+	"CrossbowQuickChargeEnd":             packet.SoundEventCrossbowQuickChargeEnd,
+	"AmbientAggressive":                  packet.SoundEventAmbientAggressive,
+	"AmbientWorried":                     packet.SoundEventAmbientWorried,
+	"CantBreed":                          packet.SoundEventCantBreed,
+	"ShieldBlock":                        packet.SoundEventShieldBlock,
+	"LecternBookPlace":                   packet.SoundEventLecternBookPlace,
+	"GrindstoneUse":                      packet.SoundEventGrindstoneUse,
+	"Bell":                               packet.SoundEventBell,
+	"CampfireCrackle":                    packet.SoundEventCampfireCrackle,
+	"Roar":                               packet.SoundEventRoar,
+	"Stun":                               packet.SoundEventStun,
+	"SweetBerryBushHurt":                 packet.SoundEventSweetBerryBushHurt,
+	"SweetBerryBushPick":                 packet.SoundEventSweetBerryBushPick,
+	"CartographyTableUse":                packet.SoundEventCartographyTableUse,
+	"StonecutterUse":                     packet.SoundEventStonecutterUse,
+	"ComposterEmpty":                     packet.SoundEventComposterEmpty,
+	"ComposterFill":                      packet.SoundEventComposterFill,
+	"ComposterFillLayer":                 packet.SoundEventComposterFillLayer,
+	"ComposterReady":                     packet.SoundEventComposterReady,
+	"BarrelOpen":                         packet.SoundEventBarrelOpen,
+	"BarrelClose":                        packet.SoundEventBarrelClose,
+	"RaidHorn":                           packet.SoundEventRaidHorn,
+	"LoomUse":                            packet.SoundEventLoomUse,
+	"AmbientInRaid":                      packet.SoundEventAmbientInRaid,
+	"UicartographyTableUse":              packet.SoundEventUicartographyTableUse,
+	"UistonecutterUse":                   packet.SoundEventUistonecutterUse,
+	"UiloomUse":                          packet.SoundEventUiloomUse,
+	"SmokerUse":                          packet.SoundEventSmokerUse,
+	"BlastFurnaceUse":                    packet.SoundEventBlastFurnaceUse,
+	"SmithingTableUse":                   packet.SoundEventSmithingTableUse,
+	"Screech":                            packet.SoundEventScreech,
+	"Sleep":                              packet.SoundEventSleep,
+	"FurnaceUse":                         packet.SoundEventFurnaceUse,
+	"MooshroomConvert":                   packet.SoundEventMooshroomConvert,
+	"MilkSuspiciously":                   packet.SoundEventMilkSuspiciously,
+	"Celebrate":                          packet.SoundEventCelebrate,
+	"JumpPrevent":                        packet.SoundEventJumpPrevent,
+	"AmbientPollinate":                   packet.SoundEventAmbientPollinate,
+	"BeehiveDrip":                        packet.SoundEventBeehiveDrip,
+	"BeehiveEnter":                       packet.SoundEventBeehiveEnter,
+	"BeehiveExit":                        packet.SoundEventBeehiveExit,
+	"BeehiveWork":                        packet.SoundEventBeehiveWork,
+	"BeehiveShear":                       packet.SoundEventBeehiveShear,
+	"HoneybottleDrink":                   packet.SoundEventHoneybottleDrink,
+	"AmbientCave":                        packet.SoundEventAmbientCave,
+	"Retreat":                            packet.SoundEventRetreat,
+	"ConvertToZombified":                 packet.SoundEventConvertToZombified,
+	"Admire":                             packet.SoundEventAdmire,
+	"StepLava":                           packet.SoundEventStepLava,
+	"Tempt":                              packet.SoundEventTempt,
+	"Panic":                              packet.SoundEventPanic,
+	"Angry":                              packet.SoundEventAngry,
+	"AmbientMoodWarpedForest":            packet.SoundEventAmbientMoodWarpedForest,
+	"AmbientMoodSoulsandValley":          packet.SoundEventAmbientMoodSoulsandValley,
+	"AmbientMoodNetherWastes":            packet.SoundEventAmbientMoodNetherWastes,
+	"AmbientMoodBasaltDeltas":            packet.SoundEventAmbientMoodBasaltDeltas,
+	"AmbientMoodCrimsonForest":           packet.SoundEventAmbientMoodCrimsonForest,
+	"RespawnAnchorCharge":                packet.SoundEventRespawnAnchorCharge,
+	"RespawnAnchorDeplete":               packet.SoundEventRespawnAnchorDeplete,
+	"RespawnAnchorSetSpawn":              packet.SoundEventRespawnAnchorSetSpawn,
+	"RespawnAnchorAmbient":               packet.SoundEventRespawnAnchorAmbient,
+	"SoulEscapeQuiet":                    packet.SoundEventSoulEscapeQuiet,
+	"SoulEscapeLoud":                     packet.SoundEventSoulEscapeLoud,
+	"RecordPigstep":                      packet.SoundEventRecordPigstep,
+	"LinkCompassToLodestone":             packet.SoundEventLinkCompassToLodestone,
+	"UseSmithingTable":                   packet.SoundEventUseSmithingTable,
+	"EquipNetherite":                     packet.SoundEventEquipNetherite,
+	"AmbientLoopWarpedForest":            packet.SoundEventAmbientLoopWarpedForest,
+	"AmbientLoopSoulsandValley":          packet.SoundEventAmbientLoopSoulsandValley,
+	"AmbientLoopNetherWastes":            packet.SoundEventAmbientLoopNetherWastes,
+	"AmbientLoopBasaltDeltas":            packet.SoundEventAmbientLoopBasaltDeltas,
+	"AmbientLoopCrimsonForest":           packet.SoundEventAmbientLoopCrimsonForest,
+	"AmbientAdditionWarpedForest":        packet.SoundEventAmbientAdditionWarpedForest,
+	"AmbientAdditionSoulsandValley":      packet.SoundEventAmbientAdditionSoulsandValley,
+	"AmbientAdditionNetherWastes":        packet.SoundEventAmbientAdditionNetherWastes,
+	"AmbientAdditionBasaltDeltas":        packet.SoundEventAmbientAdditionBasaltDeltas,
+	"AmbientAdditionCrimsonForest":       packet.SoundEventAmbientAdditionCrimsonForest,
+	"SculkSensorPowerOn":                 packet.SoundEventSculkSensorPowerOn,
+	"SculkSensorPowerOff":                packet.SoundEventSculkSensorPowerOff,
+	"BucketFillPowderSnow":               packet.SoundEventBucketFillPowderSnow,
+	"BucketEmptyPowderSnow":              packet.SoundEventBucketEmptyPowderSnow,
+	"PointedDripstoneCauldronDripWater":  packet.SoundEventPointedDripstoneCauldronDripWater,
+	"PointedDripstoneCauldronDripLava":   packet.SoundEventPointedDripstoneCauldronDripLava,
+	"PointedDripstoneDripWater":          packet.SoundEventPointedDripstoneDripWater,
+	"PointedDripstoneDripLava":           packet.SoundEventPointedDripstoneDripLava,
+	"CaveVinesPickBerries":               packet.SoundEventCaveVinesPickBerries,
+	"BigDripleafTiltDown":                packet.SoundEventBigDripleafTiltDown,
+	"BigDripleafTiltUp":                  packet.SoundEventBigDripleafTiltUp,
+	"CopperWaxOn":                        packet.SoundEventCopperWaxOn,
+	"CopperWaxOff":                       packet.SoundEventCopperWaxOff,
+	"Scrape":                             packet.SoundEventScrape,
+	"PlayerHurtDrown":                    packet.SoundEventPlayerHurtDrown,
+	"PlayerHurtOnFire":                   packet.SoundEventPlayerHurtOnFire,
+	"PlayerHurtFreeze":                   packet.SoundEventPlayerHurtFreeze,
+	"UseSpyglass":                        packet.SoundEventUseSpyglass,
+	"StopUsingSpyglass":                  packet.SoundEventStopUsingSpyglass,
+	"AmethystBlockChime":                 packet.SoundEventAmethystBlockChime,
+	"AmbientScreamer":                    packet.SoundEventAmbientScreamer,
+	"HurtScreamer":                       packet.SoundEventHurtScreamer,
+	"DeathScreamer":                      packet.SoundEventDeathScreamer,
+	"MilkScreamer":                       packet.SoundEventMilkScreamer,
+	"JumpToBlock":                        packet.SoundEventJumpToBlock,
+	"PreRam":                             packet.SoundEventPreRam,
+	"PreRamScreamer":                     packet.SoundEventPreRamScreamer,
+	"RamImpact":                          packet.SoundEventRamImpact,
+	"RamImpactScreamer":                  packet.SoundEventRamImpactScreamer,
+	"SquidInkSquirt":                     packet.SoundEventSquidInkSquirt,
+	"GlowSquidInkSquirt":                 packet.SoundEventGlowSquidInkSquirt,
+	"ConvertToStray":                     packet.SoundEventConvertToStray,
+	"CakeAddCandle":                      packet.SoundEventCakeAddCandle,
+	"ExtinguishCandle":                   packet.SoundEventExtinguishCandle,
+	"AmbientCandle":                      packet.SoundEventAmbientCandle,
+	"BlockClick":                         packet.SoundEventBlockClick,
+	"BlockClickFail":                     packet.SoundEventBlockClickFail,
+	"SculkCatalystBloom":                 packet.SoundEventSculkCatalystBloom,
+	"SculkShriekerShriek":                packet.SoundEventSculkShriekerShriek,
+	"WardenNearbyClose":                  packet.SoundEventWardenNearbyClose,
+	"WardenNearbyCloser":                 packet.SoundEventWardenNearbyCloser,
+	"WardenNearbyClosest":                packet.SoundEventWardenNearbyClosest,
+	"WardenSlightlyAngry":                packet.SoundEventWardenSlightlyAngry,
+	"RecordOtherside":                    packet.SoundEventRecordOtherside,
+	"Tongue":                             packet.SoundEventTongue,
+	"CrackIronGolem":                     packet.SoundEventCrackIronGolem,
+	"RepairIronGolem":                    packet.SoundEventRepairIronGolem,
+	"Listening":                          packet.SoundEventListening,
+	"Heartbeat":                          packet.SoundEventHeartbeat,
+	"HornBreak":                          packet.SoundEventHornBreak,
+	"SculkSpread":                        packet.SoundEventSculkSpread,
+	"SculkCharge":                        packet.SoundEventSculkCharge,
+	"SculkSensorPlace":                   packet.SoundEventSculkSensorPlace,
+	"SculkShriekerPlace":                 packet.SoundEventSculkShriekerPlace,
+	"GoatCall0":                          packet.SoundEventGoatCall0,
+	"GoatCall1":                          packet.SoundEventGoatCall1,
+	"GoatCall2":                          packet.SoundEventGoatCall2,
+	"GoatCall3":                          packet.SoundEventGoatCall3,
+	"GoatCall4":                          packet.SoundEventGoatCall4,
+	"GoatCall5":                          packet.SoundEventGoatCall5,
+	"GoatCall6":                          packet.SoundEventGoatCall6,
+	"GoatCall7":                          packet.SoundEventGoatCall7,
+	"ImitateWarden":                      packet.SoundEventImitateWarden,
+	"ListeningAngry":                     packet.SoundEventListeningAngry,
+	"ItemGiven":                          packet.SoundEventItemGiven,
+	"ItemTaken":                          packet.SoundEventItemTaken,
+	"Disappeared":                        packet.SoundEventDisappeared,
+	"Reappeared":                         packet.SoundEventReappeared,
+	"DrinkMilk":                          packet.SoundEventDrinkMilk,
+	"FrogspawnHatched":                   packet.SoundEventFrogspawnHatched,
+	"LaySpawn":                           packet.SoundEventLaySpawn,
+	"FrogspawnBreak":                     packet.SoundEventFrogspawnBreak,
+	"SonicBoom":                          packet.SoundEventSonicBoom,
+	"SonicCharge":                        packet.SoundEventSonicCharge,
+	"Record5":                            packet.SoundEventRecord5,
+	"ConvertToFrog":                      packet.SoundEventConvertToFrog,
+	"RecordPlaying":                      packet.SoundEventRecordPlaying,
+	"EnchantingTableUse":                 packet.SoundEventEnchantingTableUse,
+	"StepSand":                           packet.SoundEventStepSand,
+	"DashReady":                          packet.SoundEventDashReady,
+	"BundleDropContents":                 packet.SoundEventBundleDropContents,
+	"BundleInsert":                       packet.SoundEventBundleInsert,
+	"BundleRemoveOne":                    packet.SoundEventBundleRemoveOne,
+	"PressurePlateClickOff":              packet.SoundEventPressurePlateClickOff,
+	"PressurePlateClickOn":               packet.SoundEventPressurePlateClickOn,
+	"ButtonClickOff":                     packet.SoundEventButtonClickOff,
+	"ButtonClickOn":                      packet.SoundEventButtonClickOn,
+	"DoorOpen":                           packet.SoundEventDoorOpen,
+	"DoorClose":                          packet.SoundEventDoorClose,
+	"TrapdoorOpen":                       packet.SoundEventTrapdoorOpen,
+	"TrapdoorClose":                      packet.SoundEventTrapdoorClose,
+	"FenceGateOpen":                      packet.SoundEventFenceGateOpen,
+	"FenceGateClose":                     packet.SoundEventFenceGateClose,
+	"Insert":                             packet.SoundEventInsert,
+	"Pickup":                             packet.SoundEventPickup,
+	"InsertEnchanted":                    packet.SoundEventInsertEnchanted,
+	"PickupEnchanted":                    packet.SoundEventPickupEnchanted,
+	"Brush":                              packet.SoundEventBrush,
+	"BrushCompleted":                     packet.SoundEventBrushCompleted,
+	"ShatterDecoratedPot":                packet.SoundEventShatterDecoratedPot,
+	"BreakDecoratedPot":                  packet.SoundEventBreakDecoratedPot,
+	"SnifferEggCrack":                    packet.SoundEventSnifferEggCrack,
+	"SnifferEggHatched":                  packet.SoundEventSnifferEggHatched,
+	"WaxedSignInteractFail":              packet.SoundEventWaxedSignInteractFail,
+	"RecordRelic":                        packet.SoundEventRecordRelic,
+	"Bump":                               packet.SoundEventBump,
+	"PumpkinCarve":                       packet.SoundEventPumpkinCarve,
+	"ConvertHuskToZombie":                packet.SoundEventConvertHuskToZombie,
+	"PigDeath":                           packet.SoundEventPigDeath,
+	"HoglinZombified":                    packet.SoundEventHoglinZombified,
+	"AmbientUnderwaterEnter":             packet.SoundEventAmbientUnderwaterEnter,
+	"AmbientUnderwaterExit":              packet.SoundEventAmbientUnderwaterExit,
+	"BottleFill":                         packet.SoundEventBottleFill,
+	"BottleEmpty":                        packet.SoundEventBottleEmpty,
+	"CrafterCraft":                       packet.SoundEventCrafterCraft,
+	"CrafterFail":                        packet.SoundEventCrafterFail,
+	"DecoratedPotInsert":                 packet.SoundEventDecoratedPotInsert,
+	"DecoratedPotInsertFail":             packet.SoundEventDecoratedPotInsertFail,
+	"CrafterDisableSlot":                 packet.SoundEventCrafterDisableSlot,
+	"TrialSpawnerOpenShutter":            packet.SoundEventTrialSpawnerOpenShutter,
+	"TrialSpawnerEjectItem":              packet.SoundEventTrialSpawnerEjectItem,
+	"TrialSpawnerDetectPlayer":           packet.SoundEventTrialSpawnerDetectPlayer,
+	"TrialSpawnerSpawnMob":               packet.SoundEventTrialSpawnerSpawnMob,
+	"TrialSpawnerCloseShutter":           packet.SoundEventTrialSpawnerCloseShutter,
+	"TrialSpawnerAmbient":                packet.SoundEventTrialSpawnerAmbient,
+	"CopperBulbTurnOn":                   packet.SoundEventCopperBulbTurnOn,
+	"CopperBulbTurnOff":                  packet.SoundEventCopperBulbTurnOff,
+	"AmbientInAir":                       packet.SoundEventAmbientInAir,
+	"BreezeWindChargeBurst":              packet.SoundEventBreezeWindChargeBurst,
+	"ImitateBreeze":                      packet.SoundEventImitateBreeze,
+	"ArmadilloBrush":                     packet.SoundEventArmadilloBrush,
+	"ArmadilloScuteDrop":                 packet.SoundEventArmadilloScuteDrop,
+	"EquipWolf":                          packet.SoundEventEquipWolf,
+	"UnequipWolf":                        packet.SoundEventUnequipWolf,
+	"Reflect":                            packet.SoundEventReflect,
+	"VaultOpenShutter":                   packet.SoundEventVaultOpenShutter,
+	"VaultCloseShutter":                  packet.SoundEventVaultCloseShutter,
+	"VaultEjectItem":                     packet.SoundEventVaultEjectItem,
+	"VaultInsertItem":                    packet.SoundEventVaultInsertItem,
+	"VaultInsertItemFail":                packet.SoundEventVaultInsertItemFail,
+	"VaultAmbient":                       packet.SoundEventVaultAmbient,
+	"VaultActivate":                      packet.SoundEventVaultActivate,
+	"VaultDeactive":                      packet.SoundEventVaultDeactive,
+	"HurtReduced":                        packet.SoundEventHurtReduced,
+	"WindChargeBurst":                    packet.SoundEventWindChargeBurst,
+	"ImitateBogged":                      packet.SoundEventImitateBogged,
+	"WolfArmourCrack":                    packet.SoundEventWolfArmourCrack,
+	"WolfArmourBreak":                    packet.SoundEventWolfArmourBreak,
+	"WolfArmourRepair":                   packet.SoundEventWolfArmourRepair,
+	"MaceSmashAir":                       packet.SoundEventMaceSmashAir,
+	"MaceSmashGround":                    packet.SoundEventMaceSmashGround,
+	"TrialSpawnerChargeActivate":         packet.SoundEventTrialSpawnerChargeActivate,
+	"TrialSpawnerAmbientOminous":         packet.SoundEventTrialSpawnerAmbientOminous,
+	"OminiousItemSpawnerSpawnItem":       packet.SoundEventOminiousItemSpawnerSpawnItem,
+	"OminousBottleEndUse":                packet.SoundEventOminousBottleEndUse,
+	"MaceHeavySmashGround":               packet.SoundEventMaceHeavySmashGround,
+	"OminousItemSpawnerSpawnItemBegin":   packet.SoundEventOminousItemSpawnerSpawnItemBegin,
+	"ApplyEffectBadOmen":                 packet.SoundEventApplyEffectBadOmen,
+	"ApplyEffectRaidOmen":                packet.SoundEventApplyEffectRaidOmen,
+	"ApplyEffectTrialOmen":               packet.SoundEventApplyEffectTrialOmen,
+	"OminousItemSpawnerAboutToSpawnItem": packet.SoundEventOminousItemSpawnerAboutToSpawnItem,
+	"RecordCreator":                      packet.SoundEventRecordCreator,
+	"RecordCreatorMusicBox":              packet.SoundEventRecordCreatorMusicBox,
+	"RecordPrecipice":                    packet.SoundEventRecordPrecipice,
+	"VaultRejectRewardedPlayer":          packet.SoundEventVaultRejectRewardedPlayer,
+	"ImitateDrowned":                     packet.SoundEventImitateDrowned,
+	"BundleInsertFailed":                 packet.SoundEventBundleInsertFailed,
+}
@@ -0,0 +1,123 @@
+// Package sounds implements registries of the level sound events played using the LevelSoundEvent packet.
+// The IDs of level sound events change between protocol versions as sounds are added, so a Registry maps
+// the names of sounds, such as 'Hit', to their IDs for a single protocol version. The Registry of the
+// current protocol version is registered by default, and registries of other versions, for example those
+// used by a minecraft.Protocol, may be added using Register.
+package sounds
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Registry maps the names of level sound events to their IDs for a single protocol version. A Registry is
+// immutable once created and may be used concurrently by multiple goroutines.
+type Registry struct {
+	ids   map[string]uint32
+	names map[uint32]string
+}
+
+// NewRegistry creates a Registry holding the names and IDs passed.
+func NewRegistry(ids map[string]uint32) *Registry {
+	r := &Registry{ids: maps.Clone(ids), names: make(map[uint32]string, len(ids))}
+	for name, id := range ids {
+		r.names[id] = name
+	}
+	return r
+}
+
+// ID returns the ID of the sound with the name passed. If no sound with this name exists in the Registry,
+// false is returned.
+func (r *Registry) ID(name string) (uint32, bool) {
+	id, ok := r.ids[name]
+	return id, ok
+}
+
+// Name returns the name of the sound with the ID passed. If no sound with this ID exists in the Registry,
+// false is returned.
+func (r *Registry) Name(id uint32) (string, bool) {
+	name, ok := r.names[id]
+	return name, ok
+}
+
+// Names returns the names of all sounds in the Registry, ordered by ID.
+func (r *Registry) Names() []string {
+	ids := make([]uint32, 0, len(r.names))
+	for id := range r.names {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = r.names[id]
+	}
+	return names
+}
+
+// Event returns a LevelSoundEvent packet playing the sound with the name passed at the position passed. An
+// error is returned if no sound with this name exists in the Registry.
+func (r *Registry) Event(name string, pos mgl32.Vec3) (*packet.LevelSoundEvent, error) {
+	id, ok := r.ID(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown sound %q", name)
+	}
+	return Event(id, pos), nil
+}
+
+// Latest is the Registry of the current protocol version, holding the sounds of the SoundEvent constants in
+// the packet package.
+var Latest = NewRegistry(latestNames)
+
+var (
+	mu         sync.RWMutex
+	registries = map[int32]*Registry{protocol.CurrentProtocol: Latest}
+)
+
+// Register registers the Registry passed for the protocol version passed, replacing any Registry registered
+// for it before.
+func Register(protocolID int32, r *Registry) {
+	mu.Lock()
+	defer mu.Unlock()
+	registries[protocolID] = r
+}
+
+// ForProtocol returns the Registry registered for the protocol version passed. If no Registry was registered
+// for it, false is returned.
+func ForProtocol(protocolID int32) (*Registry, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := registries[protocolID]
+	return r, ok
+}
+
+// Event returns a LevelSoundEvent packet playing the sound with the ID passed, typically one of the
+// packet.SoundEvent constants, at the position passed. The sound has no extra data and is not played by an
+// entity.
+func Event(id uint32, pos mgl32.Vec3) *packet.LevelSoundEvent {
+	return &packet.LevelSoundEvent{SoundType: id, Position: pos, ExtraData: -1, EntityType: ":"}
+}
+
+// EntityEvent returns a LevelSoundEvent packet playing the sound with the ID passed as if played by an
+// entity of the type passed, such as 'minecraft:zombie', at the position passed. Sounds such as
+// packet.SoundEventAmbient and packet.SoundEventHurt depend on the entity type.
+func EntityEvent(id uint32, entityType string, baby bool, pos mgl32.Vec3) *packet.LevelSoundEvent {
+	return &packet.LevelSoundEvent{SoundType: id, Position: pos, ExtraData: -1, EntityType: entityType, BabyMob: baby}
+}
+
+// BlockEvent returns a LevelSoundEvent packet playing the sound with the ID passed for the block with the
+// runtime ID passed, at the position passed. Sounds such as packet.SoundEventPlace, packet.SoundEventBreak
+// and packet.SoundEventItemUseOn depend on the block.
+func BlockEvent(id uint32, blockRuntimeID uint32, pos mgl32.Vec3) *packet.LevelSoundEvent {
+	return &packet.LevelSoundEvent{SoundType: id, Position: pos, ExtraData: int32(blockRuntimeID), EntityType: ":"}
+}
+
+// Note returns a LevelSoundEvent packet playing a note block note with the instrument and pitch passed, at
+// the position passed. The pitch ranges from 0 to 24.
+func Note(instrument, pitch int32, pos mgl32.Vec3) *packet.LevelSoundEvent {
+	return &packet.LevelSoundEvent{SoundType: packet.SoundEventNote, Position: pos, ExtraData: instrument<<8 | pitch, EntityType: ":"}
+}